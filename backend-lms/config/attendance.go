@@ -0,0 +1,9 @@
+package config
+
+// Pengaturan absensi dan check-in QR.
+var (
+	// AttendanceTokenRotationSeconds adalah umur satu token QR sebelum diganti token baru.
+	AttendanceTokenRotationSeconds = getEnvInt("ATTENDANCE_TOKEN_ROTATION_SECONDS", 30)
+	// AttendanceCheckinMinutes adalah lama check-in dibuka jika guru tidak menentukan durasi.
+	AttendanceCheckinMinutes = getEnvInt("ATTENDANCE_CHECKIN_MINUTES", 15)
+)
@@ -0,0 +1,39 @@
+package config
+
+// Pengaturan hashing password. Algoritma default dipakai untuk semua hash baru,
+// hash lama dengan algoritma/parameter berbeda akan di-rehash saat login berhasil.
+var (
+	PasswordHashAlgorithm = getEnv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	Argon2Time            = getEnvInt("ARGON2_TIME", 3)
+	Argon2MemoryKiB       = getEnvInt("ARGON2_MEMORY_KIB", 64*1024)
+	Argon2Threads         = getEnvInt("ARGON2_THREADS", 2)
+	BcryptCost            = getEnvInt("BCRYPT_COST", 12)
+)
+
+// Pengaturan password policy untuk registrasi dan penggantian password.
+var (
+	PasswordMinLength = getEnvInt("PASSWORD_MIN_LENGTH", 8)
+	PasswordMaxLength = getEnvInt("PASSWORD_MAX_LENGTH", 128)
+	// BreachedPasswordsFile berisi satu password per baris, ditambahkan ke daftar bawaan.
+	BreachedPasswordsFile = getEnv("BREACHED_PASSWORDS_FILE", "")
+)
+
+// Pengaturan two-factor authentication (TOTP).
+var (
+	// MFAIssuer ditampilkan sebagai nama akun di aplikasi authenticator.
+	MFAIssuer = getEnv("MFA_ISSUER", "StudyMate")
+	// MFAChallengeTTLSeconds adalah masa berlaku token challenge antara langkah password dan kode.
+	MFAChallengeTTLSeconds = getEnvInt("MFA_CHALLENGE_TTL_SECONDS", 300)
+)
+
+// Pengaturan personal access token.
+var (
+	AccessTokenDefaultDays = getEnvInt("ACCESS_TOKEN_DEFAULT_DAYS", 90)
+	AccessTokenMaxDays     = getEnvInt("ACCESS_TOKEN_MAX_DAYS", 365)
+)
+
+// Deteksi percobaan login gagal beruntun (per username dan per IP).
+var (
+	FailedLoginBurstThreshold     = getEnvInt("FAILED_LOGIN_BURST_THRESHOLD", 5)
+	FailedLoginBurstWindowMinutes = getEnvInt("FAILED_LOGIN_BURST_WINDOW_MINUTES", 15)
+)
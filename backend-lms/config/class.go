@@ -0,0 +1,7 @@
+package config
+
+// Pengaturan kode kelas.
+var (
+	// ClassCodeLength adalah panjang kode kelas yang dibuat server (minimal 6).
+	ClassCodeLength = getEnvInt("CLASS_CODE_LENGTH", 7)
+)
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getEnv membaca environment variable dan mengembalikan fallback jika kosong.
+func getEnv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt sama seperti getEnv tetapi untuk nilai integer.
+func getEnvInt(key string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,15 @@
+package config
+
+// Pengaturan penandatanganan JWT. Token ditandatangani dengan private key (RS256 atau
+// EdDSA) dan diverifikasi dengan public key yang dipublikasikan di /.well-known/jwks.json.
+var (
+	JWTIssuer   = getEnv("JWT_ISSUER", "studymate")
+	JWTAudience = getEnv("JWT_AUDIENCE", "studymate-api")
+	// JWTAlgorithm: "RS256" atau "EdDSA"
+	JWTAlgorithm = getEnv("JWT_ALGORITHM", "RS256")
+	// JWTKeysDir menyimpan private key (PEM) supaya token tetap valid setelah restart.
+	// Jika kosong, key dibuat di memori setiap kali server start.
+	JWTKeysDir         = getEnv("JWT_KEYS_DIR", "")
+	JWTTokenTTLMinutes = getEnvInt("JWT_TOKEN_TTL_MINUTES", 60)
+	JWTRotationHours   = getEnvInt("JWT_ROTATION_HOURS", 24*30)
+)
@@ -0,0 +1,20 @@
+package config
+
+// Pengaturan login OpenID Connect. SSO aktif jika OIDC_ISSUER diisi. Issuer boleh
+// berupa IdP lokal (misalnya mock-oauth2-server di http://localhost:8081/default)
+// karena semua endpoint diambil dari discovery document.
+var (
+	OIDCIssuer       = getEnv("OIDC_ISSUER", "")
+	OIDCClientID     = getEnv("OIDC_CLIENT_ID", "")
+	OIDCClientSecret = getEnv("OIDC_CLIENT_SECRET", "")
+	OIDCRedirectURL  = getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback")
+	OIDCScopes       = getEnv("OIDC_SCOPES", "openid profile email groups")
+	// OIDCGroupsClaim adalah nama claim ID token yang berisi daftar group user.
+	OIDCGroupsClaim = getEnv("OIDC_GROUPS_CLAIM", "groups")
+	// OIDCRoleMapping memetakan group IdP ke role studymate, format "group=Role,group=Role".
+	OIDCRoleMapping = getEnv("OIDC_ROLE_MAPPING", "admins=Admin,teachers=Guru,students=Siswa")
+	OIDCDefaultRole = getEnv("OIDC_DEFAULT_ROLE", "Siswa")
+	// OIDCPostLoginRedirect adalah halaman frontend penerima token (via URL fragment).
+	// Jika kosong, callback mengembalikan token dalam JSON.
+	OIDCPostLoginRedirect = getEnv("OIDC_POST_LOGIN_REDIRECT", "")
+)
@@ -0,0 +1,5 @@
+package config
+
+// TrustedProxies berisi IP atau CIDR reverse proxy (dipisah koma). Header X-Forwarded-For dan
+// X-Real-IP hanya dipakai jika request datang dari salah satu alamat ini.
+var TrustedProxies = getEnv("TRUSTED_PROXIES", "")
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone dipakai untuk jadwal dan tanggal yang dikirim tanpa timezone (default WIB).
+var DefaultTimezone = getEnv("DEFAULT_TIMEZONE", "Asia/Jakarta")
+
+var defaultLocation *time.Location
+
+// InitTimezone memuat DefaultTimezone saat server start. Timezone yang tidak valid harus
+// menghentikan server, bukan diam-diam diganti UTC.
+func InitTimezone() error {
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid DEFAULT_TIMEZONE %q: %w", DefaultTimezone, err)
+	}
+	defaultLocation = loc
+	return nil
+}
+
+// DefaultLocation mengembalikan lokasi DefaultTimezone yang dimuat InitTimezone.
+func DefaultLocation() *time.Location {
+	if defaultLocation == nil {
+		if err := InitTimezone(); err != nil {
+			panic(err)
+		}
+	}
+	return defaultLocation
+}
@@ -0,0 +1,34 @@
+package dto
+
+// Tanggal di request tahun ajaran dan semester berformat YYYY-MM-DD.
+type AcademicYearRequest struct {
+	Name      string `json:"name" validate:"required"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+type TermRequest struct {
+	Name      string `json:"name" validate:"required"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// TermRolloverRequest mengaktifkan term baru. FromTermID default term yang sedang aktif;
+// semua kelas di term tersebut yang belum diarsipkan ikut diarsipkan.
+type TermRolloverRequest struct {
+	ToTermID   int  `json:"to_term_id" validate:"required"`
+	FromTermID *int `json:"from_term_id"`
+}
+
+type TermRolloverResponse struct {
+	Message         string `json:"message"`
+	FromTermID      *int   `json:"from_term_id"`
+	ToTermID        int    `json:"to_term_id"`
+	ArchivedClasses int    `json:"archived_classes"`
+}
+
+// ClassListFilter dipakai GET /classes. Kelas arsip tidak ditampilkan kecuali IncludeArchived.
+type ClassListFilter struct {
+	TermID          *int
+	IncludeArchived bool
+}
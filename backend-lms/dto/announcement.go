@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"project/model"
+	"time"
+)
+
+// AnnouncementRequest dipakai untuk membuat (multipart form, file di field "attachments")
+// dan mengubah pengumuman (JSON). PublishAt kosong berarti langsung terbit.
+type AnnouncementRequest struct {
+	Title       string             `json:"title"`
+	Content     string             `json:"content" validate:"required"`
+	PublishAt   *time.Time         `json:"publish_at"`
+	Pinned      bool               `json:"pinned"`
+	Attachments []model.Attachment `json:"-"`
+}
+
+// StreamItem adalah satu entri stream kelas: pengumuman, materi atau tugas.
+type StreamItem struct {
+	Type        string             `json:"type"`
+	ID          int                `json:"id"`
+	Title       string             `json:"title"`
+	Content     string             `json:"content"`
+	PublishedAt time.Time          `json:"published_at"`
+	Pinned      bool               `json:"pinned"`
+	Author      string             `json:"author,omitempty"`
+	UnitID      *int               `json:"unit_id,omitempty"`
+	DueDate     *time.Time         `json:"due_date,omitempty"`
+	Attachments []model.Attachment `json:"attachments"`
+}
+
+// ClassStream: Pinned selalu berisi pengumuman yang disematkan, Items terurut dari yang
+// terbaru. NextCursor diisi jika masih ada item lebih lama (kirim sebagai ?cursor=).
+type ClassStream struct {
+	ClassID    int          `json:"class_id"`
+	Pinned     []StreamItem `json:"pinned"`
+	Items      []StreamItem `json:"items"`
+	NextCursor *string      `json:"next_cursor"`
+}
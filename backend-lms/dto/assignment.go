@@ -6,6 +6,12 @@ type CreateAssignmentRequest struct {
     Description string `json:"description"`
     DueDate     string `json:"due_date"`
     Attachment  string `json:"attachment"`
+    UnitID      *int   `json:"unit_id"`
+    // LatePolicy: accept (default), penalty atau reject
+    LatePolicy         string  `json:"late_policy"`
+    LatePenaltyPercent float64 `json:"late_penalty_percent"`
+    // MaxPoints nilai maksimal tugas, default 100
+    MaxPoints float64 `json:"max_points"`
 }
 
 type AssignmentResponse struct {
@@ -22,4 +28,9 @@ type UpdateAssignmentRequest struct {
     Description string `json:"description"`
     DueDate     string `json:"due_date"`
     Attachment  string `json:"attachment"`
+    // LatePolicy kosong = kebijakan keterlambatan tidak diubah
+    LatePolicy         string  `json:"late_policy"`
+    LatePenaltyPercent float64 `json:"late_penalty_percent"`
+    // MaxPoints 0 = nilai maksimal tidak diubah
+    MaxPoints float64 `json:"max_points"`
 }
\ No newline at end of file
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"project/model"
+	"time"
+)
+
+// CreateSessionRequest membuat pertemuan di luar jadwal. Date YYYY-MM-DD, jam HH:MM.
+type CreateSessionRequest struct {
+	Date      string `json:"date" validate:"required"`
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+	Timezone  string `json:"timezone"`
+	Topic     string `json:"topic"`
+}
+
+// GenerateSessionsRequest membuat pertemuan dari jadwal kelas untuk rentang tanggal (YYYY-MM-DD).
+type GenerateSessionsRequest struct {
+	From string `json:"from" validate:"required"`
+	To   string `json:"to" validate:"required"`
+}
+
+type GenerateSessionsResponse struct {
+	Message  string               `json:"message"`
+	Created  int                  `json:"created"`
+	Sessions []model.ClassSession `json:"sessions"`
+}
+
+type AttendanceMark struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Status string `json:"status" validate:"required,oneof=hadir izin sakit alpa"`
+	Note   string `json:"note"`
+}
+
+// BulkAttendanceRequest menyimpan absensi banyak siswa sekaligus. MarkUnlisted (opsional)
+// dipakai untuk siswa yang tidak disebut dan belum punya status di pertemuan ini.
+type BulkAttendanceRequest struct {
+	Records      []AttendanceMark `json:"records" validate:"dive"`
+	MarkUnlisted string           `json:"mark_unlisted" validate:"omitempty,oneof=hadir izin sakit alpa"`
+}
+
+// SessionAttendanceEntry adalah satu siswa di daftar absen pertemuan. Status kosong jika belum diabsen.
+type SessionAttendanceEntry struct {
+	UserID   int        `json:"user_id"`
+	Username string     `json:"username"`
+	Status   string     `json:"status"`
+	Method   string     `json:"method,omitempty"`
+	Note     string     `json:"note,omitempty"`
+	MarkedAt *time.Time `json:"marked_at"`
+}
+
+type SessionAttendanceResponse struct {
+	Session  *model.ClassSession      `json:"session"`
+	Students []SessionAttendanceEntry `json:"students"`
+}
+
+type OpenCheckinRequest struct {
+	DurationMinutes int `json:"duration_minutes"`
+}
+
+// CheckinTokenResponse: Code adalah isi QR yang ditampilkan guru, berganti setiap ExpiresAt.
+type CheckinTokenResponse struct {
+	SessionID int       `json:"session_id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	OpenUntil time.Time `json:"open_until"`
+}
+
+type SelfCheckinRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type AttendanceTotals struct {
+	Hadir int `json:"hadir"`
+	Izin  int `json:"izin"`
+	Sakit int `json:"sakit"`
+	Alpa  int `json:"alpa"`
+}
+
+// AttendanceSummary adalah rekap kehadiran satu siswa di satu kelas. Percentage adalah
+// persentase hadir dari pertemuan yang sudah berlangsung.
+type AttendanceSummary struct {
+	ClassID  int    `json:"class_id"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	AttendanceTotals
+	Unrecorded int     `json:"unrecorded"`
+	Sessions   int     `json:"sessions"`
+	Percentage float64 `json:"percentage"`
+}
+
+type ClassAttendanceSummary struct {
+	ClassID  int                 `json:"class_id"`
+	Sessions int                 `json:"sessions"`
+	Students []AttendanceSummary `json:"students"`
+}
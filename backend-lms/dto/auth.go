@@ -0,0 +1,54 @@
+package dto
+
+// LoginResponse berisi JWT, atau MFA challenge token jika user harus memasukkan kode 2FA.
+type LoginResponse struct {
+	Token            string `json:"token,omitempty"`
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	MFAToken         string `json:"mfa_token,omitempty"`
+	ExpiresIn        int    `json:"expires_in,omitempty"`
+	MFASetupRequired bool   `json:"mfa_setup_required,omitempty"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// OIDCLinkConfirmRequest berisi link_code dari callback flow penautan akun SSO.
+type OIDCLinkConfirmRequest struct {
+	LinkCode string `json:"link_code" validate:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAPolicyRequest struct {
+	Required bool `json:"required"`
+}
+
+// ClientInfo berisi informasi request yang dicatat di riwayat login dan sesi.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=Admin Guru Siswa"`
+}
+
+// UpdateProfileRequest dipakai admin untuk mengisi data resmi user. Identifier adalah
+// NIS/NISN (siswa) atau NIP (guru), unik jika diisi.
+type UpdateProfileRequest struct {
+	FullName   string `json:"full_name" validate:"max=150"`
+	Identifier string `json:"identifier" validate:"max=50"`
+}
@@ -1,10 +1,22 @@
 package dto
 
+import (
+	"encoding/json"
+	"project/model"
+	"time"
+)
+
+// CreateClassRequest tidak menerima class_code; kode dibuat server. Jika schedules diisi,
+// jadwal_kelas dibuat otomatis dari jadwal tersebut.
 type CreateClassRequest struct {
-	Name        string `json:"name" validate:"required"`
-	JadwalKelas string `json:"jadwal_kelas" validate:"required"`
-	Teacher     string `json:"teacher"`
-	ClassCode   string `json:"class_code"`
+	Name        string          `json:"name" validate:"required"`
+	JadwalKelas string          `json:"jadwal_kelas"`
+	Teacher     string          `json:"teacher"`
+	Schedules   []ScheduleEntry `json:"schedules"`
+	// TermID default semester yang sedang aktif
+	TermID *int `json:"term_id"`
+	// IgnoreConflicts tetap menyimpan jadwal walaupun bentrok dengan kelas lain
+	IgnoreConflicts bool `json:"ignore_conflicts"`
 }
 
 type AssignmentsResponse struct {
@@ -14,17 +26,106 @@ type AssignmentsResponse struct {
 }
 
 type ClassResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	JadwalKelas string `json:"jadwal_kelas"`
-	CreatedAt   string `json:"created_at"`
-	Teacher     string `json:"teacher"`
-	ClassCode   string `json:"class_code"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	JadwalKelas string     `json:"jadwal_kelas"`
+	CreatedAt   string     `json:"created_at"`
+	Teacher     string     `json:"teacher"`
+	ClassCode   string     `json:"class_code,omitempty"`
+	TermID      *int       `json:"term_id"`
+	ArchivedAt  *time.Time `json:"archived_at"`
 }
 
+// UpdateClassRequest: field kosong tidak diubah. schedules null tidak diubah, [] menghapus jadwal.
 type UpdateClassRequest struct {
-	Name        string `json:"name"`
-	JadwalKelas string `json:"jadwal_kelas"`
-	Teacher     string `json:"teacher"`
-	ClassCode   string `json:"class_code"`
+	Name            string          `json:"name"`
+	JadwalKelas     string          `json:"jadwal_kelas"`
+	Teacher         string          `json:"teacher"`
+	Schedules       []ScheduleEntry `json:"schedules"`
+	TermID          *int            `json:"term_id"`
+	IgnoreConflicts bool            `json:"ignore_conflicts"`
+}
+
+// ClassCodeSettingsRequest mengatur kode kelas. expires_at dan max_uses null berarti tanpa batas;
+// field yang tidak dikirim tidak diubah (lihat ExpiresAtSet dan MaxUsesSet).
+type ClassCodeSettingsRequest struct {
+	Enabled   *bool      `json:"enabled"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   *int       `json:"max_uses"`
+
+	// ExpiresAtSet dan MaxUsesSet bernilai true jika key ada di body, termasuk yang bernilai null
+	ExpiresAtSet bool `json:"-"`
+	MaxUsesSet   bool `json:"-"`
+}
+
+func (r *ClassCodeSettingsRequest) UnmarshalJSON(data []byte) error {
+	type plain ClassCodeSettingsRequest
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	_, r.ExpiresAtSet = fields["expires_at"]
+	_, r.MaxUsesSet = fields["max_uses"]
+	return nil
+}
+
+type JoinClassByCodeRequest struct {
+	ClassCode string `json:"class_code" validate:"required"`
+	// Message opsional, ditampilkan ke guru jika kelas memakai mode approval
+	Message string `json:"message"`
+}
+
+// JoinClassResponse.Status berisi joined, already_member atau pending.
+type JoinClassResponse struct {
+	Status  string                  `json:"status"`
+	Message string                  `json:"message"`
+	ClassID int                     `json:"class_id"`
+	Request *model.ClassJoinRequest `json:"request,omitempty"`
+}
+
+type EnrollmentModeRequest struct {
+	Mode string `json:"mode" validate:"required,oneof=open approval"`
+}
+
+// ClassMemberRequest dipakai ManageClassMembers. Role default siswa.
+type ClassMemberRequest struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"omitempty,oneof=co_teacher assistant siswa"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID int `json:"user_id" validate:"required"`
+}
+
+// CloneClassRequest menyalin kelas ke kelas baru. StartDate (YYYY-MM-DD) adalah tanggal mulai
+// kelas baru; due date tugas digeser sebanyak selisihnya dengan tanggal mulai kelas asal.
+type CloneClassRequest struct {
+	Name      string `json:"name"`
+	TermID    *int   `json:"term_id"`
+	StartDate string `json:"start_date" validate:"required"`
+	// SourceStartDate default tanggal mulai semester kelas asal, atau tanggal kelas dibuat
+	SourceStartDate string `json:"source_start_date"`
+	ExcludeMembers  bool   `json:"exclude_members"`
+	ExcludeGrades   bool   `json:"exclude_grades"`
+}
+
+type ClonedCounts struct {
+	Schedules   int `json:"schedules"`
+	Units       int `json:"units"`
+	Materials   int `json:"materials"`
+	Assignments int `json:"assignments"`
+	Members     int `json:"members"`
+	Grades      int `json:"grades"`
+}
+
+type CloneClassResponse struct {
+	Message       string       `json:"message"`
+	SourceClassID int          `json:"source_class_id"`
+	Class         *model.Class `json:"class"`
+	Copied        ClonedCounts `json:"copied"`
+	// DueDateShiftDays adalah jumlah hari due date tugas digeser
+	DueDateShiftDays int `json:"due_date_shift_days"`
 }
@@ -37,6 +37,8 @@ type UserResponse struct {
     ID       int    `json:"id"`
     Username string `json:"username"`
     Role     string `json:"role"`
+    // ClassRole diisi pada daftar member kelas (owner, co_teacher, assistant, siswa)
+    ClassRole string `json:"class_role,omitempty"`
 }
 
 type MembersResponse struct {
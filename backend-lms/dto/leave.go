@@ -0,0 +1,29 @@
+package dto
+
+import "project/model"
+
+// CreateLeaveRequest dikirim sebagai multipart form bersama file "attachment" (opsional).
+// Tanggal berformat YYYY-MM-DD.
+type CreateLeaveRequest struct {
+	ClassID       *int   `json:"class_id"`
+	Type          string `json:"type" validate:"required,oneof=izin sakit"`
+	StartDate     string `json:"start_date" validate:"required"`
+	EndDate       string `json:"end_date" validate:"required"`
+	Reason        string `json:"reason" validate:"required"`
+	AttachmentURL string `json:"-"`
+}
+
+type ReviewLeaveRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note"`
+}
+
+// ReviewLeaveResponse: MarkedSessions adalah jumlah pertemuan yang absensinya diisi otomatis.
+type ReviewLeaveResponse struct {
+	Request        *model.LeaveRequest `json:"request"`
+	MarkedSessions int                 `json:"marked_sessions"`
+}
+
+type HomeroomRequest struct {
+	IsHomeroom bool `json:"is_homeroom"`
+}
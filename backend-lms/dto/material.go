@@ -19,6 +19,7 @@ type CreateMaterialRequest struct {
 	Content    string `json:"content" validate:"required"`
 	ClassID    int    `json:"-"`
 	Attachment string `json:"attachment"`
+	UnitID     *int   `json:"unit_id"`
 }
 
 type UpdateMaterialRequest struct {
@@ -0,0 +1,83 @@
+package dto
+
+import "project/model"
+
+// BankQuestionRequest: isi soal sama seperti soal kuis, ditambah tag untuk pencarian dan
+// pengambilan acak. Difficulty: easy, medium (default) atau hard.
+type BankQuestionRequest struct {
+	QuestionRequest
+	Subject    string `json:"subject"`
+	Topic      string `json:"topic"`
+	Difficulty string `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Competency string `json:"competency"`
+	Shared     bool   `json:"shared"`
+}
+
+// BankQuestionFilter memfilter daftar bank soal. Scope: mine, shared atau kosong untuk
+// semua soal yang bisa dipakai.
+type BankQuestionFilter struct {
+	Scope      string
+	Subject    string
+	Topic      string
+	Difficulty string
+	Competency string
+	Type       string
+	Search     string
+}
+
+type AddBankQuestionsRequest struct {
+	BankQuestionIDs []int `json:"bank_question_ids" validate:"required,min=1"`
+}
+
+// DrawRuleRequest: filter tag yang kosong berarti tidak dibatasi.
+type DrawRuleRequest struct {
+	Count      int     `json:"count" validate:"required,min=1,max=200"`
+	Points     float64 `json:"points" validate:"required,gt=0"`
+	Subject    string  `json:"subject"`
+	Topic      string  `json:"topic"`
+	Difficulty string  `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Competency string  `json:"competency"`
+}
+
+// DrawRulesRequest mengganti seluruh aturan pengambilan soal acak kuis.
+type DrawRulesRequest struct {
+	Rules []DrawRuleRequest `json:"rules" validate:"dive"`
+}
+
+// QuestionImportOptions berasal dari form import. Tag diberikan ke semua soal yang diimpor;
+// Topic kosong diisi dari kategori GIFT ($CATEGORY) jika ada.
+type QuestionImportOptions struct {
+	Format      string
+	FileName    string
+	Subject     string
+	Topic       string
+	Difficulty  string
+	Competency  string
+	Shared      bool
+	SkipInvalid bool
+}
+
+// ImportedQuestion adalah hasil baca satu soal dari file import beserta masalahnya.
+type ImportedQuestion struct {
+	Index int `json:"index"`
+	// Line baris awal soal (Aiken/GIFT), Source nama file item di paket QTI
+	Line           int             `json:"line,omitempty"`
+	Source         string          `json:"source,omitempty"`
+	Title          string          `json:"title,omitempty"`
+	Topic          string          `json:"topic,omitempty"`
+	Question       *model.Question `json:"question,omitempty"`
+	Errors         []FieldError    `json:"errors"`
+	Warnings       []string        `json:"warnings"`
+	BankQuestionID *int            `json:"bank_question_id,omitempty"`
+}
+
+// QuestionImportReport adalah laporan preview/import. Imported hanya terisi saat import.
+type QuestionImportReport struct {
+	Format    string             `json:"format"`
+	Total     int                `json:"total"`
+	Valid     int                `json:"valid"`
+	Invalid   int                `json:"invalid"`
+	Imported  int                `json:"imported"`
+	Errors    []FieldError       `json:"errors"`
+	Questions []ImportedQuestion `json:"questions"`
+}
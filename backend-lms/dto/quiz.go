@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"project/model"
+	"time"
+)
+
+// QuizRequest: TimeLimitMinutes null untuk tanpa batas waktu, MaxAttempts 0 untuk percobaan
+// tidak terbatas.
+type QuizRequest struct {
+	Title            string     `json:"title" validate:"required"`
+	Description      string     `json:"description"`
+	TimeLimitMinutes *int       `json:"time_limit_minutes" validate:"omitempty,min=1,max=1440"`
+	OpensAt          *time.Time `json:"opens_at"`
+	ClosesAt         *time.Time `json:"closes_at"`
+	MaxAttempts      int        `json:"max_attempts" validate:"min=0,max=100"`
+	ShuffleQuestions bool       `json:"shuffle_questions"`
+	ShuffleOptions   bool       `json:"shuffle_options"`
+	ShowAnswers      bool       `json:"show_answers"`
+	Published        bool       `json:"published"`
+}
+
+// QuestionRequest: untuk soal benar/salah cukup isi CorrectAnswer; opsi dibuat otomatis.
+// ID opsi yang kosong diisi a, b, c, dan seterusnya.
+type QuestionRequest struct {
+	model.Question
+	CorrectAnswer *bool `json:"correct_answer,omitempty"`
+}
+
+// SaveAnswersRequest menyimpan jawaban sementara; item yang tidak disebut tidak berubah.
+type SaveAnswersRequest struct {
+	Answers []ItemAnswer `json:"answers" validate:"required,dive"`
+}
+
+type ItemAnswer struct {
+	ItemID   int                    `json:"item_id" validate:"required"`
+	Response model.QuestionResponse `json:"response"`
+}
+
+// GradeItemRequest menilai soal esai (atau mengoreksi nilai otomatis) secara manual.
+type GradeItemRequest struct {
+	Points   *float64 `json:"points" validate:"required,min=0"`
+	Feedback string   `json:"feedback"`
+}
+
+// QuizResultEntry adalah ringkasan hasil satu siswa untuk guru.
+type QuizResultEntry struct {
+	UserID       int        `json:"user_id"`
+	Username     string     `json:"username"`
+	FullName     string     `json:"full_name"`
+	AttemptCount int        `json:"attempt_count"`
+	BestScore    *float64   `json:"best_score"`
+	MaxScore     float64    `json:"max_score"`
+	NeedsGrading int        `json:"needs_grading"`
+	LastAttempt  *time.Time `json:"last_attempt"`
+}
+
+type QuizResults struct {
+	QuizID   int               `json:"quiz_id"`
+	Students []QuizResultEntry `json:"students"`
+}
+
+// MyQuiz adalah kuis di daftar siswa dengan ringkasan percobaannya.
+type MyQuiz struct {
+	model.Quiz
+	AttemptsUsed int      `json:"attempts_used"`
+	BestScore    *float64 `json:"best_score"`
+	InProgressID *int     `json:"in_progress_attempt_id"`
+	IsOpen       bool     `json:"is_open"`
+}
@@ -1,6 +1,11 @@
 package dto
 
+// RapotResponse: Grade adalah rata-rata dari GradeCount nilai di buku nilai. Attendance
+// adalah jumlah status kehadiran siswa di kelas tersebut.
 type RapotResponse struct {
-    ClassName string `json:"class_name"`
-    Grade     int    `json:"grade"`
+    ClassID    int              `json:"class_id"`
+    ClassName  string           `json:"class_name"`
+    Grade      int              `json:"grade"`
+    GradeCount int              `json:"grade_count"`
+    Attendance AttendanceTotals `json:"attendance"`
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// RosterEntry adalah satu siswa di roster kelas. AttendancePercentage dan AverageGrade
+// null jika belum ada pertemuan atau nilai.
+type RosterEntry struct {
+	UserID               int       `json:"user_id"`
+	Username             string    `json:"username"`
+	FullName             string    `json:"full_name"`
+	Identifier           string    `json:"identifier"`
+	JoinedAt             time.Time `json:"joined_at"`
+	AttendancePercentage *float64  `json:"attendance_percentage"`
+	AverageGrade         *float64  `json:"average_grade"`
+}
+
+type ClassRoster struct {
+	ClassID   int           `json:"class_id"`
+	ClassName string        `json:"class_name"`
+	Teacher   string        `json:"teacher"`
+	Term      string        `json:"term"`
+	Students  []RosterEntry `json:"students"`
+}
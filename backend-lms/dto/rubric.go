@@ -0,0 +1,40 @@
+package dto
+
+type RubricRequest struct {
+	Title       string                   `json:"title" validate:"required"`
+	Description string                   `json:"description"`
+	Criteria    []RubricCriterionRequest `json:"criteria" validate:"required,min=1,dive"`
+}
+
+type RubricCriterionRequest struct {
+	Title       string               `json:"title" validate:"required"`
+	Description string               `json:"description"`
+	Levels      []RubricLevelRequest `json:"levels" validate:"required,min=1,dive"`
+}
+
+type RubricLevelRequest struct {
+	Title       string  `json:"title" validate:"required"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points" validate:"min=0"`
+}
+
+// AttachRubricRequest: RubricID null melepas rubrik dari tugas.
+type AttachRubricRequest struct {
+	RubricID *int `json:"rubric_id"`
+}
+
+// RubricGradeRequest menilai pengumpulan per kriteria; semua kriteria harus diisi.
+type RubricGradeRequest struct {
+	Scores   []RubricScoreRequest `json:"scores" validate:"required,min=1,dive"`
+	Feedback string               `json:"feedback"`
+	Return   bool                 `json:"return"`
+}
+
+// RubricScoreRequest: Points kosong berarti memakai poin level yang dipilih. Points boleh
+// diisi tanpa level selama tidak melebihi poin maksimal kriteria.
+type RubricScoreRequest struct {
+	CriterionID int      `json:"criterion_id" validate:"required"`
+	LevelID     *int     `json:"level_id"`
+	Points      *float64 `json:"points" validate:"omitempty,min=0"`
+	Comment     string   `json:"comment"`
+}
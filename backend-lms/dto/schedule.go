@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// ScheduleEntry adalah input satu pertemuan mingguan. day_of_week: 1 = Senin ... 7 = Minggu,
+// jam berformat HH:MM. Timezone default DEFAULT_TIMEZONE.
+type ScheduleEntry struct {
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Room      string `json:"room"`
+	Timezone  string `json:"timezone"`
+}
+
+// ScheduleConflict menjelaskan bentrok jadwal dengan kelas lain.
+// Type berisi teacher, room atau student.
+type ScheduleConflict struct {
+	Type      string `json:"type"`
+	ClassID   int    `json:"class_id"`
+	ClassName string `json:"class_name"`
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Room      string `json:"room,omitempty"`
+	// Students adalah jumlah siswa yang terdaftar di kedua kelas (untuk type student)
+	Students int `json:"students,omitempty"`
+}
+
+type ScheduleConflictResponse struct {
+	Status    string             `json:"status"`
+	Message   string             `json:"message"`
+	Conflicts []ScheduleConflict `json:"conflicts"`
+}
+
+type ScheduleExceptionRequest struct {
+	Date   string `json:"date" validate:"required"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+// TimetableEntry adalah satu pertemuan pada tanggal tertentu di /me/timetable.
+type TimetableEntry struct {
+	Date      string    `json:"date"`
+	ClassID   int       `json:"class_id"`
+	ClassName string    `json:"class_name"`
+	ClassRole string    `json:"class_role"`
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+	Room      string    `json:"room"`
+	Cancelled bool      `json:"cancelled"`
+	Reason    string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"project/model"
+	"time"
+)
+
+// SaveSubmissionRequest menyimpan draft jawaban (multipart form: text_answer dan file "files").
+// TextAnswer nil berarti teks tidak diubah.
+type SaveSubmissionRequest struct {
+	TextAnswer *string
+	Files      []model.Attachment
+}
+
+// SubmissionListEntry adalah satu siswa di daftar pengumpulan guru. Status "not_submitted"
+// untuk siswa yang belum mengumpulkan (termasuk yang baru menyimpan draft).
+type SubmissionListEntry struct {
+	UserID       int        `json:"user_id"`
+	Username     string     `json:"username"`
+	FullName     string     `json:"full_name"`
+	SubmissionID *int       `json:"submission_id"`
+	Status       string     `json:"status"`
+	Attempt      int        `json:"attempt"`
+	FileCount    int        `json:"file_count"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+	LateDays     int        `json:"late_days"`
+	Points       *float64   `json:"points"`
+}
+
+type AssignmentSubmissions struct {
+	AssignmentID int                   `json:"assignment_id"`
+	Counts       map[string]int        `json:"counts"`
+	Students     []SubmissionListEntry `json:"students"`
+}
+
+// GradeSubmissionRequest: Points antara 0 dan max_points tugas. Potongan keterlambatan
+// diterapkan otomatis saat nilai masuk ke buku nilai.
+type GradeSubmissionRequest struct {
+	Points   *float64 `json:"points" validate:"required"`
+	Feedback string   `json:"feedback"`
+	// Return langsung mengembalikan pengumpulan ke siswa setelah dinilai
+	Return bool `json:"return"`
+}
+
+// SubmissionCommentRequest: FileID kosong untuk komentar umum. Page dan Anchor (misalnya
+// kutipan teks yang dikomentari) menunjuk lokasi di dalam file.
+type SubmissionCommentRequest struct {
+	FileID *int   `json:"file_id"`
+	Page   *int   `json:"page" validate:"omitempty,min=1"`
+	Anchor string `json:"anchor"`
+	Body   string `json:"body" validate:"required"`
+}
+
+// SimilarityCheckRequest: Threshold (0-1, default 0.2) adalah porsi minimal teks pengumpulan
+// yang juga ada di pengumpulan lain. OtherClasses (default false) ikut membandingkan dengan
+// tugas berjudul sama di kelas lain yang juga bisa dinilai pemeriksa, termasuk semester sebelumnya.
+type SimilarityCheckRequest struct {
+	Threshold    *float64 `json:"threshold" validate:"omitempty,gt=0,lte=1"`
+	OtherClasses *bool    `json:"other_classes"`
+}
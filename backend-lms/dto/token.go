@@ -0,0 +1,16 @@
+package dto
+
+import "project/model"
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+	// ExpiresInDays default 90 hari, maksimal 365 hari
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// CreateAccessTokenResponse berisi token plain text yang hanya ditampilkan sekali.
+type CreateAccessTokenResponse struct {
+	Token       string                     `json:"token"`
+	AccessToken *model.PersonalAccessToken `json:"access_token"`
+}
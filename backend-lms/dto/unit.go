@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"project/model"
+	"time"
+)
+
+type UnitRequest struct {
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+}
+
+type ReorderUnitsRequest struct {
+	UnitIDs []int `json:"unit_ids" validate:"required"`
+}
+
+// OutlineItemRef menunjuk satu materi atau tugas. Type berisi material atau assignment.
+type OutlineItemRef struct {
+	Type string `json:"type" validate:"required,oneof=material assignment"`
+	ID   int    `json:"id" validate:"required"`
+}
+
+// ReorderUnitItemsRequest berisi seluruh isi unit dalam urutan baru. Item dari unit lain
+// ikut dipindahkan ke unit ini; item lama yang tidak disebut dikeluarkan dari unit.
+type ReorderUnitItemsRequest struct {
+	Items []OutlineItemRef `json:"items" validate:"dive"`
+}
+
+// MoveOutlineItemRequest memindahkan satu item ke unit (null = tanpa unit) pada posisi
+// tertentu (mulai dari 1). Posisi di luar jangkauan menempatkan item di akhir.
+type MoveOutlineItemRequest struct {
+	OutlineItemRef
+	UnitID   *int `json:"unit_id"`
+	Position int  `json:"position"`
+}
+
+type OutlineItem struct {
+	Type       string     `json:"type"`
+	ID         int        `json:"id"`
+	Title      string     `json:"title"`
+	Position   int        `json:"position"`
+	Attachment string     `json:"attachment,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type OutlineUnit struct {
+	model.ClassUnit
+	Items []OutlineItem `json:"items"`
+}
+
+// ClassOutline adalah seluruh struktur kelas: unit berurutan beserta isinya, lalu item tanpa unit.
+type ClassOutline struct {
+	ClassID    int           `json:"class_id"`
+	Units      []OutlineUnit `json:"units"`
+	Unassigned []OutlineItem `json:"unassigned"`
+}
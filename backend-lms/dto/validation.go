@@ -0,0 +1,14 @@
+package dto
+
+// FieldError menjelaskan satu pelanggaran validasi pada sebuah field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Status  string       `json:"status"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type AcademicHandler struct {
+	Service *service.AcademicService
+}
+
+func NewAcademicHandler(service *service.AcademicService) *AcademicHandler {
+	return &AcademicHandler{Service: service}
+}
+
+// GetAcademicYears - Semua tahun ajaran beserta semesternya
+func (h *AcademicHandler) GetAcademicYears(w http.ResponseWriter, r *http.Request) {
+	years, err := h.Service.GetAcademicYears()
+	if err != nil {
+		http.Error(w, "Failed to get academic years: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(years)
+}
+
+// GetActiveTerm - Semester yang sedang berjalan, 404 jika belum ada
+func (h *AcademicHandler) GetActiveTerm(w http.ResponseWriter, r *http.Request) {
+	term, err := h.Service.GetActiveTerm()
+	if err != nil {
+		http.Error(w, "Failed to get active term: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if term == nil {
+		http.Error(w, "No active term", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(term)
+}
+
+func (h *AcademicHandler) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcademicYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	year, err := h.Service.CreateAcademicYear(req)
+	if err != nil {
+		writeAcademicError(w, err, "Failed to create academic year")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(year)
+}
+
+func (h *AcademicHandler) CreateTerm(w http.ResponseWriter, r *http.Request) {
+	yearID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid academic year ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.TermRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	term, err := h.Service.CreateTerm(yearID, req)
+	if err != nil {
+		writeAcademicError(w, err, "Failed to create term")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(term)
+}
+
+// RolloverTerm - Mengaktifkan semester baru dan mengarsipkan semua kelas semester sebelumnya
+func (h *AcademicHandler) RolloverTerm(w http.ResponseWriter, r *http.Request) {
+	var req dto.TermRolloverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.Service.RolloverTerm(req)
+	if err != nil {
+		writeAcademicError(w, err, "Failed to roll over term")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeAcademicError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrAcademicYearNotFound), errors.Is(err, service.ErrTermNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrDuplicateTerm):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/middleware"
+	"project/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AnnouncementHandler struct {
+	Service *service.AnnouncementService
+}
+
+func NewAnnouncementHandler(service *service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{Service: service}
+}
+
+// GetAnnouncements - Pengumuman kelas. Pengelola konten juga melihat yang masih terjadwal
+func (h *AnnouncementHandler) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	includeScheduled := hasClassPermission(r, classID, middleware.ClassPermManageContent)
+	announcements, err := h.Service.GetAnnouncements(classID, includeScheduled)
+	if err != nil {
+		http.Error(w, "Failed to get announcements: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(announcements)
+}
+
+// CreateAnnouncement - Multipart form: title, content (HTML), publish_at (RFC3339, opsional),
+// pinned (true/false) dan file attachments (boleh lebih dari satu)
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	req := dto.AnnouncementRequest{
+		Title:   r.FormValue("title"),
+		Content: r.FormValue("content"),
+		Pinned:  r.FormValue("pinned") == "true",
+	}
+	if value := r.FormValue("publish_at"); value != "" {
+		publishAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid publish_at, use RFC3339 format", http.StatusBadRequest)
+			return
+		}
+		req.PublishAt = &publishAt
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Attachments, err = uploadFormFiles(r, "attachments")
+	if err != nil {
+		http.Error(w, "Failed to upload file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	announcement, err := h.Service.CreateAnnouncement(classID, userID, req)
+	if err != nil {
+		writeAnnouncementError(w, err, "Failed to create announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(announcement)
+}
+
+// UpdateAnnouncement - JSON: title, content, publish_at
+func (h *AnnouncementHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	classID, announcementID, ok := announcementVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	announcement, err := h.Service.UpdateAnnouncement(classID, announcementID, req)
+	if err != nil {
+		writeAnnouncementError(w, err, "Failed to update announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(announcement)
+}
+
+func (h *AnnouncementHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	classID, announcementID, ok := announcementVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteAnnouncement(classID, announcementID); err != nil {
+		writeAnnouncementError(w, err, "Failed to delete announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Announcement deleted successfully",
+	})
+}
+
+func (h *AnnouncementHandler) PinAnnouncement(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+func (h *AnnouncementHandler) UnpinAnnouncement(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+// AddAttachments - Menambah file ke pengumuman (multipart, field attachments)
+func (h *AnnouncementHandler) AddAttachments(w http.ResponseWriter, r *http.Request) {
+	classID, announcementID, ok := announcementVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+	attachments, err := uploadFormFiles(r, "attachments")
+	if err != nil {
+		http.Error(w, "Failed to upload file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(attachments) == 0 {
+		http.Error(w, "Unable to retrieve file", http.StatusBadRequest)
+		return
+	}
+
+	announcement, err := h.Service.AddAttachments(classID, announcementID, attachments)
+	if err != nil {
+		writeAnnouncementError(w, err, "Failed to add attachments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(announcement)
+}
+
+func (h *AnnouncementHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	classID, announcementID, ok := announcementVars(w, r)
+	if !ok {
+		return
+	}
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachment_id"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteAttachment(classID, announcementID, attachmentID); err != nil {
+		writeAnnouncementError(w, err, "Failed to delete attachment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// GetStream - Pengumuman, materi dan tugas kelas terbaru dulu. Query: limit, cursor (next_cursor)
+func (h *AnnouncementHandler) GetStream(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var cursor *service.StreamCursor
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		if cursor, err = service.ParseStreamCursor(value); err != nil {
+			http.Error(w, "Invalid cursor, use next_cursor from the previous page", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	stream, err := h.Service.GetStream(classID, cursor, limit)
+	if err != nil {
+		http.Error(w, "Failed to get class stream: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stream)
+}
+
+func (h *AnnouncementHandler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	classID, announcementID, ok := announcementVars(w, r)
+	if !ok {
+		return
+	}
+
+	announcement, err := h.Service.SetPinned(classID, announcementID, pinned)
+	if err != nil {
+		writeAnnouncementError(w, err, "Failed to pin announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(announcement)
+}
+
+func announcementVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	announcementID, err := strconv.Atoi(vars["announcement_id"])
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return classID, announcementID, true
+}
+
+func writeAnnouncementError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrAnnouncementNotFound):
+		http.Error(w, "Announcement not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -3,9 +3,11 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"project/dto"
+	"project/middleware"
 	"project/service"
 	"project/utils"
 	"strconv"
@@ -15,6 +17,8 @@ import (
 
 type AssignmentHandler struct {
     Service *service.AssignmentService
+    // Submissions menerima pengumpulan siswa dari client lama lewat CreateAssignment
+    Submissions *SubmissionHandler
 }
 
 // CreateAssignment - Membuat tugas baru
@@ -30,6 +34,16 @@ func (h *AssignmentHandler) CreateAssignment(w http.ResponseWriter, r *http.Requ
         return
     }
 
+    // Frontend lama masih mengumpulkan tugas siswa lewat endpoint ini
+    if role, _ := r.Context().Value("role").(string); role == "Siswa" {
+        h.Submissions.SubmitLegacy(w, r, classID)
+        return
+    }
+    if !hasClassPermission(r, classID, middleware.ClassPermManageContent) {
+        http.Error(w, "Forbidden: your role in this class does not allow this action", http.StatusForbidden)
+        return
+    }
+
     // Parse multipart form
     err = r.ParseMultipartForm(10 << 20) // 10 MB
     if err != nil {
@@ -62,7 +76,23 @@ func (h *AssignmentHandler) CreateAssignment(w http.ResponseWriter, r *http.Requ
     req.Description = r.FormValue("description")
     req.DueDate = r.FormValue("due_date")
     req.ClassID = classID
+    req.UnitID, err = formUnitID(r)
+    if err != nil {
+        http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+        return
+    }
     req.Attachment = fileURL
+    req.LatePolicy = r.FormValue("late_policy")
+    req.LatePenaltyPercent, err = formFloat(r, "late_penalty_percent")
+    if err != nil {
+        http.Error(w, "Invalid late_penalty_percent", http.StatusBadRequest)
+        return
+    }
+    req.MaxPoints, err = formFloat(r, "max_points")
+    if err != nil {
+        http.Error(w, "Invalid max_points", http.StatusBadRequest)
+        return
+    }
 
     // Ambil user_id dari context
     userID, ok := r.Context().Value("id").(int)
@@ -74,6 +104,14 @@ func (h *AssignmentHandler) CreateAssignment(w http.ResponseWriter, r *http.Requ
     // Panggil service untuk membuat assignment
     assignment, err := h.Service.CreateAssignment(req, userID)
     if err != nil {
+        if validationErr, ok := service.AsValidationError(err); ok {
+            writeValidationError(w, validationErr)
+            return
+        }
+        if errors.Is(err, service.ErrUnitNotFound) {
+            http.Error(w, "Unit not found", http.StatusBadRequest)
+            return
+        }
         http.Error(w, "Failed to create assignment: "+err.Error(), http.StatusInternalServerError)
         return
     }
@@ -121,9 +159,9 @@ func (h *AssignmentHandler) GetAssignmentsByClass(w http.ResponseWriter, r *http
 
 func (h *AssignmentHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
     vars := mux.Vars(r)
-    assignmentID := vars["id"]
+    assignmentID := vars["assignment_id"]
 
-    if err := h.Service.DeleteAssignment(assignmentID); err != nil {
+    if err := h.Service.DeleteAssignment(vars["class_id"], assignmentID); err != nil {
         if err == sql.ErrNoRows {
             http.Error(w, "Assignment not found", http.StatusNotFound)
         } else {
@@ -203,11 +241,24 @@ func (h *AssignmentHandler) UpdateAssignment(w http.ResponseWriter, r *http.Requ
     req.Description = r.FormValue("description")
     req.DueDate = r.FormValue("due_date")
     req.Attachment = fileURL
+    req.LatePolicy = r.FormValue("late_policy")
+    req.LatePenaltyPercent, err = formFloat(r, "late_penalty_percent")
+    if err != nil {
+        http.Error(w, "Invalid late_penalty_percent", http.StatusBadRequest)
+        return
+    }
+    req.MaxPoints, err = formFloat(r, "max_points")
+    if err != nil {
+        http.Error(w, "Invalid max_points", http.StatusBadRequest)
+        return
+    }
 
     // Panggil service untuk memperbarui assignment
     assignment, err := h.Service.UpdateAssignment(classID, assignmentID, req)
     if err != nil {
-        if err == sql.ErrNoRows {
+        if validationErr, ok := service.AsValidationError(err); ok {
+            writeValidationError(w, validationErr)
+        } else if err == sql.ErrNoRows {
             http.Error(w, "Assignment not found", http.StatusNotFound)
         } else {
             fmt.Println(err)
@@ -238,6 +289,12 @@ func (h *AssignmentHandler) GetAssignmentsByUserID(w http.ResponseWriter, r *htt
         return
     }
 
+    // Siswa hanya boleh melihat pengumpulannya sendiri
+    if currentID, _ := r.Context().Value("id").(int); currentID != userID {
+        http.Error(w, "Forbidden: you can only view your own assignments", http.StatusForbidden)
+        return
+    }
+
     assignments, err := h.Service.GetAssignmentsByUserID(userID, classID)
     if err != nil {
         http.Error(w, "Failed to fetch assignments: "+err.Error(), http.StatusInternalServerError)
@@ -267,4 +324,13 @@ func (h *AssignmentHandler) CountAssignmentsCreatedByUser(w http.ResponseWriter,
     response := map[string]string{"assignment": strconv.Itoa(count)}
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// formFloat membaca field angka opsional dari form (misalnya late_penalty_percent, max_points)
+func formFloat(r *http.Request, field string) (float64, error) {
+    value := r.FormValue(field)
+    if value == "" {
+        return 0, nil
+    }
+    return strconv.ParseFloat(value, 64)
+}
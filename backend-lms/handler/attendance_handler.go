@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AttendanceHandler struct {
+	Service *service.AttendanceService
+}
+
+func NewAttendanceHandler(service *service.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{Service: service}
+}
+
+// GetSessions - Daftar pertemuan kelas. Query opsional: from, to (YYYY-MM-DD)
+func (h *AttendanceHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var from, to *time.Time
+	for name, target := range map[string]**time.Time{"from": &from, "to": &to} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid "+name+" date, use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		*target = &date
+	}
+
+	sessions, err := h.Service.GetSessions(classID, from, to)
+	if err != nil {
+		http.Error(w, "Failed to get sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// CreateSession - Pertemuan tambahan di luar jadwal mingguan
+func (h *AttendanceHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.CreateSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.Service.CreateSession(classID, userID, req)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to create session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// GenerateSessions - Membuat pertemuan dari jadwal mingguan untuk rentang tanggal
+func (h *AttendanceHandler) GenerateSessions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.GenerateSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.Service.GenerateSessions(classID, userID, req)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to generate sessions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AttendanceHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteSession(classID, sessionID); err != nil {
+		writeAttendanceError(w, err, "Failed to delete session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Session deleted successfully",
+	})
+}
+
+// GetSessionAttendance - Daftar absen pertemuan, siswa yang belum diabsen berstatus kosong
+func (h *AttendanceHandler) GetSessionAttendance(w http.ResponseWriter, r *http.Request) {
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	attendance, err := h.Service.GetSessionAttendance(classID, sessionID)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to get attendance")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendance)
+}
+
+// MarkAttendance - Guru mengisi absensi banyak siswa sekaligus
+func (h *AttendanceHandler) MarkAttendance(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.BulkAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attendance, err := h.Service.MarkAttendance(classID, sessionID, userID, req)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to save attendance")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendance)
+}
+
+// OpenCheckin - Membuka check-in QR. Body opsional: duration_minutes
+func (h *AttendanceHandler) OpenCheckin(w http.ResponseWriter, r *http.Request) {
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.OpenCheckinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.Service.OpenCheckin(classID, sessionID, req.DurationMinutes)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to open check-in")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(token)
+}
+
+func (h *AttendanceHandler) CloseCheckin(w http.ResponseWriter, r *http.Request) {
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.CloseCheckin(classID, sessionID); err != nil {
+		writeAttendanceError(w, err, "Failed to close check-in")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Check-in closed",
+	})
+}
+
+// GetCheckinToken - Kode QR yang berlaku sekarang, dipanggil ulang setelah expires_at
+func (h *AttendanceHandler) GetCheckinToken(w http.ResponseWriter, r *http.Request) {
+	classID, sessionID, ok := sessionVars(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.Service.GetCheckinToken(classID, sessionID)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to get check-in code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(token)
+}
+
+// SelfCheckin - Siswa memindai QR dan tercatat hadir
+func (h *AttendanceHandler) SelfCheckin(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SelfCheckinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.Service.SelfCheckin(userID, req.Code)
+	if err != nil {
+		writeAttendanceError(w, err, "Failed to check in")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// GetClassSummary - Rekap kehadiran semua siswa di kelas
+func (h *AttendanceHandler) GetClassSummary(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.Service.GetClassSummary(classID)
+	if err != nil {
+		http.Error(w, "Failed to get attendance summary: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GetMyAttendance - Rekap kehadiran user di setiap kelasnya
+func (h *AttendanceHandler) GetMyAttendance(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	summaries, err := h.Service.GetStudentSummaries(userID)
+	if err != nil {
+		http.Error(w, "Failed to get attendance summary: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+func sessionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	sessionID, err := strconv.Atoi(vars["session_id"])
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return classID, sessionID, true
+}
+
+func writeAttendanceError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrClassSessionNotFound):
+		http.Error(w, "Session not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrSessionExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrCheckinClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrAttendanceExcused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidCheckinCode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotClassMember):
+		http.Error(w, "Forbidden: you are not a student of this class", http.StatusForbidden)
+	case errors.Is(err, service.ErrClassArchived):
+		http.Error(w, "Class is archived", http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
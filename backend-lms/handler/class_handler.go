@@ -3,10 +3,12 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"project/dto"
+	"project/middleware"
 	"project/service"
 	"strconv"
 	"time"
@@ -19,14 +21,28 @@ type ClassHandler struct {
 }
 
 func (h *ClassHandler) CreateClass(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	var req dto.CreateClassRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid payload", http.StatusBadRequest)
 		return
 	}
 
-	class, err := h.Service.CreateClass(req)
+	// Pembuat kelas otomatis menjadi owner
+	class, err := h.Service.CreateClass(userID, req)
 	if err != nil {
+		if writeScheduleError(w, err) {
+			return
+		}
+		if errors.Is(err, service.ErrTermNotFound) {
+			http.Error(w, "Term not found", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to create class: %v", err)
 		http.Error(w, "Failed to create class", http.StatusInternalServerError)
 		return
 	}
@@ -62,8 +78,27 @@ func (h *ClassHandler) DeleteClass(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetClasses - Admin melihat semua kelas, user lain kelas yang diikutinya.
+// Query opsional: term_id, include_archived=true
 func (h *ClassHandler) GetClasses(w http.ResponseWriter, r *http.Request) {
-	classes, err := h.Service.GetClasses() // Panggil service untuk mengambil kelas
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	var filter dto.ClassListFilter
+	if value := r.URL.Query().Get("term_id"); value != "" {
+		termID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid term ID", http.StatusBadRequest)
+			return
+		}
+		filter.TermID = &termID
+	}
+	filter.IncludeArchived = r.URL.Query().Get("include_archived") == "true"
+
+	classes, err := h.Service.GetClasses(userID, role, filter) // Panggil service untuk mengambil kelas
 	if err != nil {
 		http.Error(w, "Failed to get classes", http.StatusInternalServerError)
 		return
@@ -77,8 +112,10 @@ func (h *ClassHandler) GetClasses(w http.ResponseWriter, r *http.Request) {
 func (h *ClassHandler) GetClassByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	classID := vars["id"]
+	userID, _ := r.Context().Value("id").(int)
+	role, _ := r.Context().Value("role").(string)
 
-	class, err := h.Service.GetClassByID(classID)
+	class, err := h.Service.GetClassByID(classID, userID, role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Class not found", http.StatusNotFound)
@@ -107,6 +144,13 @@ func (h *ClassHandler) UpdateClass(w http.ResponseWriter, r *http.Request) {
 	// Call service to update class
 	updatedClass, err := h.Service.UpdateClass(classID, req)
 	if err != nil {
+		if writeScheduleError(w, err) {
+			return
+		}
+		if errors.Is(err, service.ErrTermNotFound) {
+			http.Error(w, "Term not found", http.StatusBadRequest)
+			return
+		}
 		if err.Error() == "class not found" {
 			http.Error(w, "Class not found", http.StatusNotFound)
 		} else {
@@ -162,27 +206,22 @@ func (h *ClassHandler) JoinClass(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    err = h.Service.JoinClass(userID, req.ClassCode)
+    result, err := h.Service.JoinClassByCode(userID, dto.JoinClassByCodeRequest{ClassCode: req.ClassCode})
     if err != nil {
-        if err.Error() == "class not found" {
-            http.Error(w, "Class not found", http.StatusNotFound)
-        } else if err.Error() == "user not found" {
-            http.Error(w, "User not found", http.StatusNotFound)
-        } else {
-            http.Error(w, err.Error(), http.StatusInternalServerError)
-        }
+        writeJoinClassError(w, err)
         return
     }
 
     response := map[string]interface{}{
-        "status":     "success",
-        "message":    "Successfully joined the class",
-        "class_id":   classID,
-        "class_code": req.ClassCode,
-        "user_id":    userID,
-        "username":   username,
-        "role":       role,
-        "timestamp":  time.Now().Format(time.RFC3339),
+        "status":      "success",
+        "message":     result.Message,
+        "join_status": result.Status,
+        "class_id":    result.ClassID,
+        "class_code":  req.ClassCode,
+        "user_id":     userID,
+        "username":    username,
+        "role":        role,
+        "timestamp":   time.Now().Format(time.RFC3339),
     }
 
     w.Header().Set("Content-Type", "application/json")
@@ -198,28 +237,42 @@ func (h *ClassHandler) ManageClassMembers(w http.ResponseWriter, r *http.Request
         return
     }
 
-    var req struct {
-        UserID int `json:"user_id"`
+    actorID, _, ok := currentUser(w, r)
+    if !ok {
+        return
     }
+    actorRole, _ := r.Context().Value("role").(string)
+
+    // role: siswa (default), co_teacher atau assistant. Co-teacher/assistant hanya bisa diatur owner.
+    var req dto.ClassMemberRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
         http.Error(w, "Invalid payload", http.StatusBadRequest)
         return
     }
 
+    if err := validate.Struct(req); err != nil {
+        http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+
     var action string
     if r.Method == http.MethodPost {
-        err = h.Service.AddMember(classID, req.UserID)
+        err = h.Service.AddMember(actorID, actorRole, classID, req.UserID, req.Role)
         action = "added"
     } else if r.Method == http.MethodDelete {
-        err = h.Service.RemoveMember(classID, req.UserID)
+        err = h.Service.RemoveMember(actorID, actorRole, classID, req.UserID)
         action = "removed"
     }
 
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeClassMemberError(w, err)
         return
     }
 
+    if req.Role == "" {
+        req.Role = service.ClassRoleStudent
+    }
+
     // Membuat response yang lebih informatif
     response := map[string]interface{}{
         "status":   "success",
@@ -228,6 +281,9 @@ func (h *ClassHandler) ManageClassMembers(w http.ResponseWriter, r *http.Request
         "user_id":  req.UserID,
         "action":   action,
     }
+    if action == "added" {
+        response["class_role"] = req.Role
+    }
 
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
@@ -253,9 +309,10 @@ func (h *ClassHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
     var userResponses []dto.UserResponse
     for _, member := range members {
         userResponses = append(userResponses, dto.UserResponse{
-            ID:       member.ID,
-            Username: member.Username,
-            Role:     member.Role,
+            ID:        member.UserID,
+            Username:  member.Username,
+            Role:      member.Role,
+            ClassRole: member.ClassRole,
         })
     }
 
@@ -279,11 +336,30 @@ func (h *ClassHandler) GetClassesByStudentID(w http.ResponseWriter, r *http.Requ
         return
     }
 
-    classes, err := h.Service.GetClassesByStudentID(studentID)
+    // Selain Admin dan siswa itu sendiri, hanya kelas yang bisa dilihat pemanggil yang ditampilkan
+    currentID, _ := r.Context().Value("id").(int)
+    role, _ := r.Context().Value("role").(string)
+    self := currentID == studentID || role == "Admin"
+    if !self && role != "Guru" {
+        http.Error(w, "Forbidden: you can only view your own classes", http.StatusForbidden)
+        return
+    }
+
+    includeArchived := r.URL.Query().Get("include_archived") == "true"
+    classes, err := h.Service.GetClassesByStudentID(studentID, currentID, role, includeArchived)
     if err != nil {
         http.Error(w, "Failed to get classes: "+err.Error(), http.StatusInternalServerError)
         return
     }
+    if !self {
+        visible := classes[:0]
+        for _, class := range classes {
+            if hasClassPermission(r, class.ID, middleware.ClassPermView) {
+                visible = append(visible, class)
+            }
+        }
+        classes = visible
+    }
 
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(classes)
@@ -308,4 +384,408 @@ func (h *ClassHandler) CountClassesByUserID(w http.ResponseWriter, r *http.Reque
     response := map[string]string{"class_count": strconv.Itoa(count)}
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+func (h *ClassHandler) GetClassCode(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.Service.GetClassCode(classID)
+	if err != nil {
+		writeClassCodeError(w, err, "Failed to get class code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(code)
+}
+
+// RegenerateClassCode - Membuat kode kelas baru, kode lama langsung tidak berlaku
+func (h *ClassHandler) RegenerateClassCode(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	// Body opsional: expires_at dan max_uses untuk kode baru
+	var req dto.ClassCodeSettingsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	code, err := h.Service.RegenerateClassCode(classID, req)
+	if err != nil {
+		writeClassCodeError(w, err, "Failed to regenerate class code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(code)
+}
+
+func (h *ClassHandler) UpdateClassCodeSettings(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ClassCodeSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.Service.UpdateClassCodeSettings(classID, req)
+	if err != nil {
+		writeClassCodeError(w, err, "Failed to update class code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(code)
+}
+
+func (h *ClassHandler) DisableClassCode(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DisableClassCode(classID); err != nil {
+		writeClassCodeError(w, err, "Failed to disable class code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Class code disabled successfully",
+	})
+}
+
+func writeClassCodeError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	if errors.Is(err, service.ErrClassNotFound) {
+		http.Error(w, "Class not found", http.StatusNotFound)
+		return
+	}
+	log.Printf("%s: %v", message, err)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// JoinClassByCode - Bergabung ke kelas hanya dengan kode kelas
+func (h *ClassHandler) JoinClassByCode(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.JoinClassByCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.Service.JoinClassByCode(userID, req)
+	if err != nil {
+		writeJoinClassError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Status == service.JoinStatusPending {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *ClassHandler) LeaveClass(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.LeaveClass(userID, classID); err != nil {
+		if errors.Is(err, service.ErrNotClassMember) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrOwnerMembership) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to leave class", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Left the class successfully",
+	})
+}
+
+func (h *ClassHandler) SetEnrollmentMode(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.EnrollmentModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.SetEnrollmentMode(classID, req.Mode); err != nil {
+		writeClassCodeError(w, err, "Failed to update enrollment mode")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":         "Enrollment mode updated successfully",
+		"enrollment_mode": req.Mode,
+	})
+}
+
+// GetJoinRequests - Antrian join request, filter ?status=pending|approved|rejected|all
+func (h *ClassHandler) GetJoinRequests(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	requests, err := h.Service.GetJoinRequests(classID, r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to get join requests: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+func (h *ClassHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewJoinRequest(w, r, true)
+}
+
+func (h *ClassHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewJoinRequest(w, r, false)
+}
+
+func (h *ClassHandler) reviewJoinRequest(w http.ResponseWriter, r *http.Request, approve bool) {
+	reviewerID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	requestID, err := strconv.Atoi(vars["request_id"])
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.Service.ReviewJoinRequest(classID, requestID, reviewerID, approve)
+	if err != nil {
+		if errors.Is(err, service.ErrJoinRequestNotFound) {
+			http.Error(w, "Join request not found or already reviewed", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to review join request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+func writeJoinClassError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrClassNotFound):
+		http.Error(w, "Class not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrClassArchived):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrClassCodeDisabled),
+		errors.Is(err, service.ErrClassCodeExpired),
+		errors.Is(err, service.ErrClassCodeExhausted):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		log.Printf("Failed to join class: %v", err)
+		http.Error(w, "Failed to join class", http.StatusInternalServerError)
+	}
+}
+
+// TransferOwnership - Owner memindahkan kepemilikan kelas ke guru lain yang sudah menjadi member
+func (h *ClassHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.TransferOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.TransferOwnership(classID, req.UserID); err != nil {
+		writeClassMemberError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Ownership transferred successfully",
+		"class_id": classID,
+		"owner_id": req.UserID,
+	})
+}
+
+func writeClassMemberError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, middleware.ErrClassForbidden):
+		http.Error(w, "Forbidden: only the class owner can manage co-teachers and assistants", http.StatusForbidden)
+	case errors.Is(err, service.ErrNotClassMember),
+		errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidClassRole),
+		errors.Is(err, service.ErrNewOwnerNotTeacher):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrStaffRoleForStudent):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrOwnerMembership):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ArchiveClass - Kelas diarsipkan: hilang dari daftar default dan read-only untuk siswa
+func (h *ClassHandler) ArchiveClass(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+func (h *ClassHandler) UnarchiveClass(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *ClassHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	if archived {
+		err = h.Service.ArchiveClass(classID)
+	} else {
+		err = h.Service.UnarchiveClass(classID)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrClassNotFound) {
+			http.Error(w, "Class not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to update class archive status", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	message := "Class archived successfully"
+	if !archived {
+		message = "Class restored successfully"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  message,
+		"class_id": classID,
+		"archived": archived,
+	})
+}
+
+// CloneClass - Menyalin kelas (pengaturan, jadwal, materi, tugas, opsional member & nilai) ke kelas baru
+func (h *ClassHandler) CloneClass(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.CloneClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.Service.CloneClass(userID, classID, req)
+	if err != nil {
+		if validationErr, ok := service.AsValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrClassNotFound):
+			http.Error(w, "Class not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrTermNotFound):
+			http.Error(w, "Term not found", http.StatusBadRequest)
+		default:
+			log.Printf("Failed to clone class: %v", err)
+			http.Error(w, "Failed to clone class", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"project/config"
+	"project/dto"
+	"project/service"
+	"project/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var rosterExportTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":  "application/pdf",
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ExportMembers - Roster siswa kelas sebagai file. Query: format=csv|xlsx|pdf (default csv)
+func (h *ClassHandler) ExportMembers(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "csv"
+	}
+	contentType, ok := rosterExportTypes[format]
+	if !ok {
+		http.Error(w, "Invalid format, use csv, xlsx or pdf", http.StatusBadRequest)
+		return
+	}
+
+	roster, err := h.Service.GetRoster(classID)
+	if err != nil {
+		if errors.Is(err, service.ErrClassNotFound) {
+			http.Error(w, "Class not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get roster: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// File dibuat di buffer dulu supaya error tidak muncul di tengah file yang sudah terkirim
+	var buf bytes.Buffer
+	table := rosterTable(roster)
+	switch format {
+	case "csv":
+		err = utils.WriteCSV(&buf, table)
+	case "xlsx":
+		err = utils.WriteXLSX(&buf, roster.ClassName, table)
+	case "pdf":
+		err = utils.WriteTablePDF(&buf, table)
+	}
+	if err != nil {
+		http.Error(w, "Failed to export roster: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fileName := fmt.Sprintf("roster-%s-%s.%s", unsafeFileNameChars.ReplaceAllString(roster.ClassName, "_"), time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+func rosterTable(roster *dto.ClassRoster) utils.Table {
+	loc := config.DefaultLocation()
+
+	table := utils.Table{
+		Title:   "Daftar Siswa " + roster.ClassName,
+		Headers: []string{"No", "Nama Lengkap", "Username", "NIS/NISN", "Tanggal Bergabung", "Kehadiran (%)", "Rata-rata Nilai"},
+		Widths:  []float64{0.6, 4, 2.4, 2, 2, 1.6, 1.6},
+	}
+	if roster.Teacher != "" {
+		table.Subtitle = append(table.Subtitle, "Guru: "+roster.Teacher)
+	}
+	if roster.Term != "" {
+		table.Subtitle = append(table.Subtitle, "Semester: "+roster.Term)
+	}
+	table.Subtitle = append(table.Subtitle, fmt.Sprintf("Jumlah siswa: %d", len(roster.Students)))
+
+	for i, student := range roster.Students {
+		var attendance, grade interface{}
+		if student.AttendancePercentage != nil {
+			attendance = *student.AttendancePercentage
+		}
+		if student.AverageGrade != nil {
+			grade = *student.AverageGrade
+		}
+		table.Rows = append(table.Rows, []interface{}{
+			i + 1, student.FullName, student.Username, student.Identifier,
+			student.JoinedAt.In(loc).Format("2006-01-02"), attendance, grade,
+		})
+	}
+	return table
+}
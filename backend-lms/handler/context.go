@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"project/dto"
+	"project/middleware"
+	"project/utils"
+)
+
+// currentUser mengambil id dan username user dari context yang diisi AuthMiddleware.
+func currentUser(w http.ResponseWriter, r *http.Request) (int, string, bool) {
+	userID, ok := r.Context().Value("id").(int)
+	if !ok || userID == 0 {
+		http.Error(w, "Unauthorized: Missing or invalid user ID", http.StatusUnauthorized)
+		return 0, "", false
+	}
+
+	username, ok := r.Context().Value("username").(string)
+	if !ok || username == "" {
+		http.Error(w, "Unauthorized: Missing or invalid token", http.StatusUnauthorized)
+		return 0, "", false
+	}
+	return userID, username, true
+}
+
+// currentSessionID mengambil id sesi (claim jti) dari context. Kosong untuk personal access token.
+func currentSessionID(r *http.Request) string {
+	sessionID, _ := r.Context().Value("session_id").(string)
+	return sessionID
+}
+
+// clientInfo mengambil IP dan user agent untuk dicatat di riwayat login dan event keamanan.
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{IP: utils.ClientIP(r), UserAgent: r.UserAgent()}
+}
+
+// hasClassPermission mengecek permission kelas tambahan di dalam handler, misalnya untuk
+// menentukan data yang boleh dilihat. Error pengecekan dianggap tidak punya permission.
+func hasClassPermission(r *http.Request, classID int, permission string) bool {
+	userID, _ := r.Context().Value("id").(int)
+	role, _ := r.Context().Value("role").(string)
+	if middleware.ClassPermissionChecker == nil {
+		return false
+	}
+	return middleware.ClassPermissionChecker(userID, role, classID, permission) == nil
+}
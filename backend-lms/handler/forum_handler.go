@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"project/dto"
-	"project/middleware"
+	"project/jwtauth"
 	"project/service"
 	"strconv"
 	"time"
@@ -162,7 +162,7 @@ func (h *ForumHandler) DeleteForum(w http.ResponseWriter, r *http.Request) {
 
 func (h *ForumHandler) GetJWTClaims(w http.ResponseWriter, r *http.Request) {
 	// Ambil claims dari context
-	claims, ok := r.Context().Value("userClaims").(*middleware.Claims)
+	claims, ok := r.Context().Value("userClaims").(*jwtauth.Claims)
 	if !ok {
 		http.Error(w, "Unauthorized: Unable to extract claims", http.StatusUnauthorized)
 		return
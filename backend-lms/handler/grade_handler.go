@@ -4,6 +4,7 @@ import (
     "encoding/json"
     "net/http"
     "project/dto"
+    "project/middleware"
     "project/service"
 )
 
@@ -22,6 +23,12 @@ func (h *GradeHandler) CreateGrade(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    // Nilai hanya boleh diinput oleh guru/asisten kelas tersebut
+    if !hasClassPermission(r, req.ClassID, middleware.ClassPermGrade) {
+        http.Error(w, "Forbidden: your role in this class does not allow this action", http.StatusForbidden)
+        return
+    }
+
     grade, err := h.Service.CreateGrade(req)
     if err != nil {
         http.Error(w, "Failed to create grade: "+err.Error(), http.StatusInternalServerError)
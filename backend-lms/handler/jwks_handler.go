@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project/jwtauth"
+)
+
+type JWKSHandler struct {
+	Keys *jwtauth.KeyStore
+}
+
+// GetJWKS mempublikasikan public key yang masih valid supaya service lain bisa memverifikasi token.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.Keys.JWKS())
+}
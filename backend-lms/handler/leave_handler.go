@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"project/utils"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type LeaveHandler struct {
+	Service *service.LeaveService
+}
+
+func NewLeaveHandler(service *service.LeaveService) *LeaveHandler {
+	return &LeaveHandler{Service: service}
+}
+
+// CreateLeaveRequest - Siswa mengajukan izin/sakit. Multipart form: type, start_date, end_date,
+// reason, class_id (opsional) dan file attachment (opsional, misalnya surat dokter)
+func (h *LeaveHandler) CreateLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	req := dto.CreateLeaveRequest{
+		Type:      r.FormValue("type"),
+		StartDate: r.FormValue("start_date"),
+		EndDate:   r.FormValue("end_date"),
+		Reason:    r.FormValue("reason"),
+	}
+	if value := r.FormValue("class_id"); value != "" {
+		classID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid class ID", http.StatusBadRequest)
+			return
+		}
+		req.ClassID = &classID
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("attachment")
+	if err != nil && err != http.ErrMissingFile {
+		http.Error(w, "Unable to retrieve file", http.StatusBadRequest)
+		return
+	}
+	if file != nil {
+		defer file.Close()
+
+		req.AttachmentURL, err = utils.UploadImage(file, fileHeader)
+		if err != nil {
+			http.Error(w, "Failed to upload file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	request, err := h.Service.CreateLeaveRequest(userID, req)
+	if err != nil {
+		writeLeaveError(w, err, "Failed to create leave request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+func (h *LeaveHandler) GetMyLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.Service.GetMyLeaveRequests(userID)
+	if err != nil {
+		http.Error(w, "Failed to get leave requests: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+func (h *LeaveHandler) CancelLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	requestID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid leave request ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.CancelLeaveRequest(userID, requestID); err != nil {
+		writeLeaveError(w, err, "Failed to cancel leave request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Leave request cancelled",
+	})
+}
+
+// GetClassLeaveRequests - Pengajuan izin yang ditinjau guru kelas ini. Query: status (default pending, "all")
+func (h *LeaveHandler) GetClassLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	requests, err := h.Service.GetClassLeaveRequests(classID, r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to get leave requests: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// ReviewLeaveRequest - Menyetujui/menolak pengajuan; jika disetujui absensi terisi otomatis
+func (h *LeaveHandler) ReviewLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	requestID, err := strconv.Atoi(vars["request_id"])
+	if err != nil {
+		http.Error(w, "Invalid leave request ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ReviewLeaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.Service.ReviewLeaveRequest(classID, requestID, userID, req)
+	if err != nil {
+		writeLeaveError(w, err, "Failed to review leave request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetHomeroom - Menandai kelas sebagai kelas perwalian
+func (h *LeaveHandler) SetHomeroom(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.HomeroomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.SetHomeroom(classID, req.IsHomeroom); err != nil {
+		writeLeaveError(w, err, "Failed to update homeroom")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"class_id":    classID,
+		"is_homeroom": req.IsHomeroom,
+	})
+}
+
+func writeLeaveError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrLeaveRequestNotFound):
+		http.Error(w, "Leave request not found or already reviewed", http.StatusNotFound)
+	case errors.Is(err, service.ErrLeaveNotReviewable):
+		http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrClassNotFound):
+		http.Error(w, "Class not found", http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
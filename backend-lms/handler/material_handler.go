@@ -3,6 +3,7 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"project/dto"
@@ -59,11 +60,20 @@ func (h *MaterialHandler) CreateMaterial(w http.ResponseWriter, r *http.Request)
     req.Title = r.FormValue("title")
     req.Content = r.FormValue("content")
     req.ClassID = classID
+    req.UnitID, err = formUnitID(r)
+    if err != nil {
+        http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+        return
+    }
     req.Attachment = fileURL
 
     // Panggil service untuk membuat material
     material, err := h.Service.CreateMaterial(req)
     if err != nil {
+        if errors.Is(err, service.ErrUnitNotFound) {
+            http.Error(w, "Unit not found", http.StatusBadRequest)
+            return
+        }
         http.Error(w, "Failed to create material: "+err.Error(), http.StatusInternalServerError)
         return
     }
@@ -112,9 +122,9 @@ func (h *MaterialHandler) GetMaterialsByClass(w http.ResponseWriter, r *http.Req
 // DeleteMaterial - Menghapus materi berdasarkan ID
 func (h *MaterialHandler) DeleteMaterial(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	materialID := vars["id"]
+	materialID := vars["material_id"]
 
-	if err := h.Service.DeleteMaterial(materialID); err != nil {
+	if err := h.Service.DeleteMaterial(vars["class_id"], materialID); err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Material not found", http.StatusNotFound)
 		} else {
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type MFAHandler struct {
+	Service *service.MFAService
+}
+
+func NewMFAHandler(service *service.MFAService) *MFAHandler {
+	return &MFAHandler{Service: service}
+}
+
+// Enroll membuat secret TOTP baru dan mengembalikan provisioning URI serta QR code PNG (base64).
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, username, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.Service.BeginEnrollment(userID, username)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	png, err := h.Service.QRCode(userID, username)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":      "success",
+		"message":     "Scan the QR code and confirm with a code from your authenticator app",
+		"secret":      enrollment.Secret,
+		"otpauth_url": enrollment.ProvisioningURI,
+		"qr_code":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// QRCode mengembalikan QR code enrollment yang sedang berjalan sebagai image/png.
+func (h *MFAHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	userID, username, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	png, err := h.Service.QRCode(userID, username)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.Service.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"message":        "Two-factor authentication enabled. Store these recovery codes in a safe place",
+		"recovery_codes": codes,
+	})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.Service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"message":        "Recovery codes regenerated",
+		"recovery_codes": codes,
+	})
+}
+
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Disable(userID, req.Code); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (h *MFAHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	enabled, err := h.Service.IsEnabled(userID)
+	if err != nil {
+		http.Error(w, "Failed to get two-factor status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	required, err := h.Service.IsRequiredForRole(role)
+	if err != nil {
+		http.Error(w, "Failed to get two-factor policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{
+		"enabled":  enabled,
+		"required": required,
+	})
+}
+
+// GetRolePolicies - Admin melihat role mana saja yang wajib 2FA
+func (h *MFAHandler) GetRolePolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.Service.GetRolePolicies()
+	if err != nil {
+		http.Error(w, "Failed to get MFA policies: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// SetRolePolicy - Admin mewajibkan/menonaktifkan kewajiban 2FA untuk sebuah role
+func (h *MFAHandler) SetRolePolicy(w http.ResponseWriter, r *http.Request) {
+	role := strings.TrimSpace(mux.Vars(r)["role"])
+	if role == "" {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.MFAPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.Service.SetRolePolicy(role, req.Required)
+	if err != nil {
+		http.Error(w, "Failed to update MFA policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidMFACode):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "Two-factor operation failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
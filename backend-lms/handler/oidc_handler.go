@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"project/config"
+	"project/dto"
+	"project/service"
+	"time"
+)
+
+// oidcStateCookie mengikat state OIDC ke browser yang memulai flow (mencegah login CSRF).
+const oidcStateCookie = "oidc_state"
+
+func setOIDCStateCookie(w http.ResponseWriter, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/auth/oidc",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+type OIDCHandler struct {
+	Service *service.OIDCService
+}
+
+func NewOIDCHandler(service *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{Service: service}
+}
+
+// Login mengarahkan browser ke halaman login identity provider.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.Service.AuthorizationURL(0)
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to start OIDC login: %v", err)
+		http.Error(w, "Failed to start single sign-on", http.StatusBadGateway)
+		return
+	}
+
+	setOIDCStateCookie(w, service.OIDCStateHash(state), service.OIDCStateTTL)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// LinkIdentity - Memulai flow untuk menautkan akun IdP ke user yang sedang login.
+// Frontend memanggil endpoint ini dengan credentials (supaya cookie state tersimpan), lalu
+// mengarahkan browser ke authorization_url. Callback mengembalikan link_code yang harus
+// dikonfirmasi lewat ConfirmLink dengan sesi user yang sama.
+func (h *OIDCHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	authURL, state, err := h.Service.AuthorizationURL(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to start OIDC link: %v", err)
+		http.Error(w, "Failed to start single sign-on", http.StatusBadGateway)
+		return
+	}
+
+	setOIDCStateCookie(w, service.OIDCStateHash(state), service.OIDCStateTTL)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"authorization_url": authURL})
+}
+
+// ConfirmLink - Menyelesaikan penautan akun IdP dengan link_code dari callback.
+func (h *OIDCHandler) ConfirmLink(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.OIDCLinkConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.ConfirmLink(userID, req.LinkCode); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCInvalidLink):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrOIDCIdentityInUse):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("Failed to link OIDC identity: %v", err)
+			http.Error(w, "Failed to link identity", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"linked": true, "user_id": userID})
+}
+
+// Callback menerima authorization code dari identity provider dan menerbitkan JWT.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if idpErr := query.Get("error"); idpErr != "" {
+		http.Error(w, "Single sign-on failed: "+idpErr+" "+query.Get("error_description"), http.StatusUnauthorized)
+		return
+	}
+
+	state, code := query.Get("state"), query.Get("code")
+	if state == "" || code == "" {
+		http.Error(w, "Missing state or code", http.StatusBadRequest)
+		return
+	}
+
+	var stateCookie string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		stateCookie = cookie.Value
+	}
+	// Cookie state hanya berlaku untuk satu callback
+	setOIDCStateCookie(w, "", -time.Second)
+
+	result, err := h.Service.HandleCallback(state, stateCookie, code, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCDisabled):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrOIDCInvalidState):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("OIDC callback failed: %v", err)
+			http.Error(w, "Single sign-on failed: "+err.Error(), http.StatusUnauthorized)
+		}
+		return
+	}
+
+	// Token dikirim lewat URL fragment supaya tidak tercatat di log server frontend
+	fragment := url.Values{}
+	var body interface{} = result.Login
+	if result.LinkCode != "" {
+		fragment.Set("link_code", result.LinkCode)
+		body = map[string]string{"link_code": result.LinkCode}
+	} else {
+		login := result.Login
+		if login.Token != "" {
+			fragment.Set("token", login.Token)
+		}
+		if login.MFARequired {
+			fragment.Set("mfa_required", "true")
+			fragment.Set("mfa_token", login.MFAToken)
+		}
+		if login.MFASetupRequired {
+			fragment.Set("mfa_setup_required", "true")
+		}
+	}
+	if config.OIDCPostLoginRedirect != "" {
+		http.Redirect(w, r, config.OIDCPostLoginRedirect+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
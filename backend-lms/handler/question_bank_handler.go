@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type QuestionBankHandler struct {
+	Service *service.QuestionBankService
+}
+
+func NewQuestionBankHandler(service *service.QuestionBankService) *QuestionBankHandler {
+	return &QuestionBankHandler{Service: service}
+}
+
+// GetQuestions - ?scope=mine|shared&subject=&topic=&difficulty=easy|medium|hard&competency=&type=&q=
+func (h *QuestionBankHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	query := r.URL.Query()
+	filter := dto.BankQuestionFilter{
+		Scope:      query.Get("scope"),
+		Subject:    query.Get("subject"),
+		Topic:      query.Get("topic"),
+		Difficulty: query.Get("difficulty"),
+		Competency: query.Get("competency"),
+		Type:       query.Get("type"),
+		Search:     query.Get("q"),
+	}
+	switch filter.Scope {
+	case "", "mine", "shared":
+	default:
+		http.Error(w, "Invalid scope", http.StatusBadRequest)
+		return
+	}
+
+	questions, err := h.Service.ListQuestions(userID, role, filter)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to get questions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// GetTags - Daftar subject, topic dan kompetensi yang sudah dipakai
+func (h *QuestionBankHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	tags, err := h.Service.GetTags(userID, role)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to get tags")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *QuestionBankHandler) GetQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+
+	question, err := h.Service.GetQuestion(questionID, userID, role)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to get question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(question)
+}
+
+// CreateQuestion - JSON: {"subject": "Matematika", "topic": "Pecahan", "difficulty": "easy", "competency": "3.1", "shared": false, "type": "multiple_choice", "prompt": "...", "points": 1, "options": [...]}
+func (h *QuestionBankHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.BankQuestionRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Service.CreateQuestion(userID, req)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to create question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(question)
+}
+
+// UpdateQuestion - Perubahan isi soal membuat versi baru; perubahan tag saja tidak
+func (h *QuestionBankHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.BankQuestionRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Service.UpdateQuestion(questionID, userID, role, req)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to update question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(question)
+}
+
+func (h *QuestionBankHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteQuestion(questionID, userID, role); err != nil {
+		writeQuestionBankError(w, err, "Failed to delete question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Question deleted successfully",
+	})
+}
+
+func (h *QuestionBankHandler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.Service.GetVersions(questionID, userID, role)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to get versions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+func (h *QuestionBankHandler) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.Service.RestoreVersion(questionID, version, userID, role)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to restore version")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(question)
+}
+
+// CopyQuestion - Menyalin soal (termasuk soal bersama guru lain) ke bank soal sendiri
+func (h *QuestionBankHandler) CopyQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, role, questionID, ok := bankQuestionVars(w, r)
+	if !ok {
+		return
+	}
+
+	question, err := h.Service.CopyQuestion(questionID, userID, role)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to copy question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(question)
+}
+
+func bankQuestionVars(w http.ResponseWriter, r *http.Request) (int, string, int, bool) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return 0, "", 0, false
+	}
+	role, _ := r.Context().Value("role").(string)
+	questionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return 0, "", 0, false
+	}
+	return userID, role, questionID, true
+}
+
+func writeQuestionBankError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrBankQuestionNotFound):
+		http.Error(w, "Question not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrBankVersionNotFound):
+		http.Error(w, "Version not found", http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
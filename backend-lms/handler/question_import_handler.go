@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxQuestionImportSize = 10 << 20 // 10 MB
+
+var questionExportTypes = map[string]struct{ contentType, extension string }{
+	service.FormatAiken: {"text/plain; charset=utf-8", "txt"},
+	service.FormatGIFT:  {"text/plain; charset=utf-8", "gift.txt"},
+	service.FormatQTI:   {"application/zip", "zip"},
+}
+
+// PreviewImport - Multipart: file, format=aiken|gift|qti (opsional, ditebak dari file), subject,
+// topic, difficulty, competency, shared. Hanya membaca dan memvalidasi, tidak menyimpan.
+func (h *QuestionBankHandler) PreviewImport(w http.ResponseWriter, r *http.Request) {
+	data, options, ok := readQuestionImport(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.PreviewImport(data, options)
+	if err != nil {
+		writeQuestionImportError(w, err, report, "Failed to read questions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ImportQuestions - Form sama dengan preview, ditambah skip_invalid=true untuk tetap menyimpan
+// soal yang valid. Tanpa skip_invalid, satu soal tidak valid membatalkan import (422 + laporan).
+func (h *QuestionBankHandler) ImportQuestions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	data, options, ok := readQuestionImport(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.ImportQuestions(userID, data, options)
+	if err != nil {
+		writeQuestionImportError(w, err, report, "Failed to import questions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportQuestions - ?format=aiken|gift|qti dengan filter yang sama seperti daftar bank soal
+func (h *QuestionBankHandler) ExportQuestions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if _, ok := questionExportTypes[format]; !ok {
+		http.Error(w, "Invalid format, use aiken, gift or qti", http.StatusBadRequest)
+		return
+	}
+
+	filter := dto.BankQuestionFilter{
+		Scope:      query.Get("scope"),
+		Subject:    query.Get("subject"),
+		Topic:      query.Get("topic"),
+		Difficulty: query.Get("difficulty"),
+		Competency: query.Get("competency"),
+		Type:       query.Get("type"),
+		Search:     query.Get("q"),
+	}
+	content, warnings, err := h.Service.ExportQuestions(userID, role, filter, format)
+	if err != nil {
+		writeQuestionBankError(w, err, "Failed to export questions")
+		return
+	}
+	writeQuestionExport(w, "bank-soal", format, content, warnings)
+}
+
+// ExportQuiz - ?format=aiken|gift|qti. Soal acak dari aturan pengambilan tidak ikut diekspor
+func (h *QuizHandler) ExportQuiz(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if _, ok := questionExportTypes[format]; !ok {
+		http.Error(w, "Invalid format, use aiken, gift or qti", http.StatusBadRequest)
+		return
+	}
+
+	content, warnings, title, err := h.Service.ExportQuiz(classID, quizID, format)
+	if err != nil {
+		writeQuizError(w, err, "Failed to export quiz")
+		return
+	}
+	writeQuestionExport(w, "kuis-"+title, format, content, warnings)
+}
+
+func readQuestionImport(w http.ResponseWriter, r *http.Request) ([]byte, dto.QuestionImportOptions, bool) {
+	var options dto.QuestionImportOptions
+	if err := r.ParseMultipartForm(maxQuestionImportSize); err != nil {
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return nil, options, false
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Unable to retrieve file", http.StatusBadRequest)
+		return nil, options, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxQuestionImportSize+1))
+	if err != nil {
+		http.Error(w, "Unable to read file", http.StatusBadRequest)
+		return nil, options, false
+	}
+	if len(data) > maxQuestionImportSize {
+		http.Error(w, "File is too large (max 10 MB)", http.StatusRequestEntityTooLarge)
+		return nil, options, false
+	}
+
+	options = dto.QuestionImportOptions{
+		Format:     r.FormValue("format"),
+		FileName:   fileHeader.Filename,
+		Subject:    strings.TrimSpace(r.FormValue("subject")),
+		Topic:      strings.TrimSpace(r.FormValue("topic")),
+		Difficulty: r.FormValue("difficulty"),
+		Competency: strings.TrimSpace(r.FormValue("competency")),
+	}
+	options.Shared, _ = strconv.ParseBool(r.FormValue("shared"))
+	options.SkipInvalid, _ = strconv.ParseBool(r.FormValue("skip_invalid"))
+	return data, options, true
+}
+
+// writeQuestionExport mengirim file ekspor. Peringatan (soal yang dilewati atau berubah)
+// dikirim lewat header X-Export-Warnings.
+func writeQuestionExport(w http.ResponseWriter, name, format string, content []byte, warnings []string) {
+	exportType := questionExportTypes[format]
+	fileName := fmt.Sprintf("%s-%s.%s", unsafeFileNameChars.ReplaceAllString(name, "_"), time.Now().Format("20060102"), exportType.extension)
+	if len(warnings) > 0 {
+		w.Header().Set("X-Export-Warnings", asciiHeader(strings.Join(warnings, "; ")))
+	}
+	w.Header().Set("Content-Type", exportType.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Write(content)
+}
+
+func asciiHeader(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 32 || r > 126 {
+			return '?'
+		}
+		return r
+	}, value)
+}
+
+func writeQuestionImportError(w http.ResponseWriter, err error, report *dto.QuestionImportReport, message string) {
+	switch {
+	case errors.Is(err, service.ErrImportInvalid) && report != nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(report)
+	case errors.Is(err, service.ErrUnknownQuestionFormat):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,523 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/middleware"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type QuizHandler struct {
+	Service *service.QuizService
+}
+
+func NewQuizHandler(service *service.QuizService) *QuizHandler {
+	return &QuizHandler{Service: service}
+}
+
+// GetQuizzes - Guru melihat semua kuis (termasuk draft), siswa hanya kuis yang dipublikasikan
+// beserta ringkasan percobaannya
+func (h *QuizHandler) GetQuizzes(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var quizzes interface{}
+	if hasClassPermission(r, classID, middleware.ClassPermManageContent) {
+		quizzes, err = h.Service.GetQuizzes(classID, true)
+	} else {
+		quizzes, err = h.Service.GetMyQuizzes(classID, userID)
+	}
+	if err != nil {
+		writeQuizError(w, err, "Failed to get quizzes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quizzes)
+}
+
+// GetQuiz - Soal dan kunci jawaban hanya untuk pengelola kelas
+func (h *QuizHandler) GetQuiz(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	quiz, err := h.Service.GetQuiz(classID, quizID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get quiz")
+		return
+	}
+	if !hasClassPermission(r, classID, middleware.ClassPermManageContent) {
+		if !quiz.Published {
+			http.Error(w, "Quiz not found", http.StatusNotFound)
+			return
+		}
+		quiz.Questions, quiz.DrawRules = nil, nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quiz)
+}
+
+// CreateQuiz - JSON: {"title": "...", "time_limit_minutes": 30, "opens_at": "...", "closes_at": "...", "max_attempts": 1}
+func (h *QuizHandler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.QuizRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	quiz, err := h.Service.CreateQuiz(classID, userID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to create quiz")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(quiz)
+}
+
+func (h *QuizHandler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.QuizRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	quiz, err := h.Service.UpdateQuiz(classID, quizID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to update quiz")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quiz)
+}
+
+func (h *QuizHandler) DeleteQuiz(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteQuiz(classID, quizID); err != nil {
+		writeQuizError(w, err, "Failed to delete quiz")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Quiz deleted successfully",
+	})
+}
+
+// AddQuestion - JSON: {"type": "multiple_choice", "prompt": "...", "points": 2, "options": [{"text": "...", "correct": true}]}
+func (h *QuizHandler) AddQuestion(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.QuestionRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Service.AddQuestion(classID, quizID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to add question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(question)
+}
+
+func (h *QuizHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+	questionID, ok := quizPathID(w, r, "question_id", "Invalid question ID")
+	if !ok {
+		return
+	}
+
+	var req dto.QuestionRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Service.UpdateQuestion(classID, quizID, questionID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to update question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(question)
+}
+
+func (h *QuizHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+	questionID, ok := quizPathID(w, r, "question_id", "Invalid question ID")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteQuestion(classID, quizID, questionID); err != nil {
+		writeQuizError(w, err, "Failed to delete question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Question deleted successfully",
+	})
+}
+
+// AddBankQuestions - JSON: {"bank_question_ids": [3, 8]}; versi terbaru soal disalin ke kuis
+func (h *QuizHandler) AddBankQuestions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.AddBankQuestionsRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	questions, err := h.Service.AddBankQuestions(classID, quizID, userID, role, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to add questions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(questions)
+}
+
+func (h *QuizHandler) GetDrawRules(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.Service.GetDrawRules(classID, quizID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get draw rules")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// SetDrawRules - JSON: {"rules": [{"count": 5, "points": 2, "subject": "Matematika", "topic": "Pecahan", "difficulty": "easy", "competency": "3.1"}]}
+func (h *QuizHandler) SetDrawRules(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.DrawRulesRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	rules, err := h.Service.SetDrawRules(classID, quizID, userID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to update draw rules")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// StartAttempt - Memulai percobaan baru, atau melanjutkan percobaan yang masih berjalan
+func (h *QuizHandler) StartAttempt(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.Service.StartAttempt(classID, quizID, userID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to start attempt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+func (h *QuizHandler) GetMyAttempts(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.Service.GetMyAttempts(classID, quizID, userID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get attempts")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempts)
+}
+
+func (h *QuizHandler) GetMyAttempt(w http.ResponseWriter, r *http.Request) {
+	userID, classID, quizID, attemptID, ok := quizAttemptVars(w, r)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.Service.GetMyAttempt(classID, quizID, attemptID, userID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get attempt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+// SaveAnswers - JSON: {"answers": [{"item_id": 1, "response": {"selected": ["a"]}}, {"item_id": 2, "response": {"text": "..."}}]}
+func (h *QuizHandler) SaveAnswers(w http.ResponseWriter, r *http.Request) {
+	userID, classID, quizID, attemptID, ok := quizAttemptVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveAnswersRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	attempt, err := h.Service.SaveAnswers(classID, quizID, attemptID, userID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to save answers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+func (h *QuizHandler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
+	userID, classID, quizID, attemptID, ok := quizAttemptVars(w, r)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.Service.SubmitAttempt(classID, quizID, attemptID, userID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to submit attempt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+func (h *QuizHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := h.Service.GetResults(classID, quizID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get quiz results")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetAttempts - ?needs_grading=true untuk percobaan yang masih punya soal esai belum dinilai
+func (h *QuizHandler) GetAttempts(w http.ResponseWriter, r *http.Request) {
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return
+	}
+	needsGrading, _ := strconv.ParseBool(r.URL.Query().Get("needs_grading"))
+
+	attempts, err := h.Service.GetAttempts(classID, quizID, needsGrading)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get attempts")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempts)
+}
+
+func (h *QuizHandler) GetAttempt(w http.ResponseWriter, r *http.Request) {
+	_, classID, quizID, attemptID, ok := quizAttemptVars(w, r)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.Service.GetAttempt(classID, quizID, attemptID)
+	if err != nil {
+		writeQuizError(w, err, "Failed to get attempt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+// GradeItem - JSON: {"points": 4, "feedback": "..."}
+func (h *QuizHandler) GradeItem(w http.ResponseWriter, r *http.Request) {
+	_, classID, quizID, attemptID, ok := quizAttemptVars(w, r)
+	if !ok {
+		return
+	}
+	itemID, ok := quizPathID(w, r, "item_id", "Invalid item ID")
+	if !ok {
+		return
+	}
+
+	var req dto.GradeItemRequest
+	if !decodeQuizRequest(w, r, &req) {
+		return
+	}
+
+	attempt, err := h.Service.GradeItem(classID, quizID, attemptID, itemID, req)
+	if err != nil {
+		writeQuizError(w, err, "Failed to grade question")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempt)
+}
+
+func decodeQuizRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return false
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func quizPathID(w http.ResponseWriter, r *http.Request, name, message string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		http.Error(w, message, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func quizVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	classID, ok := quizPathID(w, r, "id", "Invalid class ID")
+	if !ok {
+		return 0, 0, false
+	}
+	quizID, ok := quizPathID(w, r, "quiz_id", "Invalid quiz ID")
+	if !ok {
+		return 0, 0, false
+	}
+	return classID, quizID, true
+}
+
+func quizAttemptVars(w http.ResponseWriter, r *http.Request) (int, int, int, int, bool) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	classID, quizID, ok := quizVars(w, r)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	attemptID, ok := quizPathID(w, r, "attempt_id", "Invalid attempt ID")
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	return userID, classID, quizID, attemptID, true
+}
+
+func writeQuizError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrQuizNotFound):
+		http.Error(w, "Quiz not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrQuestionNotFound), errors.Is(err, service.ErrQuizItemNotFound):
+		http.Error(w, "Question not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrBankQuestionNotFound):
+		http.Error(w, "Bank question not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAttemptNotFound):
+		http.Error(w, "Attempt not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionNotStudent):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrQuizNotOpen), errors.Is(err, service.ErrNoAttemptsLeft),
+		errors.Is(err, service.ErrQuizEmpty), errors.Is(err, service.ErrAttemptFinished),
+		errors.Is(err, service.ErrAttemptNotScored), errors.Is(err, service.ErrQuestionPoolTooSmall):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
     "encoding/json"
     "net/http"
+    "project/middleware"
     "project/service"
     "strconv"
 
@@ -27,11 +28,29 @@ func (h *RapotHandler) GetRapotByUserID(w http.ResponseWriter, r *http.Request)
         return
     }
 
+    // Siswa hanya melihat rapotnya sendiri; guru hanya melihat baris kelas yang ia nilai
+    currentID, _ := r.Context().Value("id").(int)
+    role, _ := r.Context().Value("role").(string)
+    self := currentID == userID || role == "Admin"
+    if !self && role != "Guru" {
+        http.Error(w, "Forbidden: you can only view your own report", http.StatusForbidden)
+        return
+    }
+
     rapots, err := h.Service.GetRapotByUserID(userID)
     if err != nil {
         http.Error(w, "Failed to fetch rapot: "+err.Error(), http.StatusInternalServerError)
         return
     }
+    if !self {
+        visible := rapots[:0]
+        for _, rapot := range rapots {
+            if hasClassPermission(r, rapot.ClassID, middleware.ClassPermGrade) {
+                visible = append(visible, rapot)
+            }
+        }
+        rapots = visible
+    }
 
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(rapots)
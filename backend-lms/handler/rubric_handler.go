@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type RubricHandler struct {
+	Service *service.RubricService
+}
+
+func NewRubricHandler(service *service.RubricService) *RubricHandler {
+	return &RubricHandler{Service: service}
+}
+
+// GetRubrics - Rubrik milik guru yang login (Admin melihat semua)
+func (h *RubricHandler) GetRubrics(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	rubrics, err := h.Service.ListRubrics(userID, role)
+	if err != nil {
+		writeRubricError(w, err, "Failed to get rubrics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rubrics)
+}
+
+func (h *RubricHandler) GetRubric(w http.ResponseWriter, r *http.Request) {
+	userID, role, rubricID, ok := rubricVars(w, r)
+	if !ok {
+		return
+	}
+
+	rubric, err := h.Service.GetRubric(rubricID, userID, role)
+	if err != nil {
+		writeRubricError(w, err, "Failed to get rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rubric)
+}
+
+// CreateRubric - JSON: {"title": "...", "criteria": [{"title": "...", "levels": [{"title": "Baik", "points": 4}]}]}
+func (h *RubricHandler) CreateRubric(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RubricRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rubric, err := h.Service.CreateRubric(userID, req)
+	if err != nil {
+		writeRubricError(w, err, "Failed to create rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rubric)
+}
+
+func (h *RubricHandler) UpdateRubric(w http.ResponseWriter, r *http.Request) {
+	userID, role, rubricID, ok := rubricVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RubricRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rubric, err := h.Service.UpdateRubric(rubricID, userID, role, req)
+	if err != nil {
+		writeRubricError(w, err, "Failed to update rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rubric)
+}
+
+func (h *RubricHandler) DeleteRubric(w http.ResponseWriter, r *http.Request) {
+	userID, role, rubricID, ok := rubricVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteRubric(rubricID, userID, role); err != nil {
+		writeRubricError(w, err, "Failed to delete rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Rubric deleted successfully",
+	})
+}
+
+func (h *RubricHandler) CopyRubric(w http.ResponseWriter, r *http.Request) {
+	userID, role, rubricID, ok := rubricVars(w, r)
+	if !ok {
+		return
+	}
+
+	rubric, err := h.Service.CopyRubric(rubricID, userID, role)
+	if err != nil {
+		writeRubricError(w, err, "Failed to copy rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rubric)
+}
+
+// GetAssignmentRubric - Rubrik tugas, bisa dilihat siswa sebelum mengumpulkan
+func (h *RubricHandler) GetAssignmentRubric(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	rubric, err := h.Service.GetAssignmentRubric(classID, assignmentID)
+	if err != nil {
+		writeRubricError(w, err, "Failed to get rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rubric)
+}
+
+// AttachRubric - JSON: {"rubric_id": 5}, atau {"rubric_id": null} untuk melepas rubrik
+func (h *RubricHandler) AttachRubric(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.AttachRubricRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	rubric, err := h.Service.AttachToAssignment(classID, assignmentID, userID, role, req.RubricID)
+	if err != nil {
+		writeRubricError(w, err, "Failed to attach rubric")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"assignment_id": assignmentID,
+		"rubric":        rubric,
+	})
+}
+
+func rubricVars(w http.ResponseWriter, r *http.Request) (int, string, int, bool) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return 0, "", 0, false
+	}
+	role, _ := r.Context().Value("role").(string)
+	rubricID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid rubric ID", http.StatusBadRequest)
+		return 0, "", 0, false
+	}
+	return userID, role, rubricID, true
+}
+
+func writeRubricError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrRubricNotFound):
+		http.Error(w, "Rubric not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrNoRubric):
+		http.Error(w, "Assignment has no rubric", http.StatusNotFound)
+	case errors.Is(err, service.ErrAssignmentNotFound):
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrRubricInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/config"
+	"project/dto"
+	"project/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ScheduleHandler struct {
+	Service *service.ScheduleService
+}
+
+func NewScheduleHandler(service *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{Service: service}
+}
+
+// GetClassSchedule - Jadwal mingguan kelas beserta pengecualian (libur kelas dan libur umum)
+func (h *ScheduleHandler) GetClassSchedule(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := h.Service.GetClassSchedules(classID)
+	if err != nil {
+		http.Error(w, "Failed to get schedule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exceptions, err := h.Service.GetExceptions(&classID)
+	if err != nil {
+		http.Error(w, "Failed to get schedule exceptions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"class_id":   classID,
+		"schedules":  schedules,
+		"exceptions": exceptions,
+	})
+}
+
+func (h *ScheduleHandler) AddClassException(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	h.addException(w, r, &classID)
+}
+
+func (h *ScheduleHandler) DeleteClassException(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	h.deleteException(w, r, &classID, "exception_id")
+}
+
+// GetHolidays - Daftar libur umum yang berlaku untuk semua kelas
+func (h *ScheduleHandler) GetHolidays(w http.ResponseWriter, r *http.Request) {
+	holidays, err := h.Service.GetExceptions(nil)
+	if err != nil {
+		http.Error(w, "Failed to get holidays: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(holidays)
+}
+
+func (h *ScheduleHandler) AddHoliday(w http.ResponseWriter, r *http.Request) {
+	h.addException(w, r, nil)
+}
+
+func (h *ScheduleHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	h.deleteException(w, r, nil, "id")
+}
+
+// GetTimetable - Jadwal pertemuan user. Query: from=YYYY-MM-DD (default hari ini), days (default 7)
+func (h *ScheduleHandler) GetTimetable(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	loc := config.DefaultLocation()
+	from := time.Now().In(loc)
+	var err error
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.ParseInLocation("2006-01-02", value, loc); err != nil {
+			http.Error(w, "Invalid from date, use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	days := 7
+	if value := r.URL.Query().Get("days"); value != "" {
+		if days, err = strconv.Atoi(value); err != nil || days < 1 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	timetable, err := h.Service.GetTimetable(userID, from, days)
+	if err != nil {
+		http.Error(w, "Failed to get timetable: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timetable)
+}
+
+func (h *ScheduleHandler) addException(w http.ResponseWriter, r *http.Request, classID *int) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ScheduleExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	exception, err := h.Service.AddException(classID, req, userID)
+	if err != nil {
+		if validationErr, ok := service.AsValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		http.Error(w, "Failed to save schedule exception: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(exception)
+}
+
+func (h *ScheduleHandler) deleteException(w http.ResponseWriter, r *http.Request, classID *int, idVar string) {
+	exceptionID, err := strconv.Atoi(mux.Vars(r)[idVar])
+	if err != nil {
+		http.Error(w, "Invalid exception ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteException(classID, exceptionID); err != nil {
+		if errors.Is(err, service.ErrScheduleExceptionNotFound) {
+			http.Error(w, "Schedule exception not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to delete schedule exception", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Schedule exception deleted successfully",
+	})
+}
+
+// writeScheduleError menangani error validasi dan bentrok jadwal dari create/update kelas.
+// Mengembalikan false jika err bukan salah satunya.
+func writeScheduleError(w http.ResponseWriter, err error) bool {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return true
+	}
+	var conflictErr *service.ScheduleConflictError
+	if errors.As(err, &conflictErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(dto.ScheduleConflictResponse{
+			Status:    "error",
+			Message:   "Schedule conflicts with other classes, resend with ignore_conflicts to save anyway",
+			Conflicts: conflictErr.Conflicts,
+		})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+type SecurityHandler struct {
+	Service *service.SecurityService
+}
+
+func NewSecurityHandler(service *service.SecurityService) *SecurityHandler {
+	return &SecurityHandler{Service: service}
+}
+
+// GetSessions - Daftar sesi aktif user, sesi yang sedang dipakai ditandai current
+func (h *SecurityHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.Service.GetActiveSessions(userID, currentSessionID(r))
+	if err != nil {
+		http.Error(w, "Failed to get sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func (h *SecurityHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.RevokeSession(userID, mux.Vars(r)["id"], clientInfo(r)); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions - Logout dari semua perangkat lain
+func (h *SecurityHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.Service.RevokeAllSessions(userID, currentSessionID(r))
+	if err != nil {
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Other sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
+func (h *SecurityHandler) GetLoginHistory(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.Service.GetLoginHistory(userID, queryLimit(r))
+	if err != nil {
+		http.Error(w, "Failed to get login history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetSecurityEvents - Feed event keamanan untuk admin. Filter: type, user_id, before_id, limit
+func (h *SecurityHandler) GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	userID, beforeID := 0, 0
+	var err error
+	if value := query.Get("user_id"); value != "" {
+		if userID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("before_id"); value != "" {
+		if beforeID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid before_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := h.Service.GetEvents(query.Get("type"), userID, beforeID, queryLimit(r))
+	if err != nil {
+		http.Error(w, "Failed to get security events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+func queryLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		return maxHistoryLimit
+	}
+	return limit
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"project/dto"
+	"project/service"
+)
+
+type SimilarityHandler struct {
+	Service *service.SimilarityService
+}
+
+func NewSimilarityHandler(service *service.SimilarityService) *SimilarityHandler {
+	return &SimilarityHandler{Service: service}
+}
+
+// CheckSimilarity - JSON (opsional): {"threshold": 0.2, "other_classes": true}
+func (h *SimilarityHandler) CheckSimilarity(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SimilarityCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, _ := r.Context().Value("role").(string)
+	report, err := h.Service.CheckAssignment(classID, assignmentID, userID, role, req)
+	if err != nil {
+		writeSimilarityError(w, err, "Failed to check similarity")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetSimilarityReport - Hasil pemeriksaan kemiripan terakhir untuk tugas
+func (h *SimilarityHandler) GetSimilarityReport(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetReport(classID, assignmentID)
+	if err != nil {
+		writeSimilarityError(w, err, "Failed to get similarity report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetSubmissionSimilarity - Pengumpulan yang mirip dengan satu pengumpulan beserta potongan teksnya
+func (h *SimilarityHandler) GetSubmissionSimilarity(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.Service.GetSubmissionSimilarity(classID, assignmentID, submissionID)
+	if err != nil {
+		writeSimilarityError(w, err, "Failed to get similarity report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeSimilarityError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssignmentNotFound):
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionNotFound):
+		http.Error(w, "Submission not found in the similarity report", http.StatusNotFound)
+	case errors.Is(err, service.ErrSimilarityNotChecked):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
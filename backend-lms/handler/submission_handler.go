@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/middleware"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SubmissionHandler struct {
+	Service *service.SubmissionService
+}
+
+func NewSubmissionHandler(service *service.SubmissionService) *SubmissionHandler {
+	return &SubmissionHandler{Service: service}
+}
+
+// GetMySubmission - Pengumpulan milik siswa yang sedang login
+func (h *SubmissionHandler) GetMySubmission(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.Service.GetMySubmission(classID, assignmentID, userID)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to get submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// SaveDraft - Multipart form: text_answer (opsional) dan file files (boleh lebih dari satu).
+// File baru ditambahkan ke file yang sudah ada
+func (h *SubmissionHandler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.SaveSubmissionRequest
+	if values, ok := r.MultipartForm.Value["text_answer"]; ok && len(values) > 0 {
+		req.TextAnswer = &values[0]
+	}
+
+	var err error
+	req.Files, err = uploadFormFiles(r, "files")
+	if err != nil {
+		http.Error(w, "Failed to upload file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	submission, err := h.Service.SaveDraft(classID, assignmentID, userID, req)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to save submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+func (h *SubmissionHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+	fileID, err := strconv.Atoi(mux.Vars(r)["file_id"])
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteFile(classID, assignmentID, userID, fileID); err != nil {
+		writeSubmissionError(w, err, "Failed to delete file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "File deleted successfully",
+	})
+}
+
+func (h *SubmissionHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.Service.Submit(classID, assignmentID, userID)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to submit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+func (h *SubmissionHandler) Unsubmit(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.Service.Unsubmit(classID, assignmentID, userID)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to unsubmit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// GetSubmissions - Daftar pengumpulan per siswa untuk guru. Query: status
+// (not_submitted, submitted, late, returned, graded)
+func (h *SubmissionHandler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", service.SubmissionNotSubmitted, service.SubmissionSubmitted, service.SubmissionLate,
+		service.SubmissionReturned, service.SubmissionGraded:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	submissions, err := h.Service.GetSubmissions(classID, assignmentID, status)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to get submissions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submissions)
+}
+
+func (h *SubmissionHandler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.Service.GetSubmission(classID, assignmentID, submissionID)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to get submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// GradeSubmission - JSON: {"points": 85, "feedback": "...", "return": false}
+func (h *SubmissionHandler) GradeSubmission(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.GradeSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	submission, err := h.Service.GradeSubmission(classID, assignmentID, submissionID, userID, req)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to grade submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// GradeWithRubric - JSON: {"scores": [{"criterion_id": 1, "level_id": 3, "comment": ""}], "feedback": "", "return": false}
+func (h *SubmissionHandler) GradeWithRubric(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RubricGradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	submission, err := h.Service.GradeWithRubric(classID, assignmentID, submissionID, userID, req)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to grade submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+func (h *SubmissionHandler) ReturnSubmission(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.Service.ReturnSubmission(classID, assignmentID, submissionID)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to return submission")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// AddComment - JSON: {"file_id": 3, "page": 2, "anchor": "kutipan", "body": "..."}
+func (h *SubmissionHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SubmissionCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.Service.AddComment(classID, assignmentID, submissionID, userID, req)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to add comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (h *SubmissionHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	classID, assignmentID, submissionID, ok := submissionDetailVars(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := strconv.Atoi(mux.Vars(r)["comment_id"])
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteComment(classID, assignmentID, submissionID, commentID); err != nil {
+		writeSubmissionError(w, err, "Failed to delete comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Comment deleted successfully",
+	})
+}
+
+func submissionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	assignmentID, err := strconv.Atoi(vars["assignment_id"])
+	if err != nil {
+		http.Error(w, "Invalid assignment ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return classID, assignmentID, true
+}
+
+func submissionDetailVars(w http.ResponseWriter, r *http.Request) (int, int, int, bool) {
+	classID, assignmentID, ok := submissionVars(w, r)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	submissionID, err := strconv.Atoi(mux.Vars(r)["submission_id"])
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return 0, 0, 0, false
+	}
+	return classID, assignmentID, submissionID, true
+}
+
+// SubmitLegacy - Kompatibilitas client lama yang mengumpulkan lewat POST /assignment/{class_id}
+// (form title, description, attachment). Dipanggil dari AssignmentHandler.CreateAssignment untuk siswa
+func (h *SubmissionHandler) SubmitLegacy(w http.ResponseWriter, r *http.Request, classID int) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !hasClassPermission(r, classID, middleware.ClassPermParticipate) {
+		http.Error(w, "Forbidden: your role in this class does not allow this action", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.SaveSubmissionRequest
+	description := r.FormValue("description")
+	req.TextAnswer = &description
+
+	var err error
+	req.Files, err = uploadFormFiles(r, "attachment")
+	if err != nil {
+		http.Error(w, "Failed to upload file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	submission, err := h.Service.SubmitByTitle(classID, userID, r.FormValue("title"), req)
+	if err != nil {
+		writeSubmissionError(w, err, "Failed to submit assignment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(submission)
+}
+
+func writeSubmissionError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrAssignmentNotFound):
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionNotFound):
+		http.Error(w, "Submission not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		http.Error(w, "File not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrNoRubric):
+		http.Error(w, "Assignment has no rubric", http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionNotStudent):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrSubmissionLocked), errors.Is(err, service.ErrSubmissionEmpty),
+		errors.Is(err, service.ErrSubmissionPastDue), errors.Is(err, service.ErrSubmissionNotTurnedIn):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TokenHandler struct {
+	Service *service.TokenService
+}
+
+func NewTokenHandler(service *service.TokenService) *TokenHandler {
+	return &TokenHandler{Service: service}
+}
+
+// CreateToken - Membuat personal access token baru untuk user yang sedang login
+func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.Service.CreateToken(userID, req)
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create access token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *TokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.Service.GetTokens(userID)
+	if err != nil {
+		http.Error(w, "Failed to get access tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (h *TokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.RevokeToken(userID, tokenID); err != nil {
+		if errors.Is(err, service.ErrAccessTokenNotFound) {
+			http.Error(w, "Access token not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to revoke access token", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Access token revoked successfully",
+	})
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"project/dto"
+	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type UnitHandler struct {
+	Service *service.UnitService
+}
+
+func NewUnitHandler(service *service.UnitService) *UnitHandler {
+	return &UnitHandler{Service: service}
+}
+
+// GetOutline - Struktur lengkap kelas: unit berurutan beserta materi dan tugasnya
+func (h *UnitHandler) GetOutline(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	outline, err := h.Service.GetOutline(classID)
+	if err != nil {
+		http.Error(w, "Failed to get class outline: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outline)
+}
+
+func (h *UnitHandler) GetUnits(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	units, err := h.Service.GetUnits(classID)
+	if err != nil {
+		http.Error(w, "Failed to get units: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(units)
+}
+
+func (h *UnitHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UnitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	unit, err := h.Service.CreateUnit(classID, req)
+	if err != nil {
+		http.Error(w, "Failed to create unit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(unit)
+}
+
+func (h *UnitHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	classID, unitID, ok := unitPathIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UnitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	unit, err := h.Service.UpdateUnit(classID, unitID, req)
+	if err != nil {
+		writeUnitError(w, err, "Failed to update unit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unit)
+}
+
+// DeleteUnit - Materi dan tugas di dalam unit tidak ikut terhapus
+func (h *UnitHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	classID, unitID, ok := unitPathIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteUnit(classID, unitID); err != nil {
+		writeUnitError(w, err, "Failed to delete unit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Unit deleted successfully",
+	})
+}
+
+func (h *UnitHandler) ReorderUnits(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ReorderUnitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	units, err := h.Service.ReorderUnits(classID, req.UnitIDs)
+	if err != nil {
+		writeUnitError(w, err, "Failed to reorder units")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(units)
+}
+
+func (h *UnitHandler) ReorderUnitItems(w http.ResponseWriter, r *http.Request) {
+	classID, unitID, ok := unitPathIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderUnitItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.ReorderUnitItems(classID, unitID, req.Items); err != nil {
+		writeUnitError(w, err, "Failed to reorder unit items")
+		return
+	}
+	h.GetOutline(w, r)
+}
+
+// MoveItem - Memindahkan satu materi/tugas ke unit dan posisi tertentu
+func (h *UnitHandler) MoveItem(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.MoveOutlineItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.MoveItem(classID, req); err != nil {
+		writeUnitError(w, err, "Failed to move item")
+		return
+	}
+	h.GetOutline(w, r)
+}
+
+func unitPathIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	classID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	unitID, err := strconv.Atoi(vars["unit_id"])
+	if err != nil {
+		http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return classID, unitID, true
+}
+
+// formUnitID membaca unit_id opsional dari form multipart materi/tugas.
+func formUnitID(r *http.Request) (*int, error) {
+	value := r.FormValue("unit_id")
+	if value == "" {
+		return nil, nil
+	}
+	unitID, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &unitID, nil
+}
+
+func writeUnitError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrUnitNotFound), errors.Is(err, service.ErrOutlineItemNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"project/model"
+	"project/utils"
+)
+
+// uploadFormFiles mengunggah semua file di field multipart yang sama (misalnya "attachments").
+// ParseMultipartForm harus sudah dipanggil.
+func uploadFormFiles(r *http.Request, field string) ([]model.Attachment, error) {
+	if r.MultipartForm == nil {
+		return nil, nil
+	}
+
+	var attachments []model.Attachment
+	for _, fileHeader := range r.MultipartForm.File[field] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", fileHeader.Filename, err)
+		}
+		url, err := utils.UploadImage(file, fileHeader)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, model.Attachment{URL: url, FileName: fileHeader.Filename})
+	}
+	return attachments, nil
+}
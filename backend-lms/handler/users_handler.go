@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"project/dto"
 	"project/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
 )
 
 type AuthHandler struct {
@@ -32,6 +37,10 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err := h.AuthService.Register(request.Username, request.Password, request.Role)
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
 	if err != nil {
 		fmt.Printf("Failed to register user: %v", err) // Log the error for debugging
 		http.Error(w, "Failed to register user: "+err.Error(), http.StatusInternalServerError)
@@ -56,13 +65,46 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.AuthService.Login(request.Username, request.Password)
+	result, err := h.AuthService.Login(request.Username, request.Password, clientInfo(r))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// LoginMFA menyelesaikan login dua langkah dengan MFA challenge token dan kode TOTP/recovery code.
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFALoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.AuthService.CompleteMFALogin(req.MFAToken, req.Code, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFAChallenge),
+			errors.Is(err, service.ErrInvalidMFACode),
+			errors.Is(err, service.ErrMFANotEnrolled):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, service.ErrTooManyMFAAttempts):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		default:
+			http.Error(w, "Failed to verify two-factor code: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
 func (h *UserHandler) GetRoleCounts(w http.ResponseWriter, r *http.Request) {
@@ -77,4 +119,168 @@ func (h *UserHandler) GetRoleCounts(w http.ResponseWriter, r *http.Request) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
     json.NewEncoder(w).Encode(roleCounts)
-}
\ No newline at end of file
+}
+// ChangePassword mengganti password user yang sedang login. Sesi lain otomatis dicabut.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.AuthService.ChangePassword(userID, currentSessionID(r), req, clientInfo(r))
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			http.Error(w, "Current password is incorrect", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to change password: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password changed successfully"})
+}
+
+// ResetPassword - Admin mengganti password user lain
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	actorID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.AuthService.ResetPassword(actorID, userID, req.NewPassword, clientInfo(r))
+	if validationErr, ok := service.AsValidationError(err); ok {
+		writeValidationError(w, validationErr)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to reset password: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password reset successfully"})
+}
+
+// UpdateRole - Admin mengganti role user
+func (h *AuthHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	actorID, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.AuthService.UpdateRole(actorID, userID, req.Role, clientInfo(r)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to update role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Role updated successfully"})
+}
+
+func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.UserService.GetUsers(r.URL.Query().Get("role"))
+	if err != nil {
+		http.Error(w, "Failed to get users: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// UpdateProfile - Admin mengisi nama lengkap dan nomor induk user
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.UserService.UpdateProfile(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrIdentifierTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to update profile: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
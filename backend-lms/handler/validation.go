@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project/dto"
+	"project/service"
+)
+
+// writeValidationError mengirim pelanggaran validasi dari service sebagai response 422.
+func writeValidationError(w http.ResponseWriter, err *service.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(dto.ValidationErrorResponse{
+		Status:  "error",
+		Message: "Validation failed",
+		Errors:  err.Errors,
+	})
+}
@@ -0,0 +1,325 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"project/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SigningKey adalah satu pasangan key untuk menandatangani/memverifikasi JWT.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	CreatedAt  time.Time
+	// RetiresAt diisi saat key digantikan key baru. Sampai waktu itu key masih
+	// dipakai untuk verifikasi supaya token lama tidak langsung invalid.
+	RetiresAt time.Time
+}
+
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// KeyStore menyimpan key aktif untuk signing dan key lama yang masih boleh dipakai untuk verifikasi.
+type KeyStore struct {
+	mu        sync.RWMutex
+	algorithm string
+	dir       string
+	// verifyFor adalah lama key lama tetap valid untuk verifikasi setelah dirotasi.
+	verifyFor time.Duration
+	current   *SigningKey
+	keys      map[string]*SigningKey
+}
+
+// NewKeyStore memuat key dari dir (jika diisi) atau membuat key baru.
+func NewKeyStore(algorithm, dir string, verifyFor time.Duration) (*KeyStore, error) {
+	if algorithm != "RS256" && algorithm != "EdDSA" {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	ks := &KeyStore{
+		algorithm: algorithm,
+		dir:       dir,
+		verifyFor: verifyFor,
+		keys:      make(map[string]*SigningKey),
+	}
+
+	if dir != "" {
+		if err := ks.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ks.current == nil {
+		if _, err := ks.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// Rotate membuat key baru sebagai signing key. Key sebelumnya tetap bisa memverifikasi
+// token sampai verifyFor berlalu.
+func (ks *KeyStore) Rotate() (*SigningKey, error) {
+	key, err := generateSigningKey(ks.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if ks.dir != "" {
+		if err := ks.save(key); err != nil {
+			return nil, err
+		}
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range ks.keys {
+		if existing.RetiresAt.IsZero() {
+			existing.RetiresAt = now.Add(ks.verifyFor)
+		}
+		if now.After(existing.RetiresAt) {
+			delete(ks.keys, id)
+			ks.remove(id)
+		}
+	}
+
+	ks.keys[key.ID] = key
+	ks.current = key
+	log.Printf("JWT signing key rotated, new kid: %s (%s)", key.ID, key.Algorithm)
+	return key, nil
+}
+
+// StartRotation mengecek umur signing key secara berkala dan merotasinya setelah interval.
+func (ks *KeyStore) StartRotation(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(time.Minute)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if time.Since(ks.Current().CreatedAt) >= interval {
+					if _, err := ks.Rotate(); err != nil {
+						log.Printf("Failed to rotate JWT signing key: %v", err)
+					}
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (ks *KeyStore) Current() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.current
+}
+
+// VerificationKey mengembalikan key dengan kid tersebut jika belum pensiun.
+func (ks *KeyStore) VerificationKey(kid string) (*SigningKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, found := ks.keys[kid]
+	if !found {
+		return nil, false
+	}
+	if !key.RetiresAt.IsZero() && time.Now().After(key.RetiresAt) {
+		return nil, false
+	}
+	return key, true
+}
+
+// JWKS mengembalikan semua public key yang masih valid untuk verifikasi.
+func (ks *KeyStore) JWKS() utils.JSONWebKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var active []*SigningKey
+	now := time.Now()
+	for _, key := range ks.keys {
+		if key.RetiresAt.IsZero() || now.Before(key.RetiresAt) {
+			active = append(active, key)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.After(active[j].CreatedAt) })
+
+	set := utils.JSONWebKeySet{Keys: []utils.JSONWebKey{}}
+	for _, key := range active {
+		set.Keys = append(set.Keys, toJSONWebKey(key))
+	}
+	return set
+}
+
+func toJSONWebKey(key *SigningKey) utils.JSONWebKey {
+	jwk := utils.JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+
+	switch pub := key.PublicKey().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var signer crypto.Signer
+	switch algorithm {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		signer = key
+	case "EdDSA":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		signer = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	kid, err := keyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: kid, Algorithm: algorithm, PrivateKey: signer, CreatedAt: time.Now()}, nil
+}
+
+// keyID adalah thumbprint SHA-256 dari public key (16 byte pertama).
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
+
+// load membaca semua <kid>.pem di dir. Key terbaru menjadi signing key, key lain
+// dipensiunkan relatif terhadap waktu pembuatan key terbaru.
+func (ks *KeyStore) load() error {
+	if err := os.MkdirAll(ks.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create JWT keys dir: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(ks.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	var loaded []*SigningKey
+	for _, file := range files {
+		key, err := readSigningKey(file)
+		if err != nil {
+			log.Printf("Skipping JWT key %s: %v", file, err)
+			continue
+		}
+		if key.Algorithm == ks.algorithm {
+			loaded = append(loaded, key)
+		}
+	}
+	if len(loaded) == 0 {
+		return nil
+	}
+
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].CreatedAt.After(loaded[j].CreatedAt) })
+	ks.current = loaded[0]
+	for i, key := range loaded {
+		if i > 0 {
+			key.RetiresAt = loaded[i-1].CreatedAt.Add(ks.verifyFor)
+		}
+		if !key.RetiresAt.IsZero() && time.Now().After(key.RetiresAt) {
+			ks.remove(key.ID)
+			continue
+		}
+		ks.keys[key.ID] = key
+	}
+	return nil
+}
+
+func readSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{CreatedAt: info.ModTime()}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.PrivateKey = "RS256", private
+	case ed25519.PrivateKey:
+		key.Algorithm, key.PrivateKey = "EdDSA", private
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	key.ID = strings.TrimSuffix(filepath.Base(path), ".pem")
+	return key, nil
+}
+
+func (ks *KeyStore) save(key *SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	path := filepath.Join(ks.dir, key.ID+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to store JWT key: %w", err)
+	}
+	return nil
+}
+
+func (ks *KeyStore) remove(kid string) {
+	if ks.dir == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(ks.dir, kid+".pem")); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove retired JWT key %s: %v", kid, err)
+	}
+}
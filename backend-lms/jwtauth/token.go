@@ -0,0 +1,143 @@
+package jwtauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"project/config"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Claims struct untuk JWT
+type Claims struct {
+	UserID   int    `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// Purpose diisi untuk token khusus (misalnya MFA challenge) yang tidak boleh dipakai mengakses API
+	Purpose string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// MFASetupPurpose adalah purpose token login untuk user yang wajib 2FA tetapi belum enroll.
+// Token ini hanya diterima di route yang didaftarkan dengan option middleware.AllowMFASetup.
+const MFASetupPurpose = "mfa_setup"
+
+// Keys dipakai untuk menandatangani dan memverifikasi semua JWT. Diisi di main lewat InitKeyStore.
+var Keys *KeyStore
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// InitKeyStore membuat key store dari config.
+func InitKeyStore() (*KeyStore, error) {
+	// Key lama tetap valid selama umur token terpanjang ditambah sedikit toleransi
+	verifyFor := time.Duration(config.JWTTokenTTLMinutes)*time.Minute + 5*time.Minute
+
+	ks, err := NewKeyStore(config.JWTAlgorithm, config.JWTKeysDir, verifyFor)
+	if err != nil {
+		return nil, err
+	}
+	Keys = ks
+	return ks, nil
+}
+
+// MFAAudience adalah audience untuk token MFA challenge, berbeda dari audience API.
+func MFAAudience() string {
+	return config.JWTAudience + ":mfa"
+}
+
+// GenerateSessionToken menerbitkan JWT login dengan sessionID sebagai jti supaya token
+// bisa dicabut lewat middleware.SessionValidator.
+// purpose kosong untuk token biasa, MFASetupPurpose untuk token yang hanya bisa enroll 2FA.
+func GenerateSessionToken(sessionID string, id int, username, role, purpose string) (string, error) {
+	claims := &Claims{
+		UserID:   id,
+		Username: username,
+		Role:     role,
+		Purpose:  purpose,
+	}
+	claims.ID = sessionID
+
+	return IssueToken(claims, config.JWTAudience, SessionTTL())
+}
+
+// SessionTTL adalah umur JWT login biasa.
+func SessionTTL() time.Duration {
+	return time.Duration(config.JWTTokenTTLMinutes) * time.Minute
+}
+
+// IssueToken menandatangani claims dengan signing key aktif. iss, aud, iat, nbf, exp
+// dan jti diisi otomatis.
+func IssueToken(claims *Claims, audience string, ttl time.Duration) (string, error) {
+	key := Keys.Current()
+
+	jti, err := randomJTI()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.Issuer = config.JWTIssuer
+	claims.Audience = jwt.ClaimStrings{audience}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	if claims.ID == "" {
+		claims.ID = jti
+	}
+
+	var method jwt.SigningMethod = jwt.SigningMethodRS256
+	if key.Algorithm == "EdDSA" {
+		method = jwt.SigningMethodEdDSA
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// ParseToken memverifikasi signature (berdasarkan kid), iss, aud, exp dan nbf.
+// exp dan nbf wajib ada.
+func ParseToken(tokenStr, audience string) (*Claims, error) {
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "EdDSA"}))
+
+	token, err := parser.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, found := Keys.VerificationKey(kid)
+		if !found {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.PublicKey(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.ExpiresAt == nil || claims.NotBefore == nil {
+		return nil, fmt.Errorf("%w: missing exp or nbf", ErrInvalidToken)
+	}
+	if !claims.VerifyIssuer(config.JWTIssuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func randomJTI() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
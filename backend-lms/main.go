@@ -5,9 +5,13 @@ import (
 	"net/http"
 	"project/config"
 	"project/handler"
+	"project/jwtauth"
 	"project/middleware"
 	"project/postgres"
 	"project/service"
+	"time"
+	// Data timezone ikut di binary supaya DEFAULT_TIMEZONE tetap bisa dimuat di image tanpa zoneinfo
+	_ "time/tzdata"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -17,21 +21,65 @@ func main() {
 	db := postgres.Connect()
 	defer db.Close()
 	config.InitSupabase()
+	if err := config.InitTimezone(); err != nil {
+		log.Fatalf("Failed to load timezone: %v", err)
+	}
+
+	keyStore, err := jwtauth.InitKeyStore()
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT keys: %v", err)
+	}
+	stopRotation := keyStore.StartRotation(time.Duration(config.JWTRotationHours) * time.Hour)
+	defer stopRotation()
+	jwksHandler := handler.JWKSHandler{Keys: keyStore}
 
 	forumService := service.NewForumService(db)
 	forumHandler := handler.NewForumHandler(forumService)
 	commentService := service.NewCommentService(db)
 	commentHandler := handler.NewCommentHandler(commentService)
-	authService := service.AuthService{DB: db}
-	authHandler := handler.AuthHandler{AuthService: &authService}
+	mfaService := service.NewMFAService(db)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	securityService := service.NewSecurityService(db)
+	securityHandler := handler.NewSecurityHandler(securityService)
+	middleware.SessionValidator = securityService.ValidateSession
+	authService := service.NewAuthService(db, mfaService, securityService)
+	authHandler := handler.AuthHandler{AuthService: authService}
+	tokenService := service.NewTokenService(db)
+	tokenHandler := handler.NewTokenHandler(tokenService)
+	middleware.AccessTokenAuthenticator = tokenService.Authenticate
+	oidcService := service.NewOIDCService(db, authService, service.OIDCConfigFromEnv())
+	oidcHandler := handler.NewOIDCHandler(oidcService)
 	userService := service.UserService{DB: db}
 	userHandler := handler.UserHandler{UserService: &userService}
 	classService := service.ClassService{DB: db}
+	middleware.ClassPermissionChecker = classService.CheckClassPermission
 	classHandler := handler.ClassHandler{Service: &classService}
+	scheduleService := service.NewScheduleService(db)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+	academicService := service.NewAcademicService(db)
+	academicHandler := handler.NewAcademicHandler(academicService)
+	unitService := service.NewUnitService(db)
+	unitHandler := handler.NewUnitHandler(unitService)
+	attendanceService := service.NewAttendanceService(db)
+	attendanceHandler := handler.NewAttendanceHandler(attendanceService)
+	leaveService := service.NewLeaveService(db)
+	leaveHandler := handler.NewLeaveHandler(leaveService)
+	announcementService := service.NewAnnouncementService(db)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
+	submissionService := service.NewSubmissionService(db)
+	submissionHandler := handler.NewSubmissionHandler(submissionService)
+	rubricService := service.NewRubricService(db)
+	rubricHandler := handler.NewRubricHandler(rubricService)
+	quizService := service.NewQuizService(db)
+	quizHandler := handler.NewQuizHandler(quizService)
+	questionBankService := service.NewQuestionBankService(db)
+	questionBankHandler := handler.NewQuestionBankHandler(questionBankService)
+	similarityService := service.NewSimilarityService(db)
+	similarityHandler := handler.NewSimilarityHandler(similarityService)
 	materialService := service.MaterialService{DB: db}
 	materialHandler := handler.MaterialHandler{Service: &materialService}
 	assignmentService := service.AssignmentService{DB: db}
-	assignmentHandler := handler.AssignmentHandler{Service: &assignmentService}
+	assignmentHandler := handler.AssignmentHandler{Service: &assignmentService, Submissions: submissionHandler}
 	rapotService := service.RapotService{DB: db}
 	rapotHandler := handler.RapotHandler{Service: &rapotService}
 	gradeService := service.GradeService{DB: db}
@@ -40,6 +88,8 @@ func main() {
 	router := mux.NewRouter()
 
 	// JWT info Route
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
+
 	router.Handle(
 		"/get-token-claims",
 		middleware.AuthMiddleware(http.HandlerFunc(forumHandler.GetJWTClaims)),
@@ -50,18 +100,20 @@ func main() {
 		"/forums",
 		middleware.AuthMiddleware(
 			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(forumHandler.CreateForum)),
+			middleware.RequireScope(middleware.ScopeForumsWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
 		"/forums",
-		middleware.AuthMiddleware(http.HandlerFunc(forumHandler.GetForums)),
+		middleware.AuthMiddleware(http.HandlerFunc(forumHandler.GetForums), middleware.RequireScope(middleware.ScopeForumsRead)),
 	).Methods("GET")
 
 	router.Handle(
 		"/forums/{id}",
 		middleware.AuthMiddleware(
 			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(forumHandler.DeleteForum)),
+			middleware.RequireScope(middleware.ScopeForumsWrite),
 		),
 	).Methods("DELETE")
 
@@ -70,170 +122,1270 @@ func main() {
 		"/forums/{forumID}/comments",
 		middleware.AuthMiddleware(
 			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(commentHandler.CreateComment)),
+			middleware.RequireScope(middleware.ScopeForumsWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
 		"/forums/{forum_id}/comments",
-		middleware.AuthMiddleware(http.HandlerFunc(commentHandler.GetComments)),
+		middleware.AuthMiddleware(http.HandlerFunc(commentHandler.GetComments), middleware.RequireScope(middleware.ScopeForumsRead)),
 	).Methods("GET")
 
 	router.Handle(
 		"/comments/{comment_id}",
 		middleware.AuthMiddleware(
 			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(commentHandler.DeleteComment)),
+			middleware.RequireScope(middleware.ScopeForumsWrite),
 		),
 	).Methods("DELETE")
 
 	// Class Routes
 	router.Handle(
 		"/classes",
-		middleware.AuthMiddleware(http.HandlerFunc(classHandler.GetClasses)),
+		middleware.AuthMiddleware(http.HandlerFunc(classHandler.GetClasses), middleware.RequireScope(middleware.ScopeClassesRead)),
 	).Methods("GET")
 
 	router.Handle(
 		"/class/{id}",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(classHandler.GetClassByID)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(classHandler.GetClassByID))),
+			middleware.RequireScope(middleware.ScopeClassesRead),
 		),
 	).Methods("GET")
 
 	router.Handle(
 		"/classes/count/{user_id}",
-		middleware.AuthMiddleware(http.HandlerFunc(classHandler.CountClassesByUserID)),
+		middleware.AuthMiddleware(http.HandlerFunc(classHandler.CountClassesByUserID), middleware.RequireScope(middleware.ScopeClassesRead)),
 	).Methods("GET")
 
 	router.Handle(
 		"/class",
 		middleware.AuthMiddleware(
 			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(classHandler.CreateClass)),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
 		"/class/{id}",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(classHandler.DeleteClass)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageTeachers, "id")(http.HandlerFunc(classHandler.DeleteClass))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("DELETE")
 
 	router.Handle(
 		"/class/{id}",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(classHandler.UpdateClass)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.UpdateClass))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("PUT")
 
 	router.Handle(
-		"/class/{class_id}/join",
+		"/class/{id}/code",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(classHandler.JoinClass)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.GetClassCode))),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/code",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.UpdateClassCodeSettings))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/code",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.DisableClassCode))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/code/regenerate",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.RegenerateClassCode))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
-		"/class/{class_id}/members",
+		"/class/{id}/clone",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(classHandler.ManageClassMembers)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.CloneClass))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
-	).Methods("POST", "DELETE")
+	).Methods("POST")
 
 	router.Handle(
-        "/class/{class_id}/members",
-        middleware.AuthMiddleware(http.HandlerFunc(classHandler.GetMembers)),
-    ).Methods("GET")
+		"/class/{id}/archive",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.ArchiveClass))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
 
 	router.Handle(
-        "/classes/student/{student_id}",
-        middleware.AuthMiddleware(http.HandlerFunc(classHandler.GetClassesByStudentID)),
-    ).Methods("GET")
+		"/class/{id}/unarchive",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.UnarchiveClass))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
 
-	// Material Routes
+	// Academic year & term routes
 	router.Handle(
-		"/materials/{class_id}",
-		middleware.AuthMiddleware(http.HandlerFunc(materialHandler.GetMaterials)),
+		"/academic-years",
+		middleware.AuthMiddleware(http.HandlerFunc(academicHandler.GetAcademicYears), middleware.RequireScope(middleware.ScopeClassesRead)),
 	).Methods("GET")
 
 	router.Handle(
-		"/material/{class_id}",
+		"/terms/active",
+		middleware.AuthMiddleware(http.HandlerFunc(academicHandler.GetActiveTerm), middleware.RequireScope(middleware.ScopeClassesRead)),
+	).Methods("GET")
+
+	// Schedule routes
+	router.Handle(
+		"/class/{id}/schedule",
+		middleware.AuthMiddleware(
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(scheduleHandler.GetClassSchedule)),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/schedule/exceptions",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(materialHandler.CreateMaterial)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(scheduleHandler.AddClassException))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
-		"/material/{id}",
+		"/class/{id}/schedule/exceptions/{exception_id}",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(materialHandler.DeleteMaterial)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(scheduleHandler.DeleteClassException))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("DELETE")
 
 	router.Handle(
-		"/{class_id}/material/{material_id}",
+		"/holidays",
+		middleware.AuthMiddleware(http.HandlerFunc(scheduleHandler.GetHolidays), middleware.RequireScope(middleware.ScopeClassesRead)),
+	).Methods("GET")
+
+	router.Handle(
+		"/me/timetable",
+		middleware.AuthMiddleware(http.HandlerFunc(scheduleHandler.GetTimetable), middleware.RequireScope(middleware.ScopeClassesRead)),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/members/export",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(classHandler.ExportMembers))),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
+
+	// Attendance routes
+	router.Handle(
+		"/class/{id}/sessions",
+		middleware.AuthMiddleware(
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(attendanceHandler.GetSessions)),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/sessions",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.CreateSession))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/sessions/generate",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.GenerateSessions))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/sessions/{session_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.DeleteSession))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/sessions/{session_id}/attendance",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.GetSessionAttendance))),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/sessions/{session_id}/attendance",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(materialHandler.UpdateMaterial)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.MarkAttendance))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
 		),
 	).Methods("PUT")
 
-	// Assignment Routes
 	router.Handle(
-		"/assignments/{class_id}",
+		"/class/{id}/sessions/{session_id}/checkin/open",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.OpenCheckin))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/sessions/{session_id}/checkin/close",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.CloseCheckin))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/sessions/{session_id}/checkin/token",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(assignmentHandler.GetAssignments)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.GetCheckinToken))),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
 		),
 	).Methods("GET")
 
 	router.Handle(
-		"/assignments/{class_id}/{user_id}",
+		"/class/{id}/attendance/summary",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Siswa"})(http.HandlerFunc(assignmentHandler.GetAssignmentsByUserID)),
-	),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(attendanceHandler.GetClassSummary))),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
 	).Methods("GET")
 
 	router.Handle(
-		"/assignment/{class_id}",
+		"/attendance/checkin",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(assignmentHandler.CreateAssignment)),
+			middleware.RoleMiddleware([]string{"Siswa"})(http.HandlerFunc(attendanceHandler.SelfCheckin)),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
 		),
 	).Methods("POST")
 
 	router.Handle(
-		"/assignment/{id}",
+		"/me/attendance",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(assignmentHandler.DeleteAssignment)),
+			http.HandlerFunc(attendanceHandler.GetMyAttendance),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
+	).Methods("GET")
+
+	// Leave request routes
+	router.Handle(
+		"/leave-requests",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(http.HandlerFunc(leaveHandler.CreateLeaveRequest)),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/me/leave-requests",
+		middleware.AuthMiddleware(
+			http.HandlerFunc(leaveHandler.GetMyLeaveRequests),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/me/leave-requests/{id}",
+		middleware.AuthMiddleware(
+			http.HandlerFunc(leaveHandler.CancelLeaveRequest),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
 		),
 	).Methods("DELETE")
 
 	router.Handle(
-		"/{class_id}/assignment/{assignment_id}",
+		"/class/{id}/leave-requests",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(assignmentHandler.UpdateAssignment)),
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(leaveHandler.GetClassLeaveRequests))),
+			middleware.RequireScope(middleware.ScopeAttendanceRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/leave-requests/{request_id}/review",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(leaveHandler.ReviewLeaveRequest))),
+			middleware.RequireScope(middleware.ScopeAttendanceWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/homeroom",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(leaveHandler.SetHomeroom))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("PUT")
-	
+
+	// Announcement & stream routes
 	router.Handle(
-        "/assignments/count/{user_id}",
-        middleware.AuthMiddleware(http.HandlerFunc(assignmentHandler.CountAssignmentsCreatedByUser)),
-    ).Methods("GET")
+		"/class/{id}/stream",
+		middleware.AuthMiddleware(
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(announcementHandler.GetStream)),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
 
-	//grades routes
 	router.Handle(
-		"/grades",
+		"/class/{id}/announcements",
 		middleware.AuthMiddleware(
-			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(gradeHandler.CreateGrade)),
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(announcementHandler.GetAnnouncements)),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/announcements",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.CreateAnnouncement))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
 		),
 	).Methods("POST")
 
-	// Rapot Routes
 	router.Handle(
-        "/rapot/{user_id}",
-        middleware.AuthMiddleware(http.HandlerFunc(rapotHandler.GetRapotByUserID)),
-    ).Methods("GET")
+		"/class/{id}/announcements/{announcement_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.UpdateAnnouncement))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("PUT")
 
-	// Users routes
-	router.HandleFunc("/register", authHandler.Register).Methods("POST")
-	router.HandleFunc("/login", authHandler.Login).Methods("POST")
-	router.HandleFunc("/roles/count", userHandler.GetRoleCounts).Methods("GET")
+	router.Handle(
+		"/class/{id}/announcements/{announcement_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.DeleteAnnouncement))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/announcements/{announcement_id}/pin",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.PinAnnouncement))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/announcements/{announcement_id}/unpin",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.UnpinAnnouncement))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/announcements/{announcement_id}/attachments",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.AddAttachments))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/announcements/{announcement_id}/attachments/{attachment_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(announcementHandler.DeleteAttachment))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("DELETE")
+
+	// Submission routes
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submission",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(submissionHandler.GetMySubmission))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submission",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(submissionHandler.SaveDraft))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submission/submit",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(submissionHandler.Submit))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submission/unsubmit",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(submissionHandler.Unsubmit))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submission/files/{file_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(submissionHandler.DeleteFile))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.GetSubmissions))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.GetSubmission))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/grade",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.GradeSubmission))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/return",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.ReturnSubmission))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/comments",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.AddComment))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/comments/{comment_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.DeleteComment))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("DELETE")
+
+	// Rubric routes
+	router.Handle(
+		"/rubrics",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.GetRubrics)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/rubrics",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.CreateRubric)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/rubrics/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.GetRubric)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/rubrics/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.UpdateRubric)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/rubrics/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.DeleteRubric)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/rubrics/{id}/copy",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(rubricHandler.CopyRubric)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/rubric",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(rubricHandler.GetAssignmentRubric))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/rubric",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(rubricHandler.AttachRubric))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/rubric",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(submissionHandler.GradeWithRubric))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("PUT")
+
+	// Quiz routes
+	router.Handle(
+		"/class/{id}/quizzes",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(quizHandler.GetQuizzes))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.CreateQuiz))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(quizHandler.GetQuiz))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.UpdateQuiz))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.DeleteQuiz))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/questions",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.AddQuestion))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/questions/{question_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.UpdateQuestion))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/questions/{question_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.DeleteQuestion))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/my-attempts",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(quizHandler.GetMyAttempts))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/my-attempts",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(quizHandler.StartAttempt))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/my-attempts/{attempt_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(quizHandler.GetMyAttempt))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/my-attempts/{attempt_id}/answers",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(quizHandler.SaveAnswers))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/my-attempts/{attempt_id}/submit",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermParticipate, "id")(http.HandlerFunc(quizHandler.SubmitAttempt))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/results",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(quizHandler.GetResults))),
+			middleware.RequireScope(middleware.ScopeGradesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/attempts",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(quizHandler.GetAttempts))),
+			middleware.RequireScope(middleware.ScopeGradesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/attempts/{attempt_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(quizHandler.GetAttempt))),
+			middleware.RequireScope(middleware.ScopeGradesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/attempts/{attempt_id}/items/{item_id}/grade",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(quizHandler.GradeItem))),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/questions/from-bank",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.AddBankQuestions))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/export",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.ExportQuiz))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/draw-rules",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.GetDrawRules))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/quizzes/{quiz_id}/draw-rules",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(quizHandler.SetDrawRules))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	// Question bank routes
+	router.Handle(
+		"/question-bank",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.GetQuestions)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/question-bank",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.CreateQuestion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/question-bank/export",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.ExportQuestions)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/question-bank/import/preview",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.PreviewImport)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/question-bank/import",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.ImportQuestions)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/question-bank/tags",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.GetTags)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/question-bank/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.GetQuestion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/question-bank/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.UpdateQuestion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/question-bank/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.DeleteQuestion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/question-bank/{id}/versions",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.GetVersions)),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/question-bank/{id}/versions/{version}/restore",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.RestoreVersion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/question-bank/{id}/copy",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(questionBankHandler.CopyQuestion)),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/similarity",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(similarityHandler.CheckSimilarity))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/similarity",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(similarityHandler.GetSimilarityReport))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/assignments/{assignment_id}/submissions/{submission_id}/similarity",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermGrade, "id")(http.HandlerFunc(similarityHandler.GetSubmissionSimilarity))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{class_id}/join",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(classHandler.JoinClass)),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/owner",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageTeachers, "id")(http.HandlerFunc(classHandler.TransferOwnership))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/classes/join",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(classHandler.JoinClassByCode)),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/leave",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(http.HandlerFunc(classHandler.LeaveClass)),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/enrollment",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageSettings, "id")(http.HandlerFunc(classHandler.SetEnrollmentMode))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/join-requests",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageMembers, "id")(http.HandlerFunc(classHandler.GetJoinRequests))),
+			middleware.RequireScope(middleware.ScopeClassesRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/join-requests/{request_id}/approve",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageMembers, "id")(http.HandlerFunc(classHandler.ApproveJoinRequest))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/join-requests/{request_id}/reject",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageMembers, "id")(http.HandlerFunc(classHandler.RejectJoinRequest))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{class_id}/members",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageMembers, "class_id")(http.HandlerFunc(classHandler.ManageClassMembers))),
+			middleware.RequireScope(middleware.ScopeClassesWrite),
+		),
+	).Methods("POST", "DELETE")
+
+	router.Handle(
+        "/class/{class_id}/members",
+        middleware.AuthMiddleware(middleware.RequireClassPermission(middleware.ClassPermView, "class_id")(http.HandlerFunc(classHandler.GetMembers)), middleware.RequireScope(middleware.ScopeClassesRead)),
+    ).Methods("GET")
+
+	router.Handle(
+        "/classes/student/{student_id}",
+        middleware.AuthMiddleware(http.HandlerFunc(classHandler.GetClassesByStudentID), middleware.RequireScope(middleware.ScopeClassesRead)),
+    ).Methods("GET")
+
+	// Course outline & unit routes
+	router.Handle(
+		"/class/{id}/outline",
+		middleware.AuthMiddleware(
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(unitHandler.GetOutline)),
+			middleware.RequireScope(middleware.ScopeMaterialsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/units",
+		middleware.AuthMiddleware(
+			middleware.RequireClassPermission(middleware.ClassPermView, "id")(http.HandlerFunc(unitHandler.GetUnits)),
+			middleware.RequireScope(middleware.ScopeMaterialsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/class/{id}/units",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.CreateUnit))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/class/{id}/units/order",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.ReorderUnits))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/units/{unit_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.UpdateUnit))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/units/{unit_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.DeleteUnit))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/class/{id}/units/{unit_id}/items",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.ReorderUnitItems))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/class/{id}/outline/move",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "id")(http.HandlerFunc(unitHandler.MoveItem))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("PUT")
+
+	// Material Routes
+	router.Handle(
+		"/materials/{class_id}",
+		middleware.AuthMiddleware(middleware.RequireClassPermission(middleware.ClassPermView, "class_id")(http.HandlerFunc(materialHandler.GetMaterials)), middleware.RequireScope(middleware.ScopeMaterialsRead)),
+	).Methods("GET")
+
+	router.Handle(
+		"/material/{class_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "class_id")(http.HandlerFunc(materialHandler.CreateMaterial))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/{class_id}/material/{material_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "class_id")(http.HandlerFunc(materialHandler.DeleteMaterial))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/{class_id}/material/{material_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "class_id")(http.HandlerFunc(materialHandler.UpdateMaterial))),
+			middleware.RequireScope(middleware.ScopeMaterialsWrite),
+		),
+	).Methods("PUT")
+
+	// Assignment Routes
+	router.Handle(
+		"/assignments/{class_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermView, "class_id")(http.HandlerFunc(assignmentHandler.GetAssignments))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/assignments/{class_id}/{user_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "class_id")(http.HandlerFunc(assignmentHandler.GetAssignmentsByUserID))),
+			middleware.RequireScope(middleware.ScopeAssignmentsRead),
+	),
+	).Methods("GET")
+
+	router.Handle(
+		"/assignment/{class_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru", "Siswa"})(middleware.RequireClassPermission(middleware.ClassPermView, "class_id")(http.HandlerFunc(assignmentHandler.CreateAssignment))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/{class_id}/assignment/{assignment_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "class_id")(http.HandlerFunc(assignmentHandler.DeleteAssignment))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/{class_id}/assignment/{assignment_id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(middleware.RequireClassPermission(middleware.ClassPermManageContent, "class_id")(http.HandlerFunc(assignmentHandler.UpdateAssignment))),
+			middleware.RequireScope(middleware.ScopeAssignmentsWrite),
+		),
+	).Methods("PUT")
+	
+	router.Handle(
+        "/assignments/count/{user_id}",
+        middleware.AuthMiddleware(http.HandlerFunc(assignmentHandler.CountAssignmentsCreatedByUser), middleware.RequireScope(middleware.ScopeAssignmentsRead)),
+    ).Methods("GET")
+
+	//grades routes
+	router.Handle(
+		"/grades",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(gradeHandler.CreateGrade)),
+			middleware.RequireScope(middleware.ScopeGradesWrite),
+		),
+	).Methods("POST")
+
+	// Rapot Routes
+	router.Handle(
+        "/rapot/{user_id}",
+        middleware.AuthMiddleware(http.HandlerFunc(rapotHandler.GetRapotByUserID), middleware.RequireScope(middleware.ScopeGradesRead)),
+    ).Methods("GET")
+
+	// Users routes
+	router.HandleFunc("/register", authHandler.Register).Methods("POST")
+	router.HandleFunc("/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/login/mfa", authHandler.LoginMFA).Methods("POST")
+	router.HandleFunc("/auth/oidc/login", oidcHandler.Login).Methods("GET")
+	router.HandleFunc("/auth/oidc/callback", oidcHandler.Callback).Methods("GET")
+	router.Handle(
+		"/auth/oidc/link",
+		middleware.AuthMiddleware(http.HandlerFunc(oidcHandler.LinkIdentity)),
+	).Methods("POST")
+	router.Handle(
+		"/auth/oidc/link/confirm",
+		middleware.AuthMiddleware(http.HandlerFunc(oidcHandler.ConfirmLink)),
+	).Methods("POST")
+	router.HandleFunc("/roles/count", userHandler.GetRoleCounts).Methods("GET")
+
+	// Personal access token routes (hanya bisa dikelola dari sesi login biasa)
+	router.Handle(
+		"/me/tokens",
+		middleware.AuthMiddleware(http.HandlerFunc(tokenHandler.GetTokens)),
+	).Methods("GET")
+
+	router.Handle(
+		"/me/tokens",
+		middleware.AuthMiddleware(http.HandlerFunc(tokenHandler.CreateToken)),
+	).Methods("POST")
+
+	router.Handle(
+		"/me/tokens/{id}",
+		middleware.AuthMiddleware(http.HandlerFunc(tokenHandler.RevokeToken)),
+	).Methods("DELETE")
+
+	// Session & security routes
+	router.Handle(
+		"/me/sessions",
+		middleware.AuthMiddleware(http.HandlerFunc(securityHandler.GetSessions)),
+	).Methods("GET")
+
+	router.Handle(
+		"/me/sessions",
+		middleware.AuthMiddleware(http.HandlerFunc(securityHandler.RevokeOtherSessions)),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/me/sessions/{id}",
+		middleware.AuthMiddleware(http.HandlerFunc(securityHandler.RevokeSession)),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/me/login-history",
+		middleware.AuthMiddleware(http.HandlerFunc(securityHandler.GetLoginHistory)),
+	).Methods("GET")
+
+	router.Handle(
+		"/me/password",
+		middleware.AuthMiddleware(http.HandlerFunc(authHandler.ChangePassword)),
+	).Methods("PUT")
+
+	router.Handle(
+		"/admin/security-events",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(securityHandler.GetSecurityEvents)),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/admin/academic-years",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(academicHandler.CreateAcademicYear)),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/admin/academic-years/{id}/terms",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(academicHandler.CreateTerm)),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/admin/terms/rollover",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(academicHandler.RolloverTerm)),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/admin/holidays",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(scheduleHandler.AddHoliday)),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/admin/holidays/{id}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(scheduleHandler.DeleteHoliday)),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/admin/users",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(userHandler.GetUsers)),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/admin/users/{id}/profile",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(userHandler.UpdateProfile)),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/admin/users/{id}/role",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(authHandler.UpdateRole)),
+		),
+	).Methods("PUT")
+
+	router.Handle(
+		"/admin/users/{id}/password-reset",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(authHandler.ResetPassword)),
+		),
+	).Methods("POST")
+
+	// Two-factor authentication routes
+	router.Handle(
+		"/mfa",
+		middleware.AuthMiddleware(http.HandlerFunc(mfaHandler.Status), middleware.AllowMFASetup),
+	).Methods("GET")
+
+	router.Handle(
+		"/mfa/totp/enroll",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(mfaHandler.Enroll)),
+			middleware.AllowMFASetup,
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/mfa/totp/qr",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(mfaHandler.QRCode)),
+			middleware.AllowMFASetup,
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/mfa/totp/confirm",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(mfaHandler.Confirm)),
+			middleware.AllowMFASetup,
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/mfa/recovery-codes",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(mfaHandler.RegenerateRecoveryCodes)),
+		),
+	).Methods("POST")
+
+	router.Handle(
+		"/mfa/totp",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin", "Guru"})(http.HandlerFunc(mfaHandler.Disable)),
+		),
+	).Methods("DELETE")
+
+	router.Handle(
+		"/admin/mfa/policies",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(mfaHandler.GetRolePolicies)),
+		),
+	).Methods("GET")
+
+	router.Handle(
+		"/admin/mfa/policies/{role}",
+		middleware.AuthMiddleware(
+			middleware.RoleMiddleware([]string{"Admin"})(http.HandlerFunc(mfaHandler.SetRolePolicy)),
+		),
+	).Methods("PUT")
 
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173"},
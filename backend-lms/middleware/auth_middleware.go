@@ -5,23 +5,59 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"project/config"
+	"project/jwtauth"
+	"strings"
+)
 
-	"github.com/dgrijalva/jwt-go"
+// Jenis autentikasi yang disimpan di context dengan key "auth_type"
+const (
+    AuthTypeSession     = "session"
+    AuthTypeAccessToken = "access_token"
 )
 
-var jwtKey = []byte("your_secret_key")
+// AuthOption mengatur token yang diterima sebuah route. Dipasang saat route didaftarkan,
+// misalnya AuthMiddleware(handler, RequireScope(ScopeClassesRead)), jadi tidak bergantung
+// pada urutan middleware lain di dalamnya.
+type AuthOption func(*routeAuth)
+
+// routeAuth berisi aturan token satu route. Tanpa option, hanya token login biasa yang diterima.
+type routeAuth struct {
+    // scope personal access token yang dibutuhkan; kosong berarti PAT ditolak
+    scope         string
+    allowMFASetup bool
+}
 
-// Claims struct untuk JWT
-type Claims struct {
-    UserID   int    `json:"id"`
-    Username string `json:"username"`
-    Role     string `json:"role"`
-    jwt.StandardClaims
+// AllowMFASetup membuka route untuk token MFASetupPurpose.
+func AllowMFASetup(route *routeAuth) {
+    route.allowMFASetup = true
 }
 
+// AccessTokenPrefix adalah prefix personal access token (Authorization: Bearer smp_...)
+const AccessTokenPrefix = "smp_"
+
+// AccessTokenIdentity adalah hasil validasi personal access token.
+type AccessTokenIdentity struct {
+    TokenID  int
+    UserID   int
+    Username string
+    Role     string
+    Scopes   []string
+}
+
+// AccessTokenAuthenticator memvalidasi personal access token. Diisi di main karena butuh database.
+var AccessTokenAuthenticator func(token string, r *http.Request) (*AccessTokenIdentity, error)
+
+// SessionValidator mengecek bahwa sesi (claim jti) belum dicabut. Diisi di main karena butuh database.
+var SessionValidator func(sessionID string, userID int) error
+
 // Middleware AuthMiddleware
-func AuthMiddleware(next http.Handler) http.Handler {
+func AuthMiddleware(next http.Handler, options ...AuthOption) http.Handler {
+    var route routeAuth
+    for _, option := range options {
+        option(&route)
+    }
+
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         authHeader := r.Header.Get("Authorization")
         if authHeader == "" {
@@ -37,27 +73,64 @@ func AuthMiddleware(next http.Handler) http.Handler {
         }
 
         tokenStr := authHeader[7:]
-        claims := &Claims{}
-        token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-            return jwtKey, nil
-        })
+        if strings.HasPrefix(tokenStr, AccessTokenPrefix) {
+            // Personal access token hanya diterima di route dengan RequireScope
+            if route.scope == "" || AccessTokenAuthenticator == nil {
+                http.Error(w, "Forbidden: personal access tokens cannot access this resource", http.StatusForbidden)
+                return
+            }
+
+            identity, err := AccessTokenAuthenticator(tokenStr, r)
+            if err != nil {
+                log.Printf("Error validating access token: %v\n", err)
+                http.Error(w, "Invalid token", http.StatusUnauthorized)
+                return
+            }
+            if !HasScope(identity.Scopes, route.scope) {
+                log.Printf("Access token missing scope %s", route.scope)
+                http.Error(w, "Forbidden: token is missing scope "+route.scope, http.StatusForbidden)
+                return
+            }
+
+            log.Printf("Valid access token %d: UserID: %d, Username: %s, Role: %s\n", identity.TokenID, identity.UserID, identity.Username, identity.Role)
+            ctx := context.WithValue(r.Context(), "id", identity.UserID)
+            ctx = context.WithValue(ctx, "username", identity.Username)
+            ctx = context.WithValue(ctx, "role", identity.Role)
+            ctx = context.WithValue(ctx, "auth_type", AuthTypeAccessToken)
+            ctx = context.WithValue(ctx, "scopes", identity.Scopes)
+            next.ServeHTTP(w, r.WithContext(ctx))
+            return
+        }
 
+        claims, err := jwtauth.ParseToken(tokenStr, config.JWTAudience)
         if err != nil {
             log.Printf("Error parsing token: %v\n", err)
             http.Error(w, "Invalid token", http.StatusUnauthorized)
             return
         }
 
-        if !token.Valid {
-            log.Println("Token is invalid")
+        if claims.Purpose != "" && !(claims.Purpose == jwtauth.MFASetupPurpose && route.allowMFASetup) {
+            log.Printf("Rejected %s token for API access\n", claims.Purpose)
             http.Error(w, "Invalid token", http.StatusUnauthorized)
             return
         }
 
+        if SessionValidator != nil {
+            if err := SessionValidator(claims.ID, claims.UserID); err != nil {
+                log.Printf("Rejected session %s: %v\n", claims.ID, err)
+                http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+                return
+            }
+        }
+
         log.Printf("Valid token: UserID: %d, Username: %s, Role: %s\n", claims.UserID, claims.Username, claims.Role)
         ctx := context.WithValue(r.Context(), "id", claims.UserID)
         ctx = context.WithValue(ctx, "username", claims.Username)
         ctx = context.WithValue(ctx, "role", claims.Role)
+        ctx = context.WithValue(ctx, "userClaims", claims)
+        ctx = context.WithValue(ctx, "auth_type", AuthTypeSession)
+        ctx = context.WithValue(ctx, "session_id", claims.ID)
+        ctx = context.WithValue(ctx, "token_purpose", claims.Purpose)
         next.ServeHTTP(w, r.WithContext(ctx))
     })
 }
@@ -87,19 +160,3 @@ func RoleMiddleware(allowedRoles []string) func(http.Handler) http.Handler {
 		})
 	}
 }
-
-// Fungsi GenerateToken untuk login
-func GenerateToken(id int, username, role string) (string, error) {
-    expirationTime := time.Now().Add(24 * time.Hour)
-    claims := &Claims{
-        UserID:   id,
-        Username: username,
-        Role:     role,
-        StandardClaims: jwt.StandardClaims{
-            ExpiresAt: expirationTime.Unix(),
-        },
-    }
-
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(jwtKey)
-}
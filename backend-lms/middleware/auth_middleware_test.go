@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMiddlewareAccessTokenScopes(t *testing.T) {
+	AccessTokenAuthenticator = func(token string, r *http.Request) (*AccessTokenIdentity, error) {
+		return &AccessTokenIdentity{TokenID: 1, UserID: 7, Username: "guru1", Role: "Guru", Scopes: []string{ScopeGradesWrite}}, nil
+	}
+	defer func() { AccessTokenAuthenticator = nil }()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	// Middleware lain di antara AuthMiddleware dan handler tidak mengubah aturan token route
+	nested := RoleMiddleware([]string{"Guru"})(ok)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    int
+	}{
+		{name: "route without scope rejects tokens", handler: AuthMiddleware(ok), want: http.StatusForbidden},
+		{name: "granted scope", handler: AuthMiddleware(ok, RequireScope(ScopeGradesWrite)), want: http.StatusNoContent},
+		{name: "write scope covers read", handler: AuthMiddleware(ok, RequireScope(ScopeGradesRead)), want: http.StatusNoContent},
+		{name: "missing scope", handler: AuthMiddleware(ok, RequireScope(ScopeClassesRead)), want: http.StatusForbidden},
+		{name: "scope behind another middleware", handler: AuthMiddleware(nested, RequireScope(ScopeGradesRead)), want: http.StatusNoContent},
+		{name: "missing scope behind another middleware", handler: AuthMiddleware(nested, RequireScope(ScopeClassesWrite)), want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Authorization", "Bearer "+AccessTokenPrefix+"test")
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Permission yang diturunkan dari role user di dalam sebuah kelas (class_members.role).
+const (
+	ClassPermView           = "class:view"
+	ClassPermGrade          = "class:grade"
+	ClassPermManageContent  = "class:manage_content"
+	ClassPermManageMembers  = "class:manage_members"
+	ClassPermManageSettings = "class:manage_settings"
+	ClassPermManageTeachers = "class:manage_teachers"
+	// ClassPermParticipate untuk aksi siswa seperti mengumpulkan tugas; dicabut di kelas arsip
+	ClassPermParticipate = "class:participate"
+)
+
+// ErrClassForbidden dikembalikan ClassPermissionChecker jika role user di kelas tidak cukup.
+var ErrClassForbidden = errors.New("forbidden: insufficient class role")
+
+// ClassPermissionChecker mengecek permission user di sebuah kelas. Diisi di main karena butuh database.
+var ClassPermissionChecker func(userID int, globalRole string, classID int, permission string) error
+
+// RequireClassPermission mengecek permission kelas dengan id dari path variable classVar.
+// Dipasang di dalam AuthMiddleware (setelah RoleMiddleware jika ada).
+func RequireClassPermission(permission, classVar string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			classID, err := strconv.Atoi(mux.Vars(r)[classVar])
+			if err != nil {
+				http.Error(w, "Invalid class ID", http.StatusBadRequest)
+				return
+			}
+
+			userID, _ := r.Context().Value("id").(int)
+			role, _ := r.Context().Value("role").(string)
+			if ClassPermissionChecker == nil {
+				http.Error(w, "Forbidden: You don't have access to this resource", http.StatusForbidden)
+				return
+			}
+
+			if err := ClassPermissionChecker(userID, role, classID, permission); err != nil {
+				if errors.Is(err, ErrClassForbidden) {
+					http.Error(w, "Forbidden: your role in this class does not allow this action", http.StatusForbidden)
+					return
+				}
+				log.Printf("Failed to check class permission: %v", err)
+				http.Error(w, "Failed to check class permission", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
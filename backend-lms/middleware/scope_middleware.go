@@ -0,0 +1,57 @@
+package middleware
+
+// Scope untuk personal access token. Token JWT dari login biasa tidak dibatasi scope.
+const (
+	ScopeClassesRead      = "classes:read"
+	ScopeClassesWrite     = "classes:write"
+	ScopeMaterialsRead    = "materials:read"
+	ScopeMaterialsWrite   = "materials:write"
+	ScopeAssignmentsRead  = "assignments:read"
+	ScopeAssignmentsWrite = "assignments:write"
+	ScopeGradesRead       = "grades:read"
+	ScopeGradesWrite      = "grades:write"
+	ScopeForumsRead       = "forums:read"
+	ScopeForumsWrite      = "forums:write"
+	ScopeAttendanceRead   = "attendance:read"
+	ScopeAttendanceWrite  = "attendance:write"
+)
+
+// KnownScopes adalah semua scope yang boleh diberikan ke personal access token.
+var KnownScopes = []string{
+	ScopeClassesRead, ScopeClassesWrite,
+	ScopeMaterialsRead, ScopeMaterialsWrite,
+	ScopeAssignmentsRead, ScopeAssignmentsWrite,
+	ScopeGradesRead, ScopeGradesWrite,
+	ScopeForumsRead, ScopeForumsWrite,
+	ScopeAttendanceRead, ScopeAttendanceWrite,
+}
+
+// RequireScope membuka route untuk personal access token yang memiliki scope tersebut.
+// Route tanpa RequireScope menolak personal access token sama sekali.
+func RequireScope(scope string) AuthOption {
+	return func(route *routeAuth) {
+		route.scope = scope
+	}
+}
+
+// HasScope mengecek apakah scope diberikan. Scope ":write" juga mencakup ":read" untuk resource yang sama.
+func HasScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == scope {
+			return true
+		}
+		if len(scope) > 5 && scope[len(scope)-5:] == ":read" && s == scope[:len(scope)-5]+":write" {
+			return true
+		}
+	}
+	return false
+}
+
+func IsKnownScope(scope string) bool {
+	for _, known := range KnownScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
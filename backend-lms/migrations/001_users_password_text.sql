@@ -0,0 +1,2 @@
+-- Hash argon2id (~97 karakter) lebih panjang dari hash bcrypt (60 karakter).
+ALTER TABLE users ALTER COLUMN password TYPE TEXT;
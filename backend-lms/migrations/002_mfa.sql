@@ -0,0 +1,27 @@
+-- TOTP two-factor authentication
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id        INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret    TEXT NOT NULL,
+    enabled        BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    confirmed_at   TIMESTAMPTZ
+);
+
+-- Recovery codes disimpan sebagai hash SHA-256, plain text hanya ditampilkan sekali
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash  TEXT NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes (user_id);
+
+-- Role yang wajib memakai 2FA (diatur oleh Admin)
+CREATE TABLE IF NOT EXISTS mfa_role_policies (
+    role       TEXT PRIMARY KEY,
+    required   BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+-- Personal access token untuk integrasi dan script (Authorization: Bearer smp_...)
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           SERIAL PRIMARY KEY,
+    user_id      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    -- Hanya hash SHA-256 yang disimpan, token_prefix untuk ditampilkan di UI
+    token_hash   TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    expires_at   TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens (user_id);
@@ -0,0 +1,46 @@
+-- Riwayat semua percobaan login (berhasil maupun gagal)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT REFERENCES users(id) ON DELETE SET NULL,
+    username       TEXT NOT NULL,
+    success        BOOLEAN NOT NULL,
+    method         TEXT NOT NULL,
+    failure_reason TEXT,
+    ip             TEXT,
+    user_agent     TEXT,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user ON login_attempts (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_username ON login_attempts (username, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts (ip, created_at DESC);
+
+-- Satu baris per JWT yang diterbitkan; id sama dengan claim jti
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id           TEXT PRIMARY KEY,
+    user_id      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method       TEXT NOT NULL,
+    ip           TEXT,
+    user_agent   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions (user_id, created_at DESC);
+
+-- Feed event keamanan untuk admin
+CREATE TABLE IF NOT EXISTS security_events (
+    id         SERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    severity   TEXT NOT NULL DEFAULT 'info',
+    user_id    INT REFERENCES users(id) ON DELETE SET NULL,
+    actor_id   INT REFERENCES users(id) ON DELETE SET NULL,
+    ip         TEXT,
+    details    JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_created ON security_events (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_type ON security_events (event_type, created_at DESC);
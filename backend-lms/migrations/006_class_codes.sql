@@ -0,0 +1,57 @@
+-- Kode kelas dibuat server: tanpa karakter yang mirip (0/O, 1/I/L), unik, bisa dinonaktifkan,
+-- punya masa berlaku dan batas pemakaian opsional.
+ALTER TABLE classes
+    ADD COLUMN IF NOT EXISTS class_code_enabled    BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS class_code_expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS class_code_max_uses   INT CHECK (class_code_max_uses IS NULL OR class_code_max_uses > 0),
+    ADD COLUMN IF NOT EXISTS class_code_uses       INT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION generate_class_code(code_length INT) RETURNS TEXT AS $$
+DECLARE
+    alphabet CONSTANT TEXT := 'ABCDEFGHJKMNPQRSTUVWXYZ23456789';
+    result TEXT := '';
+BEGIN
+    FOR i IN 1..code_length LOOP
+        result := result || substr(alphabet, 1 + floor(random() * length(alphabet))::INT, 1);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Normalisasi yang sama dengan NormalizeClassCode di service: tanpa spasi dan tanda hubung, huruf besar.
+CREATE OR REPLACE FUNCTION normalize_class_code(code TEXT) RETURNS TEXT AS $$
+    SELECT UPPER(REPLACE(REPLACE(COALESCE(code, ''), ' ', ''), '-', ''));
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Kode lama yang kosong atau (setelah dinormalisasi) dipakai lebih dari satu kelas diganti
+-- dengan kode baru. Kelas dengan id terkecil mempertahankan kodenya.
+DO $$
+DECLARE
+    target RECORD;
+    new_code TEXT;
+BEGIN
+    FOR target IN
+        SELECT id FROM classes c
+        WHERE normalize_class_code(c.class_code) = ''
+           OR EXISTS (
+               SELECT 1 FROM classes o
+               WHERE normalize_class_code(o.class_code) = normalize_class_code(c.class_code) AND o.id < c.id
+           )
+        ORDER BY id
+    LOOP
+        LOOP
+            new_code := generate_class_code(7);
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM classes WHERE normalize_class_code(class_code) = new_code);
+        END LOOP;
+        UPDATE classes SET class_code = new_code WHERE id = target.id;
+    END LOOP;
+END $$;
+
+-- Kode lama disimpan dalam bentuk ternormalisasi supaya cocok dengan input yang dinormalisasi saat join
+UPDATE classes SET class_code = normalize_class_code(class_code) WHERE class_code <> normalize_class_code(class_code);
+
+DROP FUNCTION normalize_class_code(TEXT);
+DROP FUNCTION generate_class_code(INT);
+
+ALTER TABLE classes ALTER COLUMN class_code SET NOT NULL;
+ALTER TABLE classes ADD CONSTRAINT classes_class_code_key UNIQUE (class_code);
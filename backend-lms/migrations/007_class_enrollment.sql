@@ -0,0 +1,27 @@
+-- Mode pendaftaran per kelas: open (langsung jadi member) atau approval (menunggu persetujuan guru)
+ALTER TABLE classes
+    ADD COLUMN IF NOT EXISTS enrollment_mode TEXT NOT NULL DEFAULT 'open'
+        CHECK (enrollment_mode IN ('open', 'approval'));
+
+-- Hapus membership ganda sebelum menambahkan unique constraint
+DELETE FROM class_members a
+USING class_members b
+WHERE a.class_id = b.class_id AND a.user_id = b.user_id AND a.ctid > b.ctid;
+
+ALTER TABLE class_members ADD COLUMN IF NOT EXISTS joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE class_members ADD CONSTRAINT class_members_class_user_key UNIQUE (class_id, user_id);
+
+CREATE TABLE IF NOT EXISTS class_join_requests (
+    id          SERIAL PRIMARY KEY,
+    class_id    INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    user_id     INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status      TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    message     TEXT,
+    reviewed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Satu user hanya boleh punya satu request pending per kelas
+CREATE UNIQUE INDEX IF NOT EXISTS idx_class_join_requests_pending
+    ON class_join_requests (class_id, user_id) WHERE status = 'pending';
@@ -0,0 +1,17 @@
+-- Role per kelas di class_members: owner, co_teacher, assistant, siswa (student)
+ALTER TABLE class_members ALTER COLUMN role SET DEFAULT 'siswa';
+UPDATE class_members SET role = 'siswa' WHERE role IS NULL OR role NOT IN ('owner', 'co_teacher', 'assistant', 'siswa');
+ALTER TABLE class_members ALTER COLUMN role SET NOT NULL;
+ALTER TABLE class_members ADD CONSTRAINT class_members_role_check
+    CHECK (role IN ('owner', 'co_teacher', 'assistant', 'siswa'));
+
+-- Setiap kelas punya paling banyak satu owner
+CREATE UNIQUE INDEX IF NOT EXISTS idx_class_members_owner ON class_members (class_id) WHERE role = 'owner';
+
+-- Kelas lama: guru di kolom teacher (teks bebas) dijadikan owner jika username-nya cocok
+INSERT INTO class_members (class_id, user_id, role)
+SELECT c.id, u.id, 'owner'
+FROM classes c
+JOIN users u ON u.username = c.teacher AND u.role IN ('Guru', 'Admin')
+WHERE NOT EXISTS (SELECT 1 FROM class_members o WHERE o.class_id = c.id AND o.role = 'owner')
+ON CONFLICT (class_id, user_id) DO UPDATE SET role = 'owner';
@@ -0,0 +1,28 @@
+-- Jadwal mingguan berulang per kelas. day_of_week mengikuti ISO: 1 = Senin ... 7 = Minggu.
+-- Jam disimpan sebagai jam lokal di timezone masing-masing jadwal.
+CREATE TABLE IF NOT EXISTS class_schedules (
+    id          SERIAL PRIMARY KEY,
+    class_id    INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 1 AND 7),
+    start_time  TIME NOT NULL,
+    end_time    TIME NOT NULL,
+    room        TEXT NOT NULL DEFAULT '',
+    timezone    TEXT NOT NULL DEFAULT 'Asia/Jakarta',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_class_schedules_class ON class_schedules (class_id);
+CREATE INDEX IF NOT EXISTS idx_class_schedules_day ON class_schedules (day_of_week);
+
+-- Tanggal tanpa pertemuan. class_id NULL berarti libur untuk semua kelas.
+CREATE TABLE IF NOT EXISTS schedule_exceptions (
+    id         SERIAL PRIMARY KEY,
+    class_id   INT REFERENCES classes(id) ON DELETE CASCADE,
+    date       DATE NOT NULL,
+    reason     TEXT NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedule_exceptions_class_date ON schedule_exceptions (COALESCE(class_id, 0), date);
@@ -0,0 +1,30 @@
+-- Tahun ajaran dan semester. Kelas terikat ke satu semester dan bisa diarsipkan.
+CREATE TABLE IF NOT EXISTS academic_years (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date > start_date)
+);
+
+CREATE TABLE IF NOT EXISTS terms (
+    id               SERIAL PRIMARY KEY,
+    academic_year_id INT NOT NULL REFERENCES academic_years(id) ON DELETE CASCADE,
+    name             TEXT NOT NULL,
+    start_date       DATE NOT NULL,
+    end_date         DATE NOT NULL,
+    is_active        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date > start_date),
+    UNIQUE (academic_year_id, name)
+);
+
+-- Hanya satu semester yang aktif pada satu waktu
+CREATE UNIQUE INDEX IF NOT EXISTS idx_terms_active ON terms (is_active) WHERE is_active;
+
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS term_id INT REFERENCES terms(id) ON DELETE SET NULL;
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_classes_term ON classes (term_id);
+CREATE INDEX IF NOT EXISTS idx_classes_active ON classes (id) WHERE archived_at IS NULL;
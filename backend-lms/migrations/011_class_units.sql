@@ -0,0 +1,41 @@
+-- Bab/topik di dalam kelas. Materi dan tugas bisa ditempatkan di unit dengan posisi urut;
+-- unit_id NULL berarti belum masuk unit mana pun.
+CREATE TABLE IF NOT EXISTS class_units (
+    id          SERIAL PRIMARY KEY,
+    class_id    INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position    INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_class_units_class ON class_units (class_id, position);
+
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS unit_id INT REFERENCES class_units(id) ON DELETE SET NULL;
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS unit_id INT REFERENCES class_units(id) ON DELETE SET NULL;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+-- Data lama: urutkan berdasarkan waktu dibuat, materi dan tugas dalam satu urutan per kelas
+WITH ordered AS (
+    SELECT kind, id, ROW_NUMBER() OVER (PARTITION BY class_id ORDER BY created_at, kind, id) AS position
+    FROM (
+        SELECT 'material' AS kind, id, class_id, created_at FROM materials
+        UNION ALL
+        SELECT 'assignment' AS kind, id, class_id, created_at FROM assignments
+    ) items
+)
+UPDATE materials m SET position = o.position FROM ordered o WHERE o.kind = 'material' AND o.id = m.id;
+
+WITH ordered AS (
+    SELECT kind, id, ROW_NUMBER() OVER (PARTITION BY class_id ORDER BY created_at, kind, id) AS position
+    FROM (
+        SELECT 'material' AS kind, id, class_id, created_at FROM materials
+        UNION ALL
+        SELECT 'assignment' AS kind, id, class_id, created_at FROM assignments
+    ) items
+)
+UPDATE assignments a SET position = o.position FROM ordered o WHERE o.kind = 'assignment' AND o.id = a.id;
+
+CREATE INDEX IF NOT EXISTS idx_materials_unit ON materials (class_id, unit_id, position);
+CREATE INDEX IF NOT EXISTS idx_assignments_unit ON assignments (class_id, unit_id, position);
@@ -0,0 +1,34 @@
+-- Pertemuan kelas, dibuat dari jadwal (source = 'schedule') atau manual (source = 'ad_hoc').
+CREATE TABLE IF NOT EXISTS class_sessions (
+    id                 SERIAL PRIMARY KEY,
+    class_id           INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    schedule_id        INT REFERENCES class_schedules(id) ON DELETE SET NULL,
+    date               DATE NOT NULL,
+    start_at           TIMESTAMPTZ NOT NULL,
+    end_at             TIMESTAMPTZ NOT NULL,
+    topic              TEXT NOT NULL DEFAULT '',
+    source             TEXT NOT NULL DEFAULT 'ad_hoc' CHECK (source IN ('schedule', 'ad_hoc')),
+    -- Secret untuk token QR yang berganti berkala, hanya terisi selama check-in dibuka
+    checkin_secret     TEXT,
+    checkin_open_until TIMESTAMPTZ,
+    created_by         INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_at > start_at),
+    CONSTRAINT class_sessions_class_start_key UNIQUE (class_id, start_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_class_sessions_class_date ON class_sessions (class_id, date);
+
+CREATE TABLE IF NOT EXISTS attendance_records (
+    id         SERIAL PRIMARY KEY,
+    session_id INT NOT NULL REFERENCES class_sessions(id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status     TEXT NOT NULL CHECK (status IN ('hadir', 'izin', 'sakit', 'alpa')),
+    method     TEXT NOT NULL DEFAULT 'manual' CHECK (method IN ('manual', 'qr')),
+    note       TEXT NOT NULL DEFAULT '',
+    marked_by  INT REFERENCES users(id) ON DELETE SET NULL,
+    marked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (session_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_records_user ON attendance_records (user_id);
@@ -0,0 +1,23 @@
+-- Kelas perwalian: guru kelas ini meninjau izin siswa yang tidak ditujukan ke satu kelas.
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS is_homeroom BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Pengajuan izin/sakit siswa. class_id NULL berarti berlaku untuk semua kelas siswa.
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    class_id       INT REFERENCES classes(id) ON DELETE CASCADE,
+    type           TEXT NOT NULL CHECK (type IN ('izin', 'sakit')),
+    start_date     DATE NOT NULL,
+    end_date       DATE NOT NULL,
+    reason         TEXT NOT NULL,
+    attachment_url TEXT,
+    status         TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    review_note    TEXT NOT NULL DEFAULT '',
+    reviewed_by    INT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_user ON leave_requests (user_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_class_status ON leave_requests (class_id, status);
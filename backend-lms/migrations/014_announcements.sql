@@ -0,0 +1,25 @@
+-- Pengumuman per kelas. content berisi HTML yang sudah disanitasi.
+CREATE TABLE IF NOT EXISTS announcements (
+    id         SERIAL PRIMARY KEY,
+    class_id   INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    author_id  INT REFERENCES users(id) ON DELETE SET NULL,
+    title      TEXT NOT NULL DEFAULT '',
+    content    TEXT NOT NULL,
+    -- Pengumuman baru terlihat siswa setelah publish_at
+    publish_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    pinned_at  TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_class_publish ON announcements (class_id, publish_at DESC);
+
+CREATE TABLE IF NOT EXISTS announcement_attachments (
+    id              SERIAL PRIMARY KEY,
+    announcement_id INT NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    url             TEXT NOT NULL,
+    file_name       TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcement_attachments_announcement ON announcement_attachments (announcement_id);
@@ -0,0 +1,5 @@
+-- Nama lengkap dan nomor induk (NIS/NISN untuk siswa, NIP untuk guru) untuk roster dan dokumen resmi.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS full_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS identifier TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_identifier_key ON users (identifier) WHERE identifier <> '';
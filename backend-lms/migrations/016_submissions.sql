@@ -0,0 +1,104 @@
+-- Pengumpulan tugas siswa, terpisah dari tabel assignments.
+CREATE TABLE IF NOT EXISTS submissions (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    user_id       INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'late', 'returned', 'graded')),
+    text_answer   TEXT NOT NULL DEFAULT '',
+    -- Jumlah pengumpulan (termasuk pengumpulan ulang)
+    attempt       INT NOT NULL DEFAULT 0,
+    submitted_at  TIMESTAMPTZ,
+    returned_at   TIMESTAMPTZ,
+    graded_at     TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (assignment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_submissions_user ON submissions (user_id);
+
+CREATE TABLE IF NOT EXISTS submission_files (
+    id            SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    url           TEXT NOT NULL,
+    file_name     TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_files_submission ON submission_files (submission_id);
+
+-- Salinan jawaban setiap kali dikumpulkan, untuk riwayat pengumpulan ulang.
+CREATE TABLE IF NOT EXISTS submission_history (
+    id            SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    attempt       INT NOT NULL,
+    status        TEXT NOT NULL,
+    text_answer   TEXT NOT NULL DEFAULT '',
+    files         JSONB NOT NULL DEFAULT '[]',
+    submitted_at  TIMESTAMPTZ NOT NULL,
+    UNIQUE (submission_id, attempt)
+);
+
+-- Data lama: siswa "mengumpulkan" dengan membuat baris baru di assignments. Baris yang
+-- judulnya cocok dengan tepat satu tugas guru di kelas yang sama dipindahkan ke submissions.
+BEGIN;
+
+CREATE TEMP TABLE legacy_submissions ON COMMIT DROP AS
+SELECT s.id AS legacy_id, m.assignment_id, s.created_by AS user_id, COALESCE(s.description, '') AS text_answer,
+       s.attachment, s.created_at
+FROM assignments s
+JOIN users su ON su.id = s.created_by AND su.role = 'Siswa'
+JOIN LATERAL (
+    SELECT MIN(t.id) AS assignment_id, COUNT(*) AS matches
+    FROM assignments t
+    LEFT JOIN users tu ON tu.id = t.created_by
+    WHERE t.class_id = s.class_id AND t.id <> s.id AND COALESCE(tu.role, '') <> 'Siswa'
+      AND lower(trim(t.title)) = lower(trim(s.title))
+) m ON m.matches = 1;
+
+INSERT INTO submissions (assignment_id, user_id, status, text_answer, attempt, submitted_at, created_at, updated_at)
+SELECT DISTINCT ON (assignment_id, user_id) assignment_id, user_id, 'submitted', text_answer, 1, created_at, created_at, created_at
+FROM legacy_submissions
+ORDER BY assignment_id, user_id, created_at DESC
+ON CONFLICT (assignment_id, user_id) DO NOTHING;
+
+INSERT INTO submission_files (submission_id, url, created_at)
+SELECT sub.id, l.attachment, l.created_at
+FROM legacy_submissions l
+JOIN submissions sub ON sub.assignment_id = l.assignment_id AND sub.user_id = l.user_id
+WHERE COALESCE(l.attachment, '') <> '';
+
+DELETE FROM assignments WHERE id IN (SELECT legacy_id FROM legacy_submissions);
+
+-- Baris siswa yang judulnya tidak cocok (atau cocok dengan lebih dari satu tugas) dipindahkan
+-- ke legacy_unmatched_submissions supaya guru bisa menautkannya manual, dan tidak lagi
+-- muncul sebagai tugas kelas.
+CREATE TABLE IF NOT EXISTS legacy_unmatched_submissions (
+    legacy_id   INT PRIMARY KEY,
+    class_id    INT,
+    user_id     INT,
+    title       TEXT NOT NULL DEFAULT '',
+    text_answer TEXT NOT NULL DEFAULT '',
+    attachment  TEXT,
+    created_at  TIMESTAMPTZ
+);
+
+INSERT INTO legacy_unmatched_submissions (legacy_id, class_id, user_id, title, text_answer, attachment, created_at)
+SELECT s.id, s.class_id, s.created_by, COALESCE(s.title, ''), COALESCE(s.description, ''), s.attachment, s.created_at
+FROM assignments s
+JOIN users su ON su.id = s.created_by AND su.role = 'Siswa'
+ON CONFLICT (legacy_id) DO NOTHING;
+
+DELETE FROM assignments WHERE id IN (SELECT legacy_id FROM legacy_unmatched_submissions);
+
+DO $$
+DECLARE
+    unmatched INT;
+BEGIN
+    SELECT COUNT(*) INTO unmatched FROM legacy_unmatched_submissions;
+    IF unmatched > 0 THEN
+        RAISE NOTICE '% legacy student submissions could not be matched to an assignment, see legacy_unmatched_submissions', unmatched;
+    END IF;
+END $$;
+
+COMMIT;
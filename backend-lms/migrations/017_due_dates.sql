@@ -0,0 +1,36 @@
+-- Due date tugas disimpan sebagai TIMESTAMPTZ. Nilai lama berupa teks dibaca sebagai waktu
+-- WIB; tanggal tanpa jam dianggap berakhir pukul 23:59:59. Sesuaikan 'Asia/Jakarta' di bawah
+-- jika DEFAULT_TIMEZONE sekolah berbeda. Nilai yang tidak bisa dibaca menjadi NULL.
+CREATE OR REPLACE FUNCTION pg_temp.parse_legacy_due_date(value TEXT) RETURNS TIMESTAMPTZ AS $$
+BEGIN
+    value := trim(value);
+    IF value IS NULL OR value = '' THEN
+        RETURN NULL;
+    END IF;
+    IF value ~ '^\d{4}-\d{2}-\d{2}$' THEN
+        RETURN (value::DATE + INTERVAL '1 day' - INTERVAL '1 second') AT TIME ZONE 'Asia/Jakarta';
+    END IF;
+    IF value ~ '(Z|[+-]\d{2}(:?\d{2})?)$' THEN
+        RETURN value::TIMESTAMPTZ;
+    END IF;
+    RETURN value::TIMESTAMP AT TIME ZONE 'Asia/Jakarta';
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE assignments
+    ALTER COLUMN due_date TYPE TIMESTAMPTZ USING pg_temp.parse_legacy_due_date(due_date::TEXT);
+
+-- Kebijakan keterlambatan: accept (diterima tanpa potongan), penalty (potongan persen per
+-- hari terlambat, maksimal 100) atau reject (tidak bisa dikumpulkan setelah due date).
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_policy TEXT NOT NULL DEFAULT 'accept'
+    CHECK (late_policy IN ('accept', 'penalty', 'reject'));
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_penalty_percent NUMERIC(5, 2) NOT NULL DEFAULT 0
+    CHECK (late_penalty_percent >= 0 AND late_penalty_percent <= 100);
+
+-- Keterlambatan dihitung saat siswa mengumpulkan dan dipakai saat penilaian
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS late_days INT NOT NULL DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS late_penalty_percent NUMERIC(5, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_assignments_due_date ON assignments (class_id, due_date);
@@ -0,0 +1,27 @@
+-- Penilaian pengumpulan tugas: poin dari nilai maksimal tugas, feedback dan komentar per file.
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_points NUMERIC(7, 2) NOT NULL DEFAULT 100
+    CHECK (max_points > 0);
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS points NUMERIC(7, 2);
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS feedback TEXT NOT NULL DEFAULT '';
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS graded_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+-- Komentar guru pada file pengumpulan. file_id NULL berarti komentar untuk pengumpulan
+-- secara umum; page dan anchor (misalnya kutipan teks) menunjuk lokasi di dalam file.
+CREATE TABLE IF NOT EXISTS submission_comments (
+    id            SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    file_id       INT REFERENCES submission_files(id) ON DELETE CASCADE,
+    author_id     INT REFERENCES users(id) ON DELETE SET NULL,
+    page          INT CHECK (page > 0),
+    anchor        TEXT NOT NULL DEFAULT '',
+    body          TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_comments_submission ON submission_comments (submission_id);
+
+-- Nilai tugas masuk ke buku nilai (tabel grades) sebagai skala 0-100, satu baris per tugas.
+-- Baris tanpa assignment_id adalah nilai yang diinput manual.
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS assignment_id INT REFERENCES assignments(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grades_assignment ON grades (user_id, assignment_id) WHERE assignment_id IS NOT NULL;
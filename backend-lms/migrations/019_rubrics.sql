@@ -0,0 +1,45 @@
+-- Rubrik penilaian yang bisa dipakai ulang: kriteria dengan level (deskriptor dan poin).
+CREATE TABLE IF NOT EXISTS rubrics (
+    id          SERIAL PRIMARY KEY,
+    owner_id    INT REFERENCES users(id) ON DELETE SET NULL,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubrics_owner ON rubrics (owner_id);
+
+CREATE TABLE IF NOT EXISTS rubric_criteria (
+    id          SERIAL PRIMARY KEY,
+    rubric_id   INT NOT NULL REFERENCES rubrics(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_criteria_rubric ON rubric_criteria (rubric_id, position);
+
+CREATE TABLE IF NOT EXISTS rubric_levels (
+    id           SERIAL PRIMARY KEY,
+    criterion_id INT NOT NULL REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    position     INT NOT NULL,
+    title        TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    points       NUMERIC(7, 2) NOT NULL CHECK (points >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_levels_criterion ON rubric_levels (criterion_id, position);
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rubric_id INT REFERENCES rubrics(id) ON DELETE SET NULL;
+
+-- Skor per kriteria. Rubrik yang sudah dipakai menilai tidak bisa diubah strukturnya,
+-- jadi kriteria dan level di sini tetap valid.
+CREATE TABLE IF NOT EXISTS submission_rubric_scores (
+    submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    criterion_id  INT NOT NULL REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    level_id      INT REFERENCES rubric_levels(id) ON DELETE SET NULL,
+    points        NUMERIC(7, 2) NOT NULL,
+    comment       TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (submission_id, criterion_id)
+);
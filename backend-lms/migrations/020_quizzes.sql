@@ -0,0 +1,72 @@
+-- Kuis/ujian online per kelas.
+CREATE TABLE IF NOT EXISTS quizzes (
+    id                 SERIAL PRIMARY KEY,
+    class_id           INT NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    title              TEXT NOT NULL,
+    description        TEXT NOT NULL DEFAULT '',
+    -- NULL berarti tanpa batas waktu
+    time_limit_minutes INT CHECK (time_limit_minutes > 0),
+    opens_at           TIMESTAMPTZ,
+    closes_at          TIMESTAMPTZ,
+    -- 0 berarti percobaan tidak dibatasi
+    max_attempts       INT NOT NULL DEFAULT 1 CHECK (max_attempts >= 0),
+    shuffle_questions  BOOLEAN NOT NULL DEFAULT FALSE,
+    shuffle_options    BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Kunci jawaban ditampilkan ke siswa setelah percobaan dikumpulkan
+    show_answers       BOOLEAN NOT NULL DEFAULT FALSE,
+    published          BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by         INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (closes_at IS NULL OR opens_at IS NULL OR closes_at > opens_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quizzes_class ON quizzes (class_id);
+
+-- Isi soal (tipe, pertanyaan, opsi, kunci, poin) disimpan sebagai JSON.
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    id         SERIAL PRIMARY KEY,
+    quiz_id    INT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    position   INT NOT NULL,
+    question   JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_quiz ON quiz_questions (quiz_id, position);
+
+CREATE TABLE IF NOT EXISTS quiz_attempts (
+    id           SERIAL PRIMARY KEY,
+    quiz_id      INT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempt_no   INT NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'submitted', 'graded')),
+    started_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Batas waktu percobaan: batas durasi atau waktu tutup kuis, mana yang lebih dulu
+    deadline_at  TIMESTAMPTZ,
+    submitted_at TIMESTAMPTZ,
+    score        NUMERIC(8, 2),
+    max_score    NUMERIC(8, 2) NOT NULL DEFAULT 0,
+    UNIQUE (quiz_id, user_id, attempt_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_user ON quiz_attempts (user_id, quiz_id);
+
+-- Soal yang diterima siswa pada satu percobaan. question adalah salinan soal (dengan urutan
+-- opsi setelah diacak) supaya perubahan soal tidak mengubah percobaan yang sudah berjalan.
+CREATE TABLE IF NOT EXISTS quiz_attempt_items (
+    id             SERIAL PRIMARY KEY,
+    attempt_id     INT NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    position       INT NOT NULL,
+    question_id    INT REFERENCES quiz_questions(id) ON DELETE SET NULL,
+    question       JSONB NOT NULL,
+    response       JSONB,
+    points_awarded NUMERIC(8, 2),
+    needs_grading  BOOLEAN NOT NULL DEFAULT FALSE,
+    feedback       TEXT NOT NULL DEFAULT '',
+    UNIQUE (attempt_id, position)
+);
+
+-- Nilai kuis masuk ke buku nilai, satu baris per siswa per kuis.
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grades_quiz ON grades (user_id, quiz_id) WHERE quiz_id IS NOT NULL;
@@ -0,0 +1,52 @@
+-- Bank soal milik guru. Soal yang dibagikan (shared) bisa dipakai semua guru.
+CREATE TABLE IF NOT EXISTS bank_questions (
+    id              SERIAL PRIMARY KEY,
+    owner_id        INT REFERENCES users(id) ON DELETE SET NULL,
+    shared          BOOLEAN NOT NULL DEFAULT FALSE,
+    subject         TEXT NOT NULL DEFAULT '',
+    topic           TEXT NOT NULL DEFAULT '',
+    difficulty      TEXT NOT NULL DEFAULT 'medium' CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    -- Kompetensi dasar (KD), misalnya "3.2"
+    competency      TEXT NOT NULL DEFAULT '',
+    current_version INT NOT NULL DEFAULT 1,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_questions_owner ON bank_questions (owner_id);
+CREATE INDEX IF NOT EXISTS idx_bank_questions_tags ON bank_questions (LOWER(subject), LOWER(topic), difficulty, LOWER(competency));
+
+-- Setiap perubahan isi soal disimpan sebagai versi baru; versi lama tidak pernah diubah.
+CREATE TABLE IF NOT EXISTS bank_question_versions (
+    id               SERIAL PRIMARY KEY,
+    bank_question_id INT NOT NULL REFERENCES bank_questions(id) ON DELETE CASCADE,
+    version          INT NOT NULL,
+    question         JSONB NOT NULL,
+    created_by       INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (bank_question_id, version)
+);
+
+-- Aturan pengambilan soal acak: setiap siswa mendapat count soal dari bank yang cocok
+-- dengan filter tag (filter kosong berarti tidak dibatasi), masing-masing bernilai points.
+CREATE TABLE IF NOT EXISTS quiz_draw_rules (
+    id         SERIAL PRIMARY KEY,
+    quiz_id    INT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    position   INT NOT NULL,
+    count      INT NOT NULL CHECK (count > 0),
+    points     NUMERIC(8, 2) NOT NULL CHECK (points > 0),
+    subject    TEXT NOT NULL DEFAULT '',
+    topic      TEXT NOT NULL DEFAULT '',
+    difficulty TEXT NOT NULL DEFAULT '',
+    competency TEXT NOT NULL DEFAULT '',
+    -- Soal diambil dari bank milik guru ini dan soal yang dibagikan
+    created_by INT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_draw_rules_quiz ON quiz_draw_rules (quiz_id, position);
+
+-- Asal soal dari bank (versi yang dipakai) untuk soal kuis dan soal percobaan
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS bank_question_id INT REFERENCES bank_questions(id) ON DELETE SET NULL;
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS bank_version INT;
+ALTER TABLE quiz_attempt_items ADD COLUMN IF NOT EXISTS bank_question_id INT REFERENCES bank_questions(id) ON DELETE SET NULL;
+ALTER TABLE quiz_attempt_items ADD COLUMN IF NOT EXISTS bank_version INT;
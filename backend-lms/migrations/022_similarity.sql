@@ -0,0 +1,35 @@
+-- Deteksi kemiripan teks pengumpulan. Teks file (.txt/.docx/.pdf) diekstrak sekali lalu
+-- disimpan; extracted_text dan extract_error sama-sama NULL berarti file belum diproses.
+ALTER TABLE submission_files ADD COLUMN IF NOT EXISTS extracted_text TEXT;
+ALTER TABLE submission_files ADD COLUMN IF NOT EXISTS extract_error TEXT;
+
+-- Pemeriksaan terakhir per tugas; pemeriksaan ulang mengganti hasil sebelumnya.
+CREATE TABLE IF NOT EXISTS similarity_checks (
+    assignment_id    INT PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    threshold        NUMERIC(5, 4) NOT NULL,
+    other_classes    BOOLEAN NOT NULL DEFAULT TRUE,
+    submission_count INT NOT NULL DEFAULT 0,
+    compared_count   INT NOT NULL DEFAULT 0,
+    checked_by       INT REFERENCES users(id) ON DELETE SET NULL,
+    checked_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS similarity_submissions (
+    assignment_id INT NOT NULL REFERENCES similarity_checks(assignment_id) ON DELETE CASCADE,
+    submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    word_count    INT NOT NULL DEFAULT 0,
+    max_score     NUMERIC(5, 4) NOT NULL DEFAULT 0,
+    PRIMARY KEY (assignment_id, submission_id)
+);
+
+-- Pasangan yang melewati ambang. containment = bagian teks submission_id yang juga ada di
+-- matched_submission_id (tidak simetris); passages berisi potongan teks yang sama.
+CREATE TABLE IF NOT EXISTS similarity_matches (
+    assignment_id         INT NOT NULL REFERENCES similarity_checks(assignment_id) ON DELETE CASCADE,
+    submission_id         INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    matched_submission_id INT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    jaccard               NUMERIC(5, 4) NOT NULL,
+    containment           NUMERIC(5, 4) NOT NULL,
+    passages              JSONB NOT NULL DEFAULT '[]',
+    PRIMARY KEY (assignment_id, submission_id, matched_submission_id)
+);
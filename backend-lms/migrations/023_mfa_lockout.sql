@@ -0,0 +1,5 @@
+-- Percobaan kode 2FA yang gagal dihitung per user, bukan per challenge, jadi login ulang untuk
+-- mendapat challenge baru tidak mereset batasnya. Setiap kelipatan batas gagal mengunci
+-- verifikasi sampai locked_until (durasinya berlipat dua setiap kali terkunci lagi).
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
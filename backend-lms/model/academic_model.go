@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// AcademicYear adalah tahun ajaran, misalnya "2026/2027". Tanggal berformat YYYY-MM-DD.
+type AcademicYear struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	StartDate string    `json:"start_date"`
+	EndDate   string    `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+	Terms     []Term    `json:"terms"`
+}
+
+// Term adalah semester di dalam tahun ajaran. Hanya satu term yang aktif.
+type Term struct {
+	ID             int       `json:"id"`
+	AcademicYearID int       `json:"academic_year_id"`
+	Name           string    `json:"name"`
+	StartDate      string    `json:"start_date"`
+	EndDate        string    `json:"end_date"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Announcement adalah pengumuman di stream kelas. Content berupa HTML yang sudah disanitasi.
+type Announcement struct {
+	ID          int          `json:"id"`
+	ClassID     int          `json:"class_id"`
+	AuthorID    *int         `json:"author_id"`
+	Author      string       `json:"author"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	PublishAt   time.Time    `json:"publish_at"`
+	Published   bool         `json:"published"`
+	PinnedAt    *time.Time   `json:"pinned_at"`
+	Attachments []Attachment `json:"attachments"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Attachment adalah file yang sudah diunggah ke storage.
+type Attachment struct {
+	ID       int    `json:"id,omitempty"`
+	URL      string `json:"url"`
+	FileName string `json:"file_name"`
+}
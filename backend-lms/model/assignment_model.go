@@ -10,8 +10,21 @@ type Assignment struct {
 	ClassID     int            `json:"class_id"`
 	Title       string         `json:"title"`
 	Description string         `json:"description"`
-	DueDate     string         `json:"due_date"`
 	CreatedAt   time.Time      `json:"created_at"`
 	Attachment  sql.NullString `json:"attachment"`
 	CreatedBy   sql.NullInt64  `json:"created_by"`
+	UnitID      *int           `json:"unit_id"`
+	Position    int            `json:"position"`
+	MaxPoints   float64        `json:"max_points"`
+	Deadline
+}
+
+// Deadline adalah due date tugas beserta kebijakan keterlambatannya. RemainingSeconds dan
+// IsOverdue dihitung saat response dibuat; RemainingSeconds nil jika tidak ada due date.
+type Deadline struct {
+	DueDate            *time.Time `json:"due_date"`
+	LatePolicy         string     `json:"late_policy"`
+	LatePenaltyPercent float64    `json:"late_penalty_percent"`
+	RemainingSeconds   *int64     `json:"remaining_seconds"`
+	IsOverdue          bool       `json:"is_overdue"`
 }
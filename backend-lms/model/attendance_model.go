@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// ClassSession adalah satu pertemuan kelas yang diabsen.
+type ClassSession struct {
+	ID         int       `json:"id"`
+	ClassID    int       `json:"class_id"`
+	ScheduleID *int      `json:"schedule_id"`
+	Date       string    `json:"date"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+	Topic      string    `json:"topic"`
+	Source     string    `json:"source"`
+	// CheckinOpenUntil terisi selama check-in QR dibuka
+	CheckinOpenUntil *time.Time `json:"checkin_open_until"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// AttendanceRecord adalah status kehadiran satu siswa di satu pertemuan.
+type AttendanceRecord struct {
+	SessionID int       `json:"session_id"`
+	UserID    int       `json:"user_id"`
+	Status    string    `json:"status"`
+	Method    string    `json:"method"`
+	Note      string    `json:"note"`
+	MarkedBy  *int      `json:"marked_by"`
+	MarkedAt  time.Time `json:"marked_at"`
+}
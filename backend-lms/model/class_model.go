@@ -8,5 +8,43 @@ type Class struct {
 	JadwalKelas string    `json:"jadwal_kelas"`
 	CreatedAt   time.Time `json:"created_at"`
 	Teacher     string    `json:"teacher"`
-	ClassCode   string    `json:"class_code"`
+	// ClassCode hanya diisi untuk Admin, owner dan co_teacher
+	ClassCode string `json:"class_code,omitempty"`
+	// TermID kosong untuk kelas lama yang belum dipasang ke semester
+	TermID     *int       `json:"term_id"`
+	ArchivedAt *time.Time `json:"archived_at"`
+}
+
+// ClassCode adalah status kode bergabung sebuah kelas.
+type ClassCode struct {
+	ClassID   int        `json:"class_id"`
+	Code      string     `json:"class_code"`
+	Enabled   bool       `json:"enabled"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   *int       `json:"max_uses"`
+	Uses      int        `json:"uses"`
+}
+
+// ClassJoinRequest adalah permintaan bergabung ke kelas dengan mode approval.
+type ClassJoinRequest struct {
+	ID         int        `json:"id"`
+	ClassID    int        `json:"class_id"`
+	UserID     int        `json:"user_id"`
+	Username   string     `json:"username"`
+	Status     string     `json:"status"`
+	Message    *string    `json:"message"`
+	ReviewedBy *int       `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ClassMember adalah user beserta role-nya di sebuah kelas.
+type ClassMember struct {
+	UserID     int       `json:"id"`
+	Username   string    `json:"username"`
+	FullName   string    `json:"full_name"`
+	Identifier string    `json:"identifier"`
+	Role       string    `json:"role"`
+	ClassRole  string    `json:"class_role"`
+	JoinedAt   time.Time `json:"joined_at"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserIdentity menautkan akun di identity provider (issuer + subject) ke user lokal.
+type UserIdentity struct {
+	ID          int            `json:"id"`
+	UserID      int            `json:"user_id"`
+	Issuer      string         `json:"issuer"`
+	Subject     string         `json:"subject"`
+	Email       sql.NullString `json:"email"`
+	CreatedAt   time.Time      `json:"created_at"`
+	LastLoginAt sql.NullTime   `json:"last_login_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// LeaveRequest adalah pengajuan izin/sakit siswa untuk rentang tanggal. ClassID nil berarti
+// berlaku untuk semua kelas siswa dan ditinjau guru kelas perwalian.
+type LeaveRequest struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	Username      string     `json:"username"`
+	ClassID       *int       `json:"class_id"`
+	Type          string     `json:"type"`
+	StartDate     string     `json:"start_date"`
+	EndDate       string     `json:"end_date"`
+	Reason        string     `json:"reason"`
+	AttachmentURL *string    `json:"attachment_url"`
+	Status        string     `json:"status"`
+	ReviewNote    string     `json:"review_note"`
+	ReviewedBy    *int       `json:"reviewed_by"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
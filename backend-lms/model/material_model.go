@@ -12,4 +12,6 @@ type Material struct {
 	ClassID   int       `json:"class_id"`
 	CreatedAt time.Time `json:"created_at"`
 	Attachment sql.NullString  `json:"attachment"`
+	UnitID     *int            `json:"unit_id"`
+	Position   int             `json:"position"`
 }
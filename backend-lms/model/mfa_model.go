@@ -0,0 +1,26 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type UserMFA struct {
+	UserID       int          `json:"user_id"`
+	Secret       string       `json:"-"`
+	Enabled      bool         `json:"enabled"`
+	LastUsedStep int64        `json:"-"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ConfirmedAt  sql.NullTime `json:"confirmed_at"`
+}
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"otpauth_url"`
+}
+
+type MFARolePolicy struct {
+	Role      string    `json:"role"`
+	Required  bool      `json:"required"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// BankQuestion adalah soal di bank soal guru beserta isi versi terbarunya.
+type BankQuestion struct {
+	ID             int       `json:"id"`
+	OwnerID        *int      `json:"owner_id"`
+	OwnerUsername  string    `json:"owner_username"`
+	Shared         bool      `json:"shared"`
+	Subject        string    `json:"subject"`
+	Topic          string    `json:"topic"`
+	Difficulty     string    `json:"difficulty"`
+	Competency     string    `json:"competency"`
+	CurrentVersion int       `json:"current_version"`
+	Question       Question  `json:"question"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type BankQuestionVersion struct {
+	Version   int       `json:"version"`
+	Question  Question  `json:"question"`
+	CreatedBy *int      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QuestionTags adalah nilai tag yang sudah dipakai, untuk pilihan filter.
+type QuestionTags struct {
+	Subjects     []string `json:"subjects"`
+	Topics       []string `json:"topics"`
+	Competencies []string `json:"competencies"`
+}
+
+// QuizDrawRule mengambil Count soal acak dari bank yang cocok dengan tag untuk tiap percobaan.
+type QuizDrawRule struct {
+	ID         int     `json:"id"`
+	Position   int     `json:"position"`
+	Count      int     `json:"count"`
+	Points     float64 `json:"points"`
+	Subject    string  `json:"subject"`
+	Topic      string  `json:"topic"`
+	Difficulty string  `json:"difficulty"`
+	Competency string  `json:"competency"`
+	CreatedBy  *int    `json:"created_by"`
+	// Available jumlah soal bank yang saat ini cocok dengan aturan
+	Available int `json:"available"`
+}
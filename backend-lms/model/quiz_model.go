@@ -0,0 +1,97 @@
+package model
+
+import "time"
+
+// Quiz adalah kuis atau ujian kelas. Questions hanya diisi pada detail untuk guru.
+type Quiz struct {
+	ID               int            `json:"id"`
+	ClassID          int            `json:"class_id"`
+	Title            string         `json:"title"`
+	Description      string         `json:"description"`
+	TimeLimitMinutes *int           `json:"time_limit_minutes"`
+	OpensAt          *time.Time     `json:"opens_at"`
+	ClosesAt         *time.Time     `json:"closes_at"`
+	MaxAttempts      int            `json:"max_attempts"`
+	ShuffleQuestions bool           `json:"shuffle_questions"`
+	ShuffleOptions   bool           `json:"shuffle_options"`
+	ShowAnswers      bool           `json:"show_answers"`
+	Published        bool           `json:"published"`
+	QuestionCount    int            `json:"question_count"`
+	TotalPoints      float64        `json:"total_points"`
+	CreatedBy        *int           `json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	Questions        []QuizQuestion `json:"questions,omitempty"`
+	DrawRules        []QuizDrawRule `json:"draw_rules,omitempty"`
+}
+
+// Question adalah isi satu soal beserta kunci jawabannya. Soal benar/salah memakai dua opsi
+// dengan id "true" dan "false".
+type Question struct {
+	Type    string           `json:"type"`
+	Prompt  string           `json:"prompt"`
+	Points  float64          `json:"points"`
+	Options []QuestionOption `json:"options,omitempty"`
+	// AcceptedAnswers untuk isian singkat: teks dengan wildcard * atau regex di antara /.../
+	AcceptedAnswers []string `json:"accepted_answers,omitempty"`
+	CaseSensitive   bool     `json:"case_sensitive,omitempty"`
+	NumericAnswer   *float64 `json:"numeric_answer,omitempty"`
+	Tolerance       float64  `json:"tolerance,omitempty"`
+	// Explanation ditampilkan bersama kunci jawaban
+	Explanation string `json:"explanation,omitempty"`
+}
+
+type QuestionOption struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Correct bool   `json:"correct"`
+}
+
+type QuizQuestion struct {
+	ID             int  `json:"id"`
+	Position       int  `json:"position"`
+	BankQuestionID *int `json:"bank_question_id"`
+	BankVersion    *int `json:"bank_version"`
+	Question
+}
+
+// QuestionResponse adalah jawaban siswa: Selected untuk pilihan ganda dan benar/salah,
+// Text untuk isian singkat dan esai, Number untuk jawaban angka.
+type QuestionResponse struct {
+	Selected []string `json:"selected,omitempty"`
+	Text     string   `json:"text,omitempty"`
+	Number   *float64 `json:"number,omitempty"`
+}
+
+type QuizAttempt struct {
+	ID          int               `json:"id"`
+	QuizID      int               `json:"quiz_id"`
+	UserID      int               `json:"user_id"`
+	Username    string            `json:"username"`
+	AttemptNo   int               `json:"attempt_no"`
+	Status      string            `json:"status"`
+	StartedAt   time.Time         `json:"started_at"`
+	DeadlineAt  *time.Time        `json:"deadline_at"`
+	SubmittedAt *time.Time        `json:"submitted_at"`
+	Score       *float64          `json:"score"`
+	MaxScore    float64           `json:"max_score"`
+	Items       []QuizAttemptItem `json:"items,omitempty"`
+	// RemainingSeconds sisa waktu percobaan yang sedang berjalan
+	RemainingSeconds *int64 `json:"remaining_seconds,omitempty"`
+}
+
+// QuizAttemptItem adalah satu soal dalam percobaan. Kunci jawaban di Question dikosongkan
+// sebelum dikirim ke siswa kecuali kuis menampilkan kunci setelah dikumpulkan.
+type QuizAttemptItem struct {
+	ID         int  `json:"id"`
+	Position   int  `json:"position"`
+	QuestionID *int `json:"question_id"`
+	// BankQuestionID dan BankVersion terisi untuk soal yang diambil dari bank soal
+	BankQuestionID *int              `json:"bank_question_id"`
+	BankVersion    *int              `json:"bank_version"`
+	Question       Question          `json:"question"`
+	Response       *QuestionResponse `json:"response"`
+	PointsAwarded  *float64          `json:"points_awarded"`
+	NeedsGrading   bool              `json:"needs_grading"`
+	Feedback       string            `json:"feedback"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Rubric adalah rubrik penilaian milik guru. MaxPoints adalah jumlah poin level tertinggi
+// setiap kriteria.
+type Rubric struct {
+	ID          int               `json:"id"`
+	OwnerID     *int              `json:"owner_id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	MaxPoints   float64           `json:"max_points"`
+	InUse       bool              `json:"in_use"`
+	Criteria    []RubricCriterion `json:"criteria"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type RubricCriterion struct {
+	ID          int           `json:"id"`
+	Position    int           `json:"position"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	MaxPoints   float64       `json:"max_points"`
+	Levels      []RubricLevel `json:"levels"`
+}
+
+type RubricLevel struct {
+	ID          int     `json:"id"`
+	Position    int     `json:"position"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points"`
+}
+
+// SubmissionRubric adalah rubrik tugas yang diisi untuk satu pengumpulan. Skor kosong
+// jika pengumpulan belum dinilai dengan rubrik.
+type SubmissionRubric struct {
+	RubricID    int                    `json:"rubric_id"`
+	Title       string                 `json:"title"`
+	MaxPoints   float64                `json:"max_points"`
+	TotalPoints *float64               `json:"total_points"`
+	Criteria    []RubricCriterionScore `json:"criteria"`
+}
+
+type RubricCriterionScore struct {
+	RubricCriterion
+	LevelID *int     `json:"level_id"`
+	Points  *float64 `json:"points"`
+	Comment string   `json:"comment"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ClassSchedule adalah satu pertemuan mingguan. StartTime dan EndTime berformat HH:MM
+// di Timezone jadwal tersebut.
+type ClassSchedule struct {
+	ID        int    `json:"id"`
+	ClassID   int    `json:"class_id"`
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Room      string `json:"room"`
+	Timezone  string `json:"timezone"`
+}
+
+// ScheduleException adalah tanggal tanpa pertemuan. ClassID nil berarti libur untuk semua kelas.
+type ScheduleException struct {
+	ID        int       `json:"id"`
+	ClassID   *int      `json:"class_id"`
+	Date      string    `json:"date"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
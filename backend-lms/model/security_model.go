@@ -0,0 +1,43 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type LoginAttempt struct {
+	ID            int            `json:"id"`
+	UserID        sql.NullInt64  `json:"user_id"`
+	Username      string         `json:"username"`
+	Success       bool           `json:"success"`
+	Method        string         `json:"method"`
+	FailureReason sql.NullString `json:"failure_reason"`
+	IP            sql.NullString `json:"ip"`
+	UserAgent     sql.NullString `json:"user_agent"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type UserSession struct {
+	ID         string         `json:"id"`
+	UserID     int            `json:"user_id"`
+	Method     string         `json:"method"`
+	IP         sql.NullString `json:"ip"`
+	UserAgent  sql.NullString `json:"user_agent"`
+	CreatedAt  time.Time      `json:"created_at"`
+	LastSeenAt time.Time      `json:"last_seen_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	Current    bool           `json:"current"`
+}
+
+type SecurityEvent struct {
+	ID        int             `json:"id"`
+	EventType string          `json:"event_type"`
+	Severity  string          `json:"severity"`
+	UserID    sql.NullInt64   `json:"user_id"`
+	Username  sql.NullString  `json:"username"`
+	ActorID   sql.NullInt64   `json:"actor_id"`
+	IP        sql.NullString  `json:"ip"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
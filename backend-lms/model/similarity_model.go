@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// SimilarityReport adalah hasil pemeriksaan kemiripan terakhir untuk satu tugas.
+type SimilarityReport struct {
+	AssignmentID    int                    `json:"assignment_id"`
+	Threshold       float64                `json:"threshold"`
+	OtherClasses    bool                   `json:"other_classes"`
+	SubmissionCount int                    `json:"submission_count"`
+	ComparedCount   int                    `json:"compared_count"`
+	CheckedBy       *int                   `json:"checked_by"`
+	CheckedAt       time.Time              `json:"checked_at"`
+	Submissions     []SubmissionSimilarity `json:"submissions"`
+}
+
+// SubmissionSimilarity: MaxScore adalah containment tertinggi dari semua pasangan. Outdated
+// berarti siswa mengumpulkan ulang setelah pemeriksaan.
+type SubmissionSimilarity struct {
+	SubmissionID int               `json:"submission_id"`
+	UserID       int               `json:"user_id"`
+	Username     string            `json:"username"`
+	FullName     string            `json:"full_name"`
+	WordCount    int               `json:"word_count"`
+	MaxScore     float64           `json:"max_score"`
+	Outdated     bool              `json:"outdated"`
+	Warnings     []string          `json:"warnings"`
+	Matches      []SimilarityMatch `json:"matches"`
+}
+
+// SimilarityMatch adalah pengumpulan lain yang mirip, dari tugas yang sama atau tugas
+// berjudul sama di kelas/semester lain.
+type SimilarityMatch struct {
+	SubmissionID    int              `json:"submission_id"`
+	UserID          int              `json:"user_id"`
+	Username        string           `json:"username"`
+	FullName        string           `json:"full_name"`
+	AssignmentID    int              `json:"assignment_id"`
+	AssignmentTitle string           `json:"assignment_title"`
+	ClassID         int              `json:"class_id"`
+	ClassName       string           `json:"class_name"`
+	Term            string           `json:"term"`
+	SameAssignment  bool             `json:"same_assignment"`
+	Jaccard         float64          `json:"jaccard"`
+	Containment     float64          `json:"containment"`
+	Passages        []MatchedPassage `json:"passages"`
+}
+
+// MatchedPassage: Text dari pengumpulan yang diperiksa, MatchedText dari pengumpulan lain.
+type MatchedPassage struct {
+	Text        string `json:"text"`
+	MatchedText string `json:"matched_text"`
+	Words       int    `json:"words"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Submission adalah pengumpulan satu siswa untuk satu tugas.
+type Submission struct {
+	ID           int          `json:"id"`
+	AssignmentID int          `json:"assignment_id"`
+	UserID       int          `json:"user_id"`
+	Username     string       `json:"username"`
+	Status       string       `json:"status"`
+	TextAnswer   string       `json:"text_answer"`
+	Attempt      int          `json:"attempt"`
+	Files        []Attachment `json:"files"`
+	SubmittedAt  *time.Time   `json:"submitted_at"`
+	ReturnedAt   *time.Time   `json:"returned_at"`
+	GradedAt     *time.Time   `json:"graded_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	// LateDays dan PenaltyPercent dihitung saat dikumpulkan
+	LateDays       int     `json:"late_days"`
+	PenaltyPercent float64 `json:"penalty_percent"`
+	// Points nilai mentah dari guru; FinalPoints setelah potongan keterlambatan
+	Points      *float64            `json:"points"`
+	MaxPoints   float64             `json:"max_points"`
+	FinalPoints *float64            `json:"final_points"`
+	Feedback    string              `json:"feedback"`
+	Comments    []SubmissionComment `json:"comments"`
+	// Rubric berisi rubrik tugas dan skor per kriteria jika tugas memakai rubrik
+	Rubric  *SubmissionRubric   `json:"rubric,omitempty"`
+	History []SubmissionVersion `json:"history"`
+	Deadline
+}
+
+// SubmissionComment adalah komentar guru pada pengumpulan. FileID nil untuk komentar umum.
+type SubmissionComment struct {
+	ID        int       `json:"id"`
+	FileID    *int      `json:"file_id"`
+	AuthorID  *int      `json:"author_id"`
+	Author    string    `json:"author"`
+	Page      *int      `json:"page"`
+	Anchor    string    `json:"anchor"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SubmissionVersion adalah salinan jawaban pada satu kali pengumpulan.
+type SubmissionVersion struct {
+	Attempt     int          `json:"attempt"`
+	Status      string       `json:"status"`
+	TextAnswer  string       `json:"text_answer"`
+	Files       []Attachment `json:"files"`
+	SubmittedAt time.Time    `json:"submitted_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PersonalAccessToken struct {
+	ID          int            `json:"id"`
+	UserID      int            `json:"user_id"`
+	Name        string         `json:"name"`
+	TokenPrefix string         `json:"token_prefix"`
+	Scopes      []string       `json:"scopes"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	LastUsedAt  sql.NullTime   `json:"last_used_at"`
+	LastUsedIP  sql.NullString `json:"last_used_ip"`
+	RevokedAt   sql.NullTime   `json:"revoked_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// ClassUnit adalah bab/topik di dalam kelas.
+type ClassUnit struct {
+	ID          int       `json:"id"`
+	ClassID     int       `json:"class_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+}
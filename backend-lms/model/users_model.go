@@ -3,9 +3,11 @@ package model
 import "time"
 
 type User struct {
-    ID        int       `json:"id"`
-    Username  string    `json:"username"`
-    Password  string    `json:"-"`
-    CreatedAt time.Time `json:"created_at"`
-    Role      string    `json:"role"`
+    ID         int       `json:"id"`
+    Username   string    `json:"username"`
+    FullName   string    `json:"full_name"`
+    Identifier string    `json:"identifier"`
+    Password   string    `json:"-"`
+    CreatedAt  time.Time `json:"created_at"`
+    Role       string    `json:"role"`
 }
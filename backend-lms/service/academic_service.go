@@ -0,0 +1,257 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAcademicYearNotFound = errors.New("academic year not found")
+	ErrTermNotFound         = errors.New("term not found")
+	ErrDuplicateTerm        = errors.New("academic year or term with this name already exists")
+)
+
+type AcademicService struct {
+	DB *sql.DB
+}
+
+func NewAcademicService(db *sql.DB) *AcademicService {
+	return &AcademicService{DB: db}
+}
+
+// GetAcademicYears mengembalikan semua tahun ajaran (terbaru dulu) beserta semesternya.
+func (s *AcademicService) GetAcademicYears() ([]model.AcademicYear, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), created_at
+        FROM academic_years ORDER BY start_date DESC
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query academic years: %w", err)
+	}
+	defer rows.Close()
+
+	years := []model.AcademicYear{}
+	index := map[int]int{}
+	for rows.Next() {
+		var year model.AcademicYear
+		if err := rows.Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan academic year: %w", err)
+		}
+		year.Terms = []model.Term{}
+		index[year.ID] = len(years)
+		years = append(years, year)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating academic years: %w", err)
+	}
+
+	termRows, err := s.DB.Query(termSelect + ` ORDER BY start_date`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query terms: %w", err)
+	}
+	defer termRows.Close()
+
+	for termRows.Next() {
+		term, err := scanTerm(termRows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		if i, ok := index[term.AcademicYearID]; ok {
+			years[i].Terms = append(years[i].Terms, *term)
+		}
+	}
+	if err := termRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating terms: %w", err)
+	}
+
+	return years, nil
+}
+
+func (s *AcademicService) CreateAcademicYear(req dto.AcademicYearRequest) (*model.AcademicYear, error) {
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	year := model.AcademicYear{Terms: []model.Term{}}
+	err = s.DB.QueryRow(`
+        INSERT INTO academic_years (name, start_date, end_date) VALUES ($1, $2, $3)
+        ON CONFLICT (name) DO NOTHING
+        RETURNING id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), created_at
+    `, strings.TrimSpace(req.Name), start, end).Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateTerm
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create academic year: %w", err)
+	}
+	return &year, nil
+}
+
+// CreateTerm menambahkan semester ke tahun ajaran. Rentang tanggal harus berada di dalam
+// tahun ajaran dan tidak tumpang tindih dengan semester lain di tahun yang sama.
+func (s *AcademicService) CreateTerm(academicYearID int, req dto.TermRequest) (*model.Term, error) {
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var yearStart, yearEnd time.Time
+	err = s.DB.QueryRow(`SELECT start_date, end_date FROM academic_years WHERE id = $1`, academicYearID).Scan(&yearStart, &yearEnd)
+	if err == sql.ErrNoRows {
+		return nil, ErrAcademicYearNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get academic year: %w", err)
+	}
+
+	violations := &ValidationError{}
+	if start.Before(yearStart) || end.After(yearEnd) {
+		violations.Add("start_date", "out_of_range", "Term must be within the academic year")
+	}
+	var overlaps bool
+	err = s.DB.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM terms WHERE academic_year_id = $1 AND start_date <= $3 AND end_date >= $2)
+    `, academicYearID, start, end).Scan(&overlaps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check term overlap: %w", err)
+	}
+	if overlaps {
+		violations.Add("start_date", "overlap", "Term overlaps with another term in this academic year")
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	term, err := scanTerm(s.DB.QueryRow(`
+        INSERT INTO terms (academic_year_id, name, start_date, end_date) VALUES ($1, $2, $3, $4)
+        ON CONFLICT (academic_year_id, name) DO NOTHING
+        RETURNING id, academic_year_id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), is_active, created_at
+    `, academicYearID, strings.TrimSpace(req.Name), start, end))
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateTerm
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create term: %w", err)
+	}
+	return term, nil
+}
+
+// GetActiveTerm mengembalikan semester yang sedang aktif, nil jika belum ada.
+func (s *AcademicService) GetActiveTerm() (*model.Term, error) {
+	term, err := scanTerm(s.DB.QueryRow(termSelect + ` WHERE is_active`))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active term: %w", err)
+	}
+	return term, nil
+}
+
+// RolloverTerm mengaktifkan term baru dan mengarsipkan semua kelas di term sebelumnya
+// dalam satu transaksi.
+func (s *AcademicService) RolloverTerm(req dto.TermRolloverRequest) (*dto.TermRolloverResponse, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkTermExists(tx, req.ToTermID); err != nil {
+		return nil, err
+	}
+
+	fromTermID := req.FromTermID
+	if fromTermID == nil {
+		var activeID int
+		err := tx.QueryRow(`SELECT id FROM terms WHERE is_active FOR UPDATE`).Scan(&activeID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get active term: %w", err)
+		}
+		if err == nil {
+			fromTermID = &activeID
+		}
+	} else if err := checkTermExists(tx, *fromTermID); err != nil {
+		return nil, err
+	}
+
+	if fromTermID != nil && *fromTermID == req.ToTermID {
+		violations := &ValidationError{}
+		violations.Add("to_term_id", "same_term", "New term must be different from the previous term")
+		return nil, violations
+	}
+
+	response := &dto.TermRolloverResponse{FromTermID: fromTermID, ToTermID: req.ToTermID}
+	if fromTermID != nil {
+		result, err := tx.Exec(`UPDATE classes SET archived_at = NOW() WHERE term_id = $1 AND archived_at IS NULL`, *fromTermID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive classes: %w", err)
+		}
+		archived, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("error checking rows affected: %w", err)
+		}
+		response.ArchivedClasses = int(archived)
+	}
+
+	// Nonaktifkan dulu supaya unique index idx_terms_active tidak bentrok
+	if _, err := tx.Exec(`UPDATE terms SET is_active = FALSE WHERE is_active`); err != nil {
+		return nil, fmt.Errorf("failed to deactivate term: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE terms SET is_active = TRUE WHERE id = $1`, req.ToTermID); err != nil {
+		return nil, fmt.Errorf("failed to activate term: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to roll over term: %w", err)
+	}
+	response.Message = "Term rolled over successfully"
+	return response, nil
+}
+
+func checkTermExists(q dbExecutor, termID int) error {
+	var exists bool
+	if err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM terms WHERE id = $1)`, termID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check term: %w", err)
+	}
+	if !exists {
+		return ErrTermNotFound
+	}
+	return nil
+}
+
+func parseDateRange(startValue, endValue string) (time.Time, time.Time, error) {
+	violations := &ValidationError{}
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(startValue))
+	if err != nil {
+		violations.Add("start_date", "invalid_format", "Start date must use YYYY-MM-DD format")
+	}
+	end, err := time.Parse("2006-01-02", strings.TrimSpace(endValue))
+	if err != nil {
+		violations.Add("end_date", "invalid_format", "End date must use YYYY-MM-DD format")
+	}
+	if len(violations.Errors) == 0 && !end.After(start) {
+		violations.Add("end_date", "before_start", "End date must be after start date")
+	}
+	return start, end, violations.OrNil()
+}
+
+const termSelect = `
+    SELECT id, academic_year_id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), is_active, created_at
+    FROM terms
+`
+
+func scanTerm(row rowScanner) (*model.Term, error) {
+	var term model.Term
+	err := row.Scan(&term.ID, &term.AcademicYearID, &term.Name, &term.StartDate, &term.EndDate, &term.IsActive, &term.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
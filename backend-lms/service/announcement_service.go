@@ -0,0 +1,474 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"project/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Jenis item di stream kelas.
+const (
+	StreamAnnouncement = "announcement"
+	StreamMaterial     = "material"
+	StreamAssignment   = "assignment"
+)
+
+const (
+	defaultStreamLimit   = 20
+	maxStreamLimit       = 100
+	maxAnnouncementTitle = 200
+)
+
+var (
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrInvalidStreamCursor  = errors.New("invalid stream cursor")
+)
+
+// StreamCursor menandai posisi item terakhir di halaman stream. Urutan stream adalah
+// (published_at, type, id) menurun, jadi item dengan waktu terbit yang sama tidak terlewat.
+type StreamCursor struct {
+	PublishedAt time.Time
+	Type        string
+	ID          int
+}
+
+// Encode mengubah cursor menjadi string opaque untuk query ?cursor=.
+func (c StreamCursor) Encode() string {
+	raw := c.PublishedAt.UTC().Format(time.RFC3339Nano) + "|" + c.Type + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseStreamCursor membaca cursor dari StreamCursor.Encode.
+func ParseStreamCursor(value string) (*StreamCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidStreamCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidStreamCursor
+	}
+	publishedAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidStreamCursor
+	}
+	id, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, ErrInvalidStreamCursor
+	}
+	switch parts[1] {
+	case StreamAnnouncement, StreamMaterial, StreamAssignment:
+	default:
+		return nil, ErrInvalidStreamCursor
+	}
+	return &StreamCursor{PublishedAt: publishedAt, Type: parts[1], ID: id}, nil
+}
+
+type AnnouncementService struct {
+	DB *sql.DB
+}
+
+func NewAnnouncementService(db *sql.DB) *AnnouncementService {
+	return &AnnouncementService{DB: db}
+}
+
+// GetAnnouncements mengembalikan pengumuman kelas, yang disematkan lebih dulu. Pengumuman
+// terjadwal hanya ikut jika includeScheduled (pengelola konten kelas).
+func (s *AnnouncementService) GetAnnouncements(classID int, includeScheduled bool) ([]model.Announcement, error) {
+	rows, err := s.DB.Query(announcementSelect+`
+        WHERE a.class_id = $1 AND ($2 OR a.publish_at <= NOW())
+        ORDER BY a.pinned_at DESC NULLS LAST, a.publish_at DESC, a.id DESC
+    `, classID, includeScheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query announcements: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := []model.Announcement{}
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan announcement: %w", err)
+		}
+		announcements = append(announcements, *announcement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating announcements: %w", err)
+	}
+
+	ids := make([]int, len(announcements))
+	for i, announcement := range announcements {
+		ids[i] = announcement.ID
+	}
+	attachments, err := loadAnnouncementAttachments(s.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range announcements {
+		announcements[i].Attachments = attachmentsOrEmpty(attachments[announcements[i].ID])
+	}
+	return announcements, nil
+}
+
+// CreateAnnouncement membuat pengumuman. publish_at di masa lalu atau kosong berarti langsung terbit.
+func (s *AnnouncementService) CreateAnnouncement(classID, authorID int, req dto.AnnouncementRequest) (*model.Announcement, error) {
+	title, content, err := validateAnnouncement(req)
+	if err != nil {
+		return nil, err
+	}
+	publishAt := time.Now()
+	if req.PublishAt != nil && req.PublishAt.After(publishAt) {
+		publishAt = *req.PublishAt
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+        INSERT INTO announcements (class_id, author_id, title, content, publish_at, pinned_at)
+        VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN NOW() END)
+        RETURNING id
+    `, classID, authorID, title, content, publishAt, req.Pinned).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	if err := insertAnnouncementAttachments(tx, id, req.Attachments); err != nil {
+		return nil, err
+	}
+
+	announcement, err := getAnnouncement(tx, classID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return announcement, nil
+}
+
+// UpdateAnnouncement mengubah judul, isi dan jadwal terbit. Jadwal hanya bisa diubah
+// selama pengumuman belum terbit.
+func (s *AnnouncementService) UpdateAnnouncement(classID, announcementID int, req dto.AnnouncementRequest) (*model.Announcement, error) {
+	title, content, err := validateAnnouncement(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := getAnnouncement(tx, classID, announcementID)
+	if err != nil {
+		return nil, err
+	}
+	publishAt := current.PublishAt
+	if req.PublishAt != nil && !req.PublishAt.Equal(current.PublishAt) {
+		if current.Published {
+			violations := &ValidationError{}
+			violations.Add("publish_at", "already_published", "Announcement is already published")
+			return nil, violations
+		}
+		publishAt = time.Now()
+		if req.PublishAt.After(publishAt) {
+			publishAt = *req.PublishAt
+		}
+	}
+
+	_, err = tx.Exec(`
+        UPDATE announcements SET title = $1, content = $2, publish_at = $3, updated_at = NOW()
+        WHERE id = $4 AND class_id = $5
+    `, title, content, publishAt, announcementID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update announcement: %w", err)
+	}
+
+	announcement, err := getAnnouncement(tx, classID, announcementID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update announcement: %w", err)
+	}
+	return announcement, nil
+}
+
+func (s *AnnouncementService) DeleteAnnouncement(classID, announcementID int) error {
+	result, err := s.DB.Exec(`DELETE FROM announcements WHERE id = $1 AND class_id = $2`, announcementID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
+
+// SetPinned menyematkan atau melepas pengumuman dari atas stream.
+func (s *AnnouncementService) SetPinned(classID, announcementID int, pinned bool) (*model.Announcement, error) {
+	result, err := s.DB.Exec(`
+        UPDATE announcements SET pinned_at = CASE WHEN $1 THEN COALESCE(pinned_at, NOW()) END
+        WHERE id = $2 AND class_id = $3
+    `, pinned, announcementID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin announcement: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrAnnouncementNotFound
+	}
+	return getAnnouncement(s.DB, classID, announcementID)
+}
+
+func (s *AnnouncementService) AddAttachments(classID, announcementID int, attachments []model.Attachment) (*model.Announcement, error) {
+	if _, err := getAnnouncement(s.DB, classID, announcementID); err != nil {
+		return nil, err
+	}
+	if err := insertAnnouncementAttachments(s.DB, announcementID, attachments); err != nil {
+		return nil, err
+	}
+	return getAnnouncement(s.DB, classID, announcementID)
+}
+
+func (s *AnnouncementService) DeleteAttachment(classID, announcementID, attachmentID int) error {
+	result, err := s.DB.Exec(`
+        DELETE FROM announcement_attachments aa USING announcements a
+        WHERE aa.id = $1 AND aa.announcement_id = $2 AND a.id = aa.announcement_id AND a.class_id = $3
+    `, attachmentID, announcementID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// GetStream menggabungkan pengumuman yang sudah terbit dengan materi dan tugas kelas,
+// terbaru dulu. cursor (dari NextCursor halaman sebelumnya) dipakai untuk halaman berikutnya.
+func (s *AnnouncementService) GetStream(classID int, cursor *StreamCursor, limit int) (*dto.ClassStream, error) {
+	if limit <= 0 {
+		limit = defaultStreamLimit
+	}
+	if limit > maxStreamLimit {
+		limit = maxStreamLimit
+	}
+
+	stream := &dto.ClassStream{ClassID: classID, Pinned: []dto.StreamItem{}}
+	pinned, err := queryStream(s.DB, `
+        SELECT 'announcement', a.id, a.title, a.content, a.publish_at, TRUE, COALESCE(u.username, ''), NULL::INT, NULL::TIMESTAMPTZ, NULL::TEXT
+        FROM announcements a LEFT JOIN users u ON u.id = a.author_id
+        WHERE a.class_id = $1 AND a.pinned_at IS NOT NULL AND a.publish_at <= NOW()
+        ORDER BY a.pinned_at DESC
+    `, classID)
+	if err != nil {
+		return nil, err
+	}
+	stream.Pinned = pinned
+
+	var cursorTime *time.Time
+	var cursorType string
+	var cursorID int
+	if cursor != nil {
+		cursorTime, cursorType, cursorID = &cursor.PublishedAt, cursor.Type, cursor.ID
+	}
+
+	// Ambil satu item lebih untuk mengetahui apakah masih ada halaman berikutnya
+	items, err := queryStream(s.DB, `
+        SELECT * FROM (
+            SELECT 'announcement' AS type, a.id, a.title, a.content, a.publish_at AS published_at,
+                   a.pinned_at IS NOT NULL, COALESCE(u.username, ''), NULL::INT, NULL::TIMESTAMPTZ, NULL::TEXT
+            FROM announcements a LEFT JOIN users u ON u.id = a.author_id
+            WHERE a.class_id = $1 AND a.publish_at <= NOW()
+            UNION ALL
+            SELECT 'material', m.id, m.title, m.content, m.created_at, FALSE, '', m.unit_id, NULL::TIMESTAMPTZ, m.attachment
+            FROM materials m WHERE m.class_id = $1
+            UNION ALL
+            SELECT 'assignment', t.id, t.title, t.description, t.created_at, FALSE, COALESCE(u.username, ''),
+                   t.unit_id, t.due_date, t.attachment
+            FROM assignments t LEFT JOIN users u ON u.id = t.created_by
+            WHERE t.class_id = $1
+        ) stream
+        WHERE $2::TIMESTAMPTZ IS NULL OR (published_at, type, id) < ($2::TIMESTAMPTZ, $3::TEXT, $4::INT)
+        ORDER BY published_at DESC, type DESC, id DESC
+        LIMIT $5
+    `, classID, cursorTime, cursorType, cursorID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[limit-1]
+		next := StreamCursor{PublishedAt: last.PublishedAt, Type: last.Type, ID: last.ID}.Encode()
+		stream.NextCursor = &next
+	}
+	stream.Items = items
+	return stream, nil
+}
+
+// queryStream memindai baris stream dan melengkapi attachment pengumuman.
+func queryStream(q dbExecutor, query string, args ...interface{}) ([]dto.StreamItem, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class stream: %w", err)
+	}
+	defer rows.Close()
+
+	items := []dto.StreamItem{}
+	var announcementIDs []int
+	for rows.Next() {
+		var item dto.StreamItem
+		var attachment sql.NullString
+		if err := rows.Scan(&item.Type, &item.ID, &item.Title, &item.Content, &item.PublishedAt, &item.Pinned,
+			&item.Author, &item.UnitID, &item.DueDate, &attachment); err != nil {
+			return nil, fmt.Errorf("failed to scan class stream: %w", err)
+		}
+		item.Attachments = []model.Attachment{}
+		if attachment.Valid && attachment.String != "" {
+			item.Attachments = append(item.Attachments, model.Attachment{URL: attachment.String})
+		}
+		if item.Type == StreamAnnouncement {
+			announcementIDs = append(announcementIDs, item.ID)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating class stream: %w", err)
+	}
+
+	attachments, err := loadAnnouncementAttachments(q, announcementIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Type == StreamAnnouncement {
+			items[i].Attachments = attachmentsOrEmpty(attachments[items[i].ID])
+		}
+	}
+	return items, nil
+}
+
+func validateAnnouncement(req dto.AnnouncementRequest) (string, string, error) {
+	violations := &ValidationError{}
+	title := strings.TrimSpace(req.Title)
+	if len(title) > maxAnnouncementTitle {
+		violations.Add("title", "too_long", fmt.Sprintf("Title must not exceed %d characters", maxAnnouncementTitle))
+	}
+	content := utils.SanitizeHTML(req.Content)
+	if content == "" {
+		violations.Add("content", "required", "Content is required")
+	}
+	return title, content, violations.OrNil()
+}
+
+func insertAnnouncementAttachments(q dbExecutor, announcementID int, attachments []model.Attachment) error {
+	for _, attachment := range attachments {
+		_, err := q.Exec(`
+            INSERT INTO announcement_attachments (announcement_id, url, file_name) VALUES ($1, $2, $3)
+        `, announcementID, attachment.URL, attachment.FileName)
+		if err != nil {
+			return fmt.Errorf("failed to save attachment: %w", err)
+		}
+	}
+	return nil
+}
+
+func loadAnnouncementAttachments(q dbExecutor, announcementIDs []int) (map[int][]model.Attachment, error) {
+	attachments := map[int][]model.Attachment{}
+	if len(announcementIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := q.Query(`
+        SELECT announcement_id, id, url, file_name FROM announcement_attachments
+        WHERE announcement_id = ANY($1) ORDER BY id
+    `, pq.Array(announcementIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var announcementID int
+		var attachment model.Attachment
+		if err := rows.Scan(&announcementID, &attachment.ID, &attachment.URL, &attachment.FileName); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments[announcementID] = append(attachments[announcementID], attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+func attachmentsOrEmpty(attachments []model.Attachment) []model.Attachment {
+	if attachments == nil {
+		return []model.Attachment{}
+	}
+	return attachments
+}
+
+func getAnnouncement(q dbExecutor, classID, announcementID int) (*model.Announcement, error) {
+	announcement, err := scanAnnouncement(q.QueryRow(announcementSelect+` WHERE a.id = $1 AND a.class_id = $2`, announcementID, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrAnnouncementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+
+	attachments, err := loadAnnouncementAttachments(q, []int{announcementID})
+	if err != nil {
+		return nil, err
+	}
+	announcement.Attachments = attachmentsOrEmpty(attachments[announcementID])
+	return announcement, nil
+}
+
+const announcementSelect = `
+    SELECT a.id, a.class_id, a.author_id, COALESCE(u.username, ''), a.title, a.content, a.publish_at,
+           a.publish_at <= NOW(), a.pinned_at, a.created_at, a.updated_at
+    FROM announcements a
+    LEFT JOIN users u ON u.id = a.author_id
+`
+
+func scanAnnouncement(row rowScanner) (*model.Announcement, error) {
+	var announcement model.Announcement
+	err := row.Scan(&announcement.ID, &announcement.ClassID, &announcement.AuthorID, &announcement.Author, &announcement.Title,
+		&announcement.Content, &announcement.PublishAt, &announcement.Published, &announcement.PinnedAt,
+		&announcement.CreatedAt, &announcement.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
@@ -5,25 +5,51 @@ import (
 	"fmt"
 	"project/dto"
 	"project/model"
+	"time"
 )
 
 type AssignmentService struct {
 	DB *sql.DB
 }
 
+// CreateAssignment - Tugas baru ditempatkan di akhir unit (atau di akhir daftar tanpa unit)
 func (s *AssignmentService) CreateAssignment(req dto.CreateAssignmentRequest, createdBy int) (*model.Assignment, error) {
-    query := `INSERT INTO assignments (class_id, title, description, due_date, attachment, created_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, class_id, title, description, due_date, attachment, created_at, created_by`
+    if req.LatePolicy == "" {
+        req.LatePolicy = LatePolicyAccept
+    }
+    if req.MaxPoints == 0 {
+        req.MaxPoints = defaultMaxPoints
+    }
+    deadline, err := buildDeadline(req.DueDate, req.LatePolicy, req.LatePenaltyPercent, true)
+    if err == nil {
+        err = validateMaxPoints(req.MaxPoints)
+    }
+    if err != nil {
+        return nil, err
+    }
+    if req.UnitID != nil {
+        if err := checkUnitInClass(s.DB, req.ClassID, *req.UnitID); err != nil {
+            return nil, err
+        }
+    }
+    position, err := nextItemPosition(s.DB, req.ClassID, req.UnitID)
+    if err != nil {
+        return nil, err
+    }
+
+    query := `INSERT INTO assignments (class_id, title, description, due_date, attachment, created_by, unit_id, position, late_policy, late_penalty_percent, max_points) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, class_id, title, description, due_date, attachment, created_at, created_by, unit_id, position, late_policy, late_penalty_percent, max_points`
     var assignment model.Assignment
-    err := s.DB.QueryRow(query, req.ClassID, req.Title, req.Description, req.DueDate, req.Attachment, createdBy).Scan(&assignment.ID, &assignment.ClassID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.Attachment, &assignment.CreatedAt, &assignment.CreatedBy)
+    err = s.DB.QueryRow(query, req.ClassID, req.Title, req.Description, deadline.DueDate, req.Attachment, createdBy, req.UnitID, position, deadline.LatePolicy, deadline.LatePenaltyPercent, req.MaxPoints).Scan(&assignment.ID, &assignment.ClassID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.Attachment, &assignment.CreatedAt, &assignment.CreatedBy, &assignment.UnitID, &assignment.Position, &assignment.LatePolicy, &assignment.LatePenaltyPercent, &assignment.MaxPoints)
     if err != nil {
         return nil, err
     }
+    fillRemaining(&assignment.Deadline, time.Now())
     return &assignment, nil
 }
 
-func (s *AssignmentService) DeleteAssignment(id string) error {
-	query := `DELETE FROM assignments WHERE id = $1`
-	result, err := s.DB.Exec(query, id)
+func (s *AssignmentService) DeleteAssignment(classID, id string) error {
+	query := `DELETE FROM assignments WHERE id = $1 AND class_id = $2`
+	result, err := s.DB.Exec(query, id, classID)
 	if err != nil {
 		return fmt.Errorf("failed to delete assignment: %w", err)
 	}
@@ -42,7 +68,10 @@ func (s *AssignmentService) DeleteAssignment(id string) error {
 
 // GetAssignmentsByClass - Mengambil tugas berdasarkan class_id
 func (s *AssignmentService) GetAssignmentsByClass(classID string) ([]model.Assignment, error) {
-    query := `SELECT id, title, description, due_date, class_id, created_at, attachment FROM assignments WHERE class_id = $1`
+    query := `SELECT a.id, a.title, a.description, a.due_date, a.class_id, a.created_at, a.attachment, a.unit_id, a.position, a.late_policy, a.late_penalty_percent, a.max_points FROM assignments a
+              LEFT JOIN class_units u ON u.id = a.unit_id
+              WHERE a.class_id = $1
+              ORDER BY u.position NULLS LAST, a.unit_id, a.position, a.id`
     rows, err := s.DB.Query(query, classID)
     if err != nil {
         return nil, fmt.Errorf("failed to fetch assignments: %w", err)
@@ -50,11 +79,13 @@ func (s *AssignmentService) GetAssignmentsByClass(classID string) ([]model.Assig
     defer rows.Close()
 
     var assignments []model.Assignment
+    now := time.Now()
     for rows.Next() {
         var assignment model.Assignment
-        if err := rows.Scan(&assignment.ID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.ClassID, &assignment.CreatedAt, &assignment.Attachment); err != nil {
+        if err := rows.Scan(&assignment.ID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.ClassID, &assignment.CreatedAt, &assignment.Attachment, &assignment.UnitID, &assignment.Position, &assignment.LatePolicy, &assignment.LatePenaltyPercent, &assignment.MaxPoints); err != nil {
             return nil, fmt.Errorf("failed to scan assignment: %w", err)
         }
+        fillRemaining(&assignment.Deadline, now)
         assignments = append(assignments, assignment)
     }
 
@@ -66,7 +97,10 @@ func (s *AssignmentService) GetAssignmentsByClass(classID string) ([]model.Assig
 }
 
 func (s *AssignmentService) GetAssignments(classID string) ([]model.Assignment, error) {
-    query := `SELECT id, title, description, due_date, class_id, created_at, attachment, created_by FROM assignments WHERE class_id = $1`
+    query := `SELECT a.id, a.title, a.description, a.due_date, a.class_id, a.created_at, a.attachment, a.created_by, a.unit_id, a.position, a.late_policy, a.late_penalty_percent, a.max_points FROM assignments a
+              LEFT JOIN class_units u ON u.id = a.unit_id
+              WHERE a.class_id = $1
+              ORDER BY u.position NULLS LAST, a.unit_id, a.position, a.id`
     rows, err := s.DB.Query(query, classID)
     if err != nil {
         return nil, fmt.Errorf("failed to query assignments: %v", err)
@@ -74,11 +108,13 @@ func (s *AssignmentService) GetAssignments(classID string) ([]model.Assignment,
     defer rows.Close()
 
     var assignments []model.Assignment
+    now := time.Now()
     for rows.Next() {
         var assignment model.Assignment
-        if err := rows.Scan(&assignment.ID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.ClassID, &assignment.CreatedAt, &assignment.Attachment, &assignment.CreatedBy); err != nil {
+        if err := rows.Scan(&assignment.ID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.ClassID, &assignment.CreatedAt, &assignment.Attachment, &assignment.CreatedBy, &assignment.UnitID, &assignment.Position, &assignment.LatePolicy, &assignment.LatePenaltyPercent, &assignment.MaxPoints); err != nil {
             return nil, fmt.Errorf("failed to scan assignment row: %v", err)
         }
+        fillRemaining(&assignment.Deadline, now)
         assignments = append(assignments, assignment)
     }
 
@@ -89,16 +125,66 @@ func (s *AssignmentService) GetAssignments(classID string) ([]model.Assignment,
     return assignments, nil
 }
 
+// UpdateAssignment - late_policy kosong berarti kebijakan keterlambatan tetap seperti sebelumnya
+// (late_penalty_percent tetap boleh diubah jika kebijakannya penalty). max_points tidak boleh
+// lebih kecil dari nilai yang sudah diberikan.
 func (s *AssignmentService) UpdateAssignment(classID, assignmentID int, req dto.UpdateAssignmentRequest) (*model.Assignment, error) {
+    tx, err := s.DB.Begin()
+    if err != nil {
+        return nil, fmt.Errorf("failed to start transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var storedPolicy string
+    var storedPenalty float64
+    err = tx.QueryRow(`
+        SELECT late_policy, late_penalty_percent FROM assignments WHERE id = $1 AND class_id = $2 FOR UPDATE
+    `, assignmentID, classID).Scan(&storedPolicy, &storedPenalty)
+    if err != nil {
+        return nil, err
+    }
+
+    policy := req.LatePolicy
+    if policy == "" {
+        policy = storedPolicy
+        if req.LatePenaltyPercent == 0 {
+            req.LatePenaltyPercent = storedPenalty
+        }
+    }
+    deadline, err := buildDeadline(req.DueDate, policy, req.LatePenaltyPercent, false)
+    if err == nil && req.LatePolicy == "" && policy != LatePolicyPenalty && req.LatePenaltyPercent != 0 {
+        violations := &ValidationError{}
+        violations.Add("late_penalty_percent", "policy_mismatch", "Late penalty can only be set when the late policy is penalty")
+        err = violations
+    }
+    if err == nil && req.MaxPoints != 0 {
+        err = validateMaxPoints(req.MaxPoints)
+    }
+    if err == nil && req.MaxPoints != 0 {
+        var awarded sql.NullFloat64
+        if err := tx.QueryRow(`SELECT MAX(points) FROM submissions WHERE assignment_id = $1`, assignmentID).Scan(&awarded); err != nil {
+            return nil, fmt.Errorf("failed to check awarded points: %w", err)
+        }
+        if awarded.Valid && req.MaxPoints < awarded.Float64 {
+            violations := &ValidationError{}
+            violations.Add("max_points", "below_awarded", fmt.Sprintf("Max points cannot be lower than points already awarded (%g)", awarded.Float64))
+            err = violations
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+
     query := `
         UPDATE assignments
-        SET title = $1, description = $2, due_date = $3, attachment = $4, created_at = NOW()
+        SET title = $1, description = $2, due_date = $3, attachment = $4, created_at = NOW(), late_policy = $7, late_penalty_percent = $8,
+            max_points = COALESCE(NULLIF($9, 0), max_points)
         WHERE id = $5 AND class_id = $6
-        RETURNING id, title, description, due_date, class_id, attachment, created_at
+        RETURNING id, title, description, due_date, class_id, attachment, created_at, unit_id, position, late_policy, late_penalty_percent, max_points
     `
 
     var assignment model.Assignment
-    err := s.DB.QueryRow(query, req.Title, req.Description, req.DueDate, req.Attachment, assignmentID, classID).Scan(
+    err = tx.QueryRow(query, req.Title, req.Description, deadline.DueDate, req.Attachment, assignmentID, classID, deadline.LatePolicy, deadline.LatePenaltyPercent, req.MaxPoints).Scan(
         &assignment.ID,
         &assignment.Title,
         &assignment.Description,
@@ -106,18 +192,34 @@ func (s *AssignmentService) UpdateAssignment(classID, assignmentID int, req dto.
         &assignment.ClassID,
         &assignment.Attachment,
         &assignment.CreatedAt,
+        &assignment.UnitID,
+        &assignment.Position,
+        &assignment.LatePolicy,
+        &assignment.LatePenaltyPercent,
+        &assignment.MaxPoints,
     )
     if err != nil {
         return nil, err
     }
+    // Nilai maksimal bisa berubah, hitung ulang nilai di buku nilai
+    if err := syncAssignmentGrades(tx, assignment.ID); err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("failed to update assignment: %w", err)
+    }
+    fillRemaining(&assignment.Deadline, time.Now())
     return &assignment, nil
 }
 
+// GetAssignmentsByUserID - Tugas kelas yang sudah dikumpulkan siswa (lihat tabel submissions)
 func (s *AssignmentService) GetAssignmentsByUserID(userID, classID int) ([]model.Assignment, error) {
     query := `
-        SELECT id, class_id, title, description, due_date, created_at, attachment, created_by
-        FROM assignments
-        WHERE created_by = $1 AND class_id = $2
+        SELECT a.id, a.class_id, a.title, a.description, a.due_date, a.created_at, a.attachment, a.created_by, a.late_policy, a.late_penalty_percent, a.max_points
+        FROM assignments a
+        JOIN submissions s ON s.assignment_id = a.id
+        WHERE s.user_id = $1 AND a.class_id = $2 AND s.status <> 'draft'
+        ORDER BY s.submitted_at DESC
     `
     rows, err := s.DB.Query(query, userID, classID)
     if err != nil {
@@ -126,11 +228,13 @@ func (s *AssignmentService) GetAssignmentsByUserID(userID, classID int) ([]model
     defer rows.Close()
 
     var assignments []model.Assignment
+    now := time.Now()
     for rows.Next() {
         var assignment model.Assignment
-        if err := rows.Scan(&assignment.ID, &assignment.ClassID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.CreatedAt, &assignment.Attachment, &assignment.CreatedBy); err != nil {
+        if err := rows.Scan(&assignment.ID, &assignment.ClassID, &assignment.Title, &assignment.Description, &assignment.DueDate, &assignment.CreatedAt, &assignment.Attachment, &assignment.CreatedBy, &assignment.LatePolicy, &assignment.LatePenaltyPercent, &assignment.MaxPoints); err != nil {
             return nil, fmt.Errorf("failed to scan assignment: %w", err)
         }
+        fillRemaining(&assignment.Deadline, now)
         assignments = append(assignments, assignment)
     }
 
@@ -0,0 +1,739 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"math"
+	"project/config"
+	"project/dto"
+	"project/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status kehadiran siswa.
+const (
+	AttendanceHadir = "hadir"
+	AttendanceIzin  = "izin"
+	AttendanceSakit = "sakit"
+	AttendanceAlpa  = "alpa"
+)
+
+// Asal pertemuan dan cara absensi dicatat.
+const (
+	SessionSourceSchedule = "schedule"
+	SessionSourceAdHoc    = "ad_hoc"
+
+	AttendanceMethodManual = "manual"
+	AttendanceMethodQR     = "qr"
+)
+
+const (
+	maxSessionGenerateDays = 200
+	maxCheckinMinutes      = 240
+	checkinTokenLength     = 8
+)
+
+var (
+	ErrClassSessionNotFound = errors.New("class session not found")
+	ErrSessionExists        = errors.New("the class already has a session starting at this time")
+	ErrCheckinClosed        = errors.New("check-in for this session is not open")
+	ErrInvalidCheckinCode   = errors.New("invalid or expired check-in code")
+	ErrAttendanceExcused    = errors.New("attendance for this session is already recorded as izin or sakit")
+)
+
+type AttendanceService struct {
+	DB *sql.DB
+}
+
+func NewAttendanceService(db *sql.DB) *AttendanceService {
+	return &AttendanceService{DB: db}
+}
+
+// GetSessions mengembalikan pertemuan kelas, opsional dibatasi tanggal from/to.
+func (s *AttendanceService) GetSessions(classID int, from, to *time.Time) ([]model.ClassSession, error) {
+	rows, err := s.DB.Query(sessionSelect+`
+        WHERE class_id = $1 AND ($2::DATE IS NULL OR date >= $2) AND ($3::DATE IS NULL OR date <= $3)
+        ORDER BY start_at
+    `, classID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []model.ClassSession{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// CreateSession membuat pertemuan tambahan di luar jadwal mingguan.
+func (s *AttendanceService) CreateSession(classID, createdBy int, req dto.CreateSessionRequest) (*model.ClassSession, error) {
+	violations := &ValidationError{}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(req.Date))
+	if err != nil {
+		violations.Add("date", "invalid_format", "Date must use YYYY-MM-DD format")
+	}
+	slot := model.ClassSchedule{
+		StartTime: strings.TrimSpace(req.StartTime),
+		EndTime:   strings.TrimSpace(req.EndTime),
+		Timezone:  strings.TrimSpace(req.Timezone),
+	}
+	if slot.Timezone == "" {
+		slot.Timezone = config.DefaultTimezone
+	}
+	start, startErr := time.Parse("15:04", slot.StartTime)
+	end, endErr := time.Parse("15:04", slot.EndTime)
+	if startErr != nil {
+		violations.Add("start_time", "invalid_format", "Start time must use HH:MM format")
+	}
+	if endErr != nil {
+		violations.Add("end_time", "invalid_format", "End time must use HH:MM format")
+	}
+	if startErr == nil && endErr == nil && !end.After(start) {
+		violations.Add("end_time", "before_start", "End time must be after start time")
+	}
+	if _, err := time.LoadLocation(slot.Timezone); err != nil {
+		violations.Add("timezone", "unknown_timezone", fmt.Sprintf("Unknown timezone %q", slot.Timezone))
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	startAt, endAt := sessionTimes(slot, date)
+	session, err := scanSession(s.DB.QueryRow(`
+        INSERT INTO class_sessions (class_id, date, start_at, end_at, topic, source, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT ON CONSTRAINT class_sessions_class_start_key DO NOTHING
+        RETURNING `+sessionColumns,
+		classID, date.Format("2006-01-02"), startAt, endAt, strings.TrimSpace(req.Topic), SessionSourceAdHoc, createdBy))
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	if err := applyApprovedLeaves(s.DB, session.ID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GenerateSessions membuat pertemuan dari jadwal mingguan kelas untuk rentang tanggal.
+// Hari libur dilewati dan pertemuan yang sudah ada tidak dibuat ulang.
+func (s *AttendanceService) GenerateSessions(classID, createdBy int, req dto.GenerateSessionsRequest) (*dto.GenerateSessionsResponse, error) {
+	violations := &ValidationError{}
+	from, fromErr := time.Parse("2006-01-02", strings.TrimSpace(req.From))
+	to, toErr := time.Parse("2006-01-02", strings.TrimSpace(req.To))
+	if fromErr != nil {
+		violations.Add("from", "invalid_format", "From must use YYYY-MM-DD format")
+	}
+	if toErr != nil {
+		violations.Add("to", "invalid_format", "To must use YYYY-MM-DD format")
+	}
+	if fromErr == nil && toErr == nil {
+		if to.Before(from) {
+			violations.Add("to", "before_start", "To must not be before from")
+		} else if days := int(to.Sub(from).Hours()/24) + 1; days > maxSessionGenerateDays {
+			violations.Add("to", "out_of_range", fmt.Sprintf("Range must not exceed %d days", maxSessionGenerateDays))
+		}
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	schedules, err := loadClassSchedules(s.DB, classID)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		violations.Add("schedules", "empty", "Class has no weekly schedule, create sessions manually instead")
+		return nil, violations
+	}
+
+	holidays := map[string]bool{}
+	rows, err := s.DB.Query(`
+        SELECT to_char(date, 'YYYY-MM-DD') FROM schedule_exceptions
+        WHERE date BETWEEN $1 AND $2 AND (class_id IS NULL OR class_id = $3)
+    `, from, to, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule exceptions: %w", err)
+	}
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+		}
+		holidays[date] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule exceptions: %w", err)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	response := &dto.GenerateSessionsResponse{Sessions: []model.ClassSession{}}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format("2006-01-02")
+		if holidays[dateStr] {
+			continue
+		}
+		isoDay := int(date.Weekday())
+		if isoDay == 0 {
+			isoDay = 7
+		}
+		for _, schedule := range schedules {
+			if schedule.DayOfWeek != isoDay {
+				continue
+			}
+			startAt, endAt := sessionTimes(schedule, date)
+			session, err := scanSession(tx.QueryRow(`
+                INSERT INTO class_sessions (class_id, schedule_id, date, start_at, end_at, source, created_by)
+                VALUES ($1, $2, $3, $4, $5, $6, $7)
+                ON CONFLICT ON CONSTRAINT class_sessions_class_start_key DO NOTHING
+                RETURNING `+sessionColumns,
+				classID, schedule.ID, dateStr, startAt, endAt, SessionSourceSchedule, createdBy))
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to create session: %w", err)
+			}
+			if err := applyApprovedLeaves(tx, session.ID); err != nil {
+				return nil, err
+			}
+			response.Sessions = append(response.Sessions, *session)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to generate sessions: %w", err)
+	}
+	response.Created = len(response.Sessions)
+	response.Message = fmt.Sprintf("%d sessions created", response.Created)
+	return response, nil
+}
+
+func (s *AttendanceService) DeleteSession(classID, sessionID int) error {
+	result, err := s.DB.Exec(`DELETE FROM class_sessions WHERE id = $1 AND class_id = $2`, sessionID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassSessionNotFound
+	}
+	return nil
+}
+
+// GetSessionAttendance mengembalikan daftar absen pertemuan: semua siswa kelas beserta statusnya.
+func (s *AttendanceService) GetSessionAttendance(classID, sessionID int) (*dto.SessionAttendanceResponse, error) {
+	session, err := getSession(s.DB, classID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT u.id, u.username, ar.status, ar.method, ar.note, ar.marked_at
+        FROM class_members cm
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN attendance_records ar ON ar.session_id = $2 AND ar.user_id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.role = 'siswa'
+        ORDER BY u.username
+    `, classID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance: %w", err)
+	}
+	defer rows.Close()
+
+	response := &dto.SessionAttendanceResponse{Session: session, Students: []dto.SessionAttendanceEntry{}}
+	for rows.Next() {
+		var entry dto.SessionAttendanceEntry
+		var status, method, note sql.NullString
+		var markedAt sql.NullTime
+		if err := rows.Scan(&entry.UserID, &entry.Username, &status, &method, &note, &markedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		entry.Status, entry.Method, entry.Note = status.String, method.String, note.String
+		if markedAt.Valid {
+			entry.MarkedAt = &markedAt.Time
+		}
+		response.Students = append(response.Students, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attendance: %w", err)
+	}
+
+	return response, nil
+}
+
+// MarkAttendance menyimpan absensi banyak siswa sekaligus (bulk marking oleh guru).
+func (s *AttendanceService) MarkAttendance(classID, sessionID, markedBy int, req dto.BulkAttendanceRequest) (*dto.SessionAttendanceResponse, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getSession(tx, classID, sessionID); err != nil {
+		return nil, err
+	}
+
+	students, err := classStudentIDs(tx, classID)
+	if err != nil {
+		return nil, err
+	}
+	violations := &ValidationError{}
+	seen := map[int]bool{}
+	for i, record := range req.Records {
+		field := fmt.Sprintf("records[%d].user_id", i)
+		if !students[record.UserID] {
+			violations.Add(field, "not_student", fmt.Sprintf("User %d is not a student of this class", record.UserID))
+		}
+		if seen[record.UserID] {
+			violations.Add(field, "duplicate", fmt.Sprintf("User %d is listed more than once", record.UserID))
+		}
+		seen[record.UserID] = true
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	for _, record := range req.Records {
+		if err := upsertAttendance(tx, sessionID, record.UserID, record.Status, AttendanceMethodManual, strings.TrimSpace(record.Note), markedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.MarkUnlisted != "" {
+		_, err := tx.Exec(`
+            INSERT INTO attendance_records (session_id, user_id, status, method, marked_by)
+            SELECT $1, user_id, $2, 'manual', $3 FROM class_members WHERE class_id = $4 AND role = 'siswa'
+            ON CONFLICT (session_id, user_id) DO NOTHING
+        `, sessionID, req.MarkUnlisted, markedBy, classID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark remaining students: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save attendance: %w", err)
+	}
+	return s.GetSessionAttendance(classID, sessionID)
+}
+
+// OpenCheckin membuka check-in QR selama durationMinutes dengan secret baru, sehingga kode
+// dari pembukaan sebelumnya tidak berlaku lagi.
+func (s *AttendanceService) OpenCheckin(classID, sessionID, durationMinutes int) (*dto.CheckinTokenResponse, error) {
+	if durationMinutes <= 0 {
+		durationMinutes = config.AttendanceCheckinMinutes
+	}
+	if durationMinutes > maxCheckinMinutes {
+		durationMinutes = maxCheckinMinutes
+	}
+
+	secret, err := randomURLSafe(32)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.DB.Exec(`
+        UPDATE class_sessions SET checkin_secret = $1, checkin_open_until = NOW() + make_interval(mins => $2)
+        WHERE id = $3 AND class_id = $4
+    `, secret, durationMinutes, sessionID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open check-in: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrClassSessionNotFound
+	}
+	return s.GetCheckinToken(classID, sessionID)
+}
+
+func (s *AttendanceService) CloseCheckin(classID, sessionID int) error {
+	result, err := s.DB.Exec(`
+        UPDATE class_sessions SET checkin_secret = NULL, checkin_open_until = NULL WHERE id = $1 AND class_id = $2
+    `, sessionID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to close check-in: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassSessionNotFound
+	}
+	return nil
+}
+
+// GetCheckinToken mengembalikan kode QR yang berlaku sekarang. Frontend guru memanggil ulang
+// endpoint ini setelah expires_at untuk menampilkan kode berikutnya.
+func (s *AttendanceService) GetCheckinToken(classID, sessionID int) (*dto.CheckinTokenResponse, error) {
+	var secret sql.NullString
+	var openUntil sql.NullTime
+	err := s.DB.QueryRow(`
+        SELECT checkin_secret, checkin_open_until FROM class_sessions WHERE id = $1 AND class_id = $2
+    `, sessionID, classID).Scan(&secret, &openUntil)
+	if err == sql.ErrNoRows {
+		return nil, ErrClassSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	now := time.Now()
+	if !secret.Valid || !openUntil.Valid || now.After(openUntil.Time) {
+		return nil, ErrCheckinClosed
+	}
+
+	rotation := checkinRotation()
+	window := now.Unix() / rotation
+	expiresAt := time.Unix((window+1)*rotation, 0)
+	if expiresAt.After(openUntil.Time) {
+		expiresAt = openUntil.Time
+	}
+	return &dto.CheckinTokenResponse{
+		SessionID: sessionID,
+		Code:      fmt.Sprintf("%d-%s", sessionID, checkinToken(secret.String, sessionID, window)),
+		ExpiresAt: expiresAt,
+		OpenUntil: openUntil.Time,
+	}, nil
+}
+
+// SelfCheckin mencatat siswa hadir dari kode QR. Kode dari satu periode sebelumnya masih
+// diterima supaya siswa yang memindai tepat saat kode berganti tidak gagal.
+func (s *AttendanceService) SelfCheckin(userID int, code string) (*model.AttendanceRecord, error) {
+	parts := strings.SplitN(strings.ToUpper(strings.TrimSpace(code)), "-", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCheckinCode
+	}
+	sessionID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCheckinCode
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var classID int
+	var secret sql.NullString
+	var openUntil sql.NullTime
+	err = tx.QueryRow(`
+        SELECT class_id, checkin_secret, checkin_open_until FROM class_sessions WHERE id = $1
+    `, sessionID).Scan(&classID, &secret, &openUntil)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidCheckinCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	now := time.Now()
+	if !secret.Valid || !openUntil.Valid || now.After(openUntil.Time) {
+		return nil, ErrCheckinClosed
+	}
+
+	window := now.Unix() / checkinRotation()
+	valid := false
+	for _, w := range []int64{window, window - 1} {
+		if hmac.Equal([]byte(parts[1]), []byte(checkinToken(secret.String, sessionID, w))) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidCheckinCode
+	}
+
+	var role string
+	var archived bool
+	err = tx.QueryRow(`
+        SELECT cm.role, c.archived_at IS NOT NULL
+        FROM class_members cm JOIN classes c ON c.id = cm.class_id
+        WHERE cm.class_id = $1 AND cm.user_id = $2
+    `, classID, userID).Scan(&role, &archived)
+	if err == sql.ErrNoRows || (err == nil && role != ClassRoleStudent) {
+		return nil, ErrNotClassMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if archived {
+		return nil, ErrClassArchived
+	}
+
+	// Izin/sakit yang dicatat guru tidak boleh tertimpa check-in mandiri
+	result, err := tx.Exec(`
+        INSERT INTO attendance_records (session_id, user_id, status, method, note, marked_by)
+        VALUES ($1, $2, $3, $4, '', $2)
+        ON CONFLICT (session_id, user_id) DO UPDATE
+        SET status = EXCLUDED.status, method = EXCLUDED.method, note = EXCLUDED.note,
+            marked_by = EXCLUDED.marked_by, marked_at = NOW()
+        WHERE attendance_records.status NOT IN ($5, $6)
+    `, sessionID, userID, AttendanceHadir, AttendanceMethodQR, AttendanceIzin, AttendanceSakit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save attendance: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return nil, ErrAttendanceExcused
+	}
+
+	record, err := scanAttendanceRecord(tx.QueryRow(`
+        SELECT session_id, user_id, status, method, note, marked_by, marked_at
+        FROM attendance_records WHERE session_id = $1 AND user_id = $2
+    `, sessionID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to check in: %w", err)
+	}
+	return record, nil
+}
+
+// GetClassSummary merekap kehadiran semua siswa kelas.
+func (s *AttendanceService) GetClassSummary(classID int) (*dto.ClassAttendanceSummary, error) {
+	summaries, held, err := attendanceSummaries(s.DB, classID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ClassAttendanceSummary{ClassID: classID, Sessions: held, Students: summaries}, nil
+}
+
+// GetStudentSummaries merekap kehadiran user di setiap kelas tempat dia menjadi siswa.
+func (s *AttendanceService) GetStudentSummaries(userID int) ([]dto.AttendanceSummary, error) {
+	rows, err := s.DB.Query(`SELECT class_id FROM class_members WHERE user_id = $1 AND role = 'siswa' ORDER BY class_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classes: %w", err)
+	}
+	var classIDs []int
+	for rows.Next() {
+		var classID int
+		if err := rows.Scan(&classID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+		classIDs = append(classIDs, classID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating classes: %w", err)
+	}
+
+	result := []dto.AttendanceSummary{}
+	for _, classID := range classIDs {
+		summaries, _, err := attendanceSummaries(s.DB, classID, &userID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summaries...)
+	}
+	return result, nil
+}
+
+// attendanceSummaries menghitung rekap per siswa (atau satu siswa jika userID diisi) dan
+// jumlah pertemuan yang sudah berlangsung.
+func attendanceSummaries(q dbExecutor, classID int, userID *int) ([]dto.AttendanceSummary, int, error) {
+	var held int
+	if err := q.QueryRow(`SELECT COUNT(*) FROM class_sessions WHERE class_id = $1 AND start_at <= NOW()`, classID).Scan(&held); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	rows, err := q.Query(`
+        SELECT u.id, u.username,
+               COUNT(ar.id) FILTER (WHERE ar.status = 'hadir'),
+               COUNT(ar.id) FILTER (WHERE ar.status = 'izin'),
+               COUNT(ar.id) FILTER (WHERE ar.status = 'sakit'),
+               COUNT(ar.id) FILTER (WHERE ar.status = 'alpa'),
+               COUNT(ar.id)
+        FROM class_members cm
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN class_sessions s ON s.class_id = cm.class_id AND s.start_at <= NOW()
+        LEFT JOIN attendance_records ar ON ar.session_id = s.id AND ar.user_id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.role = 'siswa' AND ($2::INT IS NULL OR cm.user_id = $2)
+        GROUP BY u.id, u.username
+        ORDER BY u.username
+    `, classID, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query attendance summary: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []dto.AttendanceSummary{}
+	for rows.Next() {
+		summary := dto.AttendanceSummary{ClassID: classID, Sessions: held}
+		var recorded int
+		if err := rows.Scan(&summary.UserID, &summary.Username, &summary.Hadir, &summary.Izin, &summary.Sakit,
+			&summary.Alpa, &recorded); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan attendance summary: %w", err)
+		}
+		if recorded < held {
+			summary.Unrecorded = held - recorded
+		}
+		if held > 0 {
+			summary.Percentage = math.Round(float64(summary.Hadir)*1000/float64(held)) / 10
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating attendance summary: %w", err)
+	}
+	return summaries, held, nil
+}
+
+// attendanceTotalsByClass menjumlahkan status kehadiran user per kelas (dipakai rapot).
+func attendanceTotalsByClass(q dbExecutor, userID int) (map[int]dto.AttendanceTotals, error) {
+	rows, err := q.Query(`
+        SELECT s.class_id, ar.status, COUNT(*)
+        FROM attendance_records ar JOIN class_sessions s ON s.id = ar.session_id
+        WHERE ar.user_id = $1
+        GROUP BY s.class_id, ar.status
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := map[int]dto.AttendanceTotals{}
+	for rows.Next() {
+		var classID, count int
+		var status string
+		if err := rows.Scan(&classID, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance totals: %w", err)
+		}
+		total := totals[classID]
+		switch status {
+		case AttendanceHadir:
+			total.Hadir = count
+		case AttendanceIzin:
+			total.Izin = count
+		case AttendanceSakit:
+			total.Sakit = count
+		case AttendanceAlpa:
+			total.Alpa = count
+		}
+		totals[classID] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attendance totals: %w", err)
+	}
+	return totals, nil
+}
+
+func upsertAttendance(q dbExecutor, sessionID, userID int, status, method, note string, markedBy int) error {
+	_, err := q.Exec(`
+        INSERT INTO attendance_records (session_id, user_id, status, method, note, marked_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (session_id, user_id) DO UPDATE
+        SET status = EXCLUDED.status, method = EXCLUDED.method, note = EXCLUDED.note,
+            marked_by = EXCLUDED.marked_by, marked_at = NOW()
+    `, sessionID, userID, status, method, note, markedBy)
+	if err != nil {
+		return fmt.Errorf("failed to save attendance: %w", err)
+	}
+	return nil
+}
+
+// classStudentIDs mengembalikan himpunan user_id siswa di kelas.
+func classStudentIDs(q dbExecutor, classID int) (map[int]bool, error) {
+	rows, err := q.Query(`SELECT user_id FROM class_members WHERE class_id = $1 AND role = 'siswa'`, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students: %w", err)
+	}
+	defer rows.Close()
+
+	students := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		students[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating students: %w", err)
+	}
+	return students, nil
+}
+
+func checkinRotation() int64 {
+	if config.AttendanceTokenRotationSeconds < 5 {
+		return 5
+	}
+	return int64(config.AttendanceTokenRotationSeconds)
+}
+
+// checkinToken menurunkan kode pendek dari secret pertemuan dan nomor periode waktu.
+func checkinToken(secret string, sessionID int, window int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%d", sessionID, window)
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mac.Sum(nil))
+	return encoded[:checkinTokenLength]
+}
+
+func getSession(q dbExecutor, classID, sessionID int) (*model.ClassSession, error) {
+	session, err := scanSession(q.QueryRow(sessionSelect+` WHERE id = $1 AND class_id = $2`, sessionID, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrClassSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+const sessionColumns = `id, class_id, schedule_id, to_char(date, 'YYYY-MM-DD'), start_at, end_at, topic, source, checkin_open_until, created_at`
+
+const sessionSelect = `SELECT ` + sessionColumns + ` FROM class_sessions`
+
+func scanSession(row rowScanner) (*model.ClassSession, error) {
+	var session model.ClassSession
+	err := row.Scan(&session.ID, &session.ClassID, &session.ScheduleID, &session.Date, &session.StartAt, &session.EndAt,
+		&session.Topic, &session.Source, &session.CheckinOpenUntil, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func scanAttendanceRecord(row rowScanner) (*model.AttendanceRecord, error) {
+	var record model.AttendanceRecord
+	err := row.Scan(&record.SessionID, &record.UserID, &record.Status, &record.Method, &record.Note, &record.MarkedBy, &record.MarkedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
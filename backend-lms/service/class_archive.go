@@ -0,0 +1,51 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrClassArchived dikembalikan untuk aksi siswa (join, mengumpulkan, dll.) di kelas yang diarsipkan.
+var ErrClassArchived = errors.New("class has been archived")
+
+// ArchiveClass mengarsipkan kelas: disembunyikan dari daftar kelas default dan read-only untuk siswa.
+func (s *ClassService) ArchiveClass(classID int) error {
+	return s.setArchived(classID, true)
+}
+
+func (s *ClassService) UnarchiveClass(classID int) error {
+	return s.setArchived(classID, false)
+}
+
+func (s *ClassService) setArchived(classID int, archived bool) error {
+	// Kelas yang sudah diarsipkan tetap menyimpan waktu arsip pertamanya
+	result, err := s.DB.Exec(`
+        UPDATE classes SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, NOW()) ELSE NULL END
+        WHERE id = $2
+    `, archived, classID)
+	if err != nil {
+		return fmt.Errorf("failed to update class archive status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassNotFound
+	}
+	return nil
+}
+
+func isClassArchived(q dbExecutor, classID int) (bool, error) {
+	var archived bool
+	err := q.QueryRow(`SELECT archived_at IS NOT NULL FROM classes WHERE id = $1`, classID).Scan(&archived)
+	if err == sql.ErrNoRows {
+		return false, ErrClassNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check class archive status: %w", err)
+	}
+	return archived, nil
+}
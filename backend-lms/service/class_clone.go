@@ -0,0 +1,222 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+)
+
+// CloneClass menyalin pengaturan kelas, jadwal, materi (attachment memakai file yang sama) dan
+// tugas (termasuk kebijakan keterlambatan, max_points dan rubrik) ke kelas baru dengan actorID
+// sebagai owner. Member dan nilai manual ikut disalin kecuali dikecualikan lewat request.
+func (s *ClassService) CloneClass(actorID, sourceID int, req dto.CloneClassRequest) (*dto.CloneClassResponse, error) {
+	violations := &ValidationError{}
+	startDate, err := time.Parse("2006-01-02", strings.TrimSpace(req.StartDate))
+	if err != nil {
+		violations.Add("start_date", "invalid_format", "Start date must use YYYY-MM-DD format")
+	}
+	var sourceStart *time.Time
+	if value := strings.TrimSpace(req.SourceStartDate); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			violations.Add("source_start_date", "invalid_format", "Source start date must use YYYY-MM-DD format")
+		}
+		sourceStart = &parsed
+	}
+	// Nilai hanya bermakna jika siswanya ikut disalin
+	if req.ExcludeMembers && !req.ExcludeGrades {
+		violations.Add("exclude_grades", "requires_members", "Grades can only be copied together with members")
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var source model.Class
+	var enrollmentMode string
+	var termStart sql.NullTime
+	err = tx.QueryRow(`
+        SELECT c.id, c.name, c.jadwal_kelas, c.teacher, c.created_at, c.enrollment_mode, c.term_id, t.start_date
+        FROM classes c LEFT JOIN terms t ON t.id = c.term_id
+        WHERE c.id = $1
+    `, sourceID).Scan(&source.ID, &source.Name, &source.JadwalKelas, &source.Teacher, &source.CreatedAt,
+		&enrollmentMode, &source.TermID, &termStart)
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class: %w", err)
+	}
+
+	if sourceStart == nil {
+		if termStart.Valid {
+			sourceStart = &termStart.Time
+		} else {
+			created := source.CreatedAt
+			sourceStart = &created
+		}
+	}
+	from := time.Date(sourceStart.Year(), sourceStart.Month(), sourceStart.Day(), 0, 0, 0, 0, time.UTC)
+	shiftDays := int(startDate.Sub(from).Hours() / 24)
+
+	if req.TermID != nil {
+		if err := checkTermExists(tx, *req.TermID); err != nil {
+			return nil, err
+		}
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = source.Name
+	}
+
+	var class model.Class
+	err = withUniqueClassCode(func(code string) error {
+		err := tx.QueryRow(`
+            INSERT INTO classes (name, jadwal_kelas, teacher, class_code, enrollment_mode, term_id)
+            VALUES ($1, $2, (SELECT username FROM users WHERE id = $3), $4, $5, COALESCE($6, (SELECT id FROM terms WHERE is_active)))
+            ON CONFLICT ON CONSTRAINT classes_class_code_key DO NOTHING
+            RETURNING id, name, jadwal_kelas, created_at, teacher, class_code, term_id, archived_at
+        `, name, source.JadwalKelas, actorID, code, enrollmentMode, req.TermID).Scan(&class.ID, &class.Name, &class.JadwalKelas,
+			&class.CreatedAt, &class.Teacher, &class.ClassCode, &class.TermID, &class.ArchivedAt)
+		if err == sql.ErrNoRows {
+			return errClassCodeTaken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO class_members (class_id, user_id, role) VALUES ($1, $2, $3)`, class.ID, actorID, ClassRoleOwner); err != nil {
+		return nil, fmt.Errorf("failed to add class owner: %w", err)
+	}
+
+	response := &dto.CloneClassResponse{SourceClassID: sourceID, Class: &class, DueDateShiftDays: shiftDays}
+	copyRows := func(counter *int, what, query string, args ...interface{}) error {
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", what, err)
+		}
+		copied, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error checking rows affected: %w", err)
+		}
+		*counter = int(copied)
+		return nil
+	}
+
+	// Jadwal disalin tanpa cek bentrok karena kelas asal biasanya diarsipkan setelah rollover
+	if err := copyRows(&response.Copied.Schedules, "schedules", `
+        INSERT INTO class_schedules (class_id, day_of_week, start_time, end_time, room, timezone)
+        SELECT $1, day_of_week, start_time, end_time, room, timezone FROM class_schedules WHERE class_id = $2
+    `, class.ID, sourceID); err != nil {
+		return nil, err
+	}
+
+	units, err := cloneUnits(tx, sourceID, class.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Copied.Units = len(units)
+
+	// Materi dan tugas disalin dengan unit_id kelas asal, lalu dipetakan ke unit baru
+	if err := copyRows(&response.Copied.Materials, "materials", `
+        INSERT INTO materials (title, content, class_id, attachment, unit_id, position)
+        SELECT title, content, $1, attachment, unit_id, position FROM materials WHERE class_id = $2 ORDER BY id
+    `, class.ID, sourceID); err != nil {
+		return nil, err
+	}
+
+	if err := copyRows(&response.Copied.Assignments, "assignments", `
+        INSERT INTO assignments (class_id, title, description, due_date, attachment, created_by, unit_id, position,
+                                 late_policy, late_penalty_percent, max_points, rubric_id)
+        SELECT $1, title, description,
+               due_date + make_interval(days => $3),
+               attachment, $4, unit_id, position,
+               late_policy, late_penalty_percent, max_points, rubric_id
+        FROM assignments WHERE class_id = $2 ORDER BY id
+    `, class.ID, sourceID, shiftDays, actorID); err != nil {
+		return nil, err
+	}
+
+	for oldID, newID := range units {
+		for _, table := range outlineItemTables {
+			query := fmt.Sprintf(`UPDATE %s SET unit_id = $1 WHERE class_id = $2 AND unit_id = $3`, table)
+			if _, err := tx.Exec(query, newID, class.ID, oldID); err != nil {
+				return nil, fmt.Errorf("failed to map cloned units: %w", err)
+			}
+		}
+	}
+
+	if !req.ExcludeMembers {
+		// Owner kelas asal yang bukan pengkloning menjadi co-teacher di kelas baru
+		if err := copyRows(&response.Copied.Members, "members", `
+            INSERT INTO class_members (class_id, user_id, role)
+            SELECT $1, user_id, CASE WHEN role = 'owner' THEN 'co_teacher' ELSE role END
+            FROM class_members WHERE class_id = $2
+            ON CONFLICT (class_id, user_id) DO NOTHING
+        `, class.ID, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nilai tugas dan kuis terikat ke pengumpulan/percobaan kelas asal yang tidak disalin,
+	// jadi hanya nilai manual yang ikut
+	if !req.ExcludeGrades {
+		if err := copyRows(&response.Copied.Grades, "grades", `
+            INSERT INTO grades (user_id, class_id, grade)
+            SELECT user_id, $1, grade FROM grades
+            WHERE class_id = $2 AND assignment_id IS NULL AND quiz_id IS NULL
+        `, class.ID, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to clone class: %w", err)
+	}
+	response.Message = "Class cloned successfully"
+	return response, nil
+}
+
+// cloneUnits menyalin unit kelas asal dan mengembalikan pemetaan id unit lama ke id baru.
+func cloneUnits(tx *sql.Tx, sourceID, classID int) (map[int]int, error) {
+	rows, err := tx.Query(`SELECT id, title, description, position FROM class_units WHERE class_id = $1 ORDER BY position, id`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query units: %w", err)
+	}
+	var units []model.ClassUnit
+	for rows.Next() {
+		var unit model.ClassUnit
+		if err := rows.Scan(&unit.ID, &unit.Title, &unit.Description, &unit.Position); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan unit: %w", err)
+		}
+		units = append(units, unit)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating units: %w", err)
+	}
+
+	mapping := make(map[int]int, len(units))
+	for _, unit := range units {
+		var newID int
+		err := tx.QueryRow(`
+            INSERT INTO class_units (class_id, title, description, position) VALUES ($1, $2, $3, $4) RETURNING id
+        `, classID, unit.Title, unit.Description, unit.Position).Scan(&newID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy unit: %w", err)
+		}
+		mapping[unit.ID] = newID
+	}
+	return mapping, nil
+}
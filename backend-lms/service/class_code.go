@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"project/config"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// classCodeAlphabet tidak memuat karakter yang mudah tertukar saat didikte atau
+// ditulis di papan tulis (0/O, 1/I/L).
+const classCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const (
+	minClassCodeLength   = 6
+	classCodeMaxAttempts = 5
+)
+
+var (
+	ErrClassNotFound      = errors.New("class not found")
+	ErrClassCodeDisabled  = errors.New("class code has been disabled")
+	ErrClassCodeExpired   = errors.New("class code has expired")
+	ErrClassCodeExhausted = errors.New("class code has reached its maximum number of uses")
+
+	errClassCodeTaken = errors.New("class code already taken")
+)
+
+// GetClassCode mengembalikan status kode kelas.
+func (s *ClassService) GetClassCode(classID int) (*model.ClassCode, error) {
+	code, err := scanClassCode(s.DB.QueryRow(`
+        SELECT id, class_code, class_code_enabled, class_code_expires_at, class_code_max_uses, class_code_uses
+        FROM classes WHERE id = $1
+    `, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class code: %w", err)
+	}
+	return code, nil
+}
+
+// RegenerateClassCode mengganti kode kelas dengan kode baru. Kode lama langsung tidak berlaku,
+// hitungan pemakaian direset dan kode diaktifkan kembali.
+func (s *ClassService) RegenerateClassCode(classID int, req dto.ClassCodeSettingsRequest) (*model.ClassCode, error) {
+	if err := validateClassCodeSettings(req); err != nil {
+		return nil, err
+	}
+
+	var code *model.ClassCode
+	err := withUniqueClassCode(func(newCode string) error {
+		var err error
+		code, err = scanClassCode(s.DB.QueryRow(`
+            UPDATE classes
+            SET class_code = $1, class_code_enabled = TRUE, class_code_expires_at = $2,
+                class_code_max_uses = $3, class_code_uses = 0
+            WHERE id = $4
+            RETURNING id, class_code, class_code_enabled, class_code_expires_at, class_code_max_uses, class_code_uses
+        `, newCode, req.ExpiresAt, req.MaxUses, classID))
+		return err
+	})
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate class code: %w", err)
+	}
+	return code, nil
+}
+
+// UpdateClassCodeSettings mengatur status aktif, masa berlaku dan batas pemakaian kode.
+// Field yang tidak dikirim tetap; expires_at/max_uses null menghapus batasnya.
+func (s *ClassService) UpdateClassCodeSettings(classID int, req dto.ClassCodeSettingsRequest) (*model.ClassCode, error) {
+	if err := validateClassCodeSettings(req); err != nil {
+		return nil, err
+	}
+
+	code, err := scanClassCode(s.DB.QueryRow(`
+        UPDATE classes
+        SET class_code_enabled = COALESCE($1, class_code_enabled),
+            class_code_expires_at = CASE WHEN $2 THEN $3 ELSE class_code_expires_at END,
+            class_code_max_uses = CASE WHEN $4 THEN $5 ELSE class_code_max_uses END
+        WHERE id = $6
+        RETURNING id, class_code, class_code_enabled, class_code_expires_at, class_code_max_uses, class_code_uses
+    `, req.Enabled, req.ExpiresAtSet, req.ExpiresAt, req.MaxUsesSet, req.MaxUses, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update class code: %w", err)
+	}
+	return code, nil
+}
+
+// DisableClassCode menonaktifkan kode sehingga tidak ada yang bisa bergabung dengan kode tersebut.
+func (s *ClassService) DisableClassCode(classID int) error {
+	result, err := s.DB.Exec(`UPDATE classes SET class_code_enabled = FALSE WHERE id = $1`, classID)
+	if err != nil {
+		return fmt.Errorf("failed to disable class code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassNotFound
+	}
+	return nil
+}
+
+// NormalizeClassCode membuat input kode tidak sensitif huruf besar/kecil, spasi dan tanda hubung.
+func NormalizeClassCode(code string) string {
+	replacer := strings.NewReplacer(" ", "", "-", "")
+	return strings.ToUpper(replacer.Replace(strings.TrimSpace(code)))
+}
+
+// useClassCode memvalidasi kode dan menambah hitungan pemakaiannya. Dijalankan di dalam
+// transaksi join supaya pemakaian tidak terhitung jika join gagal.
+func useClassCode(tx *sql.Tx, classCode string) (int, error) {
+	code, err := scanClassCode(tx.QueryRow(`
+        SELECT id, class_code, class_code_enabled, class_code_expires_at, class_code_max_uses, class_code_uses
+        FROM classes WHERE class_code = $1
+        FOR UPDATE
+    `, NormalizeClassCode(classCode)))
+	if err == sql.ErrNoRows {
+		return 0, ErrClassNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find class by class_code: %w", err)
+	}
+
+	switch {
+	case !code.Enabled:
+		return 0, ErrClassCodeDisabled
+	case code.ExpiresAt != nil && time.Now().After(*code.ExpiresAt):
+		return 0, ErrClassCodeExpired
+	case code.MaxUses != nil && code.Uses >= *code.MaxUses:
+		return 0, ErrClassCodeExhausted
+	}
+
+	if _, err := tx.Exec(`UPDATE classes SET class_code_uses = class_code_uses + 1 WHERE id = $1`, code.ClassID); err != nil {
+		return 0, fmt.Errorf("failed to update class code usage: %w", err)
+	}
+	return code.ClassID, nil
+}
+
+func validateClassCodeSettings(req dto.ClassCodeSettingsRequest) error {
+	violations := &ValidationError{}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		violations.Add("expires_at", "in_past", "Expiry must be in the future")
+	}
+	if req.MaxUses != nil && *req.MaxUses < 1 {
+		violations.Add("max_uses", "out_of_range", "Max uses must be at least 1")
+	}
+	return violations.OrNil()
+}
+
+// withUniqueClassCode menjalankan fn dengan kode acak baru dan mengulang jika kode
+// bentrok dengan unique constraint classes_class_code_key (atau fn mengembalikan errClassCodeTaken).
+func withUniqueClassCode(fn func(code string) error) error {
+	for attempt := 0; attempt < classCodeMaxAttempts; attempt++ {
+		code, err := generateClassCode()
+		if err != nil {
+			return err
+		}
+
+		err = fn(code)
+		var pqErr *pq.Error
+		if errors.Is(err, errClassCodeTaken) ||
+			(errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "classes_class_code_key") {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("failed to generate a unique class code after %d attempts", classCodeMaxAttempts)
+}
+
+func generateClassCode() (string, error) {
+	length := config.ClassCodeLength
+	if length < minClassCodeLength {
+		length = minClassCodeLength
+	}
+
+	code := make([]byte, length)
+	max := big.NewInt(int64(len(classCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate class code: %w", err)
+		}
+		code[i] = classCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func scanClassCode(row rowScanner) (*model.ClassCode, error) {
+	var code model.ClassCode
+	var expiresAt sql.NullTime
+	var maxUses sql.NullInt64
+	if err := row.Scan(&code.ClassID, &code.Code, &code.Enabled, &expiresAt, &maxUses, &code.Uses); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		code.ExpiresAt = &expiresAt.Time
+	}
+	if maxUses.Valid {
+		uses := int(maxUses.Int64)
+		code.MaxUses = &uses
+	}
+	return &code, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+// Mode pendaftaran kelas.
+const (
+	EnrollmentOpen     = "open"
+	EnrollmentApproval = "approval"
+)
+
+// Status hasil join kelas.
+const (
+	JoinStatusJoined        = "joined"
+	JoinStatusAlreadyMember = "already_member"
+	JoinStatusPending       = "pending"
+)
+
+var (
+	ErrJoinRequestNotFound = errors.New("join request not found")
+	ErrNotClassMember      = errors.New("user is not a member of this class")
+)
+
+// JoinClassByCode memasukkan user ke kelas berdasarkan kode. Kelas dengan mode approval
+// membuat join request yang harus disetujui guru. Join ulang tidak membuat data ganda.
+func (s *ClassService) JoinClassByCode(userID int, req dto.JoinClassByCodeRequest) (*dto.JoinClassResponse, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var classID int
+	var mode string
+	var archived bool
+	err = tx.QueryRow(
+		`SELECT id, enrollment_mode, archived_at IS NOT NULL FROM classes WHERE class_code = $1`, NormalizeClassCode(req.ClassCode),
+	).Scan(&classID, &mode, &archived)
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find class by class_code: %w", err)
+	}
+	if archived {
+		return nil, ErrClassArchived
+	}
+
+	var isMember bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM class_members WHERE class_id = $1 AND user_id = $2)`, classID, userID,
+	).Scan(&isMember)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if isMember {
+		return &dto.JoinClassResponse{Status: JoinStatusAlreadyMember, Message: "Already a member of this class", ClassID: classID}, nil
+	}
+
+	pending, err := scanJoinRequest(tx.QueryRow(joinRequestSelect+`
+        WHERE r.class_id = $1 AND r.user_id = $2 AND r.status = 'pending'
+    `, classID, userID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check join request: %w", err)
+	}
+	if err == nil {
+		return &dto.JoinClassResponse{Status: JoinStatusPending, Message: "Join request is waiting for approval", ClassID: classID, Request: pending}, nil
+	}
+
+	// Kode dipakai (uses bertambah) di transaksi yang sama dengan insert member/request
+	if _, err := useClassCode(tx, req.ClassCode); err != nil {
+		return nil, err
+	}
+
+	result := &dto.JoinClassResponse{ClassID: classID}
+	if mode == EnrollmentApproval {
+		var requestID int
+		err = tx.QueryRow(`
+            INSERT INTO class_join_requests (class_id, user_id, message) VALUES ($1, $2, NULLIF($3, ''))
+            RETURNING id
+        `, classID, userID, strings.TrimSpace(req.Message)).Scan(&requestID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create join request: %w", err)
+		}
+		result.Request, err = scanJoinRequest(tx.QueryRow(joinRequestSelect+` WHERE r.id = $1`, requestID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get join request: %w", err)
+		}
+		result.Status, result.Message = JoinStatusPending, "Join request is waiting for approval"
+	} else {
+		if err := addClassMember(tx, classID, userID); err != nil {
+			return nil, err
+		}
+		result.Status, result.Message = JoinStatusJoined, "Successfully joined the class"
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to join class: %w", err)
+	}
+	return result, nil
+}
+
+// LeaveClass mengeluarkan user dari kelas atas permintaannya sendiri. Owner harus
+// memindahkan kepemilikan dulu.
+func (s *ClassService) LeaveClass(userID, classID int) error {
+	result, err := s.DB.Exec(`DELETE FROM class_members WHERE class_id = $1 AND user_id = $2 AND role <> $3`, classID, userID, ClassRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to leave class: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		role, err := s.GetClassRole(userID, classID)
+		if err != nil {
+			return err
+		}
+		if role == ClassRoleOwner {
+			return ErrOwnerMembership
+		}
+		return ErrNotClassMember
+	}
+	return nil
+}
+
+func (s *ClassService) SetEnrollmentMode(classID int, mode string) error {
+	result, err := s.DB.Exec(`UPDATE classes SET enrollment_mode = $1 WHERE id = $2`, mode, classID)
+	if err != nil {
+		return fmt.Errorf("failed to update enrollment mode: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassNotFound
+	}
+	return nil
+}
+
+// GetJoinRequests mengembalikan join request sebuah kelas, default hanya yang pending.
+func (s *ClassService) GetJoinRequests(classID int, status string) ([]model.ClassJoinRequest, error) {
+	if status == "" {
+		status = "pending"
+	}
+
+	rows, err := s.DB.Query(joinRequestSelect+`
+        WHERE r.class_id = $1 AND ($2 = 'all' OR r.status = $2)
+        ORDER BY r.created_at
+    `, classID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query join requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []model.ClassJoinRequest
+	for rows.Next() {
+		request, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan join request: %w", err)
+		}
+		requests = append(requests, *request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating join requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ReviewJoinRequest menyetujui atau menolak join request yang masih pending.
+// Request yang disetujui langsung menjadi membership.
+func (s *ClassService) ReviewJoinRequest(classID, requestID, reviewerID int, approve bool) (*model.ClassJoinRequest, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status := "rejected"
+	if approve {
+		status = "approved"
+	}
+
+	var userID int
+	err = tx.QueryRow(`
+        UPDATE class_join_requests SET status = $1, reviewed_by = $2, reviewed_at = NOW()
+        WHERE id = $3 AND class_id = $4 AND status = 'pending'
+        RETURNING user_id
+    `, status, reviewerID, requestID, classID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrJoinRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review join request: %w", err)
+	}
+
+	if approve {
+		if err := addClassMember(tx, classID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	request, err := scanJoinRequest(tx.QueryRow(joinRequestSelect+` WHERE r.id = $1`, requestID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get join request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to review join request: %w", err)
+	}
+	return request, nil
+}
+
+// addClassMember idempotent: member yang sudah ada tidak diubah.
+func addClassMember(tx *sql.Tx, classID, userID int) error {
+	_, err := tx.Exec(`
+        INSERT INTO class_members (class_id, user_id, role) VALUES ($1, $2, 'siswa')
+        ON CONFLICT (class_id, user_id) DO NOTHING
+    `, classID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+	return nil
+}
+
+const joinRequestSelect = `
+    SELECT r.id, r.class_id, r.user_id, u.username, r.status, r.message, r.reviewed_by, r.reviewed_at, r.created_at
+    FROM class_join_requests r
+    JOIN users u ON u.id = r.user_id
+`
+
+func scanJoinRequest(row rowScanner) (*model.ClassJoinRequest, error) {
+	var request model.ClassJoinRequest
+	var message sql.NullString
+	var reviewedBy sql.NullInt64
+	var reviewedAt sql.NullTime
+	err := row.Scan(&request.ID, &request.ClassID, &request.UserID, &request.Username, &request.Status,
+		&message, &reviewedBy, &reviewedAt, &request.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if message.Valid {
+		request.Message = &message.String
+	}
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		request.ReviewedBy = &id
+	}
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+	return &request, nil
+}
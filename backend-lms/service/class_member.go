@@ -0,0 +1,267 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/middleware"
+	"project/model"
+)
+
+// Role user di dalam sebuah kelas (class_members.role). Role siswa tetap memakai
+// nilai lama 'siswa' supaya data membership lama tidak perlu diubah.
+const (
+	ClassRoleOwner     = "owner"
+	ClassRoleCoTeacher = "co_teacher"
+	ClassRoleAssistant = "assistant"
+	ClassRoleStudent   = "siswa"
+)
+
+// classRolePermissions memetakan role kelas ke permission yang dicek RequireClassPermission.
+var classRolePermissions = map[string][]string{
+	ClassRoleOwner: {
+		middleware.ClassPermView, middleware.ClassPermGrade, middleware.ClassPermManageContent,
+		middleware.ClassPermManageMembers, middleware.ClassPermManageSettings, middleware.ClassPermManageTeachers,
+	},
+	ClassRoleCoTeacher: {
+		middleware.ClassPermView, middleware.ClassPermGrade, middleware.ClassPermManageContent,
+		middleware.ClassPermManageMembers, middleware.ClassPermManageSettings,
+	},
+	ClassRoleAssistant: {middleware.ClassPermView, middleware.ClassPermGrade, middleware.ClassPermParticipate},
+	ClassRoleStudent:   {middleware.ClassPermView, middleware.ClassPermParticipate},
+}
+
+var (
+	ErrInvalidClassRole   = errors.New("invalid class role")
+	ErrOwnerMembership    = errors.New("the class owner cannot be removed or changed, transfer ownership first")
+	ErrNewOwnerNotTeacher = errors.New("new owner must be a teacher member of this class")
+	// ErrStaffRoleForStudent: user dengan role global Siswa hanya bisa menjadi co-teacher atau
+	// assistant jika diatur Admin.
+	ErrStaffRoleForStudent = errors.New("students can only be made co-teacher or assistant by an Admin")
+)
+
+// classRolesWith mengembalikan role kelas yang punya permission tertentu.
+func classRolesWith(permission string) []string {
+	var roles []string
+	for role, permissions := range classRolePermissions {
+		for _, p := range permissions {
+			if p == permission {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// classCodeVisible: kode bergabung kelas hanya untuk Admin dan role kelas yang boleh mengubah
+// pengaturan kelas (owner, co_teacher). classRole kosong berarti bukan member.
+func classCodeVisible(globalRole, classRole string) bool {
+	if globalRole == "Admin" {
+		return true
+	}
+	for _, permission := range classRolePermissions[classRole] {
+		if permission == middleware.ClassPermManageSettings {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClassRole mengecek nilai role kelas yang valid.
+func IsClassRole(role string) bool {
+	_, ok := classRolePermissions[role]
+	return ok
+}
+
+// GetClassRole mengembalikan role user di kelas, string kosong jika bukan member.
+func (s *ClassService) GetClassRole(userID, classID int) (string, error) {
+	var role string
+	err := s.DB.QueryRow(
+		`SELECT role FROM class_members WHERE class_id = $1 AND user_id = $2`, classID, userID,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get class role: %w", err)
+	}
+	return role, nil
+}
+
+// CheckClassPermission dipasang sebagai middleware.ClassPermissionChecker. Admin boleh
+// semua aksi, user lain dicek berdasarkan role di class_members. Di kelas yang diarsipkan
+// siswa hanya bisa melihat.
+func (s *ClassService) CheckClassPermission(userID int, globalRole string, classID int, permission string) error {
+	if globalRole == "Admin" {
+		return nil
+	}
+
+	role, err := s.GetClassRole(userID, classID)
+	if err != nil {
+		return err
+	}
+	if role == ClassRoleStudent && permission != middleware.ClassPermView {
+		archived, err := isClassArchived(s.DB, classID)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return middleware.ErrClassForbidden
+		}
+	}
+	for _, granted := range classRolePermissions[role] {
+		if granted == permission {
+			return nil
+		}
+	}
+	return middleware.ErrClassForbidden
+}
+
+// AddMember menambahkan user ke kelas dengan role tertentu, atau mengubah role member
+// yang sudah ada. Menambahkan co-teacher/assistant hanya boleh dilakukan owner, dan untuk user
+// dengan role global Siswa hanya oleh Admin.
+func (s *ClassService) AddMember(actorID int, actorRole string, classID, userID int, role string) error {
+	if role == "" {
+		role = ClassRoleStudent
+	}
+	if !IsClassRole(role) || role == ClassRoleOwner {
+		return ErrInvalidClassRole
+	}
+	if role != ClassRoleStudent {
+		if err := s.CheckClassPermission(actorID, actorRole, classID, middleware.ClassPermManageTeachers); err != nil {
+			return err
+		}
+
+		var globalRole string
+		err := s.DB.QueryRow(`SELECT role FROM users WHERE id = $1`, userID).Scan(&globalRole)
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if globalRole == "Siswa" && actorRole != "Admin" {
+			return ErrStaffRoleForStudent
+		}
+	}
+
+	currentRole, err := s.GetClassRole(userID, classID)
+	if err != nil {
+		return err
+	}
+	if currentRole == ClassRoleOwner {
+		return ErrOwnerMembership
+	}
+	// Menurunkan co-teacher/assistant menjadi siswa juga hanya boleh dilakukan owner
+	if currentRole != "" && currentRole != ClassRoleStudent {
+		if err := s.CheckClassPermission(actorID, actorRole, classID, middleware.ClassPermManageTeachers); err != nil {
+			return err
+		}
+	}
+
+	query := `
+        INSERT INTO class_members (class_id, user_id, role) VALUES ($1, $2, $3)
+        ON CONFLICT (class_id, user_id) DO UPDATE SET role = EXCLUDED.role
+    `
+	if _, err := s.DB.Exec(query, classID, userID, role); err != nil {
+		return fmt.Errorf("failed to add member: %v", err)
+	}
+	return nil
+}
+
+// RemoveMember mengeluarkan member. Co-teacher/assistant hanya bisa dikeluarkan owner,
+// dan owner tidak bisa dikeluarkan.
+func (s *ClassService) RemoveMember(actorID int, actorRole string, classID, userID int) error {
+	currentRole, err := s.GetClassRole(userID, classID)
+	if err != nil {
+		return err
+	}
+	switch currentRole {
+	case "":
+		return ErrNotClassMember
+	case ClassRoleOwner:
+		return ErrOwnerMembership
+	case ClassRoleCoTeacher, ClassRoleAssistant:
+		if err := s.CheckClassPermission(actorID, actorRole, classID, middleware.ClassPermManageTeachers); err != nil {
+			return err
+		}
+	}
+
+	query := `DELETE FROM class_members WHERE class_id = $1 AND user_id = $2`
+	if _, err := s.DB.Exec(query, classID, userID); err != nil {
+		return fmt.Errorf("failed to remove member: %v", err)
+	}
+	return nil
+}
+
+// TransferOwnership menjadikan member lain (guru) sebagai owner. Owner lama menjadi co-teacher.
+func (s *ClassService) TransferOwnership(classID, newOwnerID int) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentRole, globalRole, username string
+	err = tx.QueryRow(`
+        SELECT cm.role, u.role, u.username
+        FROM class_members cm JOIN users u ON u.id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.user_id = $2
+        FOR UPDATE OF cm
+    `, classID, newOwnerID).Scan(&currentRole, &globalRole, &username)
+	if err == sql.ErrNoRows {
+		return ErrNewOwnerNotTeacher
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get new owner: %w", err)
+	}
+	if currentRole == ClassRoleOwner {
+		return nil
+	}
+	if globalRole != "Guru" && globalRole != "Admin" {
+		return ErrNewOwnerNotTeacher
+	}
+
+	if _, err := tx.Exec(`UPDATE class_members SET role = $1 WHERE class_id = $2 AND role = $3`, ClassRoleCoTeacher, classID, ClassRoleOwner); err != nil {
+		return fmt.Errorf("failed to demote previous owner: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE class_members SET role = $1 WHERE class_id = $2 AND user_id = $3`, ClassRoleOwner, classID, newOwnerID); err != nil {
+		return fmt.Errorf("failed to set new owner: %w", err)
+	}
+	// Kolom teacher tetap diisi username owner untuk tampilan lama
+	if _, err := tx.Exec(`UPDATE classes SET teacher = $1 WHERE id = $2`, username, classID); err != nil {
+		return fmt.Errorf("failed to update class teacher: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to transfer ownership: %w", err)
+	}
+	return nil
+}
+
+func (s *ClassService) GetMembers(classID int) ([]model.ClassMember, error) {
+	query := `SELECT u.id, u.username, u.role, cm.role, cm.joined_at, u.full_name, u.identifier FROM users u
+              JOIN class_members cm ON u.id = cm.user_id
+              WHERE cm.class_id = $1
+              ORDER BY CASE cm.role WHEN 'owner' THEN 0 WHEN 'co_teacher' THEN 1 WHEN 'assistant' THEN 2 ELSE 3 END, u.username`
+	rows, err := s.DB.Query(query, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %v", err)
+	}
+	defer rows.Close()
+
+	var members []model.ClassMember
+	for rows.Next() {
+		var member model.ClassMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role, &member.ClassRole, &member.JoinedAt, &member.FullName, &member.Identifier); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %v", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating members: %v", err)
+	}
+
+	return members, nil
+}
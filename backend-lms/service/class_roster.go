@@ -0,0 +1,73 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"project/dto"
+)
+
+// GetRoster mengembalikan daftar siswa kelas beserta persentase kehadiran dan rata-rata nilai,
+// untuk diekspor ke bagian administrasi.
+func (s *ClassService) GetRoster(classID int) (*dto.ClassRoster, error) {
+	roster := &dto.ClassRoster{ClassID: classID, Students: []dto.RosterEntry{}}
+	err := s.DB.QueryRow(`
+        SELECT c.name, c.teacher, COALESCE(y.name || ' ' || t.name, '')
+        FROM classes c
+        LEFT JOIN terms t ON t.id = c.term_id
+        LEFT JOIN academic_years y ON y.id = t.academic_year_id
+        WHERE c.id = $1
+    `, classID).Scan(&roster.ClassName, &roster.Teacher, &roster.Term)
+	if err == sql.ErrNoRows {
+		return nil, ErrClassNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class: %w", err)
+	}
+
+	summaries, held, err := attendanceSummaries(s.DB, classID, nil)
+	if err != nil {
+		return nil, err
+	}
+	attendance := map[int]float64{}
+	for _, summary := range summaries {
+		attendance[summary.UserID] = summary.Percentage
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT u.id, u.username, u.full_name, u.identifier, cm.joined_at, AVG(g.grade)
+        FROM class_members cm
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN grades g ON g.class_id = cm.class_id AND g.user_id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.role = 'siswa'
+        GROUP BY u.id, u.username, u.full_name, u.identifier, cm.joined_at
+        ORDER BY NULLIF(u.full_name, '') NULLS LAST, u.full_name, u.username
+    `, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roster: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry dto.RosterEntry
+		var average sql.NullFloat64
+		if err := rows.Scan(&entry.UserID, &entry.Username, &entry.FullName, &entry.Identifier, &entry.JoinedAt, &average); err != nil {
+			return nil, fmt.Errorf("failed to scan roster: %w", err)
+		}
+		if held > 0 {
+			percentage := attendance[entry.UserID]
+			entry.AttendancePercentage = &percentage
+		}
+		if average.Valid {
+			grade := math.Round(average.Float64*10) / 10
+			entry.AverageGrade = &grade
+		}
+		roster.Students = append(roster.Students, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roster: %w", err)
+	}
+
+	return roster, nil
+}
@@ -3,22 +3,77 @@ package service
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"project/dto"
 	"project/model"
+	"strings"
 )
 
 type ClassService struct {
 	DB *sql.DB
 }
 
-func (s *ClassService) CreateClass(req dto.CreateClassRequest) (*model.Class, error) {
-	query := `INSERT INTO classes (name, jadwal_kelas, teacher, class_code) VALUES ($1, $2, $3, $4) RETURNING id, name, jadwal_kelas, created_at, teacher, class_code`
+// CreateClass membuat kelas dengan pembuatnya sebagai owner.
+func (s *ClassService) CreateClass(ownerID int, req dto.CreateClassRequest) (*model.Class, error) {
+	schedules, err := parseScheduleEntries(req.Schedules)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) > 0 {
+		req.JadwalKelas = RenderJadwalKelas(schedules)
+	} else if strings.TrimSpace(req.JadwalKelas) == "" {
+		violations := &ValidationError{}
+		violations.Add("schedules", "required", "Either schedules or jadwal_kelas is required")
+		return nil, violations
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if req.TermID != nil {
+		if err := checkTermExists(tx, *req.TermID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Kolom teacher dipertahankan untuk tampilan, default username owner.
+	// Tanpa term_id kelas masuk ke semester yang sedang aktif.
+	query := `
+        INSERT INTO classes (name, jadwal_kelas, teacher, class_code, term_id)
+        VALUES ($1, $2, COALESCE(NULLIF($3, ''), (SELECT username FROM users WHERE id = $5)), $4,
+                COALESCE($6, (SELECT id FROM terms WHERE is_active)))
+        ON CONFLICT ON CONSTRAINT classes_class_code_key DO NOTHING
+        RETURNING id, name, jadwal_kelas, created_at, teacher, class_code, term_id, archived_at
+    `
 	var class model.Class
-	err := s.DB.QueryRow(query, req.Name, req.JadwalKelas, req.Teacher, req.ClassCode).Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode)
+	// ON CONFLICT supaya kode yang bentrok tidak membatalkan transaksi
+	err = withUniqueClassCode(func(code string) error {
+		err := tx.QueryRow(query, req.Name, req.JadwalKelas, req.Teacher, code, ownerID, req.TermID).Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode, &class.TermID, &class.ArchivedAt)
+		if err == sql.ErrNoRows {
+			return errClassCodeTaken
+		}
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	_, err = tx.Exec(`INSERT INTO class_members (class_id, user_id, role) VALUES ($1, $2, $3)`, class.ID, ownerID, ClassRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add class owner: %w", err)
+	}
+
+	if len(schedules) > 0 {
+		if _, err := saveClassSchedules(tx, class.ID, []int{ownerID}, schedules, req.IgnoreConflicts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create class: %w", err)
+	}
 	return &class, nil
 }
 
@@ -42,9 +97,17 @@ func (s *ClassService) DeleteClass(id string) error {
 	return nil
 }
 
-func (s *ClassService) GetClasses() ([]model.Class, error) {
-	query := `SELECT id, name, jadwal_kelas, created_at, teacher, class_code FROM classes`
-	rows, err := s.DB.Query(query)
+// GetClasses mengembalikan daftar kelas. Admin melihat semua kelas, user lain hanya kelas yang
+// diikutinya. Kelas yang diarsipkan hanya ikut jika filter.IncludeArchived.
+func (s *ClassService) GetClasses(viewerID int, viewerRole string, filter dto.ClassListFilter) ([]model.Class, error) {
+	query := `SELECT c.id, c.name, c.jadwal_kelas, c.created_at, c.teacher, c.class_code, c.term_id, c.archived_at,
+                     COALESCE(cm.role, '')
+              FROM classes c
+              LEFT JOIN class_members cm ON cm.class_id = c.id AND cm.user_id = $3
+              WHERE ($1::INT IS NULL OR c.term_id = $1) AND ($2 OR c.archived_at IS NULL)
+                AND ($4 OR cm.user_id IS NOT NULL)
+              ORDER BY c.id`
+	rows, err := s.DB.Query(query, filter.TermID, filter.IncludeArchived, viewerID, viewerRole == "Admin")
 	if err != nil {
 		return nil, fmt.Errorf("failed to query classes: %v", err)
 	}
@@ -53,46 +116,85 @@ func (s *ClassService) GetClasses() ([]model.Class, error) {
 	var classes []model.Class
 	for rows.Next() {
 		var class model.Class
-		if err := rows.Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode); err != nil {
+		var classRole string
+		if err := rows.Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode, &class.TermID, &class.ArchivedAt, &classRole); err != nil {
 			return nil, fmt.Errorf("failed to scan class row: %v", err)
 		}
+		if !classCodeVisible(viewerRole, classRole) {
+			class.ClassCode = ""
+		}
 		classes = append(classes, class)
 	}
 
 	return classes, nil
 }
 
-func (s *ClassService) GetClassByID(classID string) (*dto.ClassResponse, error) {
-	query := `SELECT id, name, jadwal_kelas, teacher, class_code, created_at FROM classes WHERE id = $1`
+// GetClassByID mengembalikan detail kelas; class_code hanya diisi jika viewer boleh melihatnya.
+func (s *ClassService) GetClassByID(classID string, viewerID int, viewerRole string) (*dto.ClassResponse, error) {
+	query := `SELECT c.id, c.name, c.jadwal_kelas, c.teacher, c.class_code, c.created_at, c.term_id, c.archived_at,
+                     COALESCE(cm.role, '')
+              FROM classes c
+              LEFT JOIN class_members cm ON cm.class_id = c.id AND cm.user_id = $2
+              WHERE c.id = $1`
 
 	var class dto.ClassResponse
-	err := s.DB.QueryRow(query, classID).Scan(
+	var classRole string
+	err := s.DB.QueryRow(query, classID, viewerID).Scan(
 		&class.ID,
 		&class.Name,
 		&class.JadwalKelas,
 		&class.Teacher,
 		&class.ClassCode,
 		&class.CreatedAt,
+		&class.TermID,
+		&class.ArchivedAt,
+		&classRole,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	if !classCodeVisible(viewerRole, classRole) {
+		class.ClassCode = ""
+	}
 
 	return &class, nil
 }
 
+// UpdateClass mengubah data kelas. Jika schedules dikirim (termasuk []), jadwal diganti
+// setelah dicek bentrok dan jadwal_kelas dibuat ulang dari jadwal tersebut.
 func (s *ClassService) UpdateClass(classID string, req dto.UpdateClassRequest) (*dto.ClassResponse, error) {
-	query := `UPDATE classes SET name = COALESCE($1, name), jadwal_kelas = COALESCE($2, jadwal_kelas), teacher = COALESCE($3, teacher), class_code = COALESCE($4, class_code) WHERE id = $5 RETURNING id, name, jadwal_kelas, teacher, class_code, created_at`
+	var schedules []model.ClassSchedule
+	if req.Schedules != nil {
+		var err error
+		if schedules, err = parseScheduleEntries(req.Schedules); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if req.TermID != nil {
+		if err := checkTermExists(tx, *req.TermID); err != nil {
+			return nil, err
+		}
+	}
+
+	// class_code tidak bisa diubah lewat update biasa, gunakan endpoint kode kelas
+	query := `UPDATE classes SET name = COALESCE(NULLIF($1, ''), name), jadwal_kelas = COALESCE(NULLIF($2, ''), jadwal_kelas), teacher = COALESCE(NULLIF($3, ''), teacher), term_id = COALESCE($5, term_id) WHERE id = $4 RETURNING id, name, jadwal_kelas, teacher, class_code, created_at, term_id, archived_at`
 
 	var updatedClass dto.ClassResponse
-	err := s.DB.QueryRow(
+	err = tx.QueryRow(
 		query,
 		req.Name,
 		req.JadwalKelas,
 		req.Teacher,
-		req.ClassCode,
 		classID,
+		req.TermID,
 	).Scan(
 		&updatedClass.ID,
 		&updatedClass.Name,
@@ -100,6 +202,8 @@ func (s *ClassService) UpdateClass(classID string, req dto.UpdateClassRequest) (
 		&updatedClass.Teacher,
 		&updatedClass.ClassCode,
 		&updatedClass.CreatedAt,
+		&updatedClass.TermID,
+		&updatedClass.ArchivedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -108,89 +212,41 @@ func (s *ClassService) UpdateClass(classID string, req dto.UpdateClassRequest) (
 		return nil, fmt.Errorf("error updating class: %w", err)
 	}
 
-	return &updatedClass, nil
-}
-
-func (s *ClassService) JoinClass(userID int, classCode string) error {
-	var classID int
-
-	query := `SELECT id FROM classes WHERE class_code = $1`
-	err := s.DB.QueryRow(query, classCode).Scan(&classID)
-	if err != nil {
-		log.Printf("Failed to find class by class_code: %v\n", err)
-		return fmt.Errorf("class not found")
-	}
-
-	var userExists bool
-	checkUserQuery := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
-	err = s.DB.QueryRow(checkUserQuery, userID).Scan(&userExists)
-	if err != nil || !userExists {
-		log.Printf("UserID: %d not found in users table\n", userID)
-		return fmt.Errorf("user not found")
-	}
-
-	log.Printf("UserID: %d, is joining class ID: %d\n", userID, classID)
-
-	insertQuery := `INSERT INTO class_members (user_id, class_id, role) VALUES ($1, $2, $3)`
-	_, err = s.DB.Exec(insertQuery, userID, classID, "siswa")
-	if err != nil {
-		log.Printf("Failed to insert user into class_members: %v\n", err)
-		return fmt.Errorf("failed to join class")
-	}
-
-	return nil
-}
-
-func (s *ClassService) AddMember(classID, userID int) error {
-	query := `INSERT INTO class_members (class_id, user_id, role) VALUES ($1, $2, 'siswa')`
-	_, err := s.DB.Exec(query, classID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to add member: %v", err)
-	}
-	return nil
-}
-
-func (s *ClassService) RemoveMember(classID, userID int) error {
-	query := `DELETE FROM class_members WHERE class_id = $1 AND user_id = $2`
-	_, err := s.DB.Exec(query, classID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to remove member: %v", err)
-	}
-	return nil
-}
-
-func (s *ClassService) GetMembers(classID int) ([]model.User, error) {
-	query := `SELECT u.id, u.username, u.role FROM users u
-              JOIN class_members cm ON u.id = cm.user_id
-              WHERE cm.class_id = $1`
-	rows, err := s.DB.Query(query, classID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get members: %v", err)
-	}
-	defer rows.Close()
-
-	var members []model.User
-	for rows.Next() {
-		var user model.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Role); err != nil {
-			return nil, fmt.Errorf("failed to scan member: %v", err)
+	if req.Schedules != nil {
+		teacherIDs, err := classTeacherIDs(tx, updatedClass.ID)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := saveClassSchedules(tx, updatedClass.ID, teacherIDs, schedules, req.IgnoreConflicts)
+		if err != nil {
+			return nil, err
+		}
+		// Jadwal dihapus semua: jadwal_kelas lama dipertahankan
+		if rendered != "" {
+			if _, err := tx.Exec(`UPDATE classes SET jadwal_kelas = $1 WHERE id = $2`, rendered, updatedClass.ID); err != nil {
+				return nil, fmt.Errorf("error updating class: %w", err)
+			}
+			updatedClass.JadwalKelas = rendered
 		}
-		members = append(members, user)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating members: %v", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error updating class: %w", err)
 	}
 
-	return members, nil
+	return &updatedClass, nil
 }
 
-func (s *ClassService) GetClassesByStudentID(studentID int) ([]model.Class, error) {
-    query := `SELECT c.id, c.name, c.jadwal_kelas, c.created_at, c.teacher, c.class_code
+// GetClassesByStudentID mengembalikan kelas yang diikuti studentID. class_code mengikuti role
+// viewer (pemanggil) di tiap kelas, bukan role studentID.
+func (s *ClassService) GetClassesByStudentID(studentID, viewerID int, viewerRole string, includeArchived bool) ([]model.Class, error) {
+    query := `SELECT c.id, c.name, c.jadwal_kelas, c.created_at, c.teacher, c.class_code, c.term_id, c.archived_at,
+                     COALESCE(v.role, '')
               FROM classes c
               JOIN class_members cm ON c.id = cm.class_id
-              WHERE cm.user_id = $1`
-    rows, err := s.DB.Query(query, studentID)
+              LEFT JOIN class_members v ON v.class_id = c.id AND v.user_id = $3
+              WHERE cm.user_id = $1 AND ($2 OR c.archived_at IS NULL)`
+    rows, err := s.DB.Query(query, studentID, includeArchived, viewerID)
     if err != nil {
         return nil, fmt.Errorf("failed to get classes: %v", err)
     }
@@ -199,9 +255,13 @@ func (s *ClassService) GetClassesByStudentID(studentID int) ([]model.Class, erro
     var classes []model.Class
     for rows.Next() {
         var class model.Class
-        if err := rows.Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode); err != nil {
+        var viewerClassRole string
+        if err := rows.Scan(&class.ID, &class.Name, &class.JadwalKelas, &class.CreatedAt, &class.Teacher, &class.ClassCode, &class.TermID, &class.ArchivedAt, &viewerClassRole); err != nil {
             return nil, fmt.Errorf("failed to scan class: %v", err)
         }
+        if !classCodeVisible(viewerRole, viewerClassRole) {
+            class.ClassCode = ""
+        }
         classes = append(classes, class)
     }
 
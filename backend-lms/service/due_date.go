@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"project/config"
+	"project/model"
+	"strings"
+	"time"
+)
+
+// Kebijakan pengumpulan setelah due date.
+const (
+	LatePolicyAccept  = "accept"
+	LatePolicyPenalty = "penalty"
+	LatePolicyReject  = "reject"
+)
+
+var errInvalidDueDate = errors.New("invalid due date")
+
+// parseDueDate membaca due date dari form. RFC3339 memakai offset yang dikirim; waktu tanpa
+// offset dibaca di config.DefaultTimezone dan tanggal tanpa jam berakhir pukul 23:59:59.
+func parseDueDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	loc := config.DefaultLocation()
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if parsed, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &parsed, nil
+		}
+	}
+	if parsed, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		endOfDay := parsed.AddDate(0, 0, 1).Add(-time.Second)
+		return &endOfDay, nil
+	}
+	return nil, errInvalidDueDate
+}
+
+// buildDeadline memvalidasi due date dan kebijakan keterlambatan dari request tugas.
+// requireFuture dipakai saat membuat tugas; saat update due date lama yang sudah lewat boleh tetap.
+func buildDeadline(dueDate, policy string, penaltyPercent float64, requireFuture bool) (model.Deadline, error) {
+	violations := &ValidationError{}
+	deadline := model.Deadline{LatePolicy: policy, LatePenaltyPercent: penaltyPercent}
+
+	due, err := parseDueDate(dueDate)
+	if err != nil {
+		violations.Add("due_date", "invalid_format", "Due date must use RFC3339, YYYY-MM-DDTHH:MM or YYYY-MM-DD format")
+	} else if due != nil && requireFuture && due.Before(time.Now()) {
+		violations.Add("due_date", "in_past", "Due date must be in the future")
+	}
+	deadline.DueDate = due
+
+	switch policy {
+	case LatePolicyAccept, LatePolicyReject:
+		deadline.LatePenaltyPercent = 0
+	case LatePolicyPenalty:
+		if penaltyPercent <= 0 || penaltyPercent > 100 {
+			violations.Add("late_penalty_percent", "out_of_range", "Late penalty must be greater than 0 and at most 100 percent per day")
+		}
+	default:
+		violations.Add("late_policy", "invalid", "Late policy must be accept, penalty or reject")
+	}
+
+	return deadline, violations.OrNil()
+}
+
+// fillRemaining mengisi sisa waktu menuju due date relatif terhadap now.
+func fillRemaining(deadline *model.Deadline, now time.Time) {
+	deadline.RemainingSeconds = nil
+	deadline.IsOverdue = false
+	if deadline.DueDate == nil {
+		return
+	}
+	remaining := int64(deadline.DueDate.Sub(now) / time.Second)
+	if remaining < 0 {
+		remaining = 0
+		deadline.IsOverdue = true
+	}
+	deadline.RemainingSeconds = &remaining
+}
+
+// lateness menghitung hari keterlambatan (dibulatkan ke atas) dan potongan nilai untuk
+// pengumpulan pada waktu submittedAt.
+func lateness(deadline model.Deadline, submittedAt time.Time) (int, float64) {
+	if deadline.DueDate == nil || !submittedAt.After(*deadline.DueDate) {
+		return 0, 0
+	}
+	days := int(math.Ceil(submittedAt.Sub(*deadline.DueDate).Hours() / 24))
+	if deadline.LatePolicy != LatePolicyPenalty {
+		return days, 0
+	}
+	return days, math.Min(100, float64(days)*deadline.LatePenaltyPercent)
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+)
+
+// Status pengajuan izin.
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+const maxLeaveDays = 30
+
+var (
+	ErrLeaveRequestNotFound = errors.New("leave request not found")
+	ErrLeaveNotReviewable   = errors.New("leave request does not belong to this class")
+)
+
+type LeaveService struct {
+	DB *sql.DB
+}
+
+func NewLeaveService(db *sql.DB) *LeaveService {
+	return &LeaveService{DB: db}
+}
+
+// CreateLeaveRequest membuat pengajuan izin/sakit. Tanpa class_id pengajuan berlaku untuk
+// semua kelas siswa, sehingga siswa harus punya kelas perwalian yang akan meninjaunya.
+func (s *LeaveService) CreateLeaveRequest(userID int, req dto.CreateLeaveRequest) (*model.LeaveRequest, error) {
+	violations := &ValidationError{}
+	start, startErr := time.Parse("2006-01-02", strings.TrimSpace(req.StartDate))
+	end, endErr := time.Parse("2006-01-02", strings.TrimSpace(req.EndDate))
+	if startErr != nil {
+		violations.Add("start_date", "invalid_format", "Start date must use YYYY-MM-DD format")
+	}
+	if endErr != nil {
+		violations.Add("end_date", "invalid_format", "End date must use YYYY-MM-DD format")
+	}
+	if startErr == nil && endErr == nil {
+		if end.Before(start) {
+			violations.Add("end_date", "before_start", "End date must not be before start date")
+		} else if days := int(end.Sub(start).Hours()/24) + 1; days > maxLeaveDays {
+			violations.Add("end_date", "out_of_range", fmt.Sprintf("Leave must not exceed %d days", maxLeaveDays))
+		}
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		violations.Add("reason", "required", "Reason is required")
+	}
+
+	if req.ClassID != nil {
+		var role string
+		err := s.DB.QueryRow(`SELECT role FROM class_members WHERE class_id = $1 AND user_id = $2`, *req.ClassID, userID).Scan(&role)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to check membership: %w", err)
+		}
+		if role != ClassRoleStudent {
+			violations.Add("class_id", "not_student", "You are not a student of this class")
+		}
+	} else {
+		var hasHomeroom bool
+		err := s.DB.QueryRow(`
+            SELECT EXISTS (
+                SELECT 1 FROM class_members cm JOIN classes c ON c.id = cm.class_id
+                WHERE cm.user_id = $1 AND cm.role = 'siswa' AND c.is_homeroom AND c.archived_at IS NULL
+            )
+        `, userID).Scan(&hasHomeroom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check homeroom class: %w", err)
+		}
+		if !hasHomeroom {
+			violations.Add("class_id", "required", "You have no homeroom class, choose the class this leave is for")
+		}
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	var attachment *string
+	if req.AttachmentURL != "" {
+		attachment = &req.AttachmentURL
+	}
+	var id int
+	err := s.DB.QueryRow(`
+        INSERT INTO leave_requests (user_id, class_id, type, start_date, end_date, reason, attachment_url)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `, userID, req.ClassID, req.Type, start.Format("2006-01-02"), end.Format("2006-01-02"), reason, attachment).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create leave request: %w", err)
+	}
+	return getLeaveRequest(s.DB, id)
+}
+
+// GetMyLeaveRequests mengembalikan pengajuan izin milik user, terbaru dulu.
+func (s *LeaveService) GetMyLeaveRequests(userID int) ([]model.LeaveRequest, error) {
+	return queryLeaveRequests(s.DB, leaveRequestSelect+` WHERE lr.user_id = $1 ORDER BY lr.created_at DESC`, userID)
+}
+
+// CancelLeaveRequest membatalkan pengajuan milik user yang belum ditinjau.
+func (s *LeaveService) CancelLeaveRequest(userID, requestID int) error {
+	result, err := s.DB.Exec(`
+        UPDATE leave_requests SET status = $1 WHERE id = $2 AND user_id = $3 AND status = $4
+    `, LeaveStatusCancelled, requestID, userID, LeaveStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to cancel leave request: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLeaveRequestNotFound
+	}
+	return nil
+}
+
+// GetClassLeaveRequests mengembalikan pengajuan untuk kelas ini, ditambah pengajuan tanpa
+// kelas dari siswanya jika kelas ini kelas perwalian. Default hanya yang pending.
+func (s *LeaveService) GetClassLeaveRequests(classID int, status string) ([]model.LeaveRequest, error) {
+	if status == "" {
+		status = LeaveStatusPending
+	}
+	return queryLeaveRequests(s.DB, leaveRequestSelect+`
+        WHERE `+leaveRequestInClass+` AND ($2 = 'all' OR lr.status = $2)
+        ORDER BY lr.start_date, lr.created_at
+    `, classID, status)
+}
+
+// ReviewLeaveRequest menyetujui atau menolak pengajuan pending. Pengajuan yang disetujui
+// langsung mengisi absensi pertemuan di rentang tanggalnya dengan status izin/sakit,
+// kecuali pertemuan yang sudah tercatat hadir.
+func (s *LeaveService) ReviewLeaveRequest(classID, requestID, reviewerID int, req dto.ReviewLeaveRequest) (*dto.ReviewLeaveResponse, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var inClass bool
+	err = tx.QueryRow(`
+        SELECT `+leaveRequestInClass+` FROM leave_requests lr WHERE lr.id = $2 FOR UPDATE
+    `, classID, requestID).Scan(&inClass)
+	if err == sql.ErrNoRows {
+		return nil, ErrLeaveRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave request: %w", err)
+	}
+	if !inClass {
+		return nil, ErrLeaveNotReviewable
+	}
+
+	status := LeaveStatusRejected
+	if req.Approve {
+		status = LeaveStatusApproved
+	}
+	result, err := tx.Exec(`
+        UPDATE leave_requests SET status = $1, review_note = $2, reviewed_by = $3, reviewed_at = NOW()
+        WHERE id = $4 AND status = 'pending'
+    `, status, strings.TrimSpace(req.Note), reviewerID, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review leave request: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrLeaveRequestNotFound
+	}
+
+	request, err := getLeaveRequest(tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.ReviewLeaveResponse{Request: request}
+	if req.Approve {
+		if response.MarkedSessions, err = markLeaveAttendance(tx, request); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to review leave request: %w", err)
+	}
+	return response, nil
+}
+
+// SetHomeroom menandai kelas sebagai kelas perwalian.
+func (s *LeaveService) SetHomeroom(classID int, isHomeroom bool) error {
+	result, err := s.DB.Exec(`UPDATE classes SET is_homeroom = $1 WHERE id = $2`, isHomeroom, classID)
+	if err != nil {
+		return fmt.Errorf("failed to update homeroom: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrClassNotFound
+	}
+	return nil
+}
+
+// markLeaveAttendance mengisi absensi pertemuan yang sudah ada untuk izin yang disetujui.
+func markLeaveAttendance(q dbExecutor, request *model.LeaveRequest) (int, error) {
+	result, err := q.Exec(`
+        INSERT INTO attendance_records (session_id, user_id, status, method, note, marked_by)
+        SELECT s.id, $1, $2, 'manual', $3, $4
+        FROM class_sessions s
+        JOIN class_members cm ON cm.class_id = s.class_id AND cm.user_id = $1 AND cm.role = 'siswa'
+        WHERE s.date BETWEEN $5 AND $6 AND ($7::INT IS NULL OR s.class_id = $7)
+        ON CONFLICT (session_id, user_id) DO UPDATE
+        SET status = EXCLUDED.status, method = EXCLUDED.method, note = EXCLUDED.note,
+            marked_by = EXCLUDED.marked_by, marked_at = NOW()
+        WHERE attendance_records.status <> 'hadir'
+    `, request.UserID, request.Type, leaveAttendanceNote(request.ID), request.ReviewedBy,
+		request.StartDate, request.EndDate, request.ClassID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark attendance for leave: %w", err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	return int(marked), nil
+}
+
+// applyApprovedLeaves mengisi absensi pertemuan baru untuk siswa yang izinnya sudah disetujui.
+func applyApprovedLeaves(q dbExecutor, sessionID int) error {
+	_, err := q.Exec(`
+        INSERT INTO attendance_records (session_id, user_id, status, method, note, marked_by)
+        SELECT s.id, lr.user_id, lr.type, 'manual', 'leave request #' || lr.id, lr.reviewed_by
+        FROM class_sessions s
+        JOIN class_members cm ON cm.class_id = s.class_id AND cm.role = 'siswa'
+        JOIN leave_requests lr ON lr.user_id = cm.user_id AND lr.status = 'approved'
+            AND s.date BETWEEN lr.start_date AND lr.end_date
+            AND (lr.class_id IS NULL OR lr.class_id = s.class_id)
+        WHERE s.id = $1
+        ON CONFLICT (session_id, user_id) DO NOTHING
+    `, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to apply approved leave requests: %w", err)
+	}
+	return nil
+}
+
+func leaveAttendanceNote(requestID int) string {
+	return fmt.Sprintf("leave request #%d", requestID)
+}
+
+// leaveRequestInClass: pengajuan untuk kelas $1, atau pengajuan tanpa kelas dari siswa
+// kelas $1 jika kelas itu kelas perwalian.
+const leaveRequestInClass = `(lr.class_id = $1 OR (lr.class_id IS NULL AND EXISTS (
+        SELECT 1 FROM classes c JOIN class_members cm ON cm.class_id = c.id
+        WHERE c.id = $1 AND c.is_homeroom AND cm.user_id = lr.user_id AND cm.role = 'siswa'
+    )))`
+
+const leaveRequestSelect = `
+    SELECT lr.id, lr.user_id, u.username, lr.class_id, lr.type, to_char(lr.start_date, 'YYYY-MM-DD'),
+           to_char(lr.end_date, 'YYYY-MM-DD'), lr.reason, lr.attachment_url, lr.status, lr.review_note,
+           lr.reviewed_by, lr.reviewed_at, lr.created_at
+    FROM leave_requests lr
+    JOIN users u ON u.id = lr.user_id
+`
+
+func getLeaveRequest(q dbExecutor, requestID int) (*model.LeaveRequest, error) {
+	request, err := scanLeaveRequest(q.QueryRow(leaveRequestSelect+` WHERE lr.id = $1`, requestID))
+	if err == sql.ErrNoRows {
+		return nil, ErrLeaveRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave request: %w", err)
+	}
+	return request, nil
+}
+
+func queryLeaveRequests(q dbExecutor, query string, args ...interface{}) ([]model.LeaveRequest, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leave requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []model.LeaveRequest{}
+	for rows.Next() {
+		request, err := scanLeaveRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan leave request: %w", err)
+		}
+		requests = append(requests, *request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leave requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func scanLeaveRequest(row rowScanner) (*model.LeaveRequest, error) {
+	var request model.LeaveRequest
+	err := row.Scan(&request.ID, &request.UserID, &request.Username, &request.ClassID, &request.Type, &request.StartDate,
+		&request.EndDate, &request.Reason, &request.AttachmentURL, &request.Status, &request.ReviewNote,
+		&request.ReviewedBy, &request.ReviewedAt, &request.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
@@ -12,10 +12,21 @@ type MaterialService struct {
 	DB *sql.DB
 }
 
+// CreateMaterial - Materi baru ditempatkan di akhir unit (atau di akhir daftar tanpa unit)
 func (s *MaterialService) CreateMaterial(req dto.CreateMaterialRequest) (*model.Material, error) {
-    query := `INSERT INTO materials (title, content, class_id, attachment) VALUES ($1, $2, $3, $4) RETURNING id, title, content, class_id, attachment, created_at`
+    if req.UnitID != nil {
+        if err := checkUnitInClass(s.DB, req.ClassID, *req.UnitID); err != nil {
+            return nil, err
+        }
+    }
+    position, err := nextItemPosition(s.DB, req.ClassID, req.UnitID)
+    if err != nil {
+        return nil, err
+    }
+
+    query := `INSERT INTO materials (title, content, class_id, attachment, unit_id, position) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, title, content, class_id, attachment, created_at, unit_id, position`
     var material model.Material
-    err := s.DB.QueryRow(query, req.Title, req.Content, req.ClassID, req.Attachment).Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.Attachment, &material.CreatedAt)
+    err = s.DB.QueryRow(query, req.Title, req.Content, req.ClassID, req.Attachment, req.UnitID, position).Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.Attachment, &material.CreatedAt, &material.UnitID, &material.Position)
     if err != nil {
         return nil, err
     }
@@ -23,9 +34,9 @@ func (s *MaterialService) CreateMaterial(req dto.CreateMaterialRequest) (*model.
 }
 
 
-func (s *MaterialService) DeleteMaterial(id string) error {
-	query := `DELETE FROM materials WHERE id = $1`
-	result, err := s.DB.Exec(query, id)
+func (s *MaterialService) DeleteMaterial(classID, id string) error {
+	query := `DELETE FROM materials WHERE id = $1 AND class_id = $2`
+	result, err := s.DB.Exec(query, id, classID)
 	if err != nil {
 		return fmt.Errorf("failed to delete material: %w", err)
 	}
@@ -43,7 +54,10 @@ func (s *MaterialService) DeleteMaterial(id string) error {
 }
 
 func (s *MaterialService) GetMaterialsByClass(classID string) ([]model.Material, error) {
-    query := `SELECT id, title, content, class_id, created_at, attachment FROM materials WHERE class_id = $1`
+    query := `SELECT m.id, m.title, m.content, m.class_id, m.created_at, m.attachment, m.unit_id, m.position FROM materials m
+              LEFT JOIN class_units u ON u.id = m.unit_id
+              WHERE m.class_id = $1
+              ORDER BY u.position NULLS LAST, m.unit_id, m.position, m.id`
     rows, err := s.DB.Query(query, classID)
     if err != nil {
         return nil, fmt.Errorf("failed to fetch materials: %w", err)
@@ -53,7 +67,7 @@ func (s *MaterialService) GetMaterialsByClass(classID string) ([]model.Material,
     var materials []model.Material
     for rows.Next() {
         var material model.Material
-        if err := rows.Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.CreatedAt, &material.Attachment); err != nil {
+        if err := rows.Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.CreatedAt, &material.Attachment, &material.UnitID, &material.Position); err != nil {
             return nil, fmt.Errorf("failed to scan material: %w", err)
         }
         materials = append(materials, material)
@@ -66,8 +80,12 @@ func (s *MaterialService) GetMaterialsByClass(classID string) ([]model.Material,
     return materials, nil
 }
 
+// GetMaterials - Urut sesuai outline kelas: per unit, lalu posisi di dalam unit
 func (s *MaterialService) GetMaterials(classID string) ([]model.Material, error) {
-    query := `SELECT id, title, content, class_id, created_at, attachment FROM materials WHERE class_id = $1`
+    query := `SELECT m.id, m.title, m.content, m.class_id, m.created_at, m.attachment, m.unit_id, m.position FROM materials m
+              LEFT JOIN class_units u ON u.id = m.unit_id
+              WHERE m.class_id = $1
+              ORDER BY u.position NULLS LAST, m.unit_id, m.position, m.id`
     rows, err := s.DB.Query(query, classID)
     if err != nil {
         return nil, fmt.Errorf("failed to query materials: %v", err)
@@ -77,7 +95,7 @@ func (s *MaterialService) GetMaterials(classID string) ([]model.Material, error)
     var materials []model.Material
     for rows.Next() {
         var material model.Material
-        if err := rows.Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.CreatedAt, &material.Attachment); err != nil {
+        if err := rows.Scan(&material.ID, &material.Title, &material.Content, &material.ClassID, &material.CreatedAt, &material.Attachment, &material.UnitID, &material.Position); err != nil {
             return nil, fmt.Errorf("failed to scan material row: %v", err)
         }
         materials = append(materials, material)
@@ -95,7 +113,7 @@ func (s *MaterialService) UpdateMaterial(classID, materialID int, req dto.Update
         UPDATE materials
         SET title = $1, content = $2, attachment = $3, created_at = NOW()
         WHERE id = $4 AND class_id = $5
-        RETURNING id, title, content, class_id, attachment, created_at
+        RETURNING id, title, content, class_id, attachment, created_at, unit_id, position
     `
 
     var material model.Material
@@ -106,6 +124,8 @@ func (s *MaterialService) UpdateMaterial(classID, materialID int, req dto.Update
         &material.ClassID,
         &material.Attachment,
         &material.CreatedAt,
+        &material.UnitID,
+        &material.Position,
     )
     if err != nil {
         return nil, err
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"project/config"
+	"project/model"
+	"project/utils"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	recoveryCodeCount = 10
+	// Jumlah percobaan kode login yang boleh gagal sebelum verifikasi user dikunci.
+	maxChallengeAttempts = 5
+	// Lama kunci pertama; berlipat dua setiap kali terkunci lagi, paling lama 64 kali lipat.
+	mfaLockoutBase = time.Minute
+)
+
+var (
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not enrolled")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrTooManyMFAAttempts = errors.New("too many invalid two-factor codes, please try again later")
+)
+
+type MFAService struct {
+	DB *sql.DB
+}
+
+func NewMFAService(db *sql.DB) *MFAService {
+	return &MFAService{DB: db}
+}
+
+// BeginEnrollment membuat secret TOTP baru (belum aktif) untuk user. Enrollment yang
+// belum dikonfirmasi akan ditimpa, tetapi MFA yang sudah aktif harus dinonaktifkan dulu.
+func (s *MFAService) BeginEnrollment(userID int, username string) (*model.MFAEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        INSERT INTO user_mfa (user_id, totp_secret, enabled, last_used_step, created_at)
+        VALUES ($1, $2, FALSE, 0, NOW())
+        ON CONFLICT (user_id) DO UPDATE
+        SET totp_secret = EXCLUDED.totp_secret, last_used_step = 0, created_at = NOW()
+        WHERE user_mfa.enabled = FALSE
+    `
+	result, err := s.DB.Exec(query, userID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	return &model.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(config.MFAIssuer, username, secret),
+	}, nil
+}
+
+// QRCode merender provisioning URI enrollment yang sedang berjalan sebagai PNG.
+func (s *MFAService) QRCode(userID int, username string) ([]byte, error) {
+	mfa, err := s.getMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	uri := utils.TOTPProvisioningURI(config.MFAIssuer, username, mfa.Secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return png, nil
+}
+
+// ConfirmEnrollment mengaktifkan MFA jika kode dari authenticator valid, lalu
+// mengembalikan recovery codes dalam bentuk plain text (hanya ditampilkan sekali).
+func (s *MFAService) ConfirmEnrollment(userID int, code string) ([]string, error) {
+	mfa, err := s.getMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	ok, step := utils.ValidateTOTP(mfa.Secret, code, time.Now(), 1)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE user_mfa SET enabled = TRUE, confirmed_at = NOW(), last_used_step = $1 WHERE user_id = $2`, step, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes membatalkan recovery codes lama dan membuat yang baru.
+func (s *MFAService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return codes, nil
+}
+
+// Disable mematikan MFA setelah user membuktikan kepemilikan dengan kode valid.
+func (s *MFAService) Disable(userID int, code string) error {
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return tx.Commit()
+}
+
+// IsEnabled mengecek apakah user sudah menyelesaikan enrollment MFA.
+func (s *MFAService) IsEnabled(userID int) (bool, error) {
+	var enabled bool
+	err := s.DB.QueryRow(`SELECT enabled FROM user_mfa WHERE user_id = $1`, userID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check two-factor status: %w", err)
+	}
+	return enabled, nil
+}
+
+// Verify menerima kode TOTP 6 digit atau recovery code. Kode TOTP yang sudah
+// dipakai dan recovery code yang sudah dipakai akan ditolak.
+func (s *MFAService) Verify(userID int, code string) error {
+	mfa, err := s.getMFA(userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	code = strings.TrimSpace(code)
+	if ok, step := utils.ValidateTOTP(mfa.Secret, code, time.Now(), 1); ok {
+		result, err := s.DB.Exec(`UPDATE user_mfa SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1`, step, userID)
+		if err != nil {
+			return fmt.Errorf("failed to record TOTP usage: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			// Kode untuk step ini sudah pernah dipakai
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	result, err := s.DB.Exec(
+		`UPDATE mfa_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, hashRecoveryCode(code),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 1 {
+		return nil
+	}
+	return ErrInvalidMFACode
+}
+
+// VerifyChallenge sama seperti Verify untuk langkah kedua login, tetapi membatasi percobaan
+// gagal per user di database. Percobaan dihitung sebelum kode dicek, jadi request paralel tidak
+// bisa melewati batas; kode yang benar mereset hitungannya.
+func (s *MFAService) VerifyChallenge(userID int, code string) error {
+	mfa, err := s.getMFA(userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	result, err := s.DB.Exec(`
+        UPDATE user_mfa SET failed_attempts = failed_attempts + 1,
+            locked_until = CASE WHEN (failed_attempts + 1) % $2 = 0
+                THEN NOW() + make_interval(secs => $3 * power(2, LEAST((failed_attempts + 1) / $2 - 1, 6)))
+                ELSE locked_until END
+        WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= NOW())
+    `, userID, maxChallengeAttempts, mfaLockoutBase.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record two-factor attempt: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrTooManyMFAAttempts
+	}
+
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+	if _, err := s.DB.Exec(`UPDATE user_mfa SET failed_attempts = 0, locked_until = NULL WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to reset two-factor attempts: %w", err)
+	}
+	return nil
+}
+
+// IsRequiredForRole mengecek policy MFA yang diatur admin untuk sebuah role.
+func (s *MFAService) IsRequiredForRole(role string) (bool, error) {
+	var required bool
+	err := s.DB.QueryRow(`SELECT required FROM mfa_role_policies WHERE role = $1`, role).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get MFA policy: %w", err)
+	}
+	return required, nil
+}
+
+func (s *MFAService) GetRolePolicies() ([]model.MFARolePolicy, error) {
+	rows, err := s.DB.Query(`SELECT role, required, updated_at FROM mfa_role_policies ORDER BY role`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query MFA policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []model.MFARolePolicy
+	for rows.Next() {
+		var policy model.MFARolePolicy
+		if err := rows.Scan(&policy.Role, &policy.Required, &policy.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan MFA policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating MFA policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (s *MFAService) SetRolePolicy(role string, required bool) (*model.MFARolePolicy, error) {
+	query := `
+        INSERT INTO mfa_role_policies (role, required, updated_at) VALUES ($1, $2, NOW())
+        ON CONFLICT (role) DO UPDATE SET required = EXCLUDED.required, updated_at = NOW()
+        RETURNING role, required, updated_at
+    `
+	var policy model.MFARolePolicy
+	if err := s.DB.QueryRow(query, role, required).Scan(&policy.Role, &policy.Required, &policy.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to update MFA policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (s *MFAService) getMFA(userID int) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	err := s.DB.QueryRow(
+		`SELECT user_id, totp_secret, enabled, last_used_step, created_at, confirmed_at FROM user_mfa WHERE user_id = $1`,
+		userID,
+	).Scan(&mfa.UserID, &mfa.Secret, &mfa.Enabled, &mfa.LastUsedStep, &mfa.CreatedAt, &mfa.ConfirmedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor settings: %w", err)
+	}
+	return &mfa, nil
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int) ([]string, error) {
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hashRecoveryCode(code)); err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode membuat kode dengan format xxxxx-xxxxx (50 bit entropi).
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 7)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// Recovery code memiliki entropi tinggi sehingga SHA-256 cukup untuk penyimpanannya.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
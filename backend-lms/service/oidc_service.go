@@ -0,0 +1,611 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"project/config"
+	"project/dto"
+	"project/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// OIDCStateTTL juga dipakai handler sebagai umur cookie state.
+	OIDCStateTTL  = 10 * time.Minute
+	oidcCacheTTL  = time.Hour
+	oidcClockSkew = time.Minute
+)
+
+var (
+	ErrOIDCDisabled     = errors.New("single sign-on is not configured")
+	ErrOIDCInvalidState = errors.New("invalid or expired login state")
+	// ErrOIDCIdentityInUse: identitas IdP sudah tertaut ke user lokal lain.
+	ErrOIDCIdentityInUse = errors.New("this identity provider account is already linked to another user")
+	// ErrOIDCInvalidLink: link code tidak dikenal, kedaluwarsa, atau milik user lain.
+	ErrOIDCInvalidLink = errors.New("invalid or expired identity link")
+)
+
+// OIDCConfig berisi pengaturan client OpenID Connect.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	// RoleMapping memetakan nama group IdP ke role studymate.
+	RoleMapping map[string]string
+	DefaultRole string
+}
+
+// OIDCConfigFromEnv membaca OIDCConfig dari package config.
+func OIDCConfigFromEnv() OIDCConfig {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(config.OIDCRoleMapping, ",") {
+		group, role, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || group == "" || role == "" {
+			continue
+		}
+		mapping[strings.TrimSpace(group)] = strings.TrimSpace(role)
+	}
+
+	return OIDCConfig{
+		Issuer:       strings.TrimSuffix(config.OIDCIssuer, "/"),
+		ClientID:     config.OIDCClientID,
+		ClientSecret: config.OIDCClientSecret,
+		RedirectURL:  config.OIDCRedirectURL,
+		Scopes:       strings.Fields(config.OIDCScopes),
+		GroupsClaim:  config.OIDCGroupsClaim,
+		RoleMapping:  mapping,
+		DefaultRole:  config.OIDCDefaultRole,
+	}
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcPendingLogin disimpan per state selama user berada di halaman login IdP.
+// linkUserID diisi jika flow dimulai oleh user yang sedang login untuk menautkan akunnya.
+type oidcPendingLogin struct {
+	codeVerifier string
+	nonce        string
+	linkUserID   int
+	expiresAt    time.Time
+}
+
+// oidcPendingLink menyimpan identitas hasil callback penautan sampai user yang memulai flow
+// mengonfirmasinya dengan sesi login-nya sendiri.
+type oidcPendingLink struct {
+	userID    int
+	claims    *oidcIDTokenClaims
+	expiresAt time.Time
+}
+
+// OIDCCallbackResult: Login diisi untuk login SSO, LinkCode untuk flow penautan akun.
+type OIDCCallbackResult struct {
+	Login    *dto.LoginResponse
+	LinkCode string
+}
+
+type oidcIDTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// OIDCService menjalankan authorization code flow dengan PKCE terhadap identity provider
+// sekolah, lalu menautkan atau membuat user lokal dan menerbitkan JWT studymate biasa.
+type OIDCService struct {
+	DB         *sql.DB
+	Auth       *AuthService
+	Config     OIDCConfig
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	pending     map[string]oidcPendingLogin
+	links       map[string]oidcPendingLink
+	discovery   *oidcDiscovery
+	keys        *utils.JSONWebKeySet
+	cachedUntil time.Time
+}
+
+func NewOIDCService(db *sql.DB, auth *AuthService, cfg OIDCConfig) *OIDCService {
+	return &OIDCService{
+		DB:         db,
+		Auth:       auth,
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		pending:    make(map[string]oidcPendingLogin),
+		links:      make(map[string]oidcPendingLink),
+	}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.Config.Issuer != "" && s.Config.ClientID != ""
+}
+
+// AuthorizationURL membuat URL login IdP beserta state, nonce dan PKCE code challenge (S256).
+// State juga dikembalikan supaya handler bisa mengikatnya ke browser lewat cookie
+// (OIDCStateHash). linkUserID bukan 0 berarti callback menyiapkan penautan identitas IdP ke
+// user tersebut, bukan login.
+func (s *OIDCService) AuthorizationURL(linkUserID int) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", ErrOIDCDisabled
+	}
+
+	discovery, err := s.getDiscovery()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomURLSafe(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomURLSafe(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomURLSafe(48)
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	s.mu.Lock()
+	now := time.Now()
+	for key, login := range s.pending {
+		if now.After(login.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	for key, link := range s.links {
+		if now.After(link.expiresAt) {
+			delete(s.links, key)
+		}
+	}
+	s.pending[state] = oidcPendingLogin{codeVerifier: verifier, nonce: nonce, linkUserID: linkUserID, expiresAt: now.Add(OIDCStateTTL)}
+	s.mu.Unlock()
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", s.Config.ClientID)
+	params.Set("redirect_uri", s.Config.RedirectURL)
+	params.Set("scope", strings.Join(s.Config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), state, nil
+}
+
+// OIDCStateHash adalah nilai cookie yang mengikat state ke browser yang memulai flow.
+func OIDCStateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// HandleCallback menukar authorization code dengan token dan memverifikasi ID token. stateCookie
+// harus berisi OIDCStateHash(state) dari browser yang memulai flow. Untuk login, hasilnya sama
+// dengan login password (termasuk langkah 2FA); untuk flow penautan, identitas IdP belum
+// ditautkan dan hasilnya link code yang harus dikonfirmasi lewat ConfirmLink.
+func (s *OIDCService) HandleCallback(state, stateCookie, code string, client dto.ClientInfo) (*OIDCCallbackResult, error) {
+	login, claims, rawClaims, err := s.authenticate(state, stateCookie, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if login.linkUserID != 0 {
+		linkCode, err := randomURLSafe(24)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.links[linkCode] = oidcPendingLink{userID: login.linkUserID, claims: claims, expiresAt: time.Now().Add(OIDCStateTTL)}
+		s.mu.Unlock()
+		return &OIDCCallbackResult{LinkCode: linkCode}, nil
+	}
+
+	userID, username, role, err := s.linkOrProvision(claims, s.mapRole(rawClaims), client)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.Auth.completeLogin(userID, username, role, LoginMethodOIDC, client)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCCallbackResult{Login: response}, nil
+}
+
+// ConfirmLink menautkan identitas dari callback penautan. userID adalah user dari sesi yang
+// mengonfirmasi dan harus sama dengan user yang memulai flow, jadi link code yang bocor atau
+// callback yang diselesaikan di browser orang lain tidak bisa dipakai.
+func (s *OIDCService) ConfirmLink(userID int, linkCode string) error {
+	s.mu.Lock()
+	link, found := s.links[linkCode]
+	delete(s.links, linkCode)
+	s.mu.Unlock()
+	if !found || time.Now().After(link.expiresAt) || link.userID != userID {
+		return ErrOIDCInvalidLink
+	}
+	return s.linkIdentity(userID, link.claims)
+}
+
+// authenticate mengecek state dan cookie browser-nya, menukar code (dengan PKCE verifier) dan
+// memverifikasi ID token.
+func (s *OIDCService) authenticate(state, stateCookie, code string) (oidcPendingLogin, *oidcIDTokenClaims, map[string]interface{}, error) {
+	if !s.Enabled() {
+		return oidcPendingLogin{}, nil, nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	login, found := s.pending[state]
+	delete(s.pending, state)
+	s.mu.Unlock()
+	if !found || time.Now().After(login.expiresAt) {
+		return oidcPendingLogin{}, nil, nil, ErrOIDCInvalidState
+	}
+	if subtle.ConstantTimeCompare([]byte(stateCookie), []byte(OIDCStateHash(state))) != 1 {
+		return oidcPendingLogin{}, nil, nil, ErrOIDCInvalidState
+	}
+
+	rawIDToken, err := s.exchangeCode(code, login.codeVerifier)
+	if err != nil {
+		return oidcPendingLogin{}, nil, nil, err
+	}
+
+	claims, rawClaims, err := s.verifyIDToken(rawIDToken, login.nonce)
+	if err != nil {
+		return oidcPendingLogin{}, nil, nil, err
+	}
+	return login, claims, rawClaims, nil
+}
+
+func (s *OIDCService) exchangeCode(code, verifier string) (string, error) {
+	discovery, err := s.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.Config.RedirectURL)
+	form.Set("client_id", s.Config.ClientID)
+	form.Set("code_verifier", verifier)
+	if s.Config.ClientSecret != "" {
+		form.Set("client_secret", s.Config.ClientSecret)
+	}
+
+	resp, err := s.HTTPClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return "", errors.New("token response does not contain an id_token")
+	}
+	return tokenResponse.IDToken, nil
+}
+
+// verifyIDToken mengecek signature (lewat JWKS IdP), issuer, audience, expiry dan nonce.
+func (s *OIDCService) verifyIDToken(rawIDToken, nonce string) (*oidcIDTokenClaims, map[string]interface{}, error) {
+	discovery, err := s.getDiscovery()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims := &oidcIDTokenClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+	token, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.publicKey(kid)
+	})
+	if err != nil && !isOnlyClockSkew(err, claims) {
+		return nil, nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if token == nil {
+		return nil, nil, errors.New("invalid id_token")
+	}
+
+	if claims.Issuer != discovery.Issuer {
+		return nil, nil, fmt.Errorf("unexpected id_token issuer %q", claims.Issuer)
+	}
+	if !claims.VerifyAudience(s.Config.ClientID, true) {
+		return nil, nil, errors.New("id_token audience does not match client id")
+	}
+	if claims.Nonce != nonce {
+		return nil, nil, errors.New("id_token nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, nil, errors.New("id_token has no subject")
+	}
+
+	// Claim group bisa berbeda-beda antar IdP, jadi dibaca dari payload mentah
+	rawClaims := make(map[string]interface{})
+	parts := strings.Split(rawIDToken, ".")
+	if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+		json.Unmarshal(payload, &rawClaims)
+	}
+
+	return claims, rawClaims, nil
+}
+
+// isOnlyClockSkew mentoleransi selisih jam kecil antara server dan IdP untuk iat/nbf/exp.
+func isOnlyClockSkew(err error, claims *oidcIDTokenClaims) bool {
+	validationErr, ok := err.(*jwt.ValidationError)
+	if !ok || validationErr.Errors&^(jwt.ValidationErrorExpired|jwt.ValidationErrorIssuedAt|jwt.ValidationErrorNotValidYet) != 0 {
+		return false
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt != nil && now.Add(-oidcClockSkew).After(claims.ExpiresAt.Time) {
+		return false
+	}
+	if claims.NotBefore != nil && now.Add(oidcClockSkew).Before(claims.NotBefore.Time) {
+		return false
+	}
+	if claims.IssuedAt != nil && now.Add(oidcClockSkew).Before(claims.IssuedAt.Time) {
+		return false
+	}
+	return true
+}
+
+// mapRole memilih role dengan prioritas tertinggi dari group yang dimiliki user.
+func (s *OIDCService) mapRole(rawClaims map[string]interface{}) string {
+	var groups []string
+	switch value := rawClaims[s.Config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	case string:
+		groups = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	}
+
+	priority := map[string]int{"Admin": 3, "Guru": 2, "Siswa": 1}
+	role := ""
+	for _, group := range groups {
+		mapped, ok := s.Config.RoleMapping[strings.TrimPrefix(group, "/")]
+		if !ok {
+			continue
+		}
+		if role == "" || priority[mapped] > priority[role] {
+			role = mapped
+		}
+	}
+	return role
+}
+
+// linkOrProvision mencari user lewat identitas yang sudah tertaut, atau membuat user baru.
+// User lokal yang sudah ada hanya bisa dipakai lewat penautan eksplisit (LinkIdentity), bukan
+// lewat username/email dari IdP. Role dari group IdP selalu diterapkan jika ada group yang terpetakan.
+func (s *OIDCService) linkOrProvision(claims *oidcIDTokenClaims, mappedRole string, client dto.ClientInfo) (int, string, string, error) {
+	var userID int
+	var username, role string
+
+	err := s.DB.QueryRow(`
+        SELECT u.id, u.username, u.role FROM user_identities ui
+        JOIN users u ON u.id = ui.user_id
+        WHERE ui.issuer = $1 AND ui.subject = $2
+    `, claims.Issuer, claims.Subject).Scan(&userID, &username, &role)
+
+	if err == sql.ErrNoRows {
+		userID, username, role, err = s.provisionUser(claims, mappedRole)
+		if err != nil {
+			return 0, "", "", err
+		}
+
+		_, err = s.DB.Exec(
+			`INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at) VALUES ($1, $2, $3, NULLIF($4, ''), NOW())`,
+			userID, claims.Issuer, claims.Subject, claims.Email,
+		)
+		if err != nil {
+			return 0, "", "", fmt.Errorf("failed to link identity: %w", err)
+		}
+	} else if err != nil {
+		return 0, "", "", fmt.Errorf("failed to find linked identity: %w", err)
+	} else {
+		if _, err := s.DB.Exec(
+			`UPDATE user_identities SET last_login_at = NOW(), email = COALESCE(NULLIF($1, ''), email) WHERE issuer = $2 AND subject = $3`,
+			claims.Email, claims.Issuer, claims.Subject,
+		); err != nil {
+			log.Printf("Failed to update identity login time: %v", err)
+		}
+	}
+
+	// Lewat UpdateRole supaya ada event role_changed dan sesi/token lama dengan role lama dicabut
+	if mappedRole != "" && mappedRole != role {
+		if err := s.Auth.UpdateRole(0, userID, mappedRole, client); err != nil {
+			return 0, "", "", fmt.Errorf("failed to sync role from identity provider: %w", err)
+		}
+		role = mappedRole
+	}
+
+	return userID, username, role, nil
+}
+
+// provisionUser membuat user baru untuk identitas IdP yang belum tertaut. Jika username dari
+// IdP sudah dipakai akun lokal, username diberi akhiran unik; akun lokal itu tidak disentuh.
+func (s *OIDCService) provisionUser(claims *oidcIDTokenClaims, mappedRole string) (int, string, string, error) {
+	username := claims.PreferredUsername
+	if username == "" && claims.Email != "" {
+		username = strings.Split(claims.Email, "@")[0]
+	}
+	if username == "" {
+		username = "sso-" + claims.Subject
+	}
+
+	var userID int
+	var role string
+	var exists bool
+	if err := s.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`, username).Scan(&exists); err != nil {
+		return 0, "", "", fmt.Errorf("failed to find user: %w", err)
+	}
+	if exists {
+		username = fmt.Sprintf("%s-%s", username, shortHash(claims.Issuer+claims.Subject))
+	}
+
+	role = mappedRole
+	if role == "" {
+		role = s.Config.DefaultRole
+	}
+
+	// User SSO tidak punya password lokal yang bisa dipakai: hash dari nilai acak
+	randomPassword, err := randomURLSafe(32)
+	if err != nil {
+		return 0, "", "", err
+	}
+	hashedPassword, err := s.Auth.Hashers.Hash(randomPassword)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	err = s.DB.QueryRow(
+		`INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id`,
+		username, hashedPassword, role,
+	).Scan(&userID)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("failed to provision user: %w", err)
+	}
+
+	log.Printf("Provisioned user %d (%s) from identity provider with role %s", userID, username, role)
+	return userID, username, role, nil
+}
+
+// linkIdentity menautkan identitas IdP ke user yang sedang login (dari ConfirmLink).
+func (s *OIDCService) linkIdentity(userID int, claims *oidcIDTokenClaims) error {
+	var ownerID int
+	err := s.DB.QueryRow(`
+        INSERT INTO user_identities (user_id, issuer, subject, email) VALUES ($1, $2, $3, NULLIF($4, ''))
+        ON CONFLICT (issuer, subject) DO UPDATE SET email = COALESCE(EXCLUDED.email, user_identities.email)
+        RETURNING user_id
+    `, userID, claims.Issuer, claims.Subject, claims.Email).Scan(&ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	if ownerID != userID {
+		return ErrOIDCIdentityInUse
+	}
+	log.Printf("Linked identity %s of issuer %s to user %d", claims.Subject, claims.Issuer, userID)
+	return nil
+}
+
+func (s *OIDCService) getDiscovery() (*oidcDiscovery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.discovery != nil && time.Now().Before(s.cachedUntil) {
+		return s.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := s.getJSON(s.Config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to load OpenID configuration: %w", err)
+	}
+	if discovery.Issuer != s.Config.Issuer {
+		return nil, fmt.Errorf("OpenID configuration issuer %q does not match %q", discovery.Issuer, s.Config.Issuer)
+	}
+
+	s.discovery = &discovery
+	s.keys = nil
+	s.cachedUntil = time.Now().Add(oidcCacheTTL)
+	return s.discovery, nil
+}
+
+// publicKey mengambil key dari JWKS IdP, memuat ulang JWKS sekali jika kid belum dikenal
+// (IdP baru saja melakukan rotasi key).
+func (s *OIDCService) publicKey(kid string) (interface{}, error) {
+	discovery, err := s.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.keys == nil || attempt == 1 {
+			var keys utils.JSONWebKeySet
+			if err := s.getJSON(discovery.JWKSURI, &keys); err != nil {
+				return nil, fmt.Errorf("failed to load JWKS: %w", err)
+			}
+			s.keys = &keys
+		}
+
+		if key, found := s.keys.Find(kid); found {
+			return key.PublicKey()
+		}
+	}
+	return nil, fmt.Errorf("signing key %q not found in JWKS", kid)
+}
+
+func (s *OIDCService) getJSON(endpoint string, target interface{}) error {
+	resp, err := s.HTTPClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
+
+func randomURLSafe(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%x", sum[:3])
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"project/dto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// mockIdP adalah identity provider OIDC minimal: discovery, JWKS dan token endpoint yang
+// memverifikasi PKCE, lalu menerbitkan ID token bertanda tangan RS256.
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+	// signWith diisi untuk menandatangani ID token dengan key yang tidak ada di JWKS
+	signWith *rsa.PrivateKey
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{t: t, key: key, codes: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock-key",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		auth, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+		if !ok {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+			http.Error(w, `{"error":"invalid_grant","error_description":"PKCE verification failed"}`, http.StatusBadRequest)
+			return
+		}
+
+		signer := key
+		if auth.signWith != nil {
+			signer = auth.signWith
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.claims)
+		token.Header["kid"] = "mock-key"
+		signed, err := token.SignedString(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize meniru halaman login IdP: membaca parameter authorization URL dan mengembalikan
+// state serta code. edit boleh mengubah claims ID token yang akan diterbitkan.
+func (idp *mockIdP) authorize(authURL string, edit func(claims jwt.MapClaims, auth *mockAuthorization)) (state, code string) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("response_type") != "code" {
+		idp.t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                query.Get("client_id"),
+		"sub":                "user-123",
+		"nonce":              query.Get("nonce"),
+		"preferred_username": "budi",
+		"email":              "budi@sekolah.sch.id",
+		"groups":             []string{"teachers"},
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+	}
+	auth := mockAuthorization{challenge: query.Get("code_challenge"), claims: claims}
+	if edit != nil {
+		edit(claims, &auth)
+	}
+
+	code = "code-" + query.Get("state")[:8]
+	idp.mu.Lock()
+	idp.codes[code] = auth
+	idp.mu.Unlock()
+	return query.Get("state"), code
+}
+
+func newTestOIDCService(idp *mockIdP) *OIDCService {
+	return NewOIDCService(nil, nil, OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    "studymate",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+		Scopes:      []string{"openid", "profile", "email", "groups"},
+		GroupsClaim: "groups",
+		RoleMapping: map[string]string{"admins": "Admin", "teachers": "Guru", "students": "Siswa"},
+		DefaultRole: "Siswa",
+	})
+}
+
+func TestOIDCAuthenticateAgainstMockIdP(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		linkUserID int
+		edit       func(claims jwt.MapClaims, auth *mockAuthorization)
+		wrongState bool
+		noCookie   bool
+		wantErr    string
+		wantRole   string
+	}{
+		{name: "valid login", wantRole: "Guru"},
+		{name: "link flow keeps the user who started it", linkUserID: 42, wantRole: "Guru"},
+		{
+			name: "highest mapped group wins",
+			edit: func(claims jwt.MapClaims, _ *mockAuthorization) {
+				claims["groups"] = []string{"students", "/admins", "unknown"}
+			},
+			wantRole: "Admin",
+		},
+		{name: "unknown state", wrongState: true, wantErr: ErrOIDCInvalidState.Error()},
+		{name: "callback from a browser without the state cookie", noCookie: true, wantErr: ErrOIDCInvalidState.Error()},
+		{
+			name:    "nonce mismatch",
+			edit:    func(claims jwt.MapClaims, _ *mockAuthorization) { claims["nonce"] = "other" },
+			wantErr: "nonce mismatch",
+		},
+		{
+			name:    "audience mismatch",
+			edit:    func(claims jwt.MapClaims, _ *mockAuthorization) { claims["aud"] = "other-client" },
+			wantErr: "audience",
+		},
+		{
+			name:    "issuer mismatch",
+			edit:    func(claims jwt.MapClaims, _ *mockAuthorization) { claims["iss"] = "https://evil.example" },
+			wantErr: "issuer",
+		},
+		{
+			name: "expired beyond clock skew",
+			edit: func(claims jwt.MapClaims, _ *mockAuthorization) {
+				claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			},
+			wantErr: "invalid id_token",
+		},
+		{
+			name:    "signed with a key outside the JWKS",
+			edit:    func(_ jwt.MapClaims, auth *mockAuthorization) { auth.signWith = otherKey },
+			wantErr: "invalid id_token",
+		},
+		{
+			name:    "PKCE challenge does not match verifier",
+			edit:    func(_ jwt.MapClaims, auth *mockAuthorization) { auth.challenge = "tampered" },
+			wantErr: "PKCE verification failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			s := newTestOIDCService(idp)
+
+			authURL, issuedState, err := s.AuthorizationURL(tt.linkUserID)
+			if err != nil {
+				t.Fatalf("AuthorizationURL: %v", err)
+			}
+			if !strings.HasPrefix(authURL, idp.server.URL+"/authorize?") {
+				t.Fatalf("authorization URL %q does not point to the IdP", authURL)
+			}
+			state, code := idp.authorize(authURL, tt.edit)
+			if state != issuedState {
+				t.Fatalf("state in URL %q differs from returned state %q", state, issuedState)
+			}
+			cookie := OIDCStateHash(state)
+			if tt.wrongState {
+				state = "unknown"
+			}
+			if tt.noCookie {
+				cookie = ""
+			}
+
+			login, claims, rawClaims, err := s.authenticate(state, cookie, code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if claims.Subject != "user-123" || claims.PreferredUsername != "budi" {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+			if login.linkUserID != tt.linkUserID {
+				t.Errorf("linkUserID = %d, want %d", login.linkUserID, tt.linkUserID)
+			}
+			if role := s.mapRole(rawClaims); role != tt.wantRole {
+				t.Errorf("mapped role = %q, want %q", role, tt.wantRole)
+			}
+
+			// State hanya bisa dipakai sekali
+			if _, _, _, err := s.authenticate(state, cookie, code); !errors.Is(err, ErrOIDCInvalidState) {
+				t.Errorf("reusing state: error = %v, want %v", err, ErrOIDCInvalidState)
+			}
+		})
+	}
+}
+
+func TestOIDCLinkNeedsConfirmationByTheSameUser(t *testing.T) {
+	idp := newMockIdP(t)
+	s := newTestOIDCService(idp)
+
+	authURL, _, err := s.AuthorizationURL(42)
+	if err != nil {
+		t.Fatalf("AuthorizationURL: %v", err)
+	}
+	state, code := idp.authorize(authURL, nil)
+
+	// Callback penautan belum menyentuh database, hanya menghasilkan link code
+	result, err := s.HandleCallback(state, OIDCStateHash(state), code, dto.ClientInfo{})
+	if err != nil {
+		t.Fatalf("HandleCallback: %v", err)
+	}
+	if result.LinkCode == "" || result.Login != nil {
+		t.Fatalf("unexpected link callback result: %+v", result)
+	}
+
+	if err := s.ConfirmLink(7, result.LinkCode); !errors.Is(err, ErrOIDCInvalidLink) {
+		t.Errorf("confirm by another user: error = %v, want %v", err, ErrOIDCInvalidLink)
+	}
+	// Link code hanya bisa dipakai sekali, termasuk setelah percobaan yang gagal
+	if err := s.ConfirmLink(42, result.LinkCode); !errors.Is(err, ErrOIDCInvalidLink) {
+		t.Errorf("reusing link code: error = %v, want %v", err, ErrOIDCInvalidLink)
+	}
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	s := NewOIDCService(nil, nil, OIDCConfig{})
+	if _, _, err := s.AuthorizationURL(0); !errors.Is(err, ErrOIDCDisabled) {
+		t.Errorf("AuthorizationURL error = %v, want %v", err, ErrOIDCDisabled)
+	}
+	if _, err := s.HandleCallback("state", "", "code", dto.ClientInfo{}); !errors.Is(err, ErrOIDCDisabled) {
+		t.Errorf("HandleCallback error = %v, want %v", err, ErrOIDCDisabled)
+	}
+}
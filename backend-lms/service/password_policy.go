@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"project/config"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed data/breached_passwords.txt
+var builtinBreachedPasswords string
+
+// PasswordPolicy menentukan aturan password untuk registrasi dan reset password.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// Breached berisi password (lowercase) yang diketahui bocor dan tidak boleh dipakai.
+	Breached map[string]struct{}
+}
+
+// NewPasswordPolicy membuat policy dari config, termasuk daftar password bocor bawaan
+// dan daftar tambahan dari BREACHED_PASSWORDS_FILE jika diset.
+func NewPasswordPolicy() *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength: config.PasswordMinLength,
+		MaxLength: config.PasswordMaxLength,
+		Breached:  make(map[string]struct{}),
+	}
+
+	policy.loadBreached(strings.NewReader(builtinBreachedPasswords))
+
+	if config.BreachedPasswordsFile != "" {
+		file, err := os.Open(config.BreachedPasswordsFile)
+		if err != nil {
+			log.Printf("Failed to open breached password list %s: %v", config.BreachedPasswordsFile, err)
+			return policy
+		}
+		defer file.Close()
+		policy.loadBreached(file)
+	}
+
+	return policy
+}
+
+func (p *PasswordPolicy) loadBreached(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.Breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Failed to read breached password list: %v", err)
+	}
+}
+
+// Check mengembalikan *ValidationError berisi semua pelanggaran, atau nil jika password valid.
+func (p *PasswordPolicy) Check(username, password string) error {
+	violations := &ValidationError{}
+	length := utf8.RuneCountInString(password)
+
+	if length < p.MinLength {
+		violations.Add("password", "too_short", fmt.Sprintf("Password must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations.Add("password", "too_long", fmt.Sprintf("Password must be at most %d characters", p.MaxLength))
+	}
+
+	lowered := strings.ToLower(password)
+	if _, found := p.Breached[lowered]; found {
+		violations.Add("password", "breached", "Password appears in a list of breached or common passwords")
+	}
+	if username != "" && lowered == strings.ToLower(username) {
+		violations.Add("password", "same_as_username", "Password must not be the same as the username")
+	}
+
+	return violations.OrNil()
+}
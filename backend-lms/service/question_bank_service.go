@@ -0,0 +1,367 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+var (
+	ErrBankQuestionNotFound = errors.New("bank question not found")
+	ErrBankVersionNotFound  = errors.New("question version not found")
+)
+
+// QuestionBankService mengelola bank soal guru: soal milik sendiri bisa diubah, soal yang
+// dibagikan guru lain hanya bisa dipakai atau disalin.
+type QuestionBankService struct {
+	DB *sql.DB
+}
+
+func NewQuestionBankService(db *sql.DB) *QuestionBankService {
+	return &QuestionBankService{DB: db}
+}
+
+// ListQuestions mengembalikan soal yang bisa dipakai user sesuai filter tag.
+func (s *QuestionBankService) ListQuestions(userID int, role string, filter dto.BankQuestionFilter) ([]model.BankQuestion, error) {
+	rows, err := s.DB.Query(bankQuestionSelect+`
+        WHERE (b.owner_id = $1 OR b.shared OR $2)
+          AND ($3 = '' OR ($3 = 'mine' AND b.owner_id = $1) OR ($3 = 'shared' AND b.shared))
+          AND ($4 = '' OR LOWER(b.subject) = LOWER($4))
+          AND ($5 = '' OR LOWER(b.topic) = LOWER($5))
+          AND ($6 = '' OR b.difficulty = $6)
+          AND ($7 = '' OR LOWER(b.competency) = LOWER($7))
+          AND ($8 = '' OR v.question->>'type' = $8)
+          AND ($9 = '' OR v.question->>'prompt' ILIKE '%' || $9 || '%')
+        ORDER BY b.subject, b.topic, b.updated_at DESC, b.id DESC
+    `, userID, role == "Admin", filter.Scope, strings.TrimSpace(filter.Subject), strings.TrimSpace(filter.Topic),
+		filter.Difficulty, strings.TrimSpace(filter.Competency), filter.Type, strings.TrimSpace(filter.Search))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query question bank: %w", err)
+	}
+	defer rows.Close()
+
+	questions := []model.BankQuestion{}
+	for rows.Next() {
+		question, err := scanBankQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank question: %w", err)
+		}
+		questions = append(questions, *question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question bank: %w", err)
+	}
+	return questions, nil
+}
+
+func (s *QuestionBankService) GetQuestion(questionID, userID int, role string) (*model.BankQuestion, error) {
+	return getBankQuestion(s.DB, questionID, userID, role, false)
+}
+
+func (s *QuestionBankService) CreateQuestion(userID int, req dto.BankQuestionRequest) (*model.BankQuestion, error) {
+	content, err := normalizeBankQuestion(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	questionID, err := insertBankQuestion(tx, userID, req, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create question: %w", err)
+	}
+	return getBankQuestion(s.DB, questionID, userID, "", false)
+}
+
+// UpdateQuestion mengubah tag dan isi soal. Versi baru hanya dibuat jika isi soal berubah.
+func (s *QuestionBankService) UpdateQuestion(questionID, userID int, role string, req dto.BankQuestionRequest) (*model.BankQuestion, error) {
+	content, err := normalizeBankQuestion(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := getBankQuestion(tx, questionID, userID, role, true)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+        UPDATE bank_questions SET subject = $1, topic = $2, difficulty = $3, competency = $4, shared = $5, updated_at = NOW()
+        WHERE id = $6
+    `, strings.TrimSpace(req.Subject), strings.TrimSpace(req.Topic), bankDifficulty(req.Difficulty),
+		strings.TrimSpace(req.Competency), req.Shared, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+	previous, err := json.Marshal(current.Question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode question: %w", err)
+	}
+	if !bytes.Equal(previous, content) {
+		if err := addBankVersion(tx, questionID, userID, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+	return getBankQuestion(s.DB, questionID, userID, role, false)
+}
+
+// DeleteQuestion menghapus soal dari bank. Kuis dan percobaan yang sudah memakainya tetap
+// menyimpan salinan soal.
+func (s *QuestionBankService) DeleteQuestion(questionID, userID int, role string) error {
+	if _, err := getBankQuestion(s.DB, questionID, userID, role, true); err != nil {
+		return err
+	}
+	if _, err := s.DB.Exec(`DELETE FROM bank_questions WHERE id = $1`, questionID); err != nil {
+		return fmt.Errorf("failed to delete question: %w", err)
+	}
+	return nil
+}
+
+// GetVersions mengembalikan riwayat versi soal, terbaru lebih dulu.
+func (s *QuestionBankService) GetVersions(questionID, userID int, role string) ([]model.BankQuestionVersion, error) {
+	if _, err := getBankQuestion(s.DB, questionID, userID, role, false); err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.Query(`
+        SELECT version, question, created_by, created_at FROM bank_question_versions
+        WHERE bank_question_id = $1 ORDER BY version DESC
+    `, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []model.BankQuestionVersion{}
+	for rows.Next() {
+		var version model.BankQuestionVersion
+		var content []byte
+		if err := rows.Scan(&version.Version, &content, &version.CreatedBy, &version.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan version: %w", err)
+		}
+		if err := json.Unmarshal(content, &version.Question); err != nil {
+			return nil, fmt.Errorf("failed to decode question: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating versions: %w", err)
+	}
+	return versions, nil
+}
+
+// RestoreVersion menyalin isi versi lama sebagai versi terbaru.
+func (s *QuestionBankService) RestoreVersion(questionID, version, userID int, role string) (*model.BankQuestion, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getBankQuestion(tx, questionID, userID, role, true); err != nil {
+		return nil, err
+	}
+	var content []byte
+	err = tx.QueryRow(`
+        SELECT question FROM bank_question_versions WHERE bank_question_id = $1 AND version = $2
+    `, questionID, version).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, ErrBankVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+	if err := addBankVersion(tx, questionID, userID, content); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE bank_questions SET updated_at = NOW() WHERE id = $1`, questionID); err != nil {
+		return nil, fmt.Errorf("failed to restore version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to restore version: %w", err)
+	}
+	return getBankQuestion(s.DB, questionID, userID, role, false)
+}
+
+// CopyQuestion menyalin soal (misalnya soal bersama milik guru lain) ke bank soal sendiri.
+func (s *QuestionBankService) CopyQuestion(questionID, userID int, role string) (*model.BankQuestion, error) {
+	source, err := getBankQuestion(s.DB, questionID, userID, role, false)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(source.Question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode question: %w", err)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	req := dto.BankQuestionRequest{Subject: source.Subject, Topic: source.Topic, Difficulty: source.Difficulty, Competency: source.Competency}
+	copyID, err := insertBankQuestion(tx, userID, req, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to copy question: %w", err)
+	}
+	return getBankQuestion(s.DB, copyID, userID, role, false)
+}
+
+// GetTags mengembalikan nilai tag yang dipakai di soal yang bisa diakses user.
+func (s *QuestionBankService) GetTags(userID int, role string) (*model.QuestionTags, error) {
+	tags := &model.QuestionTags{Subjects: []string{}, Topics: []string{}, Competencies: []string{}}
+	for _, column := range []struct {
+		name   string
+		values *[]string
+	}{
+		{"subject", &tags.Subjects},
+		{"topic", &tags.Topics},
+		{"competency", &tags.Competencies},
+	} {
+		rows, err := s.DB.Query(`
+            SELECT DISTINCT `+column.name+` FROM bank_questions
+            WHERE `+column.name+` <> '' AND (owner_id = $1 OR shared OR $2)
+            ORDER BY 1
+        `, userID, role == "Admin")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tags: %w", err)
+		}
+		for rows.Next() {
+			var value string
+			if err := rows.Scan(&value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan tag: %w", err)
+			}
+			*column.values = append(*column.values, value)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating tags: %w", err)
+		}
+	}
+	return tags, nil
+}
+
+// normalizeBankQuestion memvalidasi isi dan tag soal, lalu mengembalikan isi soal sebagai JSON.
+func normalizeBankQuestion(req dto.BankQuestionRequest) ([]byte, error) {
+	violations := &ValidationError{}
+	question := normalizeQuestion(req.QuestionRequest, "question", violations)
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode question: %w", err)
+	}
+	return content, nil
+}
+
+func bankDifficulty(difficulty string) string {
+	if difficulty == "" {
+		return "medium"
+	}
+	return difficulty
+}
+
+func insertBankQuestion(q dbExecutor, ownerID int, req dto.BankQuestionRequest, content []byte) (int, error) {
+	var questionID int
+	err := q.QueryRow(`
+        INSERT INTO bank_questions (owner_id, shared, subject, topic, difficulty, competency)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+    `, ownerID, req.Shared, strings.TrimSpace(req.Subject), strings.TrimSpace(req.Topic), bankDifficulty(req.Difficulty),
+		strings.TrimSpace(req.Competency)).Scan(&questionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create question: %w", err)
+	}
+	_, err = q.Exec(`
+        INSERT INTO bank_question_versions (bank_question_id, version, question, created_by) VALUES ($1, 1, $2, $3)
+    `, questionID, string(content), ownerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save question version: %w", err)
+	}
+	return questionID, nil
+}
+
+func addBankVersion(q dbExecutor, questionID, userID int, content []byte) error {
+	var version int
+	err := q.QueryRow(`
+        UPDATE bank_questions SET current_version = current_version + 1 WHERE id = $1 RETURNING current_version
+    `, questionID).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to update question version: %w", err)
+	}
+	_, err = q.Exec(`
+        INSERT INTO bank_question_versions (bank_question_id, version, question, created_by) VALUES ($1, $2, $3, $4)
+    `, questionID, version, string(content), userID)
+	if err != nil {
+		return fmt.Errorf("failed to save question version: %w", err)
+	}
+	return nil
+}
+
+const bankQuestionSelect = `
+    SELECT b.id, b.owner_id, COALESCE(u.username, ''), b.shared, b.subject, b.topic, b.difficulty, b.competency,
+           b.current_version, v.question, b.created_at, b.updated_at
+    FROM bank_questions b
+    JOIN bank_question_versions v ON v.bank_question_id = b.id AND v.version = b.current_version
+    LEFT JOIN users u ON u.id = b.owner_id
+`
+
+func scanBankQuestion(row rowScanner) (*model.BankQuestion, error) {
+	var question model.BankQuestion
+	var content []byte
+	err := row.Scan(&question.ID, &question.OwnerID, &question.OwnerUsername, &question.Shared, &question.Subject,
+		&question.Topic, &question.Difficulty, &question.Competency, &question.CurrentVersion, &content,
+		&question.CreatedAt, &question.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &question.Question); err != nil {
+		return nil, fmt.Errorf("failed to decode question: %w", err)
+	}
+	return &question, nil
+}
+
+// getBankQuestion mengambil soal jika user boleh memakainya (pemilik, soal bersama, atau
+// Admin). forEdit membatasi ke pemilik dan Admin.
+func getBankQuestion(q dbExecutor, questionID, userID int, role string, forEdit bool) (*model.BankQuestion, error) {
+	question, err := scanBankQuestion(q.QueryRow(bankQuestionSelect+` WHERE b.id = $1`, questionID))
+	if err == sql.ErrNoRows {
+		return nil, ErrBankQuestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank question: %w", err)
+	}
+	owner := question.OwnerID != nil && *question.OwnerID == userID
+	if role != "Admin" && !owner && (forEdit || !question.Shared) {
+		return nil, ErrBankQuestionNotFound
+	}
+	return question, nil
+}
@@ -0,0 +1,527 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"project/dto"
+	"project/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format file soal untuk import/ekspor.
+const (
+	FormatAiken = "aiken"
+	FormatGIFT  = "gift"
+	FormatQTI   = "qti"
+)
+
+// parsedQuestion adalah soal hasil baca file sebelum divalidasi seperti soal biasa.
+type parsedQuestion struct {
+	Line     int
+	Source   string
+	Title    string
+	Topic    string
+	Request  dto.QuestionRequest
+	Errors   []dto.FieldError
+	Warnings []string
+}
+
+func (p *parsedQuestion) fail(field, code, message string) {
+	p.Errors = append(p.Errors, dto.FieldError{Field: field, Code: code, Message: message})
+}
+
+// exportQuestion adalah soal yang ditulis ke file ekspor.
+type exportQuestion struct {
+	Title    string
+	Question model.Question
+}
+
+// splitSourceLines membuang BOM dan menyamakan akhir baris.
+func splitSourceLines(data []byte) []string {
+	text := string(bytes.TrimPrefix(data, []byte("\ufeff")))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n")
+}
+
+var (
+	aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.+)$`)
+	aikenAnswer = regexp.MustCompile(`(?i)^ANSWER\s*:\s*(\S+)\s*$`)
+)
+
+// parseAiken membaca format Aiken: pertanyaan, opsi "A." / "A)", lalu "ANSWER: X".
+// Aiken hanya untuk pilihan ganda dengan satu jawaban benar.
+func parseAiken(data []byte) []parsedQuestion {
+	var result []parsedQuestion
+	var current *parsedQuestion
+	var labels []string
+
+	for i, raw := range splitSourceLines(data) {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if current == nil {
+			current = &parsedQuestion{Line: i + 1}
+			current.Request.Type = QuestionMultipleChoice
+			current.Request.Points = 1
+			current.Request.Prompt = line
+			labels = nil
+			continue
+		}
+		if match := aikenAnswer.FindStringSubmatch(line); match != nil {
+			answer := strings.ToUpper(match[1])
+			found := false
+			for j, label := range labels {
+				if label == answer {
+					current.Request.Options[j].Correct = true
+					found = true
+				}
+			}
+			if !found {
+				current.fail("answer", "invalid", fmt.Sprintf("Line %d: answer %s does not match any option", i+1, match[1]))
+			}
+			result = append(result, *current)
+			current = nil
+			continue
+		}
+		if match := aikenOption.FindStringSubmatch(line); match != nil {
+			if expected := optionLabel(len(labels)); strings.ToLower(match[1]) != expected {
+				current.Warnings = append(current.Warnings, fmt.Sprintf("Line %d: option %s is out of order", i+1, match[1]))
+			}
+			labels = append(labels, match[1])
+			current.Request.Options = append(current.Request.Options, model.QuestionOption{Text: match[2]})
+			continue
+		}
+		if len(labels) == 0 {
+			current.Request.Prompt += "\n" + line
+			continue
+		}
+		current.fail("options", "invalid", fmt.Sprintf("Line %d: expected an option or ANSWER line", i+1))
+	}
+	if current != nil {
+		current.fail("answer", "required", "Missing ANSWER line")
+		result = append(result, *current)
+	}
+	return result
+}
+
+// writeAiken menulis soal pilihan ganda dan benar/salah; tipe lain dilewati dengan peringatan.
+func writeAiken(questions []exportQuestion) ([]byte, []string) {
+	var buf bytes.Buffer
+	var warnings []string
+	for i, item := range questions {
+		question := item.Question
+		if question.Type != QuestionMultipleChoice && question.Type != QuestionTrueFalse {
+			warnings = append(warnings, fmt.Sprintf("Question %d skipped: Aiken only supports single-answer multiple choice (%s)", i+1, question.Type))
+			continue
+		}
+		if len(question.Options) > 26 {
+			warnings = append(warnings, fmt.Sprintf("Question %d skipped: Aiken supports at most 26 options", i+1))
+			continue
+		}
+		buf.WriteString(singleLine(question.Prompt) + "\n")
+		answer := ""
+		for j, option := range question.Options {
+			label := strings.ToUpper(optionLabel(j))
+			buf.WriteString(label + ". " + singleLine(option.Text) + "\n")
+			if option.Correct {
+				answer = label
+			}
+		}
+		buf.WriteString("ANSWER: " + answer + "\n\n")
+	}
+	return buf.Bytes(), warnings
+}
+
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var (
+	giftFormatPrefix = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+	giftHTMLTag      = regexp.MustCompile(`<[^>]*>`)
+	giftWeight       = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+)
+
+// parseGIFT membaca format GIFT Moodle. Soal dipisahkan baris kosong; komentar // dan
+// kategori $CATEGORY didukung (segmen terakhir kategori menjadi topik).
+func parseGIFT(data []byte) []parsedQuestion {
+	var result []parsedQuestion
+	var block []string
+	blockLine := 0
+	topic := ""
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(block, "\n"))
+		block = nil
+		if text == "" {
+			return
+		}
+		parsed := parseGIFTQuestion(text)
+		parsed.Line = blockLine
+		parsed.Topic = topic
+		result = append(result, parsed)
+	}
+
+	for i, raw := range splitSourceLines(data) {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "//"):
+			continue
+		case strings.HasPrefix(line, "$CATEGORY:"):
+			flush()
+			segments := strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "$CATEGORY:")), "/")
+			topic = strings.TrimSpace(segments[len(segments)-1])
+			if strings.HasPrefix(topic, "$") && strings.HasSuffix(topic, "$") {
+				topic = ""
+			}
+		case line == "":
+			flush()
+		default:
+			if len(block) == 0 {
+				blockLine = i + 1
+			}
+			block = append(block, raw)
+		}
+	}
+	flush()
+	return result
+}
+
+func parseGIFTQuestion(text string) parsedQuestion {
+	var parsed parsedQuestion
+	parsed.Request.Points = 1
+
+	if strings.HasPrefix(text, "::") {
+		if end := indexUnescaped(text[2:], "::"); end >= 0 {
+			parsed.Title = giftUnescape(strings.TrimSpace(text[2 : 2+end]))
+			text = strings.TrimSpace(text[4+end:])
+		}
+	}
+	isHTML := false
+	if match := giftFormatPrefix.FindStringSubmatch(text); match != nil {
+		isHTML = match[1] == "html"
+		text = strings.TrimSpace(text[len(match[0]):])
+	}
+
+	open := indexUnescaped(text, "{")
+	if open < 0 {
+		parsed.fail("answer", "required", "Question has no answer block {...}")
+		parsed.Request.Prompt = giftText(text, isHTML)
+		return parsed
+	}
+	closing := indexUnescaped(text[open:], "}")
+	if closing < 0 {
+		parsed.fail("answer", "invalid", "Answer block is not closed with }")
+		parsed.Request.Prompt = giftText(text[:open], isHTML)
+		return parsed
+	}
+	closing += open
+	before, after := strings.TrimSpace(text[:open]), strings.TrimSpace(text[closing+1:])
+	parsed.Request.Prompt = giftText(before, isHTML)
+	if after != "" {
+		// Soal isian rumpang: jawaban berada di tengah kalimat
+		parsed.Request.Prompt = strings.TrimSpace(parsed.Request.Prompt + " _____ " + giftText(after, isHTML))
+	}
+
+	answers := strings.TrimSpace(text[open+1 : closing])
+	if general := indexUnescaped(answers, "####"); general >= 0 {
+		parsed.Request.Explanation = giftText(answers[general+4:], isHTML)
+		answers = strings.TrimSpace(answers[:general])
+	}
+
+	switch {
+	case answers == "":
+		parsed.Request.Type = QuestionEssay
+	case strings.HasPrefix(answers, "#"):
+		parseGIFTNumeric(&parsed, strings.TrimSpace(answers[1:]))
+	case isGIFTBoolean(answers):
+		parsed.Request.Type = QuestionTrueFalse
+		value := strings.HasPrefix(strings.ToUpper(answers), "T")
+		parsed.Request.CorrectAnswer = &value
+	case indexUnescaped(answers, "->") >= 0:
+		parsed.fail("type", "unsupported", "Matching questions are not supported")
+	default:
+		parseGIFTChoices(&parsed, answers, isHTML)
+	}
+	return parsed
+}
+
+func isGIFTBoolean(answers string) bool {
+	value := answers
+	if feedback := indexUnescaped(value, "#"); feedback >= 0 {
+		value = value[:feedback]
+	}
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+type giftAnswer struct {
+	marker byte
+	weight *float64
+	text   string
+}
+
+// splitGIFTAnswers memecah blok jawaban pada tanda = dan ~ yang tidak di-escape.
+func splitGIFTAnswers(answers string) []giftAnswer {
+	var result []giftAnswer
+	var current *giftAnswer
+	var text strings.Builder
+	closeAnswer := func() {
+		if current != nil {
+			current.text = text.String()
+			result = append(result, *current)
+		}
+		text.Reset()
+	}
+	for i := 0; i < len(answers); i++ {
+		c := answers[i]
+		if c == '\\' && i+1 < len(answers) {
+			text.WriteByte(c)
+			text.WriteByte(answers[i+1])
+			i++
+			continue
+		}
+		if c == '=' || c == '~' {
+			closeAnswer()
+			current = &giftAnswer{marker: c}
+			continue
+		}
+		text.WriteByte(c)
+	}
+	closeAnswer()
+
+	for i := range result {
+		value := strings.TrimSpace(result[i].text)
+		if match := giftWeight.FindStringSubmatch(value); match != nil {
+			if weight, err := strconv.ParseFloat(match[1], 64); err == nil {
+				result[i].weight = &weight
+			}
+			value = strings.TrimSpace(value[len(match[0]):])
+		}
+		if feedback := indexUnescaped(value, "#"); feedback >= 0 {
+			value = strings.TrimSpace(value[:feedback])
+		}
+		result[i].text = value
+	}
+	return result
+}
+
+func parseGIFTChoices(parsed *parsedQuestion, answers string, isHTML bool) {
+	choices := splitGIFTAnswers(answers)
+	if len(choices) == 0 {
+		parsed.fail("answer", "invalid", "Answer block has no answers")
+		return
+	}
+	hasWrong, correctCount, partial := false, 0, false
+	for _, choice := range choices {
+		if choice.marker == '~' {
+			hasWrong = true
+		}
+		if giftChoiceCorrect(choice) {
+			correctCount++
+		}
+		if choice.weight != nil && *choice.weight != 100 && *choice.weight > 0 {
+			partial = true
+		}
+	}
+
+	// Hanya jawaban "=" tanpa pilihan salah adalah soal isian singkat
+	if !hasWrong {
+		parsed.Request.Type = QuestionShortAnswer
+		for _, choice := range choices {
+			if choice.weight != nil && *choice.weight < 100 {
+				parsed.Warnings = append(parsed.Warnings, fmt.Sprintf("Partial credit for %q is ignored, the answer is accepted in full", choice.text))
+			}
+			parsed.Request.AcceptedAnswers = append(parsed.Request.AcceptedAnswers, giftText(choice.text, isHTML))
+		}
+		return
+	}
+
+	parsed.Request.Type = QuestionMultipleChoice
+	if correctCount != 1 {
+		parsed.Request.Type = QuestionMultipleAnswer
+	} else if partial {
+		parsed.Warnings = append(parsed.Warnings, "Partial credit on wrong options is ignored")
+	}
+	for _, choice := range choices {
+		parsed.Request.Options = append(parsed.Request.Options, model.QuestionOption{
+			Text:    giftText(choice.text, isHTML),
+			Correct: giftChoiceCorrect(choice),
+		})
+	}
+}
+
+func giftChoiceCorrect(choice giftAnswer) bool {
+	if choice.weight != nil {
+		return *choice.weight > 0
+	}
+	return choice.marker == '='
+}
+
+// parseGIFTNumeric membaca {#3.14:0.01}, {#1..5} atau {#=3.14:0.01 =%50%3:0.5}; jawaban
+// dengan nilai penuh yang pertama dipakai.
+func parseGIFTNumeric(parsed *parsedQuestion, answers string) {
+	parsed.Request.Type = QuestionNumeric
+	value := answers
+	if strings.HasPrefix(value, "=") {
+		choices := splitGIFTAnswers(value)
+		value = ""
+		for _, choice := range choices {
+			if choice.weight == nil || *choice.weight == 100 {
+				value = choice.text
+				break
+			}
+		}
+		if len(choices) > 1 {
+			parsed.Warnings = append(parsed.Warnings, "Only the full-credit numeric answer is imported")
+		}
+	} else if feedback := indexUnescaped(value, "#"); feedback >= 0 {
+		value = value[:feedback]
+	}
+	value = strings.TrimSpace(value)
+
+	var answer, tolerance float64
+	var err error
+	if parts := strings.SplitN(value, "..", 2); len(parts) == 2 {
+		var low, high float64
+		if low, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err == nil {
+			high, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		}
+		answer, tolerance = (low+high)/2, math.Abs(high-low)/2
+	} else {
+		parts := strings.SplitN(value, ":", 2)
+		answer, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err == nil && len(parts) == 2 {
+			tolerance, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		}
+	}
+	if err != nil || value == "" {
+		parsed.fail("numeric_answer", "invalid", fmt.Sprintf("Invalid numeric answer %q", value))
+		return
+	}
+	parsed.Request.NumericAnswer = &answer
+	parsed.Request.Tolerance = tolerance
+}
+
+// writeGIFT menulis semua tipe soal. GIFT tidak menyimpan bobot soal, jadi poin tidak ikut.
+func writeGIFT(questions []exportQuestion) ([]byte, []string) {
+	var buf bytes.Buffer
+	var warnings []string
+	for i, item := range questions {
+		question := item.Question
+		title := item.Title
+		if title == "" {
+			title = fmt.Sprintf("Q%d", i+1)
+		}
+		buf.WriteString("::" + giftEscape(title) + ":: " + giftEscape(question.Prompt) + " {")
+
+		switch question.Type {
+		case QuestionMultipleChoice:
+			for _, option := range question.Options {
+				marker := "~"
+				if option.Correct {
+					marker = "="
+				}
+				buf.WriteString("\n\t" + marker + giftEscape(option.Text))
+			}
+			buf.WriteString("\n")
+		case QuestionMultipleAnswer:
+			correct := 0
+			for _, option := range question.Options {
+				if option.Correct {
+					correct++
+				}
+			}
+			for _, option := range question.Options {
+				weight := -100.0 / float64(correct)
+				if option.Correct {
+					weight = 100.0 / float64(correct)
+				}
+				buf.WriteString(fmt.Sprintf("\n\t~%%%s%%%s", strconv.FormatFloat(math.Round(weight*100000)/100000, 'f', -1, 64), giftEscape(option.Text)))
+			}
+			buf.WriteString("\n")
+		case QuestionTrueFalse:
+			value := "FALSE"
+			for _, option := range question.Options {
+				if option.ID == "true" && option.Correct {
+					value = "TRUE"
+				}
+			}
+			buf.WriteString(value)
+		case QuestionShortAnswer:
+			for _, answer := range question.AcceptedAnswers {
+				if strings.HasPrefix(answer, "/") && strings.HasSuffix(answer, "/") && len(answer) > 2 {
+					warnings = append(warnings, fmt.Sprintf("Question %d: regex answer %s is exported as plain text", i+1, answer))
+				}
+				buf.WriteString("=" + giftEscape(answer) + " ")
+			}
+		case QuestionNumeric:
+			if question.NumericAnswer != nil {
+				buf.WriteString("#" + strconv.FormatFloat(*question.NumericAnswer, 'f', -1, 64))
+				if question.Tolerance > 0 {
+					buf.WriteString(":" + strconv.FormatFloat(question.Tolerance, 'f', -1, 64))
+				}
+			}
+		case QuestionEssay:
+		}
+		if question.Explanation != "" {
+			buf.WriteString("####" + giftEscape(question.Explanation))
+		}
+		buf.WriteString("}\n\n")
+	}
+	return buf.Bytes(), warnings
+}
+
+// indexUnescaped mencari substr yang tidak didahului backslash.
+func indexUnescaped(text, substr string) int {
+	for i := 0; i+len(substr) <= len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(text[i:], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+var giftEscaper = strings.NewReplacer(`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`, "\n", `\n`)
+
+func giftEscape(text string) string {
+	return giftEscaper.Replace(text)
+}
+
+func giftUnescape(text string) string {
+	var buf strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			if text[i] == 'n' {
+				buf.WriteByte('\n')
+			} else {
+				buf.WriteByte(text[i])
+			}
+			continue
+		}
+		buf.WriteByte(text[i])
+	}
+	return buf.String()
+}
+
+// giftText meng-unescape teks GIFT; teks [html] diubah menjadi teks biasa.
+func giftText(text string, isHTML bool) string {
+	text = giftUnescape(strings.TrimSpace(text))
+	if isHTML {
+		text = html.UnescapeString(giftHTMLTag.ReplaceAllString(text, " "))
+	}
+	return strings.TrimSpace(text)
+}
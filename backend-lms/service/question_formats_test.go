@@ -0,0 +1,340 @@
+package service
+
+import (
+	"fmt"
+	"project/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// questionKey meringkas bagian soal yang harus sama setelah dibaca dari file.
+func questionKey(question model.Question) string {
+	var options []string
+	for _, option := range question.Options {
+		options = append(options, fmt.Sprintf("%s:%v", option.Text, option.Correct))
+	}
+	numeric := "-"
+	if question.NumericAnswer != nil {
+		numeric = fmt.Sprintf("%g±%g", *question.NumericAnswer, question.Tolerance)
+	}
+	return fmt.Sprintf("%s|%s|%v|%v|%s|%s", question.Type, question.Prompt, options, question.AcceptedAnswers, numeric, question.Explanation)
+}
+
+func sampleExportQuestions() []exportQuestion {
+	answer := 3.14
+	return []exportQuestion{
+		{Title: "Planet", Question: model.Question{Type: QuestionMultipleChoice, Prompt: "Planet terdekat dari matahari?", Points: 2,
+			Options: []model.QuestionOption{{ID: "a", Text: "Merkurius", Correct: true}, {ID: "b", Text: "Venus"}, {ID: "c", Text: "Mars"}}}},
+		{Title: "Prima", Question: model.Question{Type: QuestionMultipleAnswer, Prompt: "Pilih bilangan prima", Points: 3,
+			Options: []model.QuestionOption{{ID: "a", Text: "2", Correct: true}, {ID: "b", Text: "3", Correct: true}, {ID: "c", Text: "4"}}}},
+		{Title: "Bumi", Question: model.Question{Type: QuestionTrueFalse, Prompt: "Bumi itu bulat", Points: 1,
+			Options: []model.QuestionOption{{ID: "true", Text: "Benar", Correct: true}, {ID: "false", Text: "Salah"}}}},
+		{Title: "Ibukota", Question: model.Question{Type: QuestionShortAnswer, Prompt: "Ibu kota Indonesia?", Points: 1,
+			AcceptedAnswers: []string{"Jakarta"}}},
+		{Title: "Pi", Question: model.Question{Type: QuestionNumeric, Prompt: "Nilai pi (2 desimal)", Points: 1,
+			NumericAnswer: &answer, Tolerance: 0.01}},
+		{Title: "Esai", Question: model.Question{Type: QuestionEssay, Prompt: "Jelaskan fotosintesis", Points: 5,
+			Explanation: "Cahaya, klorofil, glukosa"}},
+	}
+}
+
+func TestParseAiken(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantPrompts  []string
+		wantCorrect  []int // indeks opsi benar per soal, -1 jika tidak ada
+		wantErrors   []int
+		wantWarnings []int
+	}{
+		{
+			name:        "two questions with CRLF and BOM",
+			input:       "\ufeffIbu kota Jawa Barat?\r\nA. Bandung\r\nB. Bogor\r\nANSWER: A\r\n\r\nHasil 2+2?\r\nA) 3\r\nB) 4\r\nANSWER: b\r\n",
+			wantPrompts: []string{"Ibu kota Jawa Barat?", "Hasil 2+2?"},
+			wantCorrect: []int{0, 1},
+			wantErrors:  []int{0, 0}, wantWarnings: []int{0, 0},
+		},
+		{
+			name:        "multi-line prompt",
+			input:       "Perhatikan kalimat berikut.\nManakah kata kerja?\nA. lari\nB. meja\nANSWER: A\n",
+			wantPrompts: []string{"Perhatikan kalimat berikut.\nManakah kata kerja?"},
+			wantCorrect: []int{0},
+			wantErrors:  []int{0}, wantWarnings: []int{0},
+		},
+		{
+			name:        "answer without matching option",
+			input:       "Soal\nA. satu\nB. dua\nANSWER: C\n",
+			wantPrompts: []string{"Soal"},
+			wantCorrect: []int{-1},
+			wantErrors:  []int{1}, wantWarnings: []int{0},
+		},
+		{
+			name:        "missing answer line",
+			input:       "Soal\nA. satu\nB. dua\n",
+			wantPrompts: []string{"Soal"},
+			wantCorrect: []int{-1},
+			wantErrors:  []int{1}, wantWarnings: []int{0},
+		},
+		{
+			name:        "options out of order",
+			input:       "Soal\nA. satu\nC. dua\nANSWER: C\n",
+			wantPrompts: []string{"Soal"},
+			wantCorrect: []int{1},
+			wantErrors:  []int{0}, wantWarnings: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseAiken([]byte(tt.input))
+			if len(parsed) != len(tt.wantPrompts) {
+				t.Fatalf("got %d questions, want %d", len(parsed), len(tt.wantPrompts))
+			}
+			for i, question := range parsed {
+				if question.Request.Type != QuestionMultipleChoice {
+					t.Errorf("question %d type = %q", i, question.Request.Type)
+				}
+				if question.Request.Prompt != tt.wantPrompts[i] {
+					t.Errorf("question %d prompt = %q, want %q", i, question.Request.Prompt, tt.wantPrompts[i])
+				}
+				correct := -1
+				for j, option := range question.Request.Options {
+					if option.Correct {
+						correct = j
+					}
+				}
+				if correct != tt.wantCorrect[i] {
+					t.Errorf("question %d correct option = %d, want %d", i, correct, tt.wantCorrect[i])
+				}
+				if len(question.Errors) != tt.wantErrors[i] || len(question.Warnings) != tt.wantWarnings[i] {
+					t.Errorf("question %d errors %v warnings %v, want %d/%d", i, question.Errors, question.Warnings, tt.wantErrors[i], tt.wantWarnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseGIFT(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantType  string
+		wantKey   string
+		wantTitle string
+		wantTopic string
+		wantError bool
+	}{
+		{
+			name:      "multiple choice with title and category",
+			input:     "$CATEGORY: $course$/IPA/Tata Surya\n\n::Planet:: Planet terdekat? {=Merkurius ~Venus ~Mars}",
+			wantType:  QuestionMultipleChoice,
+			wantKey:   "multiple_choice|Planet terdekat?|[Merkurius:true Venus:false Mars:false]|[]|-|",
+			wantTitle: "Planet", wantTopic: "Tata Surya",
+		},
+		{
+			name:     "weighted options become multiple answer",
+			input:    "Bilangan prima? {~%50%2 ~%50%3 ~%-100%4}",
+			wantType: QuestionMultipleAnswer,
+			wantKey:  "multiple_answer|Bilangan prima?|[2:true 3:true 4:false]|[]|-|",
+		},
+		{
+			name:     "true false",
+			input:    "// komentar\nBumi itu datar. {F}",
+			wantType: QuestionTrueFalse,
+		},
+		{
+			name:     "short answer with escaped characters",
+			input:    `Rumus air\: {=H2O =air}`,
+			wantType: QuestionShortAnswer,
+			wantKey:  "short_answer|Rumus air:|[]|[H2O air]|-|",
+		},
+		{
+			name:     "numeric with tolerance",
+			input:    "Nilai pi? {#3.14:0.01}",
+			wantType: QuestionNumeric,
+			wantKey:  "numeric|Nilai pi?|[]|[]|3.14±0.01|",
+		},
+		{
+			name:     "numeric range",
+			input:    "Angka antara 1 dan 5? {#1..5}",
+			wantType: QuestionNumeric,
+			wantKey:  "numeric|Angka antara 1 dan 5?|[]|[]|3±2|",
+		},
+		{
+			name:     "essay with general feedback",
+			input:    "Jelaskan fotosintesis. {####Cahaya dan klorofil}",
+			wantType: QuestionEssay,
+			wantKey:  "essay|Jelaskan fotosintesis.|[]|[]|-|Cahaya dan klorofil",
+		},
+		{
+			name:     "missing word question",
+			input:    "Ibu kota Indonesia adalah {=Jakarta} sejak 1945.",
+			wantType: QuestionShortAnswer,
+			wantKey:  "short_answer|Ibu kota Indonesia adalah _____ sejak 1945.|[]|[Jakarta]|-|",
+		},
+		{
+			name:     "html question",
+			input:    "[html]<p>Warna <b>langit</b>?</p> {=biru ~merah}",
+			wantType: QuestionMultipleChoice,
+			wantKey:  "multiple_choice|Warna  langit ?|[biru:true merah:false]|[]|-|",
+		},
+		{name: "matching is not supported", input: "Pasangkan {=a -> 1 =b -> 2}", wantError: true},
+		{name: "unclosed answer block", input: "Soal {=a ~b", wantError: true},
+		{name: "no answer block", input: "Hanya teks", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseGIFT([]byte(tt.input))
+			if len(parsed) != 1 {
+				t.Fatalf("got %d questions, want 1", len(parsed))
+			}
+			question := parsed[0]
+			if tt.wantError {
+				if len(question.Errors) == 0 {
+					t.Errorf("expected a parse error, got %+v", question.Request)
+				}
+				return
+			}
+			if len(question.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", question.Errors)
+			}
+			if question.Request.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", question.Request.Type, tt.wantType)
+			}
+			if tt.wantKey != "" {
+				if got := questionKey(question.Request.Question); got != tt.wantKey {
+					t.Errorf("question = %s\nwant       %s", got, tt.wantKey)
+				}
+			}
+			if question.Title != tt.wantTitle || question.Topic != tt.wantTopic {
+				t.Errorf("title/topic = %q/%q, want %q/%q", question.Title, question.Topic, tt.wantTitle, tt.wantTopic)
+			}
+		})
+	}
+}
+
+func TestWriteAikenRoundTrip(t *testing.T) {
+	data, warnings := writeAiken(sampleExportQuestions())
+	// Hanya pilihan ganda dan benar/salah yang didukung Aiken
+	if len(warnings) != 4 {
+		t.Errorf("got %d warnings, want 4: %v", len(warnings), warnings)
+	}
+
+	parsed := parseAiken(data)
+	if len(parsed) != 2 {
+		t.Fatalf("got %d questions back, want 2:\n%s", len(parsed), data)
+	}
+	want := []string{
+		"multiple_choice|Planet terdekat dari matahari?|[Merkurius:true Venus:false Mars:false]|[]|-|",
+		"multiple_choice|Bumi itu bulat|[Benar:true Salah:false]|[]|-|",
+	}
+	for i, question := range parsed {
+		if len(question.Errors) > 0 {
+			t.Errorf("question %d errors: %v", i, question.Errors)
+		}
+		if got := questionKey(question.Request.Question); got != want[i] {
+			t.Errorf("question %d = %s\nwant         %s", i, got, want[i])
+		}
+	}
+}
+
+func TestWriteGIFTRoundTrip(t *testing.T) {
+	questions := sampleExportQuestions()
+	questions[0].Question.Prompt = "Simbol {x} = 1: pilih ~ yang #benar"
+	data, warnings := writeGIFT(questions)
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	parsed := parseGIFT(data)
+	if len(parsed) != len(questions) {
+		t.Fatalf("got %d questions back, want %d:\n%s", len(parsed), len(questions), data)
+	}
+	for i, question := range parsed {
+		if len(question.Errors) > 0 {
+			t.Errorf("question %d errors: %v", i, question.Errors)
+			continue
+		}
+		if question.Title != questions[i].Title {
+			t.Errorf("question %d title = %q, want %q", i, question.Title, questions[i].Title)
+		}
+		got := question.Request.Question
+		if got.Type == QuestionTrueFalse {
+			// Opsi benar/salah baru dibuat saat validasi dari correct_answer
+			if question.Request.CorrectAnswer == nil || !*question.Request.CorrectAnswer {
+				t.Errorf("question %d correct_answer = %v, want true", i, question.Request.CorrectAnswer)
+			}
+			continue
+		}
+		if questionKey(got) != questionKey(questions[i].Question) {
+			t.Errorf("question %d = %s\nwant         %s", i, questionKey(got), questionKey(questions[i].Question))
+		}
+	}
+}
+
+func TestQTIRoundTrip(t *testing.T) {
+	questions := sampleExportQuestions()
+	questions[0].Question.Prompt = "Planet <terdekat> & \"terpanas\"?"
+	data, _, err := writeQTI("Ulangan IPA", questions)
+	if err != nil {
+		t.Fatalf("writeQTI: %v", err)
+	}
+
+	parsed, err := parseQTI(data)
+	if err != nil {
+		t.Fatalf("parseQTI: %v", err)
+	}
+	if len(parsed) != len(questions) {
+		t.Fatalf("got %d questions back, want %d", len(parsed), len(questions))
+	}
+	for i, question := range parsed {
+		if len(question.Errors) > 0 {
+			t.Errorf("question %d errors: %v", i, question.Errors)
+			continue
+		}
+		want := questions[i].Question
+		got := question.Request.Question
+		if got.Type != want.Type || got.Prompt != want.Prompt || got.Points != want.Points {
+			t.Errorf("question %d = %s/%q/%g, want %s/%q/%g", i, got.Type, got.Prompt, got.Points, want.Type, want.Prompt, want.Points)
+		}
+		if want.Type == QuestionTrueFalse {
+			continue
+		}
+		var gotCorrect, wantCorrect []bool
+		for _, option := range got.Options {
+			gotCorrect = append(gotCorrect, option.Correct)
+		}
+		for _, option := range want.Options {
+			wantCorrect = append(wantCorrect, option.Correct)
+		}
+		if !reflect.DeepEqual(gotCorrect, wantCorrect) || !reflect.DeepEqual(got.AcceptedAnswers, want.AcceptedAnswers) {
+			t.Errorf("question %d answers = %v %v, want %v %v", i, gotCorrect, got.AcceptedAnswers, wantCorrect, want.AcceptedAnswers)
+		}
+		if (got.NumericAnswer == nil) != (want.NumericAnswer == nil) ||
+			(want.NumericAnswer != nil && (*got.NumericAnswer != *want.NumericAnswer || got.Tolerance != want.Tolerance)) {
+			t.Errorf("question %d numeric answer = %v±%g, want %v±%g", i, got.NumericAnswer, got.Tolerance, want.NumericAnswer, want.Tolerance)
+		}
+	}
+}
+
+func TestParseQTIRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "not xml", input: "bukan xml <", wantErr: "invalid XML"},
+		{name: "no assessment item", input: `<?xml version="1.0"?><assessmentTest identifier="t"/>`, wantErr: "no assessmentItem"},
+		{name: "broken zip", input: "PK\x03\x04rusak", wantErr: "invalid zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseQTI([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"project/dto"
+	"project/model"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrImportInvalid         = errors.New("import file contains invalid questions")
+	ErrUnknownQuestionFormat = errors.New("unknown question format, use aiken, gift or qti")
+)
+
+var aikenAnswerLine = regexp.MustCompile(`(?mi)^\s*ANSWER\s*:`)
+
+// detectQuestionFormat memakai format dari form, atau menebak dari ekstensi dan isi file.
+func detectQuestionFormat(format, fileName string, data []byte) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatAiken:
+		return FormatAiken, nil
+	case FormatGIFT:
+		return FormatGIFT, nil
+	case FormatQTI:
+		return FormatQTI, nil
+	case "":
+	default:
+		return "", ErrUnknownQuestionFormat
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".gift":
+		return FormatGIFT, nil
+	case ".xml", ".zip":
+		return FormatQTI, nil
+	}
+	if aikenAnswerLine.Match(data) {
+		return FormatAiken, nil
+	}
+	return FormatGIFT, nil
+}
+
+// PreviewImport membaca dan memvalidasi file tanpa menyimpan apa pun.
+func (s *QuestionBankService) PreviewImport(data []byte, options dto.QuestionImportOptions) (*dto.QuestionImportReport, error) {
+	report, _, err := buildImportReport(data, options)
+	return report, err
+}
+
+// ImportQuestions menyimpan soal valid ke bank soal user. Jika ada soal tidak valid, import
+// dibatalkan kecuali SkipInvalid; laporan tetap dikembalikan bersama ErrImportInvalid.
+func (s *QuestionBankService) ImportQuestions(userID int, data []byte, options dto.QuestionImportOptions) (*dto.QuestionImportReport, error) {
+	report, requests, err := buildImportReport(data, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Errors) > 0 || report.Valid == 0 || (report.Invalid > 0 && !options.SkipInvalid) {
+		return report, ErrImportInvalid
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i := range report.Questions {
+		item := &report.Questions[i]
+		if len(item.Errors) > 0 {
+			continue
+		}
+		content, err := json.Marshal(item.Question)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode question: %w", err)
+		}
+		questionID, err := insertBankQuestion(tx, userID, requests[i], content)
+		if err != nil {
+			return nil, err
+		}
+		item.BankQuestionID = &questionID
+		report.Imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import questions: %w", err)
+	}
+	return report, nil
+}
+
+// buildImportReport mem-parse file lalu memvalidasi setiap soal dengan aturan yang sama seperti
+// soal yang dibuat lewat API. requests berisi tag per soal, sejajar dengan report.Questions.
+func buildImportReport(data []byte, options dto.QuestionImportOptions) (*dto.QuestionImportReport, []dto.BankQuestionRequest, error) {
+	format, err := detectQuestionFormat(options.Format, options.FileName, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := &dto.QuestionImportReport{Format: format, Errors: []dto.FieldError{}, Questions: []dto.ImportedQuestion{}}
+	switch options.Difficulty {
+	case "", "easy", "medium", "hard":
+	default:
+		report.Errors = append(report.Errors, dto.FieldError{Field: "difficulty", Code: "invalid", Message: "Difficulty must be easy, medium or hard"})
+	}
+
+	var parsed []parsedQuestion
+	switch format {
+	case FormatAiken:
+		parsed = parseAiken(data)
+	case FormatGIFT:
+		parsed = parseGIFT(data)
+	case FormatQTI:
+		parsed, err = parseQTI(data)
+		if err != nil {
+			report.Errors = append(report.Errors, dto.FieldError{Field: "file", Code: "invalid", Message: err.Error()})
+		}
+	}
+	if len(parsed) == 0 && len(report.Errors) == 0 {
+		report.Errors = append(report.Errors, dto.FieldError{Field: "file", Code: "empty", Message: "No questions found in file"})
+	}
+
+	requests := make([]dto.BankQuestionRequest, 0, len(parsed))
+	for i, source := range parsed {
+		item := dto.ImportedQuestion{
+			Index:    i + 1,
+			Line:     source.Line,
+			Source:   source.Source,
+			Title:    source.Title,
+			Topic:    source.Topic,
+			Errors:   append([]dto.FieldError{}, source.Errors...),
+			Warnings: append([]string{}, source.Warnings...),
+		}
+		if options.Topic != "" {
+			item.Topic = options.Topic
+		}
+		if len(source.Errors) == 0 {
+			violations := &ValidationError{}
+			question := normalizeQuestion(source.Request, "question", violations)
+			item.Errors = append(item.Errors, violations.Errors...)
+			item.Question = &question
+		} else if source.Request.Prompt != "" {
+			question := model.Question{Type: source.Request.Type, Prompt: source.Request.Prompt, Points: source.Request.Points}
+			item.Question = &question
+		}
+
+		report.Total++
+		if len(item.Errors) == 0 {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+		report.Questions = append(report.Questions, item)
+		requests = append(requests, dto.BankQuestionRequest{
+			Subject:    options.Subject,
+			Topic:      item.Topic,
+			Difficulty: options.Difficulty,
+			Competency: options.Competency,
+			Shared:     options.Shared,
+		})
+	}
+	return report, requests, nil
+}
+
+// ExportQuestions menulis soal bank yang cocok dengan filter ke format yang dipilih.
+func (s *QuestionBankService) ExportQuestions(userID int, role string, filter dto.BankQuestionFilter, format string) ([]byte, []string, error) {
+	questions, err := s.ListQuestions(userID, role, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]exportQuestion, 0, len(questions))
+	for _, question := range questions {
+		items = append(items, exportQuestion{Title: bankExportTitle(question), Question: question.Question})
+	}
+	return writeQuestions(format, "Bank soal", items)
+}
+
+// ExportQuiz menulis soal tetap kuis. Soal acak dari aturan pengambilan tidak ikut karena
+// berbeda untuk setiap siswa.
+func (s *QuizService) ExportQuiz(classID, quizID int, format string) ([]byte, []string, string, error) {
+	quiz, err := s.GetQuiz(classID, quizID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	items := make([]exportQuestion, 0, len(quiz.Questions))
+	for _, question := range quiz.Questions {
+		items = append(items, exportQuestion{Title: fmt.Sprintf("%s %d", quiz.Title, question.Position), Question: question.Question})
+	}
+	content, warnings, err := writeQuestions(format, quiz.Title, items)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	for _, rule := range quiz.DrawRules {
+		warnings = append(warnings, fmt.Sprintf("Draw rule %d (%d random questions) is not exported", rule.Position, rule.Count))
+	}
+	return content, warnings, quiz.Title, nil
+}
+
+func writeQuestions(format, title string, items []exportQuestion) ([]byte, []string, error) {
+	switch format {
+	case FormatAiken:
+		content, warnings := writeAiken(items)
+		return content, warnings, nil
+	case FormatGIFT:
+		content, warnings := writeGIFT(items)
+		return content, warnings, nil
+	case FormatQTI:
+		return writeQTI(title, items)
+	}
+	return nil, nil, ErrUnknownQuestionFormat
+}
+
+func bankExportTitle(question model.BankQuestion) string {
+	parts := []string{}
+	for _, part := range []string{question.Subject, question.Topic} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	parts = append(parts, fmt.Sprintf("#%d", question.ID))
+	return strings.Join(parts, " - ")
+}
@@ -0,0 +1,636 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"project/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	qtiNamespace   = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiMapResponse = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+	// Batas ukuran satu file di dalam paket zip
+	qtiMaxEntrySize = 5 << 20
+)
+
+// xmlNode adalah pohon XML sederhana yang menjaga urutan teks dan elemen (untuk konten campuran
+// seperti <p>Ibu kota <textEntryInteraction/> adalah ...</p>). Nama memakai local name.
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Children []interface{}
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, string(t))
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty XML document")
+	}
+	return root, nil
+}
+
+// find mengembalikan keturunan pertama dengan nama tersebut.
+func (n *xmlNode) find(name string) *xmlNode {
+	for _, child := range n.Children {
+		if node, ok := child.(*xmlNode); ok {
+			if node.Name == name {
+				return node
+			}
+			if found := node.find(name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) findAll(name string) []*xmlNode {
+	var result []*xmlNode
+	for _, child := range n.Children {
+		if node, ok := child.(*xmlNode); ok {
+			if node.Name == name {
+				result = append(result, node)
+			}
+			result = append(result, node.findAll(name)...)
+		}
+	}
+	return result
+}
+
+var qtiBlockElements = map[string]bool{"p": true, "div": true, "br": true, "li": true, "tr": true, "h1": true, "h2": true, "h3": true, "blockquote": true, "pre": true}
+
+// text mengubah konten campuran menjadi teks biasa. Interaksi dilewati, kecuali isian
+// (textEntryInteraction) yang diganti garis kosong.
+func (n *xmlNode) text() string {
+	var buf strings.Builder
+	var walk func(node *xmlNode)
+	walk = func(node *xmlNode) {
+		for _, child := range node.Children {
+			switch c := child.(type) {
+			case string:
+				buf.WriteString(c)
+			case *xmlNode:
+				switch {
+				case c.Name == "textEntryInteraction" || c.Name == "inlineChoiceInteraction":
+					buf.WriteString(" _____ ")
+				case strings.HasSuffix(c.Name, "Interaction") || c.Name == "feedbackInline" || c.Name == "rubricBlock":
+				default:
+					if qtiBlockElements[c.Name] {
+						buf.WriteString("\n")
+					}
+					walk(c)
+					if qtiBlockElements[c.Name] {
+						buf.WriteString("\n")
+					}
+				}
+			}
+		}
+	}
+	walk(n)
+
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = singleLine(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseQTI membaca satu assessmentItem QTI 2.1, atau paket konten zip berisi banyak item.
+func parseQTI(data []byte) ([]parsedQuestion, error) {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return parseQTIPackage(data)
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XML: %v", err)
+	}
+	items := []*xmlNode{root}
+	if root.Name != "assessmentItem" {
+		items = root.findAll("assessmentItem")
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no assessmentItem found; upload a QTI item or a content package (zip) for tests")
+	}
+	result := make([]parsedQuestion, 0, len(items))
+	for _, item := range items {
+		result = append(result, parseQTIItem(item))
+	}
+	return result, nil
+}
+
+func parseQTIPackage(data []byte) ([]parsedQuestion, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip package: %v", err)
+	}
+	files := map[string]*zip.File{}
+	var order []string
+	for _, file := range archive.File {
+		if strings.HasSuffix(strings.ToLower(file.Name), ".xml") {
+			files[file.Name] = file
+			order = append(order, file.Name)
+		}
+	}
+
+	// Urutan soal mengikuti manifest jika ada
+	if manifest, ok := files["imsmanifest.xml"]; ok {
+		content, err := readZipEntry(manifest)
+		if err != nil {
+			return nil, err
+		}
+		root, err := parseXMLTree(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid imsmanifest.xml: %v", err)
+		}
+		var listed []string
+		for _, resource := range root.findAll("resource") {
+			href := path.Clean(resource.Attrs["href"])
+			if strings.HasPrefix(resource.Attrs["type"], "imsqti_item") && files[href] != nil {
+				listed = append(listed, href)
+			}
+		}
+		if len(listed) > 0 {
+			order = listed
+		}
+	}
+
+	var result []parsedQuestion
+	for _, name := range order {
+		if name == "imsmanifest.xml" {
+			continue
+		}
+		content, err := readZipEntry(files[name])
+		if err != nil {
+			return nil, err
+		}
+		root, err := parseXMLTree(content)
+		if err != nil {
+			parsed := parsedQuestion{Source: name}
+			parsed.fail("", "invalid_xml", fmt.Sprintf("Invalid XML: %v", err))
+			result = append(result, parsed)
+			continue
+		}
+		if root.Name != "assessmentItem" {
+			continue
+		}
+		parsed := parseQTIItem(root)
+		parsed.Source = name
+		result = append(result, parsed)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("package contains no assessmentItem")
+	}
+	return result, nil
+}
+
+func readZipEntry(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > qtiMaxEntrySize {
+		return nil, fmt.Errorf("%s is too large", file.Name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", file.Name, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, qtiMaxEntrySize))
+}
+
+func parseQTIItem(item *xmlNode) parsedQuestion {
+	parsed := parsedQuestion{Title: item.Attrs["title"]}
+	parsed.Request.Points = qtiMaxScore(item)
+	if feedback := item.find("modalFeedback"); feedback != nil {
+		parsed.Request.Explanation = feedback.text()
+	}
+
+	body := item.find("itemBody")
+	if body == nil {
+		parsed.fail("", "invalid", "Item has no itemBody")
+		return parsed
+	}
+	var interactions []*xmlNode
+	for _, name := range []string{"choiceInteraction", "textEntryInteraction", "extendedTextInteraction"} {
+		interactions = append(interactions, body.findAll(name)...)
+	}
+	prompt := body.text()
+	if len(interactions) == 1 {
+		if inner := interactions[0].find("prompt"); inner != nil {
+			prompt = strings.TrimSpace(prompt + "\n" + inner.text())
+		}
+	}
+	// Kotak isian di baris sendiri tidak perlu ditulis di pertanyaan
+	parsed.Request.Prompt = strings.TrimSpace(strings.TrimSuffix(prompt, "_____"))
+
+	if len(interactions) != 1 {
+		parsed.fail("type", "unsupported", "Only items with exactly one choice, text entry or extended text interaction are supported")
+		return parsed
+	}
+	interaction := interactions[0]
+	declaration := qtiResponseDeclaration(item, interaction.Attrs["responseIdentifier"])
+	correct, caseSensitive := qtiCorrectValues(declaration)
+
+	switch interaction.Name {
+	case "choiceInteraction":
+		var options []model.QuestionOption
+		for _, choice := range interaction.findAll("simpleChoice") {
+			id := choice.Attrs["identifier"]
+			options = append(options, model.QuestionOption{ID: id, Text: choice.text(), Correct: correct[id]})
+		}
+		parsed.Request.Options = options
+		parsed.Request.Type = QuestionMultipleChoice
+		maxChoices := interaction.Attrs["maxChoices"]
+		if (declaration != nil && declaration.Attrs["cardinality"] == "multiple") || (maxChoices != "" && maxChoices != "1") {
+			parsed.Request.Type = QuestionMultipleAnswer
+		}
+		if parsed.Request.Type == QuestionMultipleChoice && isQTIBoolean(options) {
+			parsed.Request.Type = QuestionTrueFalse
+			value := false
+			for _, option := range options {
+				if option.Correct {
+					value = strings.EqualFold(option.ID, "true") || qtiTrueTexts[strings.ToLower(option.Text)]
+				}
+			}
+			parsed.Request.CorrectAnswer = &value
+			parsed.Request.Options = nil
+		}
+	case "textEntryInteraction":
+		baseType := ""
+		if declaration != nil {
+			baseType = declaration.Attrs["baseType"]
+		}
+		if baseType == "float" || baseType == "integer" {
+			parsed.Request.Type = QuestionNumeric
+			for value := range correct {
+				number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					parsed.fail("numeric_answer", "invalid", fmt.Sprintf("Invalid numeric answer %q", value))
+					continue
+				}
+				parsed.Request.NumericAnswer = &number
+				break
+			}
+			parsed.Request.Tolerance = qtiTolerance(item, parsed.Request.NumericAnswer)
+		} else {
+			parsed.Request.Type = QuestionShortAnswer
+			parsed.Request.CaseSensitive = caseSensitive
+			for _, value := range qtiOrderedValues(declaration) {
+				if correct[value] {
+					parsed.Request.AcceptedAnswers = append(parsed.Request.AcceptedAnswers, value)
+				}
+			}
+		}
+	case "extendedTextInteraction":
+		parsed.Request.Type = QuestionEssay
+	}
+	return parsed
+}
+
+var qtiTrueTexts = map[string]bool{"true": true, "benar": true, "betul": true}
+
+func isQTIBoolean(options []model.QuestionOption) bool {
+	if len(options) != 2 {
+		return false
+	}
+	known := map[string]bool{"true": true, "false": true, "benar": true, "salah": true, "betul": true}
+	for _, option := range options {
+		if !known[strings.ToLower(option.ID)] && !known[strings.ToLower(option.Text)] {
+			return false
+		}
+	}
+	return true
+}
+
+func qtiResponseDeclaration(item *xmlNode, identifier string) *xmlNode {
+	for _, declaration := range item.findAll("responseDeclaration") {
+		if declaration.Attrs["identifier"] == identifier {
+			return declaration
+		}
+	}
+	return nil
+}
+
+// qtiCorrectValues mengambil jawaban benar dari correctResponse, ditambah mapEntry dengan
+// nilai positif (dipakai paket yang hanya memakai mapping).
+func qtiCorrectValues(declaration *xmlNode) (map[string]bool, bool) {
+	correct := map[string]bool{}
+	caseSensitive := false
+	if declaration == nil {
+		return correct, false
+	}
+	if response := declaration.find("correctResponse"); response != nil {
+		for _, value := range response.findAll("value") {
+			correct[strings.TrimSpace(value.text())] = true
+		}
+	}
+	for _, entry := range declaration.findAll("mapEntry") {
+		if mapped, err := strconv.ParseFloat(entry.Attrs["mappedValue"], 64); err == nil && mapped > 0 {
+			correct[entry.Attrs["mapKey"]] = true
+		}
+		if entry.Attrs["caseSensitive"] == "true" {
+			caseSensitive = true
+		}
+	}
+	return correct, caseSensitive
+}
+
+// qtiOrderedValues mengembalikan nilai correctResponse lalu mapKey sesuai urutan di file.
+func qtiOrderedValues(declaration *xmlNode) []string {
+	if declaration == nil {
+		return nil
+	}
+	var values []string
+	seen := map[string]bool{}
+	add := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if response := declaration.find("correctResponse"); response != nil {
+		for _, value := range response.findAll("value") {
+			add(strings.TrimSpace(value.text()))
+		}
+	}
+	for _, entry := range declaration.findAll("mapEntry") {
+		add(entry.Attrs["mapKey"])
+	}
+	return values
+}
+
+// qtiMaxScore membaca bobot soal dari MAXSCORE atau normalMaximum SCORE (default 1).
+func qtiMaxScore(item *xmlNode) float64 {
+	for _, outcome := range item.findAll("outcomeDeclaration") {
+		if outcome.Attrs["identifier"] == "MAXSCORE" {
+			if value := outcome.find("value"); value != nil {
+				if points, err := strconv.ParseFloat(strings.TrimSpace(value.text()), 64); err == nil && points > 0 {
+					return points
+				}
+			}
+		}
+	}
+	for _, outcome := range item.findAll("outcomeDeclaration") {
+		if outcome.Attrs["identifier"] == "SCORE" {
+			if points, err := strconv.ParseFloat(outcome.Attrs["normalMaximum"], 64); err == nil && points > 0 {
+				return points
+			}
+		}
+	}
+	return 1
+}
+
+// qtiTolerance membaca toleransi dari <equal toleranceMode="absolute|relative" tolerance="...">.
+func qtiTolerance(item *xmlNode, answer *float64) float64 {
+	processing := item.find("responseProcessing")
+	if processing == nil {
+		return 0
+	}
+	equal := processing.find("equal")
+	if equal == nil {
+		return 0
+	}
+	fields := strings.Fields(equal.Attrs["tolerance"])
+	if len(fields) == 0 {
+		return 0
+	}
+	tolerance, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	if equal.Attrs["toleranceMode"] == "relative" && answer != nil {
+		return math.Abs(*answer) * tolerance / 100
+	}
+	return tolerance
+}
+
+var qtiIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// writeQTI menulis paket konten QTI 2.1 (zip): imsmanifest.xml, satu file per soal dan
+// assessmentTest yang memuat semua soal sesuai urutan.
+func writeQTI(title string, questions []exportQuestion) ([]byte, []string, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	var resources, refs strings.Builder
+	var dependencies strings.Builder
+
+	for i, item := range questions {
+		identifier := fmt.Sprintf("item_%03d", i+1)
+		href := "items/" + identifier + ".xml"
+		writer, err := archive.Create(href)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := io.WriteString(writer, qtiItemXML(identifier, item, i)); err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(&resources, `    <resource identifier="%s" type="imsqti_item_xmlv2p1" href="%s"><file href="%s"/></resource>`+"\n", identifier, href, href)
+		fmt.Fprintf(&dependencies, `      <dependency identifierref="%s"/>`+"\n", identifier)
+		fmt.Fprintf(&refs, `      <assessmentItemRef identifier="%s" href="%s"/>`+"\n", identifier, href)
+	}
+
+	files := []struct{ name, content string }{
+		{"assessment.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<assessmentTest xmlns="` + qtiNamespace + `" identifier="test" title="` + xmlEscapeText(title) + `">
+  <testPart identifier="part_1" navigationMode="nonlinear" submissionMode="simultaneous">
+    <assessmentSection identifier="section_1" title="` + xmlEscapeText(title) + `" visible="true">
+` + refs.String() + `    </assessmentSection>
+  </testPart>
+</assessmentTest>
+`},
+		{"imsmanifest.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1" identifier="manifest">
+  <metadata><schema>QTIv2.1 Package</schema><schemaversion>1.0.0</schemaversion></metadata>
+  <organizations/>
+  <resources>
+    <resource identifier="test" type="imsqti_test_xmlv2p1" href="assessment.xml">
+      <file href="assessment.xml"/>
+` + dependencies.String() + `    </resource>
+` + resources.String() + `  </resources>
+</manifest>
+`},
+	}
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := io.WriteString(writer, file.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	for i, item := range questions {
+		for _, answer := range item.Question.AcceptedAnswers {
+			if strings.Contains(answer, "*") || (strings.HasPrefix(answer, "/") && strings.HasSuffix(answer, "/") && len(answer) > 2) {
+				warnings = append(warnings, fmt.Sprintf("Question %d: pattern answer %s is exported as an exact answer", i+1, answer))
+			}
+		}
+	}
+	return buf.Bytes(), warnings, nil
+}
+
+func qtiItemXML(identifier string, item exportQuestion, index int) string {
+	question := item.Question
+	title := item.Title
+	if title == "" {
+		title = fmt.Sprintf("Question %d", index+1)
+	}
+	points := formatQTINumber(question.Points)
+
+	var declaration, body, processing strings.Builder
+	body.WriteString(qtiParagraphs(question.Prompt))
+
+	switch question.Type {
+	case QuestionMultipleChoice, QuestionMultipleAnswer, QuestionTrueFalse:
+		cardinality, maxChoices := "single", "1"
+		if question.Type == QuestionMultipleAnswer {
+			cardinality, maxChoices = "multiple", "0"
+		}
+		correctCount := 0
+		for _, option := range question.Options {
+			if option.Correct {
+				correctCount++
+			}
+		}
+		var correct, mapping, choices strings.Builder
+		for i, option := range question.Options {
+			id := option.ID
+			if !qtiIdentifier.MatchString(id) {
+				id = "choice_" + optionLabel(i)
+			}
+			value := 0.0
+			if option.Correct {
+				fmt.Fprintf(&correct, "<value>%s</value>", id)
+				value = question.Points / float64(correctCount)
+			} else if question.Type == QuestionMultipleAnswer {
+				value = -question.Points / float64(correctCount)
+			}
+			fmt.Fprintf(&mapping, `<mapEntry mapKey="%s" mappedValue="%s"/>`, id, formatQTINumber(value))
+			fmt.Fprintf(&choices, "      <simpleChoice identifier=\"%s\">%s</simpleChoice>\n", id, xmlEscapeText(option.Text))
+		}
+		fmt.Fprintf(&declaration, `  <responseDeclaration identifier="RESPONSE" cardinality="%s" baseType="identifier">
+    <correctResponse>%s</correctResponse>
+    <mapping lowerBound="0" upperBound="%s" defaultValue="0">%s</mapping>
+  </responseDeclaration>
+`, cardinality, correct.String(), points, mapping.String())
+		fmt.Fprintf(&body, "    <choiceInteraction responseIdentifier=\"RESPONSE\" shuffle=\"false\" maxChoices=\"%s\">\n%s    </choiceInteraction>\n", maxChoices, choices.String())
+		processing.WriteString(`  <responseProcessing template="` + qtiMapResponse + `"/>` + "\n")
+	case QuestionShortAnswer:
+		var correct, mapping strings.Builder
+		for _, answer := range question.AcceptedAnswers {
+			fmt.Fprintf(&correct, "<value>%s</value>", xmlEscapeText(answer))
+			fmt.Fprintf(&mapping, `<mapEntry mapKey="%s" mappedValue="%s" caseSensitive="%t"/>`, xmlEscapeText(answer), points, question.CaseSensitive)
+		}
+		fmt.Fprintf(&declaration, `  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string">
+    <correctResponse>%s</correctResponse>
+    <mapping defaultValue="0">%s</mapping>
+  </responseDeclaration>
+`, correct.String(), mapping.String())
+		body.WriteString("    <p><textEntryInteraction responseIdentifier=\"RESPONSE\"/></p>\n")
+		processing.WriteString(`  <responseProcessing template="` + qtiMapResponse + `"/>` + "\n")
+	case QuestionNumeric:
+		answer := "0"
+		if question.NumericAnswer != nil {
+			answer = formatQTINumber(*question.NumericAnswer)
+		}
+		tolerance := formatQTINumber(question.Tolerance)
+		fmt.Fprintf(&declaration, `  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="float">
+    <correctResponse><value>%s</value></correctResponse>
+  </responseDeclaration>
+`, answer)
+		body.WriteString("    <p><textEntryInteraction responseIdentifier=\"RESPONSE\"/></p>\n")
+		fmt.Fprintf(&processing, `  <responseProcessing>
+    <responseCondition>
+      <responseIf>
+        <equal toleranceMode="absolute" tolerance="%s %s"><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></equal>
+        <setOutcomeValue identifier="SCORE"><baseValue baseType="float">%s</baseValue></setOutcomeValue>
+      </responseIf>
+    </responseCondition>
+  </responseProcessing>
+`, tolerance, tolerance, points)
+	case QuestionEssay:
+		declaration.WriteString(`  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string"/>` + "\n")
+		body.WriteString("    <extendedTextInteraction responseIdentifier=\"RESPONSE\"/>\n")
+	}
+
+	var feedback, feedbackOutcome string
+	if question.Explanation != "" {
+		feedbackOutcome = `  <outcomeDeclaration identifier="FEEDBACK" cardinality="single" baseType="identifier"/>` + "\n"
+		feedback = `  <modalFeedback outcomeIdentifier="FEEDBACK" identifier="EXPLANATION" showHide="hide">` +
+			xmlEscapeText(question.Explanation) + "</modalFeedback>\n"
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="` + qtiNamespace + `" identifier="` + identifier + `" title="` + xmlEscapeText(title) + `" adaptive="false" timeDependent="false">
+` + declaration.String() + `  <outcomeDeclaration identifier="SCORE" cardinality="single" baseType="float" normalMaximum="` + points + `"><defaultValue><value>0</value></defaultValue></outcomeDeclaration>
+  <outcomeDeclaration identifier="MAXSCORE" cardinality="single" baseType="float"><defaultValue><value>` + points + `</value></defaultValue></outcomeDeclaration>
+` + feedbackOutcome + `  <itemBody>
+` + body.String() + `  </itemBody>
+` + processing.String() + feedback + `</assessmentItem>
+`
+}
+
+func qtiParagraphs(text string) string {
+	var buf strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			buf.WriteString("    <p>" + xmlEscapeText(line) + "</p>\n")
+		}
+	}
+	return buf.String()
+}
+
+func formatQTINumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100000)/100000, 'f', -1, 64)
+}
+
+func xmlEscapeText(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
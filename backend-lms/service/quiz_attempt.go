@@ -0,0 +1,644 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+)
+
+// Status percobaan kuis. submitted berarti masih ada soal esai yang belum dinilai.
+const (
+	AttemptInProgress = "in_progress"
+	AttemptSubmitted  = "submitted"
+	AttemptGraded     = "graded"
+)
+
+var (
+	ErrQuizNotOpen      = errors.New("quiz is not open")
+	ErrNoAttemptsLeft   = errors.New("no attempts left for this quiz")
+	ErrQuizEmpty        = errors.New("quiz has no questions")
+	ErrAttemptNotFound  = errors.New("quiz attempt not found")
+	ErrAttemptFinished  = errors.New("quiz attempt has already been submitted or its time is up")
+	ErrAttemptNotScored = errors.New("quiz attempt has not been submitted")
+	ErrQuizItemNotFound = errors.New("question not found in this attempt")
+)
+
+// GetMyQuizzes mengembalikan kuis yang sudah dipublikasikan dengan ringkasan percobaan siswa.
+func (s *QuizService) GetMyQuizzes(classID, userID int) ([]dto.MyQuiz, error) {
+	if err := s.expireAttempts(0, userID); err != nil {
+		return nil, err
+	}
+	quizzes, err := s.GetQuizzes(classID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ringkasan percobaan semua kuis kelas diambil sekaligus
+	rows, err := s.DB.Query(`
+        SELECT a.quiz_id, COUNT(*), MAX(a.score) FILTER (WHERE a.status = 'graded'),
+               MAX(a.id) FILTER (WHERE a.status = 'in_progress')
+        FROM quiz_attempts a
+        JOIN quizzes q ON q.id = a.quiz_id
+        WHERE q.class_id = $1 AND a.user_id = $2
+        GROUP BY a.quiz_id
+    `, classID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiz attempts: %w", err)
+	}
+	defer rows.Close()
+	summaries := map[int]dto.MyQuiz{}
+	for rows.Next() {
+		var quizID int
+		var entry dto.MyQuiz
+		if err := rows.Scan(&quizID, &entry.AttemptsUsed, &entry.BestScore, &entry.InProgressID); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz attempts: %w", err)
+		}
+		summaries[quizID] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quiz attempts: %w", err)
+	}
+
+	now := time.Now()
+	result := make([]dto.MyQuiz, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		entry := summaries[quiz.ID]
+		entry.Quiz = quiz
+		entry.IsOpen = quizOpen(&quiz, now)
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// StartAttempt memulai percobaan baru, atau melanjutkan percobaan yang masih berjalan.
+// Soal (dan opsi) diacak sesuai pengaturan kuis lalu disalin ke percobaan.
+func (s *QuizService) StartAttempt(classID, quizID, userID int) (*model.QuizAttempt, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quiz, err := getQuiz(tx, classID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if !quiz.Published {
+		return nil, ErrQuizNotFound
+	}
+	if err := checkClassStudent(tx, classID, userID); err != nil {
+		return nil, err
+	}
+	if err := expireAttemptsTx(tx, quizID, userID); err != nil {
+		return nil, err
+	}
+
+	var inProgressID int
+	err = tx.QueryRow(`
+        SELECT id FROM quiz_attempts WHERE quiz_id = $1 AND user_id = $2 AND status = 'in_progress'
+    `, quizID, userID).Scan(&inProgressID)
+	if err == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to start attempt: %w", err)
+		}
+		return s.GetMyAttempt(classID, quizID, inProgressID, userID)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	now := time.Now()
+	if !quizOpen(quiz, now) {
+		return nil, ErrQuizNotOpen
+	}
+	var used int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM quiz_attempts WHERE quiz_id = $1 AND user_id = $2`, quizID, userID).Scan(&used); err != nil {
+		return nil, fmt.Errorf("failed to count attempts: %w", err)
+	}
+	if quiz.MaxAttempts > 0 && used >= quiz.MaxAttempts {
+		return nil, ErrNoAttemptsLeft
+	}
+
+	items, err := buildAttemptItems(tx, quiz)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrQuizEmpty
+	}
+	rng := rand.New(rand.NewSource(now.UnixNano()))
+	if quiz.ShuffleQuestions {
+		rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	}
+
+	var deadline *time.Time
+	if quiz.TimeLimitMinutes != nil {
+		limit := now.Add(time.Duration(*quiz.TimeLimitMinutes) * time.Minute)
+		deadline = &limit
+	}
+	if quiz.ClosesAt != nil && (deadline == nil || quiz.ClosesAt.Before(*deadline)) {
+		deadline = quiz.ClosesAt
+	}
+	maxScore := 0.0
+	for _, item := range items {
+		maxScore += item.Question.Points
+	}
+
+	var attemptID int
+	err = tx.QueryRow(`
+        INSERT INTO quiz_attempts (quiz_id, user_id, attempt_no, started_at, deadline_at, max_score)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+    `, quizID, userID, used+1, now, deadline, maxScore).Scan(&attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start attempt: %w", err)
+	}
+	for i, item := range items {
+		if quiz.ShuffleOptions {
+			shuffleOptions(&item.Question, rng)
+		}
+		content, err := json.Marshal(item.Question)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode question: %w", err)
+		}
+		_, err = tx.Exec(`
+            INSERT INTO quiz_attempt_items (attempt_id, position, question_id, bank_question_id, bank_version, question, needs_grading)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+        `, attemptID, i+1, item.QuestionID, item.BankQuestionID, item.BankVersion, string(content), item.Question.Type == QuestionEssay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save attempt question: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to start attempt: %w", err)
+	}
+	return s.GetMyAttempt(classID, quizID, attemptID, userID)
+}
+
+// buildAttemptItems menyiapkan soal untuk percobaan baru: soal tetap kuis, lalu soal acak
+// dari bank soal sesuai aturan pengambilan.
+func buildAttemptItems(q dbExecutor, quiz *model.Quiz) ([]model.QuizAttemptItem, error) {
+	questions, err := loadQuizQuestions(q, quiz.ID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.QuizAttemptItem, 0, len(questions))
+	used := []int{}
+	for _, question := range questions {
+		id := question.ID
+		items = append(items, model.QuizAttemptItem{QuestionID: &id, BankQuestionID: question.BankQuestionID,
+			BankVersion: question.BankVersion, Question: question.Question})
+		if question.BankQuestionID != nil {
+			used = append(used, *question.BankQuestionID)
+		}
+	}
+
+	rules, err := loadDrawRules(q, quiz.ID)
+	if err != nil {
+		return nil, err
+	}
+	drawn, err := drawBankQuestions(q, rules, used)
+	if err != nil {
+		return nil, err
+	}
+	return append(items, drawn...), nil
+}
+
+// GetMyAttempts mengembalikan semua percobaan siswa untuk satu kuis (tanpa soal).
+func (s *QuizService) GetMyAttempts(classID, quizID, userID int) ([]model.QuizAttempt, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	if err := s.expireAttempts(quizID, userID); err != nil {
+		return nil, err
+	}
+	return queryAttempts(s.DB, `WHERE a.quiz_id = $1 AND a.user_id = $2 ORDER BY a.attempt_no`, quizID, userID)
+}
+
+// GetMyAttempt mengembalikan percobaan siswa beserta soalnya. Kunci jawaban hanya
+// ditampilkan setelah dikumpulkan jika kuis mengizinkan.
+func (s *QuizService) GetMyAttempt(classID, quizID, attemptID, userID int) (*model.QuizAttempt, error) {
+	quiz, err := getQuiz(s.DB, classID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.expireAttempts(quizID, userID); err != nil {
+		return nil, err
+	}
+	attempt, err := getAttempt(s.DB, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.UserID != userID {
+		return nil, ErrAttemptNotFound
+	}
+	if err := loadAttemptItems(s.DB, attempt); err != nil {
+		return nil, err
+	}
+
+	revealKey := quiz.ShowAnswers && attempt.Status != AttemptInProgress
+	for i := range attempt.Items {
+		if !revealKey {
+			attempt.Items[i].Question = withoutAnswerKey(attempt.Items[i].Question)
+		}
+	}
+	return attempt, nil
+}
+
+// SaveAnswers menyimpan jawaban sementara selama percobaan masih berjalan.
+func (s *QuizService) SaveAnswers(classID, quizID, attemptID, userID int, req dto.SaveAnswersRequest) (*model.QuizAttempt, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	attempt, err := getOwnAttemptForUpdate(tx, classID, quizID, attemptID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Status != AttemptInProgress || attemptExpired(attempt, time.Now()) {
+		return nil, ErrAttemptFinished
+	}
+
+	for _, answer := range req.Answers {
+		response := answer.Response
+		response.Text = strings.TrimSpace(response.Text)
+		content, err := json.Marshal(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode answer: %w", err)
+		}
+		result, err := tx.Exec(`UPDATE quiz_attempt_items SET response = $1 WHERE id = $2 AND attempt_id = $3`,
+			string(content), answer.ItemID, attemptID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save answer: %w", err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("error checking rows affected: %w", err)
+		} else if rowsAffected == 0 {
+			return nil, ErrQuizItemNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save answers: %w", err)
+	}
+	return s.GetMyAttempt(classID, quizID, attemptID, userID)
+}
+
+// SubmitAttempt mengumpulkan percobaan dan menilai soal objektif secara otomatis.
+func (s *QuizService) SubmitAttempt(classID, quizID, attemptID, userID int) (*model.QuizAttempt, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	attempt, err := getOwnAttemptForUpdate(tx, classID, quizID, attemptID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Status != AttemptInProgress {
+		return nil, ErrAttemptFinished
+	}
+	// Pengumpulan setelah waktu habis tetap diproses dengan jawaban yang sudah tersimpan
+	submittedAt := time.Now()
+	if attemptExpired(attempt, submittedAt) {
+		submittedAt = *attempt.DeadlineAt
+	}
+	if err := finalizeAttempt(tx, attempt, submittedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to submit attempt: %w", err)
+	}
+	return s.GetMyAttempt(classID, quizID, attemptID, userID)
+}
+
+// GetResults merangkum hasil kuis per siswa kelas untuk guru.
+func (s *QuizService) GetResults(classID, quizID int) (*dto.QuizResults, error) {
+	quiz, err := getQuiz(s.DB, classID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.expireAttempts(quizID, 0); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT u.id, u.username, u.full_name, COUNT(a.id),
+               MAX(a.score) FILTER (WHERE a.status = 'graded'),
+               COUNT(a.id) FILTER (WHERE a.status = 'submitted'),
+               MAX(a.started_at)
+        FROM class_members cm
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN quiz_attempts a ON a.quiz_id = $2 AND a.user_id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.role = 'siswa'
+        GROUP BY u.id, u.username, u.full_name
+        ORDER BY u.username
+    `, classID, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz results: %w", err)
+	}
+	defer rows.Close()
+
+	results := &dto.QuizResults{QuizID: quizID, Students: []dto.QuizResultEntry{}}
+	for rows.Next() {
+		entry := dto.QuizResultEntry{MaxScore: quiz.TotalPoints}
+		if err := rows.Scan(&entry.UserID, &entry.Username, &entry.FullName, &entry.AttemptCount, &entry.BestScore,
+			&entry.NeedsGrading, &entry.LastAttempt); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz result: %w", err)
+		}
+		results.Students = append(results.Students, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quiz results: %w", err)
+	}
+	return results, nil
+}
+
+// GetAttempts mengembalikan semua percobaan kuis (opsional hanya yang perlu dinilai manual).
+func (s *QuizService) GetAttempts(classID, quizID int, needsGrading bool) ([]model.QuizAttempt, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	if err := s.expireAttempts(quizID, 0); err != nil {
+		return nil, err
+	}
+	return queryAttempts(s.DB, `
+        WHERE a.quiz_id = $1 AND ($2 = FALSE OR a.status = 'submitted')
+        ORDER BY a.submitted_at NULLS LAST, u.username, a.attempt_no
+    `, quizID, needsGrading)
+}
+
+// GetAttempt mengembalikan detail percobaan lengkap dengan kunci jawaban (untuk guru).
+func (s *QuizService) GetAttempt(classID, quizID, attemptID int) (*model.QuizAttempt, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	attempt, err := getAttempt(s.DB, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, loadAttemptItems(s.DB, attempt)
+}
+
+// GradeItem memberi nilai manual pada satu soal (esai, atau koreksi nilai otomatis).
+func (s *QuizService) GradeItem(classID, quizID, attemptID, itemID int, req dto.GradeItemRequest) (*model.QuizAttempt, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getQuiz(tx, classID, quizID); err != nil {
+		return nil, err
+	}
+	attempt, err := getAttempt(tx, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Status == AttemptInProgress {
+		return nil, ErrAttemptNotScored
+	}
+
+	var content []byte
+	err = tx.QueryRow(`SELECT question FROM quiz_attempt_items WHERE id = $1 AND attempt_id = $2`, itemID, attemptID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, ErrQuizItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt question: %w", err)
+	}
+	var question model.Question
+	if err := json.Unmarshal(content, &question); err != nil {
+		return nil, fmt.Errorf("failed to decode question: %w", err)
+	}
+	if *req.Points > question.Points {
+		violations := &ValidationError{}
+		violations.Add("points", "out_of_range", fmt.Sprintf("Points must be between 0 and %g", question.Points))
+		return nil, violations
+	}
+
+	_, err = tx.Exec(`
+        UPDATE quiz_attempt_items SET points_awarded = $1, feedback = $2 WHERE id = $3
+    `, *req.Points, strings.TrimSpace(req.Feedback), itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grade question: %w", err)
+	}
+	if err := updateAttemptScore(tx, attempt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to grade question: %w", err)
+	}
+	return s.GetAttempt(classID, quizID, attemptID)
+}
+
+func quizOpen(quiz *model.Quiz, now time.Time) bool {
+	if quiz.OpensAt != nil && now.Before(*quiz.OpensAt) {
+		return false
+	}
+	return quiz.ClosesAt == nil || now.Before(*quiz.ClosesAt)
+}
+
+func attemptExpired(attempt *model.QuizAttempt, now time.Time) bool {
+	return attempt.DeadlineAt != nil && now.After(*attempt.DeadlineAt)
+}
+
+// expireAttempts mengumpulkan otomatis percobaan yang waktunya sudah habis dalam transaksi
+// sendiri. quizID atau userID 0 berarti tidak difilter.
+func (s *QuizService) expireAttempts(quizID, userID int) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := expireAttemptsTx(tx, quizID, userID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to expire attempts: %w", err)
+	}
+	return nil
+}
+
+// expireAttemptsTx mengunci percobaan yang kedaluwarsa supaya tidak bentrok dengan
+// SubmitAttempt yang berjalan bersamaan, lalu mengumpulkannya.
+func expireAttemptsTx(tx *sql.Tx, quizID, userID int) error {
+	attempts, err := queryAttempts(tx, `
+        WHERE a.status = 'in_progress' AND a.deadline_at < NOW()
+          AND ($1 = 0 OR a.quiz_id = $1) AND ($2 = 0 OR a.user_id = $2)
+        ORDER BY a.id
+        FOR UPDATE OF a
+    `, quizID, userID)
+	if err != nil {
+		return err
+	}
+	for i := range attempts {
+		if err := finalizeAttempt(tx, &attempts[i], *attempts[i].DeadlineAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalizeAttempt menilai semua soal objektif, menyimpan waktu pengumpulan dan skor.
+func finalizeAttempt(q dbExecutor, attempt *model.QuizAttempt, submittedAt time.Time) error {
+	if err := loadAttemptItems(q, attempt); err != nil {
+		return err
+	}
+	for _, item := range attempt.Items {
+		points, needsGrading := scoreQuestion(item.Question, item.Response)
+		_, err := q.Exec(`
+            UPDATE quiz_attempt_items SET points_awarded = $1, needs_grading = $2 WHERE id = $3
+        `, points, needsGrading, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to score question: %w", err)
+		}
+	}
+	if _, err := q.Exec(`UPDATE quiz_attempts SET submitted_at = $1 WHERE id = $2`, submittedAt, attempt.ID); err != nil {
+		return fmt.Errorf("failed to submit attempt: %w", err)
+	}
+	return updateAttemptScore(q, attempt)
+}
+
+// updateAttemptScore menjumlahkan nilai soal. Percobaan menjadi graded jika semua soal
+// sudah bernilai, lalu nilai kuis di buku nilai diperbarui.
+func updateAttemptScore(q dbExecutor, attempt *model.QuizAttempt) error {
+	_, err := q.Exec(`
+        UPDATE quiz_attempts a
+        SET score = totals.score,
+            status = CASE WHEN totals.pending = 0 THEN 'graded' ELSE 'submitted' END
+        FROM (
+            SELECT COALESCE(SUM(points_awarded), 0) AS score, COUNT(*) FILTER (WHERE points_awarded IS NULL) AS pending
+            FROM quiz_attempt_items WHERE attempt_id = $1
+        ) totals
+        WHERE a.id = $1
+    `, attempt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update attempt score: %w", err)
+	}
+	return syncQuizGrade(q, attempt.QuizID, attempt.UserID)
+}
+
+// syncQuizGrade menulis nilai tertinggi dari percobaan yang sudah dinilai penuh ke tabel
+// grades (skala 0-100).
+func syncQuizGrade(q dbExecutor, quizID, userID int) error {
+	_, err := q.Exec(`
+        INSERT INTO grades (user_id, class_id, grade, quiz_id)
+        SELECT a.user_id, qz.class_id, ROUND(MAX(a.score / NULLIF(a.max_score, 0)) * 100)::INT, qz.id
+        FROM quiz_attempts a
+        JOIN quizzes qz ON qz.id = a.quiz_id
+        WHERE a.quiz_id = $1 AND a.user_id = $2 AND a.status = 'graded' AND a.max_score > 0
+        GROUP BY a.user_id, qz.class_id, qz.id
+        ON CONFLICT (user_id, quiz_id) WHERE quiz_id IS NOT NULL
+        DO UPDATE SET grade = EXCLUDED.grade
+    `, quizID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update gradebook: %w", err)
+	}
+	return nil
+}
+
+func getOwnAttemptForUpdate(q dbExecutor, classID, quizID, attemptID, userID int) (*model.QuizAttempt, error) {
+	if _, err := getQuiz(q, classID, quizID); err != nil {
+		return nil, err
+	}
+	attempts, err := queryAttempts(q, `WHERE a.id = $1 AND a.quiz_id = $2 AND a.user_id = $3 FOR UPDATE OF a`, attemptID, quizID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		return nil, ErrAttemptNotFound
+	}
+	return &attempts[0], nil
+}
+
+func getAttempt(q dbExecutor, quizID, attemptID int) (*model.QuizAttempt, error) {
+	attempts, err := queryAttempts(q, `WHERE a.id = $1 AND a.quiz_id = $2`, attemptID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		return nil, ErrAttemptNotFound
+	}
+	return &attempts[0], nil
+}
+
+func queryAttempts(q dbExecutor, where string, args ...interface{}) ([]model.QuizAttempt, error) {
+	rows, err := q.Query(`
+        SELECT a.id, a.quiz_id, a.user_id, u.username, a.attempt_no, a.status, a.started_at, a.deadline_at,
+               a.submitted_at, a.score, a.max_score
+        FROM quiz_attempts a
+        JOIN users u ON u.id = a.user_id
+    `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attempts: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	attempts := []model.QuizAttempt{}
+	for rows.Next() {
+		var attempt model.QuizAttempt
+		if err := rows.Scan(&attempt.ID, &attempt.QuizID, &attempt.UserID, &attempt.Username, &attempt.AttemptNo,
+			&attempt.Status, &attempt.StartedAt, &attempt.DeadlineAt, &attempt.SubmittedAt, &attempt.Score, &attempt.MaxScore); err != nil {
+			return nil, fmt.Errorf("failed to scan attempt: %w", err)
+		}
+		if attempt.Status == AttemptInProgress && attempt.DeadlineAt != nil {
+			remaining := int64(attempt.DeadlineAt.Sub(now) / time.Second)
+			if remaining < 0 {
+				remaining = 0
+			}
+			attempt.RemainingSeconds = &remaining
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+func loadAttemptItems(q dbExecutor, attempt *model.QuizAttempt) error {
+	rows, err := q.Query(`
+        SELECT id, position, question_id, bank_question_id, bank_version, question, response, points_awarded,
+               needs_grading, feedback
+        FROM quiz_attempt_items WHERE attempt_id = $1 ORDER BY position
+    `, attempt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query attempt questions: %w", err)
+	}
+	defer rows.Close()
+
+	attempt.Items = []model.QuizAttemptItem{}
+	for rows.Next() {
+		var item model.QuizAttemptItem
+		var question, response []byte
+		if err := rows.Scan(&item.ID, &item.Position, &item.QuestionID, &item.BankQuestionID, &item.BankVersion, &question,
+			&response, &item.PointsAwarded, &item.NeedsGrading, &item.Feedback); err != nil {
+			return fmt.Errorf("failed to scan attempt question: %w", err)
+		}
+		if err := json.Unmarshal(question, &item.Question); err != nil {
+			return fmt.Errorf("failed to decode attempt question: %w", err)
+		}
+		if response != nil {
+			item.Response = &model.QuestionResponse{}
+			if err := json.Unmarshal(response, item.Response); err != nil {
+				return fmt.Errorf("failed to decode answer: %w", err)
+			}
+		}
+		attempt.Items = append(attempt.Items, item)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"project/dto"
+	"project/model"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var ErrQuestionPoolTooSmall = errors.New("not enough questions in the question bank for this quiz")
+
+// AddBankQuestions menyalin versi terbaru soal bank ke kuis sebagai soal tetap.
+func (s *QuizService) AddBankQuestions(classID, quizID, userID int, role string, req dto.AddBankQuestionsRequest) ([]model.QuizQuestion, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getQuiz(tx, classID, quizID); err != nil {
+		return nil, err
+	}
+	added := []model.QuizQuestion{}
+	for _, bankQuestionID := range req.BankQuestionIDs {
+		source, err := getBankQuestion(tx, bankQuestionID, userID, role, false)
+		if err != nil {
+			return nil, err
+		}
+		content, err := json.Marshal(source.Question)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode question: %w", err)
+		}
+
+		saved := model.QuizQuestion{BankQuestionID: &source.ID, BankVersion: &source.CurrentVersion, Question: source.Question}
+		err = tx.QueryRow(`
+            INSERT INTO quiz_questions (quiz_id, position, question, bank_question_id, bank_version)
+            VALUES ($1, (SELECT COALESCE(MAX(position), 0) + 1 FROM quiz_questions WHERE quiz_id = $1), $2, $3, $4)
+            RETURNING id, position
+        `, quizID, string(content), source.ID, source.CurrentVersion).Scan(&saved.ID, &saved.Position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add question: %w", err)
+		}
+		added = append(added, saved)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to add questions: %w", err)
+	}
+	s.touchQuiz(quizID)
+	return added, nil
+}
+
+func (s *QuizService) GetDrawRules(classID, quizID int) ([]model.QuizDrawRule, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	return loadDrawRules(s.DB, quizID)
+}
+
+// SetDrawRules mengganti aturan pengambilan soal acak. Setiap aturan harus punya cukup soal
+// di bank (milik guru dan soal bersama) yang cocok dengan tagnya.
+func (s *QuizService) SetDrawRules(classID, quizID, userID int, req dto.DrawRulesRequest) ([]model.QuizDrawRule, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getQuiz(tx, classID, quizID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM quiz_draw_rules WHERE quiz_id = $1`, quizID); err != nil {
+		return nil, fmt.Errorf("failed to update draw rules: %w", err)
+	}
+	for i, rule := range req.Rules {
+		_, err := tx.Exec(`
+            INSERT INTO quiz_draw_rules (quiz_id, position, count, points, subject, topic, difficulty, competency, created_by)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        `, quizID, i+1, rule.Count, rule.Points, strings.TrimSpace(rule.Subject), strings.TrimSpace(rule.Topic),
+			rule.Difficulty, strings.TrimSpace(rule.Competency), userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save draw rule: %w", err)
+		}
+	}
+
+	rules, err := loadDrawRules(tx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	// Aturan dicek bersama: soal yang cocok dengan beberapa aturan hanya bisa dipakai sekali
+	fixed, err := quizBankQuestionIDs(tx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := loadDrawPools(tx, rules, fixed)
+	if err != nil {
+		return nil, err
+	}
+	picked := matchDrawPools(drawCounts(rules), pools)
+	violations := &ValidationError{}
+	for i, rule := range rules {
+		if rule.Available < rule.Count {
+			violations.Add(fmt.Sprintf("rules[%d].count", i), "not_enough_questions",
+				fmt.Sprintf("Only %d matching questions in the question bank", rule.Available))
+		} else if len(picked[i]) < rule.Count {
+			violations.Add(fmt.Sprintf("rules[%d].count", i), "overlapping_pool",
+				fmt.Sprintf("Only %d matching questions left after the quiz questions and other rules", len(picked[i])))
+		}
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update draw rules: %w", err)
+	}
+	s.touchQuiz(quizID)
+	return rules, nil
+}
+
+// bankPoolMatch memilih soal bank (b) yang cocok dengan aturan (r): milik pembuat aturan atau
+// soal bersama, dengan tag yang sama (filter kosong tidak membatasi).
+const bankPoolMatch = `
+    (b.owner_id = r.created_by OR b.shared)
+    AND (r.subject = '' OR LOWER(b.subject) = LOWER(r.subject))
+    AND (r.topic = '' OR LOWER(b.topic) = LOWER(r.topic))
+    AND (r.difficulty = '' OR b.difficulty = r.difficulty)
+    AND (r.competency = '' OR LOWER(b.competency) = LOWER(r.competency))
+`
+
+func loadDrawRules(q dbExecutor, quizID int) ([]model.QuizDrawRule, error) {
+	rows, err := q.Query(`
+        SELECT r.id, r.position, r.count, r.points, r.subject, r.topic, r.difficulty, r.competency, r.created_by,
+               (SELECT COUNT(*) FROM bank_questions b WHERE `+bankPoolMatch+`)
+        FROM quiz_draw_rules r
+        WHERE r.quiz_id = $1
+        ORDER BY r.position
+    `, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draw rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.QuizDrawRule{}
+	for rows.Next() {
+		var rule model.QuizDrawRule
+		if err := rows.Scan(&rule.ID, &rule.Position, &rule.Count, &rule.Points, &rule.Subject, &rule.Topic,
+			&rule.Difficulty, &rule.Competency, &rule.CreatedBy, &rule.Available); err != nil {
+			return nil, fmt.Errorf("failed to scan draw rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating draw rules: %w", err)
+	}
+	return rules, nil
+}
+
+// drawBankQuestions mengambil soal acak untuk semua aturan sekaligus. Soal yang sudah ada di
+// kuis (exclude) tidak diambil lagi dan satu soal tidak dipakai dua aturan, termasuk saat tag
+// aturan saling beririsan.
+func drawBankQuestions(q dbExecutor, rules []model.QuizDrawRule, exclude []int) ([]model.QuizAttemptItem, error) {
+	pools, err := loadDrawPools(q, rules, exclude)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	}
+	picked := matchDrawPools(drawCounts(rules), pools)
+	var ids []int
+	for i, rule := range rules {
+		if len(picked[i]) < rule.Count {
+			return nil, ErrQuestionPoolTooSmall
+		}
+		ids = append(ids, picked[i]...)
+	}
+	if len(ids) == 0 {
+		return []model.QuizAttemptItem{}, nil
+	}
+
+	rows, err := q.Query(`
+        SELECT b.id, b.current_version, v.question
+        FROM bank_questions b
+        JOIN bank_question_versions v ON v.bank_question_id = b.id AND v.version = b.current_version
+        WHERE b.id = ANY($1)
+    `, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw questions: %w", err)
+	}
+	defer rows.Close()
+
+	byID := map[int]model.QuizAttemptItem{}
+	for rows.Next() {
+		var item model.QuizAttemptItem
+		var bankQuestionID, version int
+		var content []byte
+		if err := rows.Scan(&bankQuestionID, &version, &content); err != nil {
+			return nil, fmt.Errorf("failed to scan drawn question: %w", err)
+		}
+		if err := json.Unmarshal(content, &item.Question); err != nil {
+			return nil, fmt.Errorf("failed to decode question: %w", err)
+		}
+		item.BankQuestionID, item.BankVersion = &bankQuestionID, &version
+		byID[bankQuestionID] = item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drawn questions: %w", err)
+	}
+
+	items := make([]model.QuizAttemptItem, 0, len(ids))
+	for i, rule := range rules {
+		for _, id := range picked[i] {
+			item, ok := byID[id]
+			if !ok {
+				return nil, ErrQuestionPoolTooSmall
+			}
+			// Semua siswa mendapat bobot yang sama untuk aturan ini
+			item.Question.Points = rule.Points
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// loadDrawPools mengembalikan id soal bank yang cocok untuk setiap aturan, tanpa exclude.
+func loadDrawPools(q dbExecutor, rules []model.QuizDrawRule, exclude []int) ([][]int, error) {
+	pools := make([][]int, len(rules))
+	for i, rule := range rules {
+		rows, err := q.Query(`
+            WITH r AS (
+                SELECT $1::INT AS created_by, $2::TEXT AS subject, $3::TEXT AS topic, $4::TEXT AS difficulty,
+                       $5::TEXT AS competency
+            )
+            SELECT b.id FROM r, bank_questions b
+            WHERE `+bankPoolMatch+` AND NOT (b.id = ANY($6))
+            ORDER BY b.id
+        `, rule.CreatedBy, rule.Subject, rule.Topic, rule.Difficulty, rule.Competency, pq.Array(exclude))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query question pool: %w", err)
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan question pool: %w", err)
+			}
+			pools[i] = append(pools[i], id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating question pool: %w", err)
+		}
+	}
+	return pools, nil
+}
+
+// quizBankQuestionIDs mengembalikan id soal bank yang sudah menjadi soal tetap kuis.
+func quizBankQuestionIDs(q dbExecutor, quizID int) ([]int, error) {
+	rows, err := q.Query(`SELECT bank_question_id FROM quiz_questions WHERE quiz_id = $1 AND bank_question_id IS NOT NULL`, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz questions: %w", err)
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func drawCounts(rules []model.QuizDrawRule) []int {
+	counts := make([]int, len(rules))
+	for i, rule := range rules {
+		counts[i] = rule.Count
+	}
+	return counts
+}
+
+// matchDrawPools membagi soal ke aturan sehingga tidak ada soal yang dipakai dua kali
+// (bipartite matching dengan augmenting path; aturan i punya counts[i] slot). Urutan pools[i]
+// menjadi preferensi. Aturan yang tidak bisa dipenuhi mendapat kurang dari counts[i] soal.
+func matchDrawPools(counts []int, pools [][]int) [][]int {
+	var slotRule []int
+	for i, count := range counts {
+		for j := 0; j < count; j++ {
+			slotRule = append(slotRule, i)
+		}
+	}
+	owner := map[int]int{} // id soal -> slot
+	var assign func(slot int, seen map[int]bool) bool
+	assign = func(slot int, seen map[int]bool) bool {
+		for _, id := range pools[slotRule[slot]] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if other, taken := owner[id]; !taken || assign(other, seen) {
+				owner[id] = slot
+				return true
+			}
+		}
+		return false
+	}
+	for slot := range slotRule {
+		assign(slot, map[int]bool{})
+	}
+
+	picked := make([][]int, len(counts))
+	for i, pool := range pools {
+		for _, id := range pool {
+			if slot, ok := owner[id]; ok && slotRule[slot] == i {
+				picked[i] = append(picked[i], id)
+			}
+		}
+	}
+	return picked
+}
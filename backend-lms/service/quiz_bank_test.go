@@ -0,0 +1,76 @@
+package service
+
+import "testing"
+
+func TestMatchDrawPools(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		pools  [][]int
+		want   []int // jumlah soal yang didapat tiap aturan
+	}{
+		{
+			name:   "disjoint pools",
+			counts: []int{2, 1},
+			pools:  [][]int{{1, 2, 3}, {4}},
+			want:   []int{2, 1},
+		},
+		{
+			name:   "broad rule leaves the shared question to the narrow rule",
+			counts: []int{2, 1},
+			pools:  [][]int{{1, 2, 3}, {1}},
+			want:   []int{2, 1},
+		},
+		{
+			name:   "overlapping pools are too small together",
+			counts: []int{2, 2},
+			pools:  [][]int{{1, 2, 3}, {2, 3}},
+			want:   []int{2, 1},
+		},
+		{
+			name:   "identical pools split the questions",
+			counts: []int{1, 1},
+			pools:  [][]int{{5, 6}, {5, 6}},
+			want:   []int{1, 1},
+		},
+		{
+			name:   "no rules",
+			counts: []int{},
+			pools:  [][]int{},
+			want:   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchDrawPools(tt.counts, tt.pools)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d rules, want %d", len(got), len(tt.want))
+			}
+			used := map[int]bool{}
+			for i, picked := range got {
+				if len(picked) != tt.want[i] {
+					t.Errorf("rule %d got %v, want %d questions", i, picked, tt.want[i])
+				}
+				for _, id := range picked {
+					if used[id] {
+						t.Errorf("question %d picked more than once", id)
+					}
+					used[id] = true
+					if !containsInt(tt.pools[i], id) {
+						t.Errorf("rule %d got question %d outside its pool", i, id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
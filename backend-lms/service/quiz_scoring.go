@@ -0,0 +1,255 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"project/dto"
+	"project/model"
+	"regexp"
+	"strings"
+)
+
+// Tipe soal kuis.
+const (
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionMultipleAnswer = "multiple_answer"
+	QuestionTrueFalse      = "true_false"
+	QuestionShortAnswer    = "short_answer"
+	QuestionNumeric        = "numeric"
+	QuestionEssay          = "essay"
+)
+
+// normalizeQuestion memvalidasi soal dari request dan melengkapi opsi (id opsi, opsi benar/salah).
+func normalizeQuestion(req dto.QuestionRequest, field string, violations *ValidationError) model.Question {
+	question := req.Question
+	question.Prompt = strings.TrimSpace(question.Prompt)
+	question.Explanation = strings.TrimSpace(question.Explanation)
+	if question.Prompt == "" {
+		violations.Add(field+".prompt", "required", "Question prompt is required")
+	}
+	if question.Points <= 0 {
+		violations.Add(field+".points", "out_of_range", "Points must be greater than 0")
+	}
+
+	// Field yang tidak dipakai tipe soal dibuang supaya kunci jawaban tetap bersih
+	keepOptions, keepText, keepNumber := false, false, false
+	switch question.Type {
+	case QuestionMultipleChoice, QuestionMultipleAnswer:
+		keepOptions = true
+		correct := normalizeOptions(&question, field, violations)
+		if len(question.Options) < 2 {
+			violations.Add(field+".options", "too_few", "At least two options are required")
+		}
+		if question.Type == QuestionMultipleChoice && correct != 1 {
+			violations.Add(field+".options", "correct_count", "Multiple choice needs exactly one correct option")
+		}
+		if question.Type == QuestionMultipleAnswer && correct == 0 {
+			violations.Add(field+".options", "correct_count", "At least one option must be correct")
+		}
+	case QuestionTrueFalse:
+		keepOptions = true
+		answer := req.CorrectAnswer
+		if answer == nil {
+			for _, option := range question.Options {
+				if option.Correct {
+					value := option.ID == "true"
+					answer = &value
+				}
+			}
+		}
+		if answer == nil {
+			violations.Add(field+".correct_answer", "required", "True/false questions need correct_answer")
+			answer = new(bool)
+		}
+		question.Options = []model.QuestionOption{
+			{ID: "true", Text: "Benar", Correct: *answer},
+			{ID: "false", Text: "Salah", Correct: !*answer},
+		}
+	case QuestionShortAnswer:
+		keepText = true
+		var accepted []string
+		for i, pattern := range question.AcceptedAnswers {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := compileAnswerPattern(pattern, question.CaseSensitive); err != nil {
+				violations.Add(fmt.Sprintf("%s.accepted_answers[%d]", field, i), "invalid_pattern", err.Error())
+			}
+			accepted = append(accepted, pattern)
+		}
+		question.AcceptedAnswers = accepted
+		if len(accepted) == 0 {
+			violations.Add(field+".accepted_answers", "required", "At least one accepted answer is required")
+		}
+	case QuestionNumeric:
+		keepNumber = true
+		if question.NumericAnswer == nil {
+			violations.Add(field+".numeric_answer", "required", "Numeric questions need numeric_answer")
+		}
+		if question.Tolerance < 0 {
+			violations.Add(field+".tolerance", "out_of_range", "Tolerance cannot be negative")
+		}
+	case QuestionEssay:
+	default:
+		violations.Add(field+".type", "invalid", "Type must be multiple_choice, multiple_answer, true_false, short_answer, numeric or essay")
+	}
+
+	if !keepOptions {
+		question.Options = nil
+	}
+	if !keepText {
+		question.AcceptedAnswers, question.CaseSensitive = nil, false
+	}
+	if !keepNumber {
+		question.NumericAnswer, question.Tolerance = nil, 0
+	}
+	return question
+}
+
+// normalizeOptions mengisi id opsi yang kosong dan mengembalikan jumlah opsi benar.
+func normalizeOptions(question *model.Question, field string, violations *ValidationError) int {
+	seen := map[string]bool{}
+	correct := 0
+	for i := range question.Options {
+		option := &question.Options[i]
+		option.Text = strings.TrimSpace(option.Text)
+		option.ID = strings.TrimSpace(option.ID)
+		if option.ID == "" {
+			option.ID = optionLabel(i)
+		}
+		if option.Text == "" {
+			violations.Add(fmt.Sprintf("%s.options[%d].text", field, i), "required", "Option text is required")
+		}
+		if seen[option.ID] {
+			violations.Add(fmt.Sprintf("%s.options[%d].id", field, i), "duplicate", "Option IDs must be unique")
+		}
+		seen[option.ID] = true
+		if option.Correct {
+			correct++
+		}
+	}
+	return correct
+}
+
+// optionLabel menghasilkan a, b, ..., z, aa, ab, ...
+func optionLabel(i int) string {
+	if i < 26 {
+		return string(rune('a' + i))
+	}
+	return optionLabel(i/26-1) + string(rune('a'+i%26))
+}
+
+// compileAnswerPattern: /regex/ dipakai apa adanya, selain itu teks biasa dengan wildcard *.
+// Spasi berlebih diabaikan dan huruf besar/kecil dianggap sama kecuali caseSensitive.
+func compileAnswerPattern(pattern string, caseSensitive bool) (*regexp.Regexp, error) {
+	var expr string
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr = "^(?:" + pattern[1:len(pattern)-1] + ")$"
+	} else {
+		parts := strings.Split(normalizeAnswerText(pattern), "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		expr = "^" + strings.Join(parts, ".*") + "$"
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
+
+func normalizeAnswerText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// scoreQuestion menilai jawaban secara otomatis. needsGrading true untuk esai (nilai nil).
+// Pilihan ganda kompleks dinilai parsial: (benar dipilih - salah dipilih) / jumlah benar.
+func scoreQuestion(question model.Question, response *model.QuestionResponse) (*float64, bool) {
+	if question.Type == QuestionEssay {
+		return nil, true
+	}
+	score := 0.0
+	if response == nil {
+		return &score, false
+	}
+
+	switch question.Type {
+	case QuestionMultipleChoice, QuestionTrueFalse:
+		if len(response.Selected) == 1 {
+			for _, option := range question.Options {
+				if option.ID == response.Selected[0] && option.Correct {
+					score = question.Points
+				}
+			}
+		}
+	case QuestionMultipleAnswer:
+		selected := map[string]bool{}
+		for _, id := range response.Selected {
+			selected[id] = true
+		}
+		totalCorrect, hits, misses := 0, 0, 0
+		for _, option := range question.Options {
+			if option.Correct {
+				totalCorrect++
+				if selected[option.ID] {
+					hits++
+				}
+			} else if selected[option.ID] {
+				misses++
+			}
+		}
+		if totalCorrect > 0 && hits > misses {
+			score = question.Points * float64(hits-misses) / float64(totalCorrect)
+		}
+	case QuestionShortAnswer:
+		answer := normalizeAnswerText(response.Text)
+		for _, pattern := range question.AcceptedAnswers {
+			re, err := compileAnswerPattern(pattern, question.CaseSensitive)
+			if err == nil && answer != "" && re.MatchString(answer) {
+				score = question.Points
+				break
+			}
+		}
+	case QuestionNumeric:
+		if response.Number != nil && question.NumericAnswer != nil &&
+			math.Abs(*response.Number-*question.NumericAnswer) <= question.Tolerance+1e-9 {
+			score = question.Points
+		}
+	}
+	score = math.Round(score*100) / 100
+	return &score, false
+}
+
+// withoutAnswerKey mengosongkan kunci jawaban sebelum soal dikirim ke siswa.
+func withoutAnswerKey(question model.Question) model.Question {
+	options := make([]model.QuestionOption, len(question.Options))
+	for i, option := range question.Options {
+		options[i] = model.QuestionOption{ID: option.ID, Text: option.Text}
+	}
+	question.Options = options
+	if len(options) == 0 {
+		question.Options = nil
+	}
+	question.AcceptedAnswers = nil
+	question.CaseSensitive = false
+	question.NumericAnswer = nil
+	question.Tolerance = 0
+	question.Explanation = ""
+	return question
+}
+
+// shuffleOptions mengacak urutan opsi kecuali soal benar/salah.
+func shuffleOptions(question *model.Question, rng *rand.Rand) {
+	if question.Type == QuestionTrueFalse {
+		return
+	}
+	rng.Shuffle(len(question.Options), func(i, j int) {
+		question.Options[i], question.Options[j] = question.Options[j], question.Options[i]
+	})
+}
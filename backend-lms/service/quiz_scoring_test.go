@@ -0,0 +1,108 @@
+package service
+
+import (
+	"project/model"
+	"testing"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestCompileAnswerPattern(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		caseSensitive bool
+		answer        string
+		want          bool
+		wantErr       bool
+	}{
+		{name: "plain text ignores case", pattern: "Jakarta", answer: "jakarta", want: true},
+		{name: "plain text case sensitive", pattern: "Jakarta", caseSensitive: true, answer: "jakarta", want: false},
+		{name: "extra spaces in pattern", pattern: "  ibu   kota ", answer: "ibu kota", want: true},
+		{name: "wildcard", pattern: "foto*sintesis", answer: "fotosintesis", want: true},
+		{name: "wildcard in the middle", pattern: "foto*sintesis", answer: "foto-sintesis", want: true},
+		{name: "whole answer must match", pattern: "air", answer: "air laut", want: false},
+		{name: "regex metacharacters are literal", pattern: "3.14", answer: "3x14", want: false},
+		{name: "regex between slashes", pattern: "/(H2O|air)/", answer: "h2o", want: true},
+		{name: "regex is anchored", pattern: "/air/", answer: "air laut", want: false},
+		{name: "invalid regex", pattern: "/(/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileAnswerPattern(tt.pattern, tt.caseSensitive)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q", tt.pattern)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compileAnswerPattern(%q): %v", tt.pattern, err)
+			}
+			if got := re.MatchString(normalizeAnswerText(tt.answer)); got != tt.want {
+				t.Errorf("match %q against %q = %v, want %v", tt.answer, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreQuestion(t *testing.T) {
+	choice := model.Question{Type: QuestionMultipleChoice, Points: 2, Options: []model.QuestionOption{
+		{ID: "a", Text: "Merkurius", Correct: true}, {ID: "b", Text: "Venus"},
+	}}
+	multi := model.Question{Type: QuestionMultipleAnswer, Points: 3, Options: []model.QuestionOption{
+		{ID: "a", Correct: true}, {ID: "b", Correct: true}, {ID: "c", Correct: true}, {ID: "d"},
+	}}
+	trueFalse := model.Question{Type: QuestionTrueFalse, Points: 1, Options: []model.QuestionOption{
+		{ID: "true", Correct: false}, {ID: "false", Correct: true},
+	}}
+	short := model.Question{Type: QuestionShortAnswer, Points: 4, AcceptedAnswers: []string{"Soekarno", "/(ir\\.? )?sukarno/"}}
+	numeric := model.Question{Type: QuestionNumeric, Points: 5, NumericAnswer: floatPtr(3.14), Tolerance: 0.01}
+
+	tests := []struct {
+		name      string
+		question  model.Question
+		response  *model.QuestionResponse
+		want      *float64
+		needsHand bool
+	}{
+		{name: "essay needs manual grading", question: model.Question{Type: QuestionEssay, Points: 10},
+			response: &model.QuestionResponse{Text: "..."}, needsHand: true},
+		{name: "no answer scores zero", question: choice, want: floatPtr(0)},
+		{name: "correct choice", question: choice, response: &model.QuestionResponse{Selected: []string{"a"}}, want: floatPtr(2)},
+		{name: "wrong choice", question: choice, response: &model.QuestionResponse{Selected: []string{"b"}}, want: floatPtr(0)},
+		{name: "several choices on single answer", question: choice,
+			response: &model.QuestionResponse{Selected: []string{"a", "b"}}, want: floatPtr(0)},
+		{name: "multiple answer all correct", question: multi,
+			response: &model.QuestionResponse{Selected: []string{"a", "b", "c"}}, want: floatPtr(3)},
+		{name: "multiple answer partial", question: multi,
+			response: &model.QuestionResponse{Selected: []string{"a", "b"}}, want: floatPtr(2)},
+		{name: "multiple answer wrong pick cancels a hit", question: multi,
+			response: &model.QuestionResponse{Selected: []string{"a", "b", "d"}}, want: floatPtr(1)},
+		{name: "multiple answer never negative", question: multi,
+			response: &model.QuestionResponse{Selected: []string{"d"}}, want: floatPtr(0)},
+		{name: "true false", question: trueFalse, response: &model.QuestionResponse{Selected: []string{"false"}}, want: floatPtr(1)},
+		{name: "short answer plain", question: short, response: &model.QuestionResponse{Text: " soekarno "}, want: floatPtr(4)},
+		{name: "short answer regex", question: short, response: &model.QuestionResponse{Text: "Ir. Sukarno"}, want: floatPtr(4)},
+		{name: "short answer empty", question: short, response: &model.QuestionResponse{Text: "  "}, want: floatPtr(0)},
+		{name: "numeric within tolerance", question: numeric, response: &model.QuestionResponse{Number: floatPtr(3.15)}, want: floatPtr(5)},
+		{name: "numeric outside tolerance", question: numeric, response: &model.QuestionResponse{Number: floatPtr(3.2)}, want: floatPtr(0)},
+		{name: "numeric without number", question: numeric, response: &model.QuestionResponse{Text: "3.14"}, want: floatPtr(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, needsGrading := scoreQuestion(tt.question, tt.response)
+			if needsGrading != tt.needsHand {
+				t.Errorf("needsGrading = %v, want %v", needsGrading, tt.needsHand)
+			}
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("score = %v, want nil", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("score = %v, want %v", got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+var (
+	ErrQuizNotFound     = errors.New("quiz not found")
+	ErrQuestionNotFound = errors.New("question not found")
+)
+
+// QuizService mengelola kuis kelas, soalnya dan percobaan siswa.
+type QuizService struct {
+	DB *sql.DB
+}
+
+func NewQuizService(db *sql.DB) *QuizService {
+	return &QuizService{DB: db}
+}
+
+// GetQuizzes mengembalikan kuis kelas. Kuis yang belum dipublikasikan hanya untuk pengelola konten.
+func (s *QuizService) GetQuizzes(classID int, includeDrafts bool) ([]model.Quiz, error) {
+	rows, err := s.DB.Query(quizSelect+`
+        WHERE q.class_id = $1 AND (q.published OR $2)
+        ORDER BY COALESCE(q.opens_at, q.created_at) DESC, q.id DESC
+    `, classID, includeDrafts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quizzes: %w", err)
+	}
+	defer rows.Close()
+
+	quizzes := []model.Quiz{}
+	for rows.Next() {
+		quiz, err := scanQuiz(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quiz: %w", err)
+		}
+		quizzes = append(quizzes, *quiz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quizzes: %w", err)
+	}
+	return quizzes, nil
+}
+
+// GetQuiz mengembalikan detail kuis beserta soal, kunci jawaban dan aturan soal acak (untuk guru).
+func (s *QuizService) GetQuiz(classID, quizID int) (*model.Quiz, error) {
+	quiz, err := getQuiz(s.DB, classID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Questions, err = loadQuizQuestions(s.DB, quizID); err != nil {
+		return nil, err
+	}
+	if quiz.DrawRules, err = loadDrawRules(s.DB, quizID); err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
+
+func (s *QuizService) CreateQuiz(classID, userID int, req dto.QuizRequest) (*model.Quiz, error) {
+	if err := validateQuizRequest(req); err != nil {
+		return nil, err
+	}
+
+	var quizID int
+	err := s.DB.QueryRow(`
+        INSERT INTO quizzes (class_id, title, description, time_limit_minutes, opens_at, closes_at, max_attempts,
+                             shuffle_questions, shuffle_options, show_answers, published, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING id
+    `, classID, strings.TrimSpace(req.Title), strings.TrimSpace(req.Description), req.TimeLimitMinutes, req.OpensAt,
+		req.ClosesAt, req.MaxAttempts, req.ShuffleQuestions, req.ShuffleOptions, req.ShowAnswers, req.Published, userID).Scan(&quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quiz: %w", err)
+	}
+	return s.GetQuiz(classID, quizID)
+}
+
+func (s *QuizService) UpdateQuiz(classID, quizID int, req dto.QuizRequest) (*model.Quiz, error) {
+	if err := validateQuizRequest(req); err != nil {
+		return nil, err
+	}
+
+	result, err := s.DB.Exec(`
+        UPDATE quizzes
+        SET title = $1, description = $2, time_limit_minutes = $3, opens_at = $4, closes_at = $5, max_attempts = $6,
+            shuffle_questions = $7, shuffle_options = $8, show_answers = $9, published = $10, updated_at = NOW()
+        WHERE id = $11 AND class_id = $12
+    `, strings.TrimSpace(req.Title), strings.TrimSpace(req.Description), req.TimeLimitMinutes, req.OpensAt, req.ClosesAt,
+		req.MaxAttempts, req.ShuffleQuestions, req.ShuffleOptions, req.ShowAnswers, req.Published, quizID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update quiz: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrQuizNotFound
+	}
+	return s.GetQuiz(classID, quizID)
+}
+
+func (s *QuizService) DeleteQuiz(classID, quizID int) error {
+	result, err := s.DB.Exec(`DELETE FROM quizzes WHERE id = $1 AND class_id = $2`, quizID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to delete quiz: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrQuizNotFound
+	}
+	return nil
+}
+
+// AddQuestion menambah soal di akhir kuis.
+func (s *QuizService) AddQuestion(classID, quizID int, req dto.QuestionRequest) (*model.QuizQuestion, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	violations := &ValidationError{}
+	question := normalizeQuestion(req, "question", violations)
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode question: %w", err)
+	}
+
+	saved := model.QuizQuestion{Question: question}
+	err = s.DB.QueryRow(`
+        INSERT INTO quiz_questions (quiz_id, position, question)
+        VALUES ($1, (SELECT COALESCE(MAX(position), 0) + 1 FROM quiz_questions WHERE quiz_id = $1), $2)
+        RETURNING id, position
+    `, quizID, string(content)).Scan(&saved.ID, &saved.Position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add question: %w", err)
+	}
+	s.touchQuiz(quizID)
+	return &saved, nil
+}
+
+// UpdateQuestion mengubah soal. Percobaan yang sudah dimulai tetap memakai salinan soal lama.
+func (s *QuizService) UpdateQuestion(classID, quizID, questionID int, req dto.QuestionRequest) (*model.QuizQuestion, error) {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return nil, err
+	}
+	violations := &ValidationError{}
+	question := normalizeQuestion(req, "question", violations)
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode question: %w", err)
+	}
+
+	saved := model.QuizQuestion{ID: questionID, Question: question}
+	err = s.DB.QueryRow(`
+        UPDATE quiz_questions SET question = $1, updated_at = NOW() WHERE id = $2 AND quiz_id = $3 RETURNING position
+    `, string(content), questionID, quizID).Scan(&saved.Position)
+	if err == sql.ErrNoRows {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update question: %w", err)
+	}
+	s.touchQuiz(quizID)
+	return &saved, nil
+}
+
+func (s *QuizService) DeleteQuestion(classID, quizID, questionID int) error {
+	if _, err := getQuiz(s.DB, classID, quizID); err != nil {
+		return err
+	}
+	var position int
+	err := s.DB.QueryRow(`DELETE FROM quiz_questions WHERE id = $1 AND quiz_id = $2 RETURNING position`, questionID, quizID).Scan(&position)
+	if err == sql.ErrNoRows {
+		return ErrQuestionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete question: %w", err)
+	}
+	if _, err := s.DB.Exec(`UPDATE quiz_questions SET position = position - 1 WHERE quiz_id = $1 AND position > $2`, quizID, position); err != nil {
+		return fmt.Errorf("failed to reorder questions: %w", err)
+	}
+	s.touchQuiz(quizID)
+	return nil
+}
+
+func (s *QuizService) touchQuiz(quizID int) {
+	s.DB.Exec(`UPDATE quizzes SET updated_at = NOW() WHERE id = $1`, quizID)
+}
+
+func validateQuizRequest(req dto.QuizRequest) error {
+	violations := &ValidationError{}
+	if req.OpensAt != nil && req.ClosesAt != nil && !req.ClosesAt.After(*req.OpensAt) {
+		violations.Add("closes_at", "before_open", "Close time must be after open time")
+	}
+	return violations.OrNil()
+}
+
+const quizSelect = `
+    SELECT q.id, q.class_id, q.title, q.description, q.time_limit_minutes, q.opens_at, q.closes_at, q.max_attempts,
+           q.shuffle_questions, q.shuffle_options, q.show_answers, q.published, q.created_by, q.created_at, q.updated_at,
+           (SELECT COUNT(*) FROM quiz_questions qq WHERE qq.quiz_id = q.id)
+               + (SELECT COALESCE(SUM(r.count), 0) FROM quiz_draw_rules r WHERE r.quiz_id = q.id),
+           (SELECT COALESCE(SUM((qq.question->>'points')::NUMERIC), 0) FROM quiz_questions qq WHERE qq.quiz_id = q.id)
+               + (SELECT COALESCE(SUM(r.count * r.points), 0) FROM quiz_draw_rules r WHERE r.quiz_id = q.id)
+    FROM quizzes q
+`
+
+func scanQuiz(row rowScanner) (*model.Quiz, error) {
+	var quiz model.Quiz
+	err := row.Scan(&quiz.ID, &quiz.ClassID, &quiz.Title, &quiz.Description, &quiz.TimeLimitMinutes, &quiz.OpensAt,
+		&quiz.ClosesAt, &quiz.MaxAttempts, &quiz.ShuffleQuestions, &quiz.ShuffleOptions, &quiz.ShowAnswers, &quiz.Published,
+		&quiz.CreatedBy, &quiz.CreatedAt, &quiz.UpdatedAt, &quiz.QuestionCount, &quiz.TotalPoints)
+	if err != nil {
+		return nil, err
+	}
+	return &quiz, nil
+}
+
+func getQuiz(q dbExecutor, classID, quizID int) (*model.Quiz, error) {
+	quiz, err := scanQuiz(q.QueryRow(quizSelect+` WHERE q.id = $1 AND q.class_id = $2`, quizID, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrQuizNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiz: %w", err)
+	}
+	return quiz, nil
+}
+
+func loadQuizQuestions(q dbExecutor, quizID int) ([]model.QuizQuestion, error) {
+	rows, err := q.Query(`
+        SELECT id, position, bank_question_id, bank_version, question FROM quiz_questions
+        WHERE quiz_id = $1 ORDER BY position, id
+    `, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions: %w", err)
+	}
+	defer rows.Close()
+
+	questions := []model.QuizQuestion{}
+	for rows.Next() {
+		var question model.QuizQuestion
+		var content []byte
+		if err := rows.Scan(&question.ID, &question.Position, &question.BankQuestionID, &question.BankVersion, &content); err != nil {
+			return nil, fmt.Errorf("failed to scan question: %w", err)
+		}
+		if err := json.Unmarshal(content, &question.Question); err != nil {
+			return nil, fmt.Errorf("failed to decode question %d: %w", question.ID, err)
+		}
+		questions = append(questions, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating questions: %w", err)
+	}
+	return questions, nil
+}
@@ -14,12 +14,22 @@ func NewRapotService(db *sql.DB) *RapotService {
     return &RapotService{DB: db}
 }
 
+// GetRapotByUserID - Nilai rapot per kelas adalah rata-rata buku nilai: nilai manual dan
+// nilai tugas yang masuk otomatis saat pengumpulan dinilai. Kelas tempat user menjadi siswa
+// atau punya catatan kehadiran tetap muncul (grade 0, grade_count 0) walaupun belum ada nilai.
 func (s *RapotService) GetRapotByUserID(userID int) ([]dto.RapotResponse, error) {
     query := `
-        SELECT c.name, g.grade
+        SELECT c.id, c.name, COALESCE(ROUND(AVG(g.grade))::INT, 0), COUNT(g.id)
         FROM classes c
-        JOIN grades g ON c.id = g.class_id
-        WHERE g.user_id = $1
+        LEFT JOIN grades g ON g.class_id = c.id AND g.user_id = $1
+        WHERE EXISTS (SELECT 1 FROM class_members cm WHERE cm.class_id = c.id AND cm.user_id = $1 AND cm.role = 'siswa')
+           OR EXISTS (
+               SELECT 1 FROM attendance_records ar JOIN class_sessions cs ON cs.id = ar.session_id
+               WHERE cs.class_id = c.id AND ar.user_id = $1
+           )
+           OR g.id IS NOT NULL
+        GROUP BY c.id, c.name
+        ORDER BY c.name
     `
     rows, err := s.DB.Query(query, userID)
     if err != nil {
@@ -30,7 +40,7 @@ func (s *RapotService) GetRapotByUserID(userID int) ([]dto.RapotResponse, error)
     var rapots []dto.RapotResponse
     for rows.Next() {
         var rapot dto.RapotResponse
-        if err := rows.Scan(&rapot.ClassName, &rapot.Grade); err != nil {
+        if err := rows.Scan(&rapot.ClassID, &rapot.ClassName, &rapot.Grade, &rapot.GradeCount); err != nil {
             return nil, fmt.Errorf("failed to scan rapot: %v", err)
         }
         rapots = append(rapots, rapot)
@@ -40,5 +50,14 @@ func (s *RapotService) GetRapotByUserID(userID int) ([]dto.RapotResponse, error)
         return nil, fmt.Errorf("error iterating rapots: %v", err)
     }
 
+    // Rekap kehadiran ikut dicantumkan di rapot per kelas
+    attendance, err := attendanceTotalsByClass(s.DB, userID)
+    if err != nil {
+        return nil, err
+    }
+    for i := range rapots {
+        rapots[i].Attendance = attendance[rapots[i].ClassID]
+    }
+
     return rapots, nil
 }
\ No newline at end of file
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+// GradeWithRubric menilai pengumpulan per kriteria rubrik tugas. Total poin menjadi nilai
+// pengumpulan dan diproses sama seperti GradeSubmission.
+func (s *SubmissionService) GradeWithRubric(classID, assignmentID, submissionID, graderID int, req dto.RubricGradeRequest) (*model.Submission, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rubricID, err := getAssignmentRubricID(tx, classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if rubricID == nil {
+		return nil, ErrNoRubric
+	}
+	rubric, err := loadRubric(tx, *rubricID)
+	if err != nil {
+		return nil, err
+	}
+	submission, err := getTeacherSubmission(tx, assignmentID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, total, err := scoreRubric(rubric, req.Scores)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM submission_rubric_scores WHERE submission_id = $1`, submissionID); err != nil {
+		return nil, fmt.Errorf("failed to save rubric scores: %w", err)
+	}
+	for _, score := range scores {
+		_, err := tx.Exec(`
+            INSERT INTO submission_rubric_scores (submission_id, criterion_id, level_id, points, comment)
+            VALUES ($1, $2, $3, $4, $5)
+        `, submissionID, score.ID, score.LevelID, *score.Points, score.Comment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save rubric scores: %w", err)
+		}
+	}
+
+	// Penilaian tidak mengubah tugas; rubrik yang totalnya berbeda harus dipasang ulang dulu
+	if rubric.MaxPoints != submission.MaxPoints {
+		violations := &ValidationError{}
+		violations.Add("scores", "max_points_mismatch", fmt.Sprintf("Rubric total (%g) differs from assignment max points (%g)", rubric.MaxPoints, submission.MaxPoints))
+		return nil, violations
+	}
+	if err := saveSubmissionGrade(tx, submission, graderID, total, req.Feedback, req.Return); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to grade submission: %w", err)
+	}
+	return s.GetSubmission(classID, assignmentID, submissionID)
+}
+
+// scoreRubric memvalidasi skor per kriteria dan menghitung totalnya. Semua kriteria wajib dinilai.
+func scoreRubric(rubric *model.Rubric, requests []dto.RubricScoreRequest) ([]model.RubricCriterionScore, float64, error) {
+	violations := &ValidationError{}
+	byCriterion := map[int]int{}
+	for i, score := range requests {
+		if _, duplicate := byCriterion[score.CriterionID]; duplicate {
+			violations.Add(fmt.Sprintf("scores[%d].criterion_id", i), "duplicate", "Criterion is scored more than once")
+		}
+		byCriterion[score.CriterionID] = i
+	}
+
+	var scores []model.RubricCriterionScore
+	var total float64
+	known := map[int]bool{}
+	for _, criterion := range rubric.Criteria {
+		known[criterion.ID] = true
+		i, ok := byCriterion[criterion.ID]
+		if !ok {
+			violations.Add("scores", "missing_criterion", fmt.Sprintf("Criterion %q has not been scored", criterion.Title))
+			continue
+		}
+		req := requests[i]
+		field := fmt.Sprintf("scores[%d]", i)
+
+		score := model.RubricCriterionScore{RubricCriterion: criterion, LevelID: req.LevelID, Comment: strings.TrimSpace(req.Comment)}
+		if req.LevelID != nil {
+			for _, level := range criterion.Levels {
+				if level.ID == *req.LevelID {
+					points := level.Points
+					score.Points = &points
+				}
+			}
+			if score.Points == nil {
+				violations.Add(field+".level_id", "unknown_level", "Level does not belong to this criterion")
+				continue
+			}
+		}
+		if req.Points != nil {
+			if *req.Points > criterion.MaxPoints {
+				violations.Add(field+".points", "out_of_range", fmt.Sprintf("Points must be at most %g", criterion.MaxPoints))
+				continue
+			}
+			points := *req.Points
+			score.Points = &points
+		}
+		if score.Points == nil {
+			violations.Add(field, "required", "Choose a level or fill in points")
+			continue
+		}
+		total += *score.Points
+		scores = append(scores, score)
+	}
+	for i, score := range requests {
+		if !known[score.CriterionID] {
+			violations.Add(fmt.Sprintf("scores[%d].criterion_id", i), "unknown_criterion", "Criterion does not belong to the assignment rubric")
+		}
+	}
+
+	if err := violations.OrNil(); err != nil {
+		return nil, 0, err
+	}
+	return scores, total, nil
+}
+
+// loadSubmissionRubric mengisi rubrik tugas beserta skor pengumpulan (jika sudah dinilai).
+func loadSubmissionRubric(q dbExecutor, submission *model.Submission) error {
+	var rubricID *int
+	err := q.QueryRow(`SELECT rubric_id FROM assignments WHERE id = $1`, submission.AssignmentID).Scan(&rubricID)
+	if err != nil {
+		return fmt.Errorf("failed to get assignment rubric: %w", err)
+	}
+	if rubricID == nil {
+		submission.Rubric = nil
+		return nil
+	}
+	rubric, err := loadRubric(q, *rubricID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Query(`
+        SELECT criterion_id, level_id, points, comment FROM submission_rubric_scores WHERE submission_id = $1
+    `, submission.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query rubric scores: %w", err)
+	}
+	defer rows.Close()
+
+	type storedScore struct {
+		levelID *int
+		points  float64
+		comment string
+	}
+	stored := map[int]storedScore{}
+	for rows.Next() {
+		var criterionID int
+		var score storedScore
+		if err := rows.Scan(&criterionID, &score.levelID, &score.points, &score.comment); err != nil {
+			return fmt.Errorf("failed to scan rubric score: %w", err)
+		}
+		stored[criterionID] = score
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rubric scores: %w", err)
+	}
+
+	filled := &model.SubmissionRubric{
+		RubricID:  rubric.ID,
+		Title:     rubric.Title,
+		MaxPoints: rubric.MaxPoints,
+		Criteria:  []model.RubricCriterionScore{},
+	}
+	var total float64
+	for _, criterion := range rubric.Criteria {
+		score := model.RubricCriterionScore{RubricCriterion: criterion}
+		if saved, ok := stored[criterion.ID]; ok {
+			points := saved.points
+			score.LevelID, score.Points, score.Comment = saved.levelID, &points, saved.comment
+			total += points
+		}
+		filled.Criteria = append(filled.Criteria, score)
+	}
+	if len(stored) > 0 {
+		filled.TotalPoints = &total
+	}
+	submission.Rubric = filled
+	return nil
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+var (
+	ErrRubricNotFound = errors.New("rubric not found")
+	ErrRubricInUse    = errors.New("rubric has already been used for grading, make a copy to change it")
+	ErrNoRubric       = errors.New("assignment has no rubric")
+)
+
+// RubricService mengelola rubrik milik guru dan pemasangannya ke tugas.
+type RubricService struct {
+	DB *sql.DB
+}
+
+func NewRubricService(db *sql.DB) *RubricService {
+	return &RubricService{DB: db}
+}
+
+// ListRubrics mengembalikan rubrik milik user; Admin melihat semua rubrik.
+func (s *RubricService) ListRubrics(userID int, role string) ([]model.Rubric, error) {
+	rows, err := s.DB.Query(`
+        SELECT id FROM rubrics WHERE owner_id = $1 OR $2 ORDER BY updated_at DESC, id DESC
+    `, userID, role == "Admin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rubrics: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rubric: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rubrics: %w", err)
+	}
+
+	rubrics := []model.Rubric{}
+	for _, id := range ids {
+		rubric, err := loadRubric(s.DB, id)
+		if err != nil {
+			return nil, err
+		}
+		rubrics = append(rubrics, *rubric)
+	}
+	return rubrics, nil
+}
+
+func (s *RubricService) GetRubric(rubricID, userID int, role string) (*model.Rubric, error) {
+	if err := checkRubricOwner(s.DB, rubricID, userID, role); err != nil {
+		return nil, err
+	}
+	return loadRubric(s.DB, rubricID)
+}
+
+func (s *RubricService) CreateRubric(ownerID int, req dto.RubricRequest) (*model.Rubric, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rubricID int
+	err = tx.QueryRow(`
+        INSERT INTO rubrics (owner_id, title, description) VALUES ($1, $2, $3) RETURNING id
+    `, ownerID, strings.TrimSpace(req.Title), strings.TrimSpace(req.Description)).Scan(&rubricID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rubric: %w", err)
+	}
+	if err := insertRubricCriteria(tx, rubricID, req.Criteria); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create rubric: %w", err)
+	}
+	return loadRubric(s.DB, rubricID)
+}
+
+// UpdateRubric mengganti judul dan seluruh kriteria. Rubrik yang sudah dipakai menilai
+// ditolak supaya skor lama tetap sesuai dengan kriterianya.
+func (s *RubricService) UpdateRubric(rubricID, userID int, role string, req dto.RubricRequest) (*model.Rubric, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkRubricOwner(tx, rubricID, userID, role); err != nil {
+		return nil, err
+	}
+	if err := checkRubricUnused(tx, rubricID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE rubrics SET title = $1, description = $2, updated_at = NOW() WHERE id = $3
+    `, strings.TrimSpace(req.Title), strings.TrimSpace(req.Description), rubricID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rubric: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM rubric_criteria WHERE rubric_id = $1`, rubricID); err != nil {
+		return nil, fmt.Errorf("failed to update rubric: %w", err)
+	}
+	if err := insertRubricCriteria(tx, rubricID, req.Criteria); err != nil {
+		return nil, err
+	}
+	if err := syncRubricMaxPoints(tx, rubricID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update rubric: %w", err)
+	}
+	return loadRubric(s.DB, rubricID)
+}
+
+func (s *RubricService) DeleteRubric(rubricID, userID int, role string) error {
+	if err := checkRubricOwner(s.DB, rubricID, userID, role); err != nil {
+		return err
+	}
+	if err := checkRubricUnused(s.DB, rubricID); err != nil {
+		return err
+	}
+	if _, err := s.DB.Exec(`DELETE FROM rubrics WHERE id = $1`, rubricID); err != nil {
+		return fmt.Errorf("failed to delete rubric: %w", err)
+	}
+	return nil
+}
+
+// CopyRubric membuat salinan rubrik milik user, misalnya untuk mengubah rubrik yang sudah dipakai.
+func (s *RubricService) CopyRubric(rubricID, userID int, role string) (*model.Rubric, error) {
+	source, err := s.GetRubric(rubricID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dto.RubricRequest{Title: source.Title + " (salinan)", Description: source.Description}
+	for _, criterion := range source.Criteria {
+		criterionReq := dto.RubricCriterionRequest{Title: criterion.Title, Description: criterion.Description}
+		for _, level := range criterion.Levels {
+			criterionReq.Levels = append(criterionReq.Levels, dto.RubricLevelRequest{
+				Title: level.Title, Description: level.Description, Points: level.Points,
+			})
+		}
+		req.Criteria = append(req.Criteria, criterionReq)
+	}
+	return s.CreateRubric(userID, req)
+}
+
+// AttachToAssignment memasang rubrik (milik user, atau rubrik apa pun untuk Admin) ke tugas.
+// max_points tugas mengikuti total poin rubrik, kecuali sudah ada pengumpulan yang dinilai
+// dengan max_points lain. rubricID nil melepas rubrik.
+func (s *RubricService) AttachToAssignment(classID, assignmentID, userID int, role string, rubricID *int) (*model.Rubric, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getAssignmentDeadline(tx, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	var maxPoints float64
+	if err := tx.QueryRow(`SELECT max_points FROM assignments WHERE id = $1 FOR UPDATE`, assignmentID).Scan(&maxPoints); err != nil {
+		return nil, fmt.Errorf("failed to lock assignment: %w", err)
+	}
+
+	var rubric *model.Rubric
+	if rubricID != nil {
+		if err := checkRubricOwner(tx, *rubricID, userID, role); err != nil {
+			return nil, err
+		}
+		if rubric, err = loadRubric(tx, *rubricID); err != nil {
+			return nil, err
+		}
+	}
+
+	if rubric != nil && rubric.MaxPoints > 0 && rubric.MaxPoints != maxPoints {
+		var graded int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM submissions WHERE assignment_id = $1 AND points IS NOT NULL`, assignmentID).Scan(&graded); err != nil {
+			return nil, fmt.Errorf("failed to check graded submissions: %w", err)
+		}
+		if graded > 0 {
+			violations := &ValidationError{}
+			violations.Add("rubric_id", "max_points_mismatch", fmt.Sprintf("Rubric total (%g) differs from max points (%g) of already graded submissions", rubric.MaxPoints, maxPoints))
+			return nil, violations
+		}
+	}
+
+	if rubric != nil && rubric.MaxPoints > 0 {
+		_, err = tx.Exec(`UPDATE assignments SET rubric_id = $1, max_points = $2 WHERE id = $3`, rubric.ID, rubric.MaxPoints, assignmentID)
+	} else {
+		_, err = tx.Exec(`UPDATE assignments SET rubric_id = $1 WHERE id = $2`, rubricID, assignmentID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach rubric: %w", err)
+	}
+	if err := syncAssignmentGrades(tx, assignmentID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to attach rubric: %w", err)
+	}
+	return rubric, nil
+}
+
+// GetAssignmentRubric mengembalikan rubrik yang terpasang di tugas (untuk guru dan siswa kelas).
+func (s *RubricService) GetAssignmentRubric(classID, assignmentID int) (*model.Rubric, error) {
+	rubricID, err := getAssignmentRubricID(s.DB, classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if rubricID == nil {
+		return nil, ErrNoRubric
+	}
+	return loadRubric(s.DB, *rubricID)
+}
+
+func getAssignmentRubricID(q dbExecutor, classID, assignmentID int) (*int, error) {
+	var rubricID *int
+	err := q.QueryRow(`SELECT rubric_id FROM assignments WHERE id = $1 AND class_id = $2`, assignmentID, classID).Scan(&rubricID)
+	if err == sql.ErrNoRows {
+		return nil, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+	return rubricID, nil
+}
+
+func checkRubricOwner(q dbExecutor, rubricID, userID int, role string) error {
+	var ownerID sql.NullInt64
+	err := q.QueryRow(`SELECT owner_id FROM rubrics WHERE id = $1`, rubricID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return ErrRubricNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get rubric: %w", err)
+	}
+	if role != "Admin" && (!ownerID.Valid || int(ownerID.Int64) != userID) {
+		return ErrRubricNotFound
+	}
+	return nil
+}
+
+func checkRubricUnused(q dbExecutor, rubricID int) error {
+	var inUse bool
+	err := q.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM submission_rubric_scores sc JOIN rubric_criteria c ON c.id = sc.criterion_id
+            WHERE c.rubric_id = $1
+        )
+    `, rubricID).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("failed to check rubric usage: %w", err)
+	}
+	if inUse {
+		return ErrRubricInUse
+	}
+	return nil
+}
+
+// syncRubricMaxPoints menyamakan max_points tugas yang memakai rubrik dengan total poin rubrik.
+func syncRubricMaxPoints(q dbExecutor, rubricID int) error {
+	_, err := q.Exec(`
+        UPDATE assignments a SET max_points = totals.max_points
+        FROM (
+            SELECT SUM(max_level) AS max_points FROM (
+                SELECT MAX(l.points) AS max_level FROM rubric_criteria c
+                JOIN rubric_levels l ON l.criterion_id = c.id
+                WHERE c.rubric_id = $1 GROUP BY c.id
+            ) levels
+        ) totals
+        WHERE a.rubric_id = $1 AND totals.max_points > 0
+    `, rubricID)
+	if err != nil {
+		return fmt.Errorf("failed to update assignment max points: %w", err)
+	}
+
+	rows, err := q.Query(`SELECT id FROM assignments WHERE rubric_id = $1`, rubricID)
+	if err != nil {
+		return fmt.Errorf("failed to query rubric assignments: %w", err)
+	}
+	var assignmentIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan assignment: %w", err)
+		}
+		assignmentIDs = append(assignmentIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating assignments: %w", err)
+	}
+	for _, assignmentID := range assignmentIDs {
+		if err := syncAssignmentGrades(q, assignmentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertRubricCriteria(q dbExecutor, rubricID int, criteria []dto.RubricCriterionRequest) error {
+	for i, criterion := range criteria {
+		var criterionID int
+		err := q.QueryRow(`
+            INSERT INTO rubric_criteria (rubric_id, position, title, description) VALUES ($1, $2, $3, $4) RETURNING id
+        `, rubricID, i+1, strings.TrimSpace(criterion.Title), strings.TrimSpace(criterion.Description)).Scan(&criterionID)
+		if err != nil {
+			return fmt.Errorf("failed to save rubric criterion: %w", err)
+		}
+		for j, level := range criterion.Levels {
+			_, err := q.Exec(`
+                INSERT INTO rubric_levels (criterion_id, position, title, description, points) VALUES ($1, $2, $3, $4, $5)
+            `, criterionID, j+1, strings.TrimSpace(level.Title), strings.TrimSpace(level.Description), level.Points)
+			if err != nil {
+				return fmt.Errorf("failed to save rubric level: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadRubric memuat rubrik lengkap dengan kriteria dan levelnya.
+func loadRubric(q dbExecutor, rubricID int) (*model.Rubric, error) {
+	var rubric model.Rubric
+	err := q.QueryRow(`
+        SELECT id, owner_id, title, description, created_at, updated_at,
+               EXISTS (
+                   SELECT 1 FROM submission_rubric_scores sc JOIN rubric_criteria c ON c.id = sc.criterion_id
+                   WHERE c.rubric_id = rubrics.id
+               )
+        FROM rubrics WHERE id = $1
+    `, rubricID).Scan(&rubric.ID, &rubric.OwnerID, &rubric.Title, &rubric.Description, &rubric.CreatedAt, &rubric.UpdatedAt, &rubric.InUse)
+	if err == sql.ErrNoRows {
+		return nil, ErrRubricNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rubric: %w", err)
+	}
+
+	rows, err := q.Query(`
+        SELECT c.id, c.position, c.title, c.description, l.id, l.position, l.title, l.description, l.points
+        FROM rubric_criteria c
+        LEFT JOIN rubric_levels l ON l.criterion_id = c.id
+        WHERE c.rubric_id = $1
+        ORDER BY c.position, l.position
+    `, rubricID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rubric criteria: %w", err)
+	}
+	defer rows.Close()
+
+	rubric.Criteria = []model.RubricCriterion{}
+	for rows.Next() {
+		var criterion model.RubricCriterion
+		var levelID, levelPosition sql.NullInt64
+		var levelTitle, levelDescription sql.NullString
+		var levelPoints sql.NullFloat64
+		if err := rows.Scan(&criterion.ID, &criterion.Position, &criterion.Title, &criterion.Description,
+			&levelID, &levelPosition, &levelTitle, &levelDescription, &levelPoints); err != nil {
+			return nil, fmt.Errorf("failed to scan rubric criterion: %w", err)
+		}
+		if n := len(rubric.Criteria); n == 0 || rubric.Criteria[n-1].ID != criterion.ID {
+			criterion.Levels = []model.RubricLevel{}
+			rubric.Criteria = append(rubric.Criteria, criterion)
+		}
+		if !levelID.Valid {
+			continue
+		}
+		current := &rubric.Criteria[len(rubric.Criteria)-1]
+		current.Levels = append(current.Levels, model.RubricLevel{
+			ID: int(levelID.Int64), Position: int(levelPosition.Int64), Title: levelTitle.String,
+			Description: levelDescription.String, Points: levelPoints.Float64,
+		})
+		if levelPoints.Float64 > current.MaxPoints {
+			current.MaxPoints = levelPoints.Float64
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rubric criteria: %w", err)
+	}
+
+	for _, criterion := range rubric.Criteria {
+		rubric.MaxPoints += criterion.MaxPoints
+	}
+	return &rubric, nil
+}
@@ -0,0 +1,526 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/config"
+	"project/dto"
+	"project/model"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	minutesPerWeek   = 7 * 24 * 60
+	maxTimetableDays = 31
+)
+
+// dayNames dipakai untuk membuat jadwal_kelas yang bisa dibaca manusia (index = ISO day of week).
+var dayNames = [...]string{"", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu", "Minggu"}
+
+var ErrScheduleExceptionNotFound = errors.New("schedule exception not found")
+
+// ScheduleConflictError dikembalikan jika jadwal bentrok dengan kelas lain.
+type ScheduleConflictError struct {
+	Conflicts []dto.ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("schedule conflicts with %d other class session(s)", len(e.Conflicts))
+}
+
+// dbExecutor dipenuhi *sql.DB dan *sql.Tx.
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ScheduleService mengelola jadwal kelas, hari libur dan timetable user.
+type ScheduleService struct {
+	DB *sql.DB
+}
+
+func NewScheduleService(db *sql.DB) *ScheduleService {
+	return &ScheduleService{DB: db}
+}
+
+// parseScheduleEntries memvalidasi input jadwal dan mengubahnya ke model.
+func parseScheduleEntries(entries []dto.ScheduleEntry) ([]model.ClassSchedule, error) {
+	violations := &ValidationError{}
+	schedules := make([]model.ClassSchedule, 0, len(entries))
+
+	for i, entry := range entries {
+		field := fmt.Sprintf("schedules[%d]", i)
+		schedule := model.ClassSchedule{
+			DayOfWeek: entry.DayOfWeek,
+			StartTime: strings.TrimSpace(entry.StartTime),
+			EndTime:   strings.TrimSpace(entry.EndTime),
+			Room:      strings.TrimSpace(entry.Room),
+			Timezone:  strings.TrimSpace(entry.Timezone),
+		}
+		if schedule.Timezone == "" {
+			schedule.Timezone = config.DefaultTimezone
+		}
+
+		if schedule.DayOfWeek < 1 || schedule.DayOfWeek > 7 {
+			violations.Add(field+".day_of_week", "out_of_range", "Day of week must be between 1 (Monday) and 7 (Sunday)")
+		}
+		start, startErr := time.Parse("15:04", schedule.StartTime)
+		end, endErr := time.Parse("15:04", schedule.EndTime)
+		if startErr != nil {
+			violations.Add(field+".start_time", "invalid_format", "Start time must use HH:MM format")
+		}
+		if endErr != nil {
+			violations.Add(field+".end_time", "invalid_format", "End time must use HH:MM format")
+		}
+		if startErr == nil && endErr == nil && !end.After(start) {
+			violations.Add(field+".end_time", "before_start", "End time must be after start time")
+		}
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			violations.Add(field+".timezone", "unknown_timezone", fmt.Sprintf("Unknown timezone %q", schedule.Timezone))
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	// Jadwal dalam satu kelas tidak boleh saling tumpang tindih
+	if violations.OrNil() == nil {
+		for i := range schedules {
+			for j := i + 1; j < len(schedules); j++ {
+				if schedulesOverlap(schedules[i], schedules[j]) {
+					violations.Add(fmt.Sprintf("schedules[%d]", j), "overlap", fmt.Sprintf("Overlaps with schedules[%d]", i))
+				}
+			}
+		}
+	}
+
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// RenderJadwalKelas membuat teks jadwal lama, contoh "Senin 07:30-09:00 (R. 101), Rabu 10:00-11:30".
+func RenderJadwalKelas(schedules []model.ClassSchedule) string {
+	sorted := append([]model.ClassSchedule(nil), schedules...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].DayOfWeek != sorted[j].DayOfWeek {
+			return sorted[i].DayOfWeek < sorted[j].DayOfWeek
+		}
+		return sorted[i].StartTime < sorted[j].StartTime
+	})
+
+	parts := make([]string, 0, len(sorted))
+	for _, schedule := range sorted {
+		part := fmt.Sprintf("%s %s-%s", dayNames[schedule.DayOfWeek], schedule.StartTime, schedule.EndTime)
+		if schedule.Room != "" {
+			part += " (" + schedule.Room + ")"
+		}
+		if schedule.Timezone != config.DefaultTimezone {
+			part += " " + schedule.Timezone
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// weekMinutes mengubah jadwal ke rentang menit dalam satu minggu (UTC) supaya jadwal
+// dengan timezone berbeda bisa dibandingkan.
+func weekMinutes(schedule model.ClassSchedule) (int, int) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, _ := time.Parse("15:04", schedule.StartTime)
+	end, _ := time.Parse("15:04", schedule.EndTime)
+
+	// Senin referensi: 2024-01-01
+	day := time.Date(2024, 1, schedule.DayOfWeek, 0, 0, 0, 0, loc)
+	reference := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := day.Add(time.Duration(start.Hour()*60+start.Minute()) * time.Minute).Sub(reference)
+	to := day.Add(time.Duration(end.Hour()*60+end.Minute()) * time.Minute).Sub(reference)
+
+	fromMinutes := ((int(from.Minutes()) % minutesPerWeek) + minutesPerWeek) % minutesPerWeek
+	return fromMinutes, fromMinutes + int((to - from).Minutes())
+}
+
+func schedulesOverlap(a, b model.ClassSchedule) bool {
+	aStart, aEnd := weekMinutes(a)
+	bStart, bEnd := weekMinutes(b)
+	for _, shift := range []int{-minutesPerWeek, 0, minutesPerWeek} {
+		if aStart < bEnd+shift && bStart+shift < aEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// findScheduleConflicts mencari jadwal kelas lain yang bentrok: ruangan sama, guru yang sama
+// (owner/co-teacher) atau siswa yang terdaftar di kedua kelas.
+func findScheduleConflicts(q dbExecutor, classID int, teacherIDs []int, schedules []model.ClassSchedule) ([]dto.ScheduleConflict, error) {
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+
+	rooms := []string{}
+	for _, schedule := range schedules {
+		if schedule.Room != "" {
+			rooms = append(rooms, strings.ToLower(schedule.Room))
+		}
+	}
+
+	rows, err := q.Query(`
+        SELECT s.class_id, c.name, s.day_of_week, to_char(s.start_time, 'HH24:MI'), to_char(s.end_time, 'HH24:MI'), s.room, s.timezone,
+            s.room <> '' AND LOWER(s.room) = ANY($2) AS room_match,
+            EXISTS (
+                SELECT 1 FROM class_members m
+                WHERE m.class_id = s.class_id AND m.role IN ('owner', 'co_teacher') AND m.user_id = ANY($3)
+            ) AS teacher_match,
+            (
+                SELECT COUNT(*) FROM class_members m
+                JOIN class_members own ON own.user_id = m.user_id AND own.class_id = $1 AND own.role = 'siswa'
+                WHERE m.class_id = s.class_id AND m.role = 'siswa'
+            ) AS shared_students
+        FROM class_schedules s
+        JOIN classes c ON c.id = s.class_id
+        WHERE s.class_id <> $1 AND c.archived_at IS NULL
+    `, classID, pq.Array(rooms), pq.Array(teacherIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []dto.ScheduleConflict
+	for rows.Next() {
+		var other model.ClassSchedule
+		var className string
+		var roomMatch, teacherMatch bool
+		var sharedStudents int
+		if err := rows.Scan(&other.ClassID, &className, &other.DayOfWeek, &other.StartTime, &other.EndTime, &other.Room,
+			&other.Timezone, &roomMatch, &teacherMatch, &sharedStudents); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		if !roomMatch && !teacherMatch && sharedStudents == 0 {
+			continue
+		}
+
+		for _, schedule := range schedules {
+			if !schedulesOverlap(schedule, other) {
+				continue
+			}
+			conflict := dto.ScheduleConflict{
+				ClassID:   other.ClassID,
+				ClassName: className,
+				DayOfWeek: other.DayOfWeek,
+				StartTime: other.StartTime,
+				EndTime:   other.EndTime,
+				Room:      other.Room,
+			}
+			if teacherMatch {
+				conflict.Type = "teacher"
+				conflicts = append(conflicts, conflict)
+			}
+			if roomMatch && strings.EqualFold(schedule.Room, other.Room) {
+				conflict.Type = "room"
+				conflicts = append(conflicts, conflict)
+			}
+			if sharedStudents > 0 {
+				conflict.Type = "student"
+				conflict.Students = sharedStudents
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+// classTeacherIDs mengembalikan owner dan co-teacher sebuah kelas.
+func classTeacherIDs(q dbExecutor, classID int) ([]int, error) {
+	rows, err := q.Query(`SELECT user_id FROM class_members WHERE class_id = $1 AND role IN ('owner', 'co_teacher')`, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class teachers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan class teacher: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// saveClassSchedules mengganti seluruh jadwal kelas setelah mengecek bentrok, lalu
+// mengembalikan jadwal_kelas hasil render.
+func saveClassSchedules(q dbExecutor, classID int, teacherIDs []int, schedules []model.ClassSchedule, ignoreConflicts bool) (string, error) {
+	if !ignoreConflicts {
+		conflicts, err := findScheduleConflicts(q, classID, teacherIDs, schedules)
+		if err != nil {
+			return "", err
+		}
+		if len(conflicts) > 0 {
+			return "", &ScheduleConflictError{Conflicts: conflicts}
+		}
+	}
+
+	if _, err := q.Exec(`DELETE FROM class_schedules WHERE class_id = $1`, classID); err != nil {
+		return "", fmt.Errorf("failed to clear schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		_, err := q.Exec(`
+            INSERT INTO class_schedules (class_id, day_of_week, start_time, end_time, room, timezone)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, classID, schedule.DayOfWeek, schedule.StartTime, schedule.EndTime, schedule.Room, schedule.Timezone)
+		if err != nil {
+			return "", fmt.Errorf("failed to save schedule: %w", err)
+		}
+	}
+	return RenderJadwalKelas(schedules), nil
+}
+
+func (s *ScheduleService) GetClassSchedules(classID int) ([]model.ClassSchedule, error) {
+	return loadClassSchedules(s.DB, classID)
+}
+
+func loadClassSchedules(q dbExecutor, classID int) ([]model.ClassSchedule, error) {
+	rows, err := q.Query(`
+        SELECT id, class_id, day_of_week, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), room, timezone
+        FROM class_schedules WHERE class_id = $1
+        ORDER BY day_of_week, start_time
+    `, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []model.ClassSchedule{}
+	for rows.Next() {
+		var schedule model.ClassSchedule
+		if err := rows.Scan(&schedule.ID, &schedule.ClassID, &schedule.DayOfWeek, &schedule.StartTime,
+			&schedule.EndTime, &schedule.Room, &schedule.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetExceptions mengembalikan pengecualian jadwal kelas (termasuk libur umum) atau,
+// jika classID nil, hanya libur umum.
+func (s *ScheduleService) GetExceptions(classID *int) ([]model.ScheduleException, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, class_id, to_char(date, 'YYYY-MM-DD'), reason, created_at
+        FROM schedule_exceptions
+        WHERE class_id IS NULL OR class_id = $1
+        ORDER BY date
+    `, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := []model.ScheduleException{}
+	for rows.Next() {
+		exception, err := scanScheduleException(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+		}
+		exceptions = append(exceptions, *exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule exceptions: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// AddException menambahkan tanggal tanpa pertemuan. classID nil berarti libur umum.
+func (s *ScheduleService) AddException(classID *int, req dto.ScheduleExceptionRequest, createdBy int) (*model.ScheduleException, error) {
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(req.Date))
+	if err != nil {
+		violations := &ValidationError{}
+		violations.Add("date", "invalid_format", "Date must use YYYY-MM-DD format")
+		return nil, violations
+	}
+
+	exception, err := scanScheduleException(s.DB.QueryRow(`
+        INSERT INTO schedule_exceptions (class_id, date, reason, created_by) VALUES ($1, $2, $3, $4)
+        ON CONFLICT ((COALESCE(class_id, 0)), date) DO UPDATE SET reason = EXCLUDED.reason
+        RETURNING id, class_id, to_char(date, 'YYYY-MM-DD'), reason, created_at
+    `, classID, date, strings.TrimSpace(req.Reason), createdBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save schedule exception: %w", err)
+	}
+	return exception, nil
+}
+
+// DeleteException menghapus pengecualian milik kelas (atau libur umum jika classID nil).
+func (s *ScheduleService) DeleteException(classID *int, exceptionID int) error {
+	result, err := s.DB.Exec(`
+        DELETE FROM schedule_exceptions
+        WHERE id = $1 AND (($2::INT IS NULL AND class_id IS NULL) OR class_id = $2)
+    `, exceptionID, classID)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule exception: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrScheduleExceptionNotFound
+	}
+	return nil
+}
+
+// GetTimetable mengembalikan pertemuan semua kelas user (sebagai siswa maupun guru)
+// mulai tanggal from selama days hari. Pertemuan di hari libur ditandai cancelled.
+func (s *ScheduleService) GetTimetable(userID int, from time.Time, days int) ([]dto.TimetableEntry, error) {
+	if days < 1 {
+		days = 7
+	}
+	if days > maxTimetableDays {
+		days = maxTimetableDays
+	}
+	to := from.AddDate(0, 0, days-1)
+
+	type session struct {
+		model.ClassSchedule
+		className string
+		classRole string
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT s.class_id, c.name, m.role, s.day_of_week, to_char(s.start_time, 'HH24:MI'), to_char(s.end_time, 'HH24:MI'), s.room, s.timezone
+        FROM class_members m
+        JOIN classes c ON c.id = m.class_id
+        JOIN class_schedules s ON s.class_id = m.class_id
+        WHERE m.user_id = $1 AND c.archived_at IS NULL
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timetable: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []session
+	classIDs := []int{}
+	seen := map[int]bool{}
+	for rows.Next() {
+		var item session
+		if err := rows.Scan(&item.ClassID, &item.className, &item.classRole, &item.DayOfWeek, &item.StartTime,
+			&item.EndTime, &item.Room, &item.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan timetable: %w", err)
+		}
+		sessions = append(sessions, item)
+		if !seen[item.ClassID] {
+			seen[item.ClassID] = true
+			classIDs = append(classIDs, item.ClassID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating timetable: %w", err)
+	}
+
+	// key: "<class_id>|<date>", class_id 0 untuk libur umum
+	exceptions := map[string]string{}
+	exceptionRows, err := s.DB.Query(`
+        SELECT COALESCE(class_id, 0), to_char(date, 'YYYY-MM-DD'), reason
+        FROM schedule_exceptions
+        WHERE date BETWEEN $1 AND $2 AND (class_id IS NULL OR class_id = ANY($3))
+    `, from.Format("2006-01-02"), to.Format("2006-01-02"), pq.Array(classIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule exceptions: %w", err)
+	}
+	defer exceptionRows.Close()
+	for exceptionRows.Next() {
+		var classID int
+		var date, reason string
+		if err := exceptionRows.Scan(&classID, &date, &reason); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+		}
+		exceptions[fmt.Sprintf("%d|%s", classID, date)] = reason
+	}
+	if err := exceptionRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule exceptions: %w", err)
+	}
+
+	entries := []dto.TimetableEntry{}
+	for day := 0; day < days; day++ {
+		date := from.AddDate(0, 0, day)
+		isoDay := int(date.Weekday())
+		if isoDay == 0 {
+			isoDay = 7
+		}
+		dateStr := date.Format("2006-01-02")
+
+		for _, item := range sessions {
+			if item.DayOfWeek != isoDay {
+				continue
+			}
+			startAt, endAt := sessionTimes(item.ClassSchedule, date)
+			entry := dto.TimetableEntry{
+				Date:      dateStr,
+				ClassID:   item.ClassID,
+				ClassName: item.className,
+				ClassRole: item.classRole,
+				StartAt:   startAt,
+				EndAt:     endAt,
+				Room:      item.Room,
+			}
+			if reason, ok := exceptions[fmt.Sprintf("%d|%s", item.ClassID, dateStr)]; ok {
+				entry.Cancelled, entry.Reason = true, reason
+			} else if reason, ok := exceptions["0|"+dateStr]; ok {
+				entry.Cancelled, entry.Reason = true, reason
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartAt.Before(entries[j].StartAt) })
+	return entries, nil
+}
+
+// sessionTimes menghitung waktu mulai dan selesai pertemuan pada tanggal tertentu di timezone jadwal.
+func sessionTimes(schedule model.ClassSchedule, date time.Time) (time.Time, time.Time) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, _ := time.Parse("15:04", schedule.StartTime)
+	end, _ := time.Parse("15:04", schedule.EndTime)
+	year, month, day := date.Date()
+	return time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, loc),
+		time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, loc)
+}
+
+func scanScheduleException(row rowScanner) (*model.ScheduleException, error) {
+	var exception model.ScheduleException
+	var classID sql.NullInt64
+	if err := row.Scan(&exception.ID, &classID, &exception.Date, &exception.Reason, &exception.CreatedAt); err != nil {
+		return nil, err
+	}
+	if classID.Valid {
+		id := int(classID.Int64)
+		exception.ClassID = &id
+	}
+	return &exception, nil
+}
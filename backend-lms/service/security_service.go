@@ -0,0 +1,291 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"project/config"
+	"project/dto"
+	"project/model"
+	"time"
+)
+
+// Jenis event keamanan yang dicatat di security_events.
+const (
+	EventFailedLoginBurst = "failed_login_burst"
+	EventRoleChanged      = "role_changed"
+	EventPasswordReset    = "password_reset"
+	EventPasswordChanged  = "password_changed"
+	EventSessionRevoked   = "session_revoked"
+)
+
+// Metode login yang dicatat di login_attempts dan user_sessions.
+const (
+	LoginMethodPassword = "password"
+	LoginMethodMFA      = "mfa"
+	LoginMethodOIDC     = "oidc"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked or expired")
+)
+
+// SecurityService mencatat riwayat login, sesi aktif dan event keamanan.
+type SecurityService struct {
+	DB *sql.DB
+}
+
+func NewSecurityService(db *sql.DB) *SecurityService {
+	return &SecurityService{DB: db}
+}
+
+// RecordLoginAttempt mencatat percobaan login. Kegagalan beruntun per username atau
+// per IP di dalam window memunculkan event failed_login_burst.
+func (s *SecurityService) RecordLoginAttempt(userID int, username, method string, success bool, reason string, client dto.ClientInfo) {
+	_, err := s.DB.Exec(`
+        INSERT INTO login_attempts (user_id, username, success, method, failure_reason, ip, user_agent)
+        VALUES (NULLIF($1, 0), $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''))
+    `, userID, username, success, method, reason, client.IP, client.UserAgent)
+	if err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+		return
+	}
+
+	if !success {
+		s.detectFailedLoginBurst(userID, username, client)
+	}
+}
+
+func (s *SecurityService) detectFailedLoginBurst(userID int, username string, client dto.ClientInfo) {
+	window := fmt.Sprintf("%d minutes", config.FailedLoginBurstWindowMinutes)
+
+	var byUsername, byIP int
+	err := s.DB.QueryRow(`
+        SELECT
+            COUNT(*) FILTER (WHERE username = $1),
+            COUNT(*) FILTER (WHERE ip = NULLIF($2, ''))
+        FROM login_attempts
+        WHERE success = FALSE AND created_at > NOW() - $3::interval
+    `, username, client.IP, window).Scan(&byUsername, &byIP)
+	if err != nil {
+		log.Printf("Failed to count failed logins: %v", err)
+		return
+	}
+
+	// Event hanya dibuat saat ambang batas tercapai, bukan untuk setiap kegagalan berikutnya
+	threshold := config.FailedLoginBurstThreshold
+	if byUsername == threshold || byIP == threshold {
+		s.RecordEvent(EventFailedLoginBurst, "warning", userID, 0, client.IP, map[string]interface{}{
+			"username":          username,
+			"failures_username": byUsername,
+			"failures_ip":       byIP,
+			"window_minutes":    config.FailedLoginBurstWindowMinutes,
+			"user_agent":        client.UserAgent,
+		})
+	}
+}
+
+// RecordEvent menyimpan event keamanan. Error hanya di-log supaya tidak menggagalkan aksi utama.
+func (s *SecurityService) RecordEvent(eventType, severity string, userID, actorID int, ip string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(details)
+	if err != nil {
+		log.Printf("Failed to encode security event details: %v", err)
+		payload = []byte("{}")
+	}
+
+	_, err = s.DB.Exec(`
+        INSERT INTO security_events (event_type, severity, user_id, actor_id, ip, details)
+        VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, ''), $6)
+    `, eventType, severity, userID, actorID, ip, payload)
+	if err != nil {
+		log.Printf("Failed to record security event %s: %v", eventType, err)
+	}
+}
+
+// CreateSession mencatat sesi untuk JWT dengan jti sessionID.
+func (s *SecurityService) CreateSession(sessionID string, userID int, method string, expiresAt time.Time, client dto.ClientInfo) error {
+	_, err := s.DB.Exec(`
+        INSERT INTO user_sessions (id, user_id, method, ip, user_agent, expires_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+    `, sessionID, userID, method, client.IP, client.UserAgent, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// ValidateSession dipasang sebagai middleware.SessionValidator. Sesi yang dicabut atau
+// kedaluwarsa ditolak; last_seen_at diperbarui paling sering sekali per menit.
+func (s *SecurityService) ValidateSession(sessionID string, userID int) error {
+	result, err := s.DB.Exec(`
+        UPDATE user_sessions SET last_seen_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+          AND last_seen_at < NOW() - INTERVAL '1 minute'
+    `, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 1 {
+		return nil
+	}
+
+	var active bool
+	err = s.DB.QueryRow(`
+        SELECT revoked_at IS NULL AND expires_at > NOW() FROM user_sessions WHERE id = $1 AND user_id = $2
+    `, sessionID, userID).Scan(&active)
+	if err == sql.ErrNoRows || (err == nil && !active) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to validate session: %w", err)
+	}
+	return nil
+}
+
+// GetActiveSessions mengembalikan sesi yang belum dicabut dan belum kedaluwarsa.
+func (s *SecurityService) GetActiveSessions(userID int, currentSessionID string) ([]model.UserSession, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, user_id, method, ip, user_agent, created_at, last_seen_at, expires_at
+        FROM user_sessions
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY last_seen_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.UserSession
+	for rows.Next() {
+		var session model.UserSession
+		if err := rows.Scan(&session.ID, &session.UserID, &session.Method, &session.IP, &session.UserAgent,
+			&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session.Current = session.ID == currentSessionID
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession mencabut satu sesi milik user.
+func (s *SecurityService) RevokeSession(userID int, sessionID string, client dto.ClientInfo) error {
+	result, err := s.DB.Exec(
+		`UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	s.RecordEvent(EventSessionRevoked, "info", userID, userID, client.IP, map[string]interface{}{"session_id": sessionID})
+	return nil
+}
+
+// RevokeAllSessions mencabut semua sesi user kecuali exceptSessionID (boleh kosong).
+func (s *SecurityService) RevokeAllSessions(userID int, exceptSessionID string) (int64, error) {
+	result, err := s.DB.Exec(
+		`UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`,
+		userID, exceptSessionID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RevokeAllAccessTokens mencabut semua personal access token aktif milik user.
+func (s *SecurityService) RevokeAllAccessTokens(userID int) (int64, error) {
+	result, err := s.DB.Exec(
+		`UPDATE personal_access_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *SecurityService) GetLoginHistory(userID, limit int) ([]model.LoginAttempt, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, user_id, username, success, method, failure_reason, ip, user_agent, created_at
+        FROM login_attempts WHERE user_id = $1
+        ORDER BY created_at DESC LIMIT $2
+    `, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login history: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []model.LoginAttempt
+	for rows.Next() {
+		var attempt model.LoginAttempt
+		if err := rows.Scan(&attempt.ID, &attempt.UserID, &attempt.Username, &attempt.Success, &attempt.Method,
+			&attempt.FailureReason, &attempt.IP, &attempt.UserAgent, &attempt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan login attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating login history: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// GetEvents mengembalikan feed event keamanan terbaru, bisa difilter per jenis dan user.
+// beforeID dipakai untuk pagination (0 = dari yang terbaru).
+func (s *SecurityService) GetEvents(eventType string, userID, beforeID, limit int) ([]model.SecurityEvent, error) {
+	rows, err := s.DB.Query(`
+        SELECT e.id, e.event_type, e.severity, e.user_id, u.username, e.actor_id, e.ip, e.details, e.created_at
+        FROM security_events e
+        LEFT JOIN users u ON u.id = e.user_id
+        WHERE ($1 = '' OR e.event_type = $1)
+          AND ($2 = 0 OR e.user_id = $2)
+          AND ($3 = 0 OR e.id < $3)
+        ORDER BY e.id DESC
+        LIMIT $4
+    `, eventType, userID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query security events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.SecurityEvent
+	for rows.Next() {
+		var event model.SecurityEvent
+		var details []byte
+		if err := rows.Scan(&event.ID, &event.EventType, &event.Severity, &event.UserID, &event.Username,
+			&event.ActorID, &event.IP, &details, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan security event: %w", err)
+		}
+		event.Details = json.RawMessage(details)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating security events: %w", err)
+	}
+
+	return events, nil
+}
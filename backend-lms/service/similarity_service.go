@@ -0,0 +1,489 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"project/dto"
+	"project/middleware"
+	"project/model"
+	"project/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultSimilarityThreshold = 0.2
+	// Pasangan yang berbagi kurang dari ini tidak dilaporkan (kalimat pembuka yang umum, dll.)
+	minSharedShingles     = 3
+	maxSimilarityPassages = 10
+	maxPassageChars       = 500
+	maxExtractDownload    = 20 << 20
+)
+
+var ErrSimilarityNotChecked = errors.New("similarity has not been checked for this assignment yet")
+
+// SimilarityService membandingkan teks pengumpulan (jawaban teks dan isi file) antar siswa
+// tanpa layanan eksternal: shingle kata, MinHash untuk penyaringan dan Jaccard untuk skor.
+type SimilarityService struct {
+	DB         *sql.DB
+	HTTPClient *http.Client
+}
+
+func NewSimilarityService(db *sql.DB) *SimilarityService {
+	return &SimilarityService{
+		DB:         db,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type similarityDocument struct {
+	submissionID int
+	assignmentID int
+	textAnswer   string
+	fingerprint  *utils.Fingerprint
+}
+
+type similarityMatch struct {
+	matchedID   int
+	jaccard     float64
+	containment float64
+	passages    []model.MatchedPassage
+}
+
+// CheckAssignment memeriksa semua pengumpulan tugas (selain draft) terhadap satu sama lain dan,
+// jika OtherClasses, terhadap pengumpulan tugas berjudul sama di kelas lain termasuk semester
+// sebelumnya. Kelas lain dibatasi pada kelas yang bisa dinilai pemeriksa (semua kelas untuk
+// Admin). Hasil pemeriksaan sebelumnya diganti.
+func (s *SimilarityService) CheckAssignment(classID, assignmentID, checkerID int, checkerRole string, req dto.SimilarityCheckRequest) (*model.SimilarityReport, error) {
+	threshold := defaultSimilarityThreshold
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+	otherClasses := req.OtherClasses != nil && *req.OtherClasses
+
+	var title string
+	err := s.DB.QueryRow(`SELECT title FROM assignments WHERE id = $1 AND class_id = $2`, assignmentID, classID).Scan(&title)
+	if err == sql.ErrNoRows {
+		return nil, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	targets, err := s.queryDocuments(`
+        SELECT id, assignment_id, text_answer FROM submissions
+        WHERE assignment_id = $1 AND status <> 'draft'
+    `, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	var others []*similarityDocument
+	if otherClasses {
+		others, err = s.queryDocuments(`
+            SELECT s.id, s.assignment_id, s.text_answer
+            FROM submissions s
+            JOIN assignments a ON a.id = s.assignment_id
+            WHERE a.id <> $1 AND lower(trim(a.title)) = lower(trim($2)) AND s.status <> 'draft'
+              AND ($3 = 'Admin' OR EXISTS (
+                  SELECT 1 FROM class_members cm
+                  WHERE cm.class_id = a.class_id AND cm.user_id = $4 AND cm.role = ANY($5)
+              ))
+        `, assignmentID, title, checkerRole, checkerID, pq.Array(classRolesWith(middleware.ClassPermGrade)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	all := append(append([]*similarityDocument{}, targets...), others...)
+	if err := s.fingerprintDocuments(all); err != nil {
+		return nil, err
+	}
+
+	results := map[int][]similarityMatch{}
+	for _, doc := range targets {
+		for _, other := range all {
+			if other.submissionID == doc.submissionID {
+				continue
+			}
+			if match, ok := compareDocuments(doc, other, threshold); ok {
+				results[doc.submissionID] = append(results[doc.submissionID], match)
+			}
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM similarity_checks WHERE assignment_id = $1`, assignmentID); err != nil {
+		return nil, fmt.Errorf("failed to clear previous similarity check: %w", err)
+	}
+	_, err = tx.Exec(`
+        INSERT INTO similarity_checks (assignment_id, threshold, other_classes, submission_count, compared_count, checked_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, assignmentID, threshold, otherClasses, len(targets), len(others), checkerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save similarity check: %w", err)
+	}
+	for _, doc := range targets {
+		maxScore := 0.0
+		for _, match := range results[doc.submissionID] {
+			if match.containment > maxScore {
+				maxScore = match.containment
+			}
+			passages, err := json.Marshal(match.passages)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode passages: %w", err)
+			}
+			_, err = tx.Exec(`
+                INSERT INTO similarity_matches (assignment_id, submission_id, matched_submission_id, jaccard, containment, passages)
+                VALUES ($1, $2, $3, $4, $5, $6)
+            `, assignmentID, doc.submissionID, match.matchedID, match.jaccard, match.containment, passages)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save similarity match: %w", err)
+			}
+		}
+		_, err := tx.Exec(`
+            INSERT INTO similarity_submissions (assignment_id, submission_id, word_count, max_score)
+            VALUES ($1, $2, $3, $4)
+        `, assignmentID, doc.submissionID, len(doc.fingerprint.Tokens), maxScore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save similarity result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save similarity check: %w", err)
+	}
+	return s.GetReport(classID, assignmentID)
+}
+
+// compareDocuments: MinHash menyaring pasangan yang hampir pasti tidak berbagi shingle,
+// sisanya dihitung persis. Skor yang dibandingkan dengan ambang adalah containment.
+func compareDocuments(doc, other *similarityDocument, threshold float64) (similarityMatch, bool) {
+	a, b := doc.fingerprint, other.fingerprint
+	if utils.EstimateJaccard(a.Signature, b.Signature) == 0 {
+		return similarityMatch{}, false
+	}
+	jaccard, containment := a.Compare(b)
+	if containment < threshold || containment*float64(len(a.Set)) < minSharedShingles {
+		return similarityMatch{}, false
+	}
+
+	match := similarityMatch{matchedID: other.submissionID, jaccard: jaccard, containment: containment, passages: []model.MatchedPassage{}}
+	for _, passage := range a.MatchedPassages(b, maxSimilarityPassages, maxPassageChars) {
+		match.passages = append(match.passages, model.MatchedPassage{
+			Text:        passage.Text,
+			MatchedText: passage.MatchedText,
+			Words:       passage.Words,
+		})
+	}
+	return match, true
+}
+
+func (s *SimilarityService) queryDocuments(query string, args ...interface{}) ([]*similarityDocument, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submissions: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*similarityDocument
+	for rows.Next() {
+		var doc similarityDocument
+		if err := rows.Scan(&doc.submissionID, &doc.assignmentID, &doc.textAnswer); err != nil {
+			return nil, fmt.Errorf("failed to scan submission: %w", err)
+		}
+		docs = append(docs, &doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submissions: %w", err)
+	}
+	return docs, nil
+}
+
+// fingerprintDocuments menggabungkan jawaban teks dengan teks file setiap pengumpulan. File
+// yang belum pernah diproses diunduh dan diekstrak dulu, hasilnya disimpan di submission_files.
+func (s *SimilarityService) fingerprintDocuments(docs []*similarityDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	ids := make([]int, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.submissionID
+	}
+
+	type fileText struct {
+		id, submissionID int
+		url, fileName    string
+		text, extractErr *string
+	}
+	rows, err := s.DB.Query(`
+        SELECT id, submission_id, url, file_name, extracted_text, extract_error FROM submission_files
+        WHERE submission_id = ANY($1) ORDER BY id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to query submission files: %w", err)
+	}
+	var files []fileText
+	for rows.Next() {
+		var file fileText
+		if err := rows.Scan(&file.id, &file.submissionID, &file.url, &file.fileName, &file.text, &file.extractErr); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan submission file: %w", err)
+		}
+		files = append(files, file)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating submission files: %w", err)
+	}
+
+	texts := map[int][]string{}
+	for _, file := range files {
+		if file.text == nil && (file.extractErr == nil || utils.CanExtractText(submissionFileName(file.url, file.fileName))) {
+			text, extractErr := s.extractFile(file.url, file.fileName)
+			var textValue, errValue *string
+			if extractErr != nil {
+				message := extractErr.Error()
+				errValue = &message
+			} else {
+				textValue = &text
+			}
+			_, err := s.DB.Exec(`UPDATE submission_files SET extracted_text = $1, extract_error = $2 WHERE id = $3`,
+				textValue, errValue, file.id)
+			if err != nil {
+				return fmt.Errorf("failed to save extracted text: %w", err)
+			}
+			file.text = textValue
+		}
+		if file.text != nil {
+			texts[file.submissionID] = append(texts[file.submissionID], *file.text)
+		}
+	}
+
+	for _, doc := range docs {
+		parts := append([]string{doc.textAnswer}, texts[doc.submissionID]...)
+		doc.fingerprint = utils.NewFingerprint(strings.Join(parts, "\n\n"))
+	}
+	return nil
+}
+
+// extractFile mengunduh file pengumpulan dari storage dan membaca teksnya.
+func (s *SimilarityService) extractFile(fileURL, fileName string) (string, error) {
+	name := submissionFileName(fileURL, fileName)
+	if !utils.CanExtractText(name) {
+		return "", utils.ErrUnsupportedDocument
+	}
+
+	resp, err := s.HTTPClient.Get(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxExtractDownload+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	if len(data) > maxExtractDownload {
+		return "", errors.New("file is too large to check")
+	}
+	return utils.ExtractText(name, data)
+}
+
+// submissionFileName: file lama tidak menyimpan nama file, jadi ekstensinya diambil dari URL.
+func submissionFileName(fileURL, fileName string) string {
+	if fileName != "" {
+		return fileName
+	}
+	if parsed, err := url.Parse(fileURL); err == nil {
+		return path.Base(parsed.Path)
+	}
+	return fileURL
+}
+
+// GetReport mengembalikan hasil pemeriksaan terakhir, diurutkan dari skor tertinggi.
+func (s *SimilarityService) GetReport(classID, assignmentID int) (*model.SimilarityReport, error) {
+	report, err := s.getCheck(classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Submissions, err = s.loadSubmissionSimilarity(assignmentID, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetSubmissionSimilarity mengembalikan hasil satu pengumpulan beserta potongan teks yang sama.
+func (s *SimilarityService) GetSubmissionSimilarity(classID, assignmentID, submissionID int) (*model.SubmissionSimilarity, error) {
+	if _, err := s.getCheck(classID, assignmentID); err != nil {
+		return nil, err
+	}
+	results, err := s.loadSubmissionSimilarity(assignmentID, &submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return &results[0], nil
+}
+
+func (s *SimilarityService) getCheck(classID, assignmentID int) (*model.SimilarityReport, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	report := &model.SimilarityReport{AssignmentID: assignmentID}
+	err := s.DB.QueryRow(`
+        SELECT threshold, other_classes, submission_count, compared_count, checked_by, checked_at
+        FROM similarity_checks WHERE assignment_id = $1
+    `, assignmentID).Scan(&report.Threshold, &report.OtherClasses, &report.SubmissionCount, &report.ComparedCount,
+		&report.CheckedBy, &report.CheckedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSimilarityNotChecked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similarity check: %w", err)
+	}
+	return report, nil
+}
+
+// loadSubmissionSimilarity mengisi hasil per pengumpulan; submissionID nil berarti semua.
+func (s *SimilarityService) loadSubmissionSimilarity(assignmentID int, submissionID *int) ([]model.SubmissionSimilarity, error) {
+	rows, err := s.DB.Query(`
+        SELECT ss.submission_id, s.user_id, u.username, u.full_name, ss.word_count, ss.max_score,
+               COALESCE(s.submitted_at > c.checked_at, FALSE)
+        FROM similarity_submissions ss
+        JOIN similarity_checks c ON c.assignment_id = ss.assignment_id
+        JOIN submissions s ON s.id = ss.submission_id
+        JOIN users u ON u.id = s.user_id
+        WHERE ss.assignment_id = $1 AND ($2::INT IS NULL OR ss.submission_id = $2)
+        ORDER BY ss.max_score DESC, u.username
+    `, assignmentID, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similarity results: %w", err)
+	}
+	results := []model.SubmissionSimilarity{}
+	var ids []int
+	for rows.Next() {
+		result := model.SubmissionSimilarity{Warnings: []string{}, Matches: []model.SimilarityMatch{}}
+		if err := rows.Scan(&result.SubmissionID, &result.UserID, &result.Username, &result.FullName,
+			&result.WordCount, &result.MaxScore, &result.Outdated); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan similarity result: %w", err)
+		}
+		results = append(results, result)
+		ids = append(ids, result.SubmissionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating similarity results: %w", err)
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	matches, err := s.loadMatches(assignmentID, ids)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := s.loadExtractWarnings(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		id := results[i].SubmissionID
+		if matches[id] != nil {
+			results[i].Matches = matches[id]
+		}
+		if warnings[id] != nil {
+			results[i].Warnings = warnings[id]
+		}
+	}
+	return results, nil
+}
+
+func (s *SimilarityService) loadMatches(assignmentID int, submissionIDs []int) (map[int][]model.SimilarityMatch, error) {
+	rows, err := s.DB.Query(`
+        SELECT m.submission_id, o.id, o.user_id, u.username, u.full_name, a.id, a.title, c.id, c.name,
+               COALESCE(y.name || ' ' || t.name, ''), a.id = m.assignment_id, m.jaccard, m.containment, m.passages
+        FROM similarity_matches m
+        JOIN submissions o ON o.id = m.matched_submission_id
+        JOIN users u ON u.id = o.user_id
+        JOIN assignments a ON a.id = o.assignment_id
+        JOIN classes c ON c.id = a.class_id
+        LEFT JOIN terms t ON t.id = c.term_id
+        LEFT JOIN academic_years y ON y.id = t.academic_year_id
+        WHERE m.assignment_id = $1 AND m.submission_id = ANY($2)
+        ORDER BY m.containment DESC, u.username
+    `, assignmentID, pq.Array(submissionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similarity matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := map[int][]model.SimilarityMatch{}
+	for rows.Next() {
+		var submissionID int
+		var match model.SimilarityMatch
+		var passages []byte
+		if err := rows.Scan(&submissionID, &match.SubmissionID, &match.UserID, &match.Username, &match.FullName,
+			&match.AssignmentID, &match.AssignmentTitle, &match.ClassID, &match.ClassName, &match.Term,
+			&match.SameAssignment, &match.Jaccard, &match.Containment, &passages); err != nil {
+			return nil, fmt.Errorf("failed to scan similarity match: %w", err)
+		}
+		if err := json.Unmarshal(passages, &match.Passages); err != nil {
+			return nil, fmt.Errorf("failed to decode passages: %w", err)
+		}
+		matches[submissionID] = append(matches[submissionID], match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating similarity matches: %w", err)
+	}
+	return matches, nil
+}
+
+// loadExtractWarnings melaporkan file yang tidak ikut diperiksa karena teksnya tidak terbaca.
+func (s *SimilarityService) loadExtractWarnings(submissionIDs []int) (map[int][]string, error) {
+	rows, err := s.DB.Query(`
+        SELECT submission_id, file_name, url, extract_error FROM submission_files
+        WHERE submission_id = ANY($1) AND (extract_error IS NOT NULL OR extracted_text = '')
+        ORDER BY id
+    `, pq.Array(submissionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submission files: %w", err)
+	}
+	defer rows.Close()
+
+	warnings := map[int][]string{}
+	for rows.Next() {
+		var submissionID int
+		var fileName, fileURL string
+		var extractErr *string
+		if err := rows.Scan(&submissionID, &fileName, &fileURL, &extractErr); err != nil {
+			return nil, fmt.Errorf("failed to scan submission file: %w", err)
+		}
+		message := "no text found in file (scanned document?)"
+		if extractErr != nil {
+			message = *extractErr
+		}
+		warnings[submissionID] = append(warnings[submissionID], submissionFileName(fileURL, fileName)+": "+message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submission files: %w", err)
+	}
+	return warnings, nil
+}
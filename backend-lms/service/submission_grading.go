@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+)
+
+// defaultMaxPoints dipakai jika guru tidak mengisi nilai maksimal tugas.
+const defaultMaxPoints = 100
+
+var (
+	ErrSubmissionCommentNotFound = errors.New("submission comment not found")
+	ErrSubmissionNotTurnedIn     = errors.New("submission has not been turned in")
+)
+
+// GradeSubmission menyimpan poin dan feedback lalu memperbarui buku nilai siswa.
+// Dengan req.Return pengumpulan langsung dikembalikan ke siswa.
+func (s *SubmissionService) GradeSubmission(classID, assignmentID, submissionID, graderID int, req dto.GradeSubmissionRequest) (*model.Submission, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := getAssignmentDeadline(tx, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	submission, err := getTeacherSubmission(tx, assignmentID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if *req.Points < 0 || *req.Points > submission.MaxPoints {
+		violations := &ValidationError{}
+		violations.Add("points", "out_of_range", fmt.Sprintf("Points must be between 0 and %g", submission.MaxPoints))
+		return nil, violations
+	}
+	if err := saveSubmissionGrade(tx, submission, graderID, *req.Points, req.Feedback, req.Return); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to grade submission: %w", err)
+	}
+	return s.GetSubmission(classID, assignmentID, submissionID)
+}
+
+// ReturnSubmission mengembalikan pengumpulan ke siswa. Siswa bisa memperbaiki dan
+// mengumpulkan ulang; nilai yang sudah ada tetap tercatat sampai dinilai ulang.
+func (s *SubmissionService) ReturnSubmission(classID, assignmentID, submissionID int) (*model.Submission, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	submission, err := getTeacherSubmission(s.DB, assignmentID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.Status == SubmissionReturned {
+		return nil, ErrSubmissionNotTurnedIn
+	}
+
+	_, err = s.DB.Exec(`
+        UPDATE submissions SET status = $1, returned_at = NOW(), updated_at = NOW() WHERE id = $2
+    `, SubmissionReturned, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to return submission: %w", err)
+	}
+	return s.GetSubmission(classID, assignmentID, submissionID)
+}
+
+// AddComment menambah komentar guru, opsional menunjuk file dan lokasi di dalamnya.
+func (s *SubmissionService) AddComment(classID, assignmentID, submissionID, authorID int, req dto.SubmissionCommentRequest) (*model.SubmissionComment, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	if _, err := getTeacherSubmission(s.DB, assignmentID, submissionID); err != nil {
+		return nil, err
+	}
+	if req.FileID != nil {
+		var exists bool
+		err := s.DB.QueryRow(`
+            SELECT EXISTS (SELECT 1 FROM submission_files WHERE id = $1 AND submission_id = $2)
+        `, *req.FileID, submissionID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check submission file: %w", err)
+		}
+		if !exists {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+
+	var commentID int
+	err := s.DB.QueryRow(`
+        INSERT INTO submission_comments (submission_id, file_id, author_id, page, anchor, body)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+    `, submissionID, req.FileID, authorID, req.Page, strings.TrimSpace(req.Anchor), strings.TrimSpace(req.Body)).Scan(&commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	comments, err := loadSubmissionComments(s.DB, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		if comments[i].ID == commentID {
+			return &comments[i], nil
+		}
+	}
+	return nil, ErrSubmissionCommentNotFound
+}
+
+func (s *SubmissionService) DeleteComment(classID, assignmentID, submissionID, commentID int) error {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return err
+	}
+
+	result, err := s.DB.Exec(`
+        DELETE FROM submission_comments c USING submissions s
+        WHERE c.id = $1 AND c.submission_id = $2 AND s.id = c.submission_id AND s.assignment_id = $3
+    `, commentID, submissionID, assignmentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSubmissionCommentNotFound
+	}
+	return nil
+}
+
+// saveSubmissionGrade menyimpan poin dan feedback, mengubah status (graded atau returned)
+// dan memperbarui buku nilai.
+func saveSubmissionGrade(q dbExecutor, submission *model.Submission, graderID int, points float64, feedback string, returnToStudent bool) error {
+	status := SubmissionGraded
+	if returnToStudent {
+		status = SubmissionReturned
+	}
+	_, err := q.Exec(`
+        UPDATE submissions
+        SET points = $1, feedback = $2, graded_by = $3, graded_at = NOW(), status = $4, updated_at = NOW(),
+            returned_at = CASE WHEN $4 = 'returned' THEN NOW() ELSE returned_at END
+        WHERE id = $5
+    `, points, strings.TrimSpace(feedback), graderID, status, submission.ID)
+	if err != nil {
+		return fmt.Errorf("failed to grade submission: %w", err)
+	}
+	return syncAssignmentGrades(q, submission.AssignmentID)
+}
+
+// syncAssignmentGrades menulis nilai semua pengumpulan yang sudah dinilai ke tabel grades
+// (skala 0-100, setelah potongan keterlambatan), satu baris per siswa per tugas.
+func syncAssignmentGrades(q dbExecutor, assignmentID int) error {
+	_, err := q.Exec(`
+        INSERT INTO grades (user_id, class_id, grade, assignment_id)
+        SELECT s.user_id, a.class_id, ROUND(s.points * (100 - s.late_penalty_percent) / a.max_points)::INT, a.id
+        FROM submissions s
+        JOIN assignments a ON a.id = s.assignment_id
+        WHERE a.id = $1 AND s.points IS NOT NULL
+        ON CONFLICT (user_id, assignment_id) WHERE assignment_id IS NOT NULL
+        DO UPDATE SET grade = EXCLUDED.grade
+    `, assignmentID)
+	if err != nil {
+		return fmt.Errorf("failed to update gradebook: %w", err)
+	}
+	return nil
+}
+
+func loadSubmissionComments(q dbExecutor, submissionID int) ([]model.SubmissionComment, error) {
+	rows, err := q.Query(`
+        SELECT c.id, c.file_id, c.author_id, COALESCE(u.username, ''), c.page, c.anchor, c.body, c.created_at
+        FROM submission_comments c
+        LEFT JOIN users u ON u.id = c.author_id
+        WHERE c.submission_id = $1
+        ORDER BY c.file_id NULLS FIRST, c.page NULLS FIRST, c.id
+    `, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submission comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []model.SubmissionComment{}
+	for rows.Next() {
+		var comment model.SubmissionComment
+		if err := rows.Scan(&comment.ID, &comment.FileID, &comment.AuthorID, &comment.Author, &comment.Page,
+			&comment.Anchor, &comment.Body, &comment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan submission comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submission comments: %w", err)
+	}
+	return comments, nil
+}
+
+func validateMaxPoints(maxPoints float64) error {
+	if maxPoints <= 0 || maxPoints > 10000 {
+		violations := &ValidationError{}
+		violations.Add("max_points", "out_of_range", "Max points must be greater than 0 and at most 10000")
+		return violations
+	}
+	return nil
+}
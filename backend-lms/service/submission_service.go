@@ -0,0 +1,542 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Status pengumpulan tugas. SubmissionNotSubmitted hanya dipakai di daftar guru.
+const (
+	SubmissionDraft        = "draft"
+	SubmissionSubmitted    = "submitted"
+	SubmissionLate         = "late"
+	SubmissionReturned     = "returned"
+	SubmissionGraded       = "graded"
+	SubmissionNotSubmitted = "not_submitted"
+)
+
+const maxSubmissionFiles = 10
+
+var (
+	ErrAssignmentNotFound   = errors.New("assignment not found in this class")
+	ErrSubmissionNotFound   = errors.New("submission not found")
+	ErrSubmissionLocked     = errors.New("submission has already been turned in, unsubmit it first")
+	ErrSubmissionNotStudent = errors.New("only students of this class can submit")
+	ErrSubmissionEmpty      = errors.New("submission has no answer or files")
+	ErrSubmissionPastDue    = errors.New("the due date has passed and this assignment does not accept late submissions")
+)
+
+type SubmissionService struct {
+	DB *sql.DB
+}
+
+func NewSubmissionService(db *sql.DB) *SubmissionService {
+	return &SubmissionService{DB: db}
+}
+
+// GetMySubmission mengembalikan pengumpulan siswa beserta riwayatnya. Nilai, feedback dan
+// skor rubrik baru terlihat setelah guru mengembalikan pengumpulan (status returned).
+func (s *SubmissionService) GetMySubmission(classID, assignmentID, userID int) (*model.Submission, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	submission, err := scanSubmission(s.DB.QueryRow(submissionSelect+` WHERE s.assignment_id = $1 AND s.user_id = $2`, assignmentID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission: %w", err)
+	}
+	if err := loadSubmissionDetails(s.DB, submission); err != nil {
+		return nil, err
+	}
+	if submission.Status == SubmissionGraded {
+		hideUnreturnedGrade(submission)
+	}
+	return submission, nil
+}
+
+// hideUnreturnedGrade menghapus nilai yang belum dikembalikan guru dari tampilan siswa.
+func hideUnreturnedGrade(submission *model.Submission) {
+	submission.Points = nil
+	submission.FinalPoints = nil
+	submission.Feedback = ""
+	if submission.Rubric != nil {
+		submission.Rubric.TotalPoints = nil
+		for i := range submission.Rubric.Criteria {
+			submission.Rubric.Criteria[i].LevelID = nil
+			submission.Rubric.Criteria[i].Points = nil
+			submission.Rubric.Criteria[i].Comment = ""
+		}
+	}
+}
+
+// SaveDraft menyimpan jawaban teks dan menambah file. Pengumpulan yang sudah dikumpulkan
+// harus ditarik dulu; pengumpulan yang dikembalikan guru kembali menjadi draft.
+func (s *SubmissionService) SaveDraft(classID, assignmentID, userID int, req dto.SaveSubmissionRequest) (*model.Submission, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveDraftTx(tx, classID, assignmentID, userID, req, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save submission: %w", err)
+	}
+	return s.GetMySubmission(classID, assignmentID, userID)
+}
+
+// saveDraftTx menyimpan draft di dalam transaksi. replaceFiles mengganti semua file lama
+// dengan req.Files, bukan menambahkannya.
+func saveDraftTx(tx *sql.Tx, classID, assignmentID, userID int, req dto.SaveSubmissionRequest, replaceFiles bool) error {
+	if _, err := getAssignmentDeadline(tx, classID, assignmentID); err != nil {
+		return err
+	}
+	if err := checkClassStudent(tx, classID, userID); err != nil {
+		return err
+	}
+
+	var submissionID int
+	var status string
+	err := tx.QueryRow(`
+        INSERT INTO submissions (assignment_id, user_id) VALUES ($1, $2)
+        ON CONFLICT (assignment_id, user_id) DO UPDATE SET updated_at = submissions.updated_at
+        RETURNING id, status
+    `, assignmentID, userID).Scan(&submissionID, &status)
+	if err != nil {
+		return fmt.Errorf("failed to save submission: %w", err)
+	}
+	if status != SubmissionDraft && status != SubmissionReturned {
+		return ErrSubmissionLocked
+	}
+
+	var fileCount int
+	if replaceFiles {
+		if _, err := tx.Exec(`DELETE FROM submission_files WHERE submission_id = $1`, submissionID); err != nil {
+			return fmt.Errorf("failed to replace submission files: %w", err)
+		}
+	} else if err := tx.QueryRow(`SELECT COUNT(*) FROM submission_files WHERE submission_id = $1`, submissionID).Scan(&fileCount); err != nil {
+		return fmt.Errorf("failed to count files: %w", err)
+	}
+	if fileCount+len(req.Files) > maxSubmissionFiles {
+		violations := &ValidationError{}
+		violations.Add("files", "too_many", fmt.Sprintf("A submission can have at most %d files", maxSubmissionFiles))
+		return violations
+	}
+
+	_, err = tx.Exec(`
+        UPDATE submissions SET status = $1, text_answer = COALESCE($2, text_answer), updated_at = NOW()
+        WHERE id = $3
+    `, SubmissionDraft, req.TextAnswer, submissionID)
+	if err != nil {
+		return fmt.Errorf("failed to save submission: %w", err)
+	}
+	for _, file := range req.Files {
+		_, err := tx.Exec(`INSERT INTO submission_files (submission_id, url, file_name) VALUES ($1, $2, $3)`, submissionID, file.URL, file.FileName)
+		if err != nil {
+			return fmt.Errorf("failed to save submission file: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteFile menghapus file dari draft.
+func (s *SubmissionService) DeleteFile(classID, assignmentID, userID, fileID int) error {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return err
+	}
+
+	var status string
+	err := s.DB.QueryRow(`
+        SELECT s.status FROM submission_files f JOIN submissions s ON s.id = f.submission_id
+        WHERE f.id = $1 AND s.assignment_id = $2 AND s.user_id = $3
+    `, fileID, assignmentID, userID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get submission file: %w", err)
+	}
+	if status != SubmissionDraft && status != SubmissionReturned {
+		return ErrSubmissionLocked
+	}
+
+	if _, err := s.DB.Exec(`DELETE FROM submission_files WHERE id = $1`, fileID); err != nil {
+		return fmt.Errorf("failed to delete submission file: %w", err)
+	}
+	return nil
+}
+
+// Submit mengumpulkan draft. Setelah due date status menjadi late (dengan potongan sesuai
+// kebijakan tugas) atau ditolak jika kebijakannya reject. Salinan jawaban disimpan ke riwayat.
+func (s *SubmissionService) Submit(classID, assignmentID, userID int) (*model.Submission, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := submitTx(tx, classID, assignmentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to submit: %w", err)
+	}
+	return s.GetMySubmission(classID, assignmentID, userID)
+}
+
+// submitTx mengumpulkan draft di dalam transaksi (dipakai Submit dan SubmitByTitle).
+func submitTx(tx *sql.Tx, classID, assignmentID, userID int) error {
+	deadline, err := getAssignmentDeadline(tx, classID, assignmentID)
+	if err != nil {
+		return err
+	}
+	if err := checkClassStudent(tx, classID, userID); err != nil {
+		return err
+	}
+
+	submission, err := scanSubmission(tx.QueryRow(submissionSelect+`
+        WHERE s.assignment_id = $1 AND s.user_id = $2 FOR UPDATE OF s
+    `, assignmentID, userID))
+	if err == sql.ErrNoRows {
+		return ErrSubmissionEmpty
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get submission: %w", err)
+	}
+	if submission.Status != SubmissionDraft && submission.Status != SubmissionReturned {
+		return ErrSubmissionLocked
+	}
+	if err := loadSubmissionDetails(tx, submission); err != nil {
+		return err
+	}
+	if strings.TrimSpace(submission.TextAnswer) == "" && len(submission.Files) == 0 {
+		return ErrSubmissionEmpty
+	}
+
+	now := time.Now()
+	status := SubmissionSubmitted
+	lateDays, penalty := lateness(deadline, now)
+	if lateDays > 0 {
+		if deadline.LatePolicy == LatePolicyReject {
+			return ErrSubmissionPastDue
+		}
+		status = SubmissionLate
+	}
+	attempt := submission.Attempt + 1
+	files, err := json.Marshal(submission.Files)
+	if err != nil {
+		return fmt.Errorf("failed to encode submission files: %w", err)
+	}
+
+	_, err = tx.Exec(`
+        UPDATE submissions SET status = $1, attempt = $2, submitted_at = $3, updated_at = $3,
+            late_days = $4, late_penalty_percent = $5
+        WHERE id = $6
+    `, status, attempt, now, lateDays, penalty, submission.ID)
+	if err != nil {
+		return fmt.Errorf("failed to submit: %w", err)
+	}
+	_, err = tx.Exec(`
+        INSERT INTO submission_history (submission_id, attempt, status, text_answer, files, submitted_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, submission.ID, attempt, status, submission.TextAnswer, string(files), now)
+	if err != nil {
+		return fmt.Errorf("failed to save submission history: %w", err)
+	}
+	return nil
+}
+
+// SubmitByTitle dipakai client lama yang mengumpulkan dengan membuat "tugas" baru lewat
+// POST /assignment/{class_id}. Judul dicocokkan dengan tugas kelas (seperti migrasi 016), lalu
+// jawaban disimpan dan langsung dikumpulkan. Pengumpulan yang belum dinilai ditarik dulu, dan
+// file lama diganti dengan file dari request.
+func (s *SubmissionService) SubmitByTitle(classID, userID int, title string, req dto.SaveSubmissionRequest) (*model.Submission, error) {
+	rows, err := s.DB.Query(`
+        SELECT id FROM assignments WHERE class_id = $1 AND lower(trim(title)) = lower(trim($2))
+    `, classID, title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find assignment: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment: %w", err)
+		}
+		matches = append(matches, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignments: %w", err)
+	}
+	if len(matches) != 1 {
+		violations := &ValidationError{}
+		if len(matches) == 0 {
+			violations.Add("title", "no_match", "Title does not match any assignment in this class")
+		} else {
+			violations.Add("title", "ambiguous", "Title matches more than one assignment, submit via /class/{id}/assignments/{assignment_id}/submission")
+		}
+		return nil, violations
+	}
+	assignmentID := matches[0]
+
+	// Tarik, simpan dan kumpulkan dalam satu transaksi: jika pengumpulan ulang ditolak (misalnya
+	// lewat due date dengan kebijakan reject), pengumpulan sebelumnya tetap utuh
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        UPDATE submissions SET status = $1, updated_at = NOW()
+        WHERE assignment_id = $2 AND user_id = $3 AND status IN ($4, $5)
+    `, SubmissionDraft, assignmentID, userID, SubmissionSubmitted, SubmissionLate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unsubmit: %w", err)
+	}
+	if err := saveDraftTx(tx, classID, assignmentID, userID, req, true); err != nil {
+		return nil, err
+	}
+	if err := submitTx(tx, classID, assignmentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to submit: %w", err)
+	}
+	return s.GetMySubmission(classID, assignmentID, userID)
+}
+
+// Unsubmit menarik pengumpulan yang belum dinilai supaya bisa diubah lagi.
+func (s *SubmissionService) Unsubmit(classID, assignmentID, userID int) (*model.Submission, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.DB.Exec(`
+        UPDATE submissions SET status = $1, updated_at = NOW()
+        WHERE assignment_id = $2 AND user_id = $3 AND status IN ($4, $5)
+    `, SubmissionDraft, assignmentID, userID, SubmissionSubmitted, SubmissionLate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unsubmit: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return s.GetMySubmission(classID, assignmentID, userID)
+}
+
+// GetSubmissions mengembalikan semua siswa kelas dengan status pengumpulannya, termasuk
+// yang belum mengumpulkan. status (opsional) memfilter daftar.
+func (s *SubmissionService) GetSubmissions(classID, assignmentID int, status string) (*dto.AssignmentSubmissions, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT u.id, u.username, u.full_name, s.id,
+               CASE WHEN s.id IS NULL OR s.status = 'draft' THEN 'not_submitted' ELSE s.status END,
+               COALESCE(s.attempt, 0),
+               (SELECT COUNT(*) FROM submission_files f WHERE f.submission_id = s.id),
+               s.submitted_at, COALESCE(s.late_days, 0), s.points
+        FROM class_members cm
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN submissions s ON s.assignment_id = $2 AND s.user_id = cm.user_id
+        WHERE cm.class_id = $1 AND cm.role = 'siswa'
+        ORDER BY s.submitted_at NULLS LAST, u.username
+    `, classID, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submissions: %w", err)
+	}
+	defer rows.Close()
+
+	response := &dto.AssignmentSubmissions{
+		AssignmentID: assignmentID,
+		Counts:       map[string]int{},
+		Students:     []dto.SubmissionListEntry{},
+	}
+	for _, key := range []string{SubmissionNotSubmitted, SubmissionSubmitted, SubmissionLate, SubmissionReturned, SubmissionGraded} {
+		response.Counts[key] = 0
+	}
+	for rows.Next() {
+		var entry dto.SubmissionListEntry
+		if err := rows.Scan(&entry.UserID, &entry.Username, &entry.FullName, &entry.SubmissionID, &entry.Status,
+			&entry.Attempt, &entry.FileCount, &entry.SubmittedAt, &entry.LateDays, &entry.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan submission: %w", err)
+		}
+		response.Counts[entry.Status]++
+		if status == "" || status == entry.Status {
+			response.Students = append(response.Students, entry)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submissions: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetSubmission mengembalikan detail satu pengumpulan untuk guru. Draft tidak bisa dilihat guru.
+func (s *SubmissionService) GetSubmission(classID, assignmentID, submissionID int) (*model.Submission, error) {
+	if _, err := getAssignmentDeadline(s.DB, classID, assignmentID); err != nil {
+		return nil, err
+	}
+	submission, err := getTeacherSubmission(s.DB, assignmentID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	return submission, loadSubmissionDetails(s.DB, submission)
+}
+
+func getTeacherSubmission(q dbExecutor, assignmentID, submissionID int) (*model.Submission, error) {
+	submission, err := scanSubmission(q.QueryRow(submissionSelect+`
+        WHERE s.id = $1 AND s.assignment_id = $2 AND s.status <> 'draft'
+    `, submissionID, assignmentID))
+	if err == sql.ErrNoRows {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission: %w", err)
+	}
+	return submission, nil
+}
+
+// getAssignmentDeadline memastikan tugas ada di kelas dan mengembalikan due date serta kebijakan keterlambatannya.
+func getAssignmentDeadline(q dbExecutor, classID, assignmentID int) (model.Deadline, error) {
+	var deadline model.Deadline
+	err := q.QueryRow(`
+        SELECT due_date, late_policy, late_penalty_percent FROM assignments WHERE id = $1 AND class_id = $2
+    `, assignmentID, classID).Scan(&deadline.DueDate, &deadline.LatePolicy, &deadline.LatePenaltyPercent)
+	if err == sql.ErrNoRows {
+		return deadline, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return deadline, fmt.Errorf("failed to get assignment: %w", err)
+	}
+	return deadline, nil
+}
+
+func checkClassStudent(q dbExecutor, classID, userID int) error {
+	var role string
+	err := q.QueryRow(`SELECT role FROM class_members WHERE class_id = $1 AND user_id = $2`, classID, userID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	if role != ClassRoleStudent {
+		return ErrSubmissionNotStudent
+	}
+	return nil
+}
+
+// loadSubmissionDetails melengkapi file, komentar, rubrik dan riwayat pengumpulan.
+func loadSubmissionDetails(q dbExecutor, submission *model.Submission) error {
+	files, err := loadSubmissionFiles(q, []int{submission.ID})
+	if err != nil {
+		return err
+	}
+	submission.Files = attachmentsOrEmpty(files[submission.ID])
+	if submission.Comments, err = loadSubmissionComments(q, submission.ID); err != nil {
+		return err
+	}
+	if err := loadSubmissionRubric(q, submission); err != nil {
+		return err
+	}
+
+	rows, err := q.Query(`
+        SELECT attempt, status, text_answer, files, submitted_at FROM submission_history
+        WHERE submission_id = $1 ORDER BY attempt DESC
+    `, submission.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query submission history: %w", err)
+	}
+	defer rows.Close()
+
+	submission.History = []model.SubmissionVersion{}
+	for rows.Next() {
+		var version model.SubmissionVersion
+		var files []byte
+		if err := rows.Scan(&version.Attempt, &version.Status, &version.TextAnswer, &files, &version.SubmittedAt); err != nil {
+			return fmt.Errorf("failed to scan submission history: %w", err)
+		}
+		if err := json.Unmarshal(files, &version.Files); err != nil {
+			return fmt.Errorf("failed to decode submission history files: %w", err)
+		}
+		version.Files = attachmentsOrEmpty(version.Files)
+		submission.History = append(submission.History, version)
+	}
+	return rows.Err()
+}
+
+func loadSubmissionFiles(q dbExecutor, submissionIDs []int) (map[int][]model.Attachment, error) {
+	rows, err := q.Query(`
+        SELECT submission_id, id, url, file_name FROM submission_files
+        WHERE submission_id = ANY($1) ORDER BY id
+    `, pq.Array(submissionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submission files: %w", err)
+	}
+	defer rows.Close()
+
+	files := map[int][]model.Attachment{}
+	for rows.Next() {
+		var submissionID int
+		var file model.Attachment
+		if err := rows.Scan(&submissionID, &file.ID, &file.URL, &file.FileName); err != nil {
+			return nil, fmt.Errorf("failed to scan submission file: %w", err)
+		}
+		files[submissionID] = append(files[submissionID], file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submission files: %w", err)
+	}
+	return files, nil
+}
+
+const submissionSelect = `
+    SELECT s.id, s.assignment_id, s.user_id, u.username, s.status, s.text_answer, s.attempt,
+           s.submitted_at, s.returned_at, s.graded_at, s.created_at, s.updated_at,
+           s.late_days, s.late_penalty_percent, a.due_date, a.late_policy, a.late_penalty_percent,
+           s.points, a.max_points, s.feedback
+    FROM submissions s
+    JOIN users u ON u.id = s.user_id
+    JOIN assignments a ON a.id = s.assignment_id
+`
+
+func scanSubmission(row rowScanner) (*model.Submission, error) {
+	var submission model.Submission
+	err := row.Scan(&submission.ID, &submission.AssignmentID, &submission.UserID, &submission.Username, &submission.Status,
+		&submission.TextAnswer, &submission.Attempt, &submission.SubmittedAt, &submission.ReturnedAt, &submission.GradedAt,
+		&submission.CreatedAt, &submission.UpdatedAt, &submission.LateDays, &submission.PenaltyPercent,
+		&submission.DueDate, &submission.LatePolicy, &submission.LatePenaltyPercent,
+		&submission.Points, &submission.MaxPoints, &submission.Feedback)
+	if err != nil {
+		return nil, err
+	}
+	if submission.Points != nil {
+		final := *submission.Points * (100 - submission.PenaltyPercent) / 100
+		submission.FinalPoints = &final
+	}
+	fillRemaining(&submission.Deadline, time.Now())
+	return &submission, nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"project/config"
+	"project/dto"
+	"project/middleware"
+	"project/model"
+	"project/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	accessTokenLength   = 40
+	accessTokenAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrAccessTokenInvalid  = errors.New("access token is invalid, expired or revoked")
+)
+
+// TokenService mengelola personal access token milik user.
+type TokenService struct {
+	DB *sql.DB
+}
+
+func NewTokenService(db *sql.DB) *TokenService {
+	return &TokenService{DB: db}
+}
+
+// CreateToken membuat token baru. Token plain text hanya dikembalikan sekali di sini,
+// database hanya menyimpan hash SHA-256.
+func (s *TokenService) CreateToken(userID int, req dto.CreateAccessTokenRequest) (*dto.CreateAccessTokenResponse, error) {
+	violations := &ValidationError{}
+	if strings.TrimSpace(req.Name) == "" {
+		violations.Add("name", "required", "Token name is required")
+	}
+	if len(req.Scopes) == 0 {
+		violations.Add("scopes", "required", "At least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !middleware.IsKnownScope(scope) {
+			violations.Add("scopes", "unknown_scope", fmt.Sprintf("Unknown scope %q", scope))
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = config.AccessTokenDefaultDays
+	}
+	if days < 1 || days > config.AccessTokenMaxDays {
+		violations.Add("expires_in_days", "out_of_range", fmt.Sprintf("Expiry must be between 1 and %d days", config.AccessTokenMaxDays))
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	plain, err := generateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, user_id, name, token_prefix, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+    `
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	token, err := scanAccessToken(s.DB.QueryRow(query,
+		userID, strings.TrimSpace(req.Name), hashAccessToken(plain), plain[:len(middleware.AccessTokenPrefix)+6],
+		pq.Array(req.Scopes), expiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	return &dto.CreateAccessTokenResponse{Token: plain, AccessToken: token}, nil
+}
+
+func (s *TokenService) GetTokens(userID int) ([]model.PersonalAccessToken, error) {
+	query := `
+        SELECT id, user_id, name, token_prefix, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+        FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC
+    `
+	rows, err := s.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []model.PersonalAccessToken
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (s *TokenService) RevokeToken(userID, tokenID int) error {
+	result, err := s.DB.Exec(
+		`UPDATE personal_access_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		tokenID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate dipasang sebagai middleware.AccessTokenAuthenticator. Role diambil dari
+// tabel users sehingga perubahan role langsung berlaku untuk token yang sudah ada.
+func (s *TokenService) Authenticate(token string, r *http.Request) (*middleware.AccessTokenIdentity, error) {
+	query := `
+        SELECT t.id, t.user_id, u.username, u.role, t.scopes
+        FROM personal_access_tokens t
+        JOIN users u ON u.id = t.user_id
+        WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND t.expires_at > NOW()
+    `
+	var identity middleware.AccessTokenIdentity
+	err := s.DB.QueryRow(query, hashAccessToken(token)).Scan(
+		&identity.TokenID, &identity.UserID, &identity.Username, &identity.Role, pq.Array(&identity.Scopes),
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrAccessTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate access token: %w", err)
+	}
+
+	// last_used_at cukup diperbarui paling sering sekali per menit
+	_, err = s.DB.Exec(`
+        UPDATE personal_access_tokens SET last_used_at = NOW(), last_used_ip = $2
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+    `, identity.TokenID, utils.ClientIP(r))
+	if err != nil {
+		log.Printf("Failed to update access token usage: %v", err)
+	}
+
+	return &identity, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccessToken(row rowScanner) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, pq.Array(&token.Scopes),
+		&token.ExpiresAt, &token.LastUsedAt, &token.LastUsedIP, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func generateAccessToken() (string, error) {
+	var builder strings.Builder
+	builder.WriteString(middleware.AccessTokenPrefix)
+
+	max := big.NewInt(int64(len(accessTokenAlphabet)))
+	for i := 0; i < accessTokenLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate access token: %w", err)
+		}
+		builder.WriteByte(accessTokenAlphabet[n.Int64()])
+	}
+	return builder.String(), nil
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
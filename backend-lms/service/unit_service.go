@@ -0,0 +1,424 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"sort"
+	"strings"
+)
+
+// Tipe item di outline kelas.
+const (
+	OutlineItemMaterial   = "material"
+	OutlineItemAssignment = "assignment"
+)
+
+// outlineItemTables memetakan tipe item ke tabelnya. Nama tabel hanya diambil dari map ini.
+var outlineItemTables = map[string]string{
+	OutlineItemMaterial:   "materials",
+	OutlineItemAssignment: "assignments",
+}
+
+var (
+	ErrUnitNotFound        = errors.New("unit not found")
+	ErrOutlineItemNotFound = errors.New("material or assignment not found in this class")
+)
+
+type UnitService struct {
+	DB *sql.DB
+}
+
+func NewUnitService(db *sql.DB) *UnitService {
+	return &UnitService{DB: db}
+}
+
+func (s *UnitService) GetUnits(classID int) ([]model.ClassUnit, error) {
+	rows, err := s.DB.Query(unitSelect+` WHERE class_id = $1 ORDER BY position, id`, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query units: %w", err)
+	}
+	defer rows.Close()
+
+	units := []model.ClassUnit{}
+	for rows.Next() {
+		unit, err := scanUnit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unit: %w", err)
+		}
+		units = append(units, *unit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating units: %w", err)
+	}
+
+	return units, nil
+}
+
+// CreateUnit menambahkan unit baru di urutan terakhir.
+func (s *UnitService) CreateUnit(classID int, req dto.UnitRequest) (*model.ClassUnit, error) {
+	unit, err := scanUnit(s.DB.QueryRow(`
+        INSERT INTO class_units (class_id, title, description, position)
+        VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM class_units WHERE class_id = $1))
+        RETURNING id, class_id, title, description, position, created_at
+    `, classID, strings.TrimSpace(req.Title), req.Description))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unit: %w", err)
+	}
+	return unit, nil
+}
+
+func (s *UnitService) UpdateUnit(classID, unitID int, req dto.UnitRequest) (*model.ClassUnit, error) {
+	unit, err := scanUnit(s.DB.QueryRow(`
+        UPDATE class_units SET title = $1, description = $2
+        WHERE id = $3 AND class_id = $4
+        RETURNING id, class_id, title, description, position, created_at
+    `, strings.TrimSpace(req.Title), req.Description, unitID, classID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnitNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update unit: %w", err)
+	}
+	return unit, nil
+}
+
+// DeleteUnit menghapus unit. Materi dan tugas di dalamnya tidak ikut terhapus, melainkan
+// dipindahkan ke akhir daftar item tanpa unit dengan urutan yang sama.
+func (s *UnitService) DeleteUnit(classID, unitID int) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkUnitInClass(tx, classID, unitID); err != nil {
+		return err
+	}
+
+	base, err := nextItemPosition(tx, classID, nil)
+	if err != nil {
+		return err
+	}
+	for _, table := range outlineItemTables {
+		query := fmt.Sprintf(`UPDATE %s SET unit_id = NULL, position = position + $1 WHERE class_id = $2 AND unit_id = $3`, table)
+		if _, err := tx.Exec(query, base, classID, unitID); err != nil {
+			return fmt.Errorf("failed to move unit items: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM class_units WHERE id = $1 AND class_id = $2`, unitID, classID); err != nil {
+		return fmt.Errorf("failed to delete unit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete unit: %w", err)
+	}
+	return nil
+}
+
+// ReorderUnits menyimpan urutan unit baru. unitIDs harus berisi semua unit kelas tepat sekali.
+func (s *UnitService) ReorderUnits(classID int, unitIDs []int) ([]model.ClassUnit, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM class_units WHERE class_id = $1 FOR UPDATE`, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query units: %w", err)
+	}
+	existing := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan unit: %w", err)
+		}
+		existing[id] = false
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating units: %w", err)
+	}
+
+	violations := &ValidationError{}
+	for _, id := range unitIDs {
+		seen, ok := existing[id]
+		if !ok {
+			violations.Add("unit_ids", "unknown_unit", fmt.Sprintf("Unit %d does not belong to this class", id))
+			continue
+		}
+		if seen {
+			violations.Add("unit_ids", "duplicate", fmt.Sprintf("Unit %d is listed more than once", id))
+		}
+		existing[id] = true
+	}
+	if len(violations.Errors) == 0 && len(unitIDs) != len(existing) {
+		violations.Add("unit_ids", "incomplete", "All units of the class must be listed")
+	}
+	if err := violations.OrNil(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range unitIDs {
+		if _, err := tx.Exec(`UPDATE class_units SET position = $1 WHERE id = $2`, i+1, id); err != nil {
+			return nil, fmt.Errorf("failed to reorder units: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to reorder units: %w", err)
+	}
+	return s.GetUnits(classID)
+}
+
+// ReorderUnitItems menjadikan items sebagai isi unit dengan urutan tersebut (drag and drop).
+// Item lama unit yang tidak disebut dipindahkan ke akhir daftar tanpa unit.
+func (s *UnitService) ReorderUnitItems(classID, unitID int, items []dto.OutlineItemRef) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkUnitInClass(tx, classID, unitID); err != nil {
+		return err
+	}
+
+	listed := map[dto.OutlineItemRef]bool{}
+	violations := &ValidationError{}
+	for _, item := range items {
+		if listed[item] {
+			violations.Add("items", "duplicate", fmt.Sprintf("%s %d is listed more than once", item.Type, item.ID))
+		}
+		listed[item] = true
+	}
+	if err := violations.OrNil(); err != nil {
+		return err
+	}
+	for item := range listed {
+		if err := checkOutlineItemInClass(tx, classID, item); err != nil {
+			return err
+		}
+	}
+
+	outline, err := loadOutlineItems(tx, classID)
+	if err != nil {
+		return err
+	}
+	base, err := nextItemPosition(tx, classID, nil)
+	if err != nil {
+		return err
+	}
+	for _, current := range outline[unitID] {
+		ref := dto.OutlineItemRef{Type: current.Type, ID: current.ID}
+		if listed[ref] {
+			continue
+		}
+		if err := setItemPlacement(tx, ref, nil, base); err != nil {
+			return err
+		}
+		base++
+	}
+
+	for i, item := range items {
+		if err := setItemPlacement(tx, item, &unitID, i+1); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to reorder unit items: %w", err)
+	}
+	return nil
+}
+
+// MoveItem memindahkan satu materi/tugas ke unit lain (atau keluar dari unit) pada posisi
+// tertentu; item lain di tujuan bergeser.
+func (s *UnitService) MoveItem(classID int, req dto.MoveOutlineItemRequest) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkOutlineItemInClass(tx, classID, req.OutlineItemRef); err != nil {
+		return err
+	}
+	key := 0
+	if req.UnitID != nil {
+		if err := checkUnitInClass(tx, classID, *req.UnitID); err != nil {
+			return err
+		}
+		key = *req.UnitID
+	}
+
+	outline, err := loadOutlineItems(tx, classID)
+	if err != nil {
+		return err
+	}
+	siblings := make([]dto.OutlineItemRef, 0, len(outline[key])+1)
+	for _, item := range outline[key] {
+		ref := dto.OutlineItemRef{Type: item.Type, ID: item.ID}
+		if ref != req.OutlineItemRef {
+			siblings = append(siblings, ref)
+		}
+	}
+
+	index := req.Position - 1
+	if index < 0 || index > len(siblings) {
+		index = len(siblings)
+	}
+	siblings = append(siblings[:index], append([]dto.OutlineItemRef{req.OutlineItemRef}, siblings[index:]...)...)
+
+	for i, item := range siblings {
+		if err := setItemPlacement(tx, item, req.UnitID, i+1); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to move item: %w", err)
+	}
+	return nil
+}
+
+// GetOutline mengembalikan seluruh struktur kelas dalam satu response.
+func (s *UnitService) GetOutline(classID int) (*dto.ClassOutline, error) {
+	units, err := s.GetUnits(classID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadOutlineItems(s.DB, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	outline := &dto.ClassOutline{ClassID: classID, Units: []dto.OutlineUnit{}, Unassigned: items[0]}
+	for _, unit := range units {
+		unitItems := items[unit.ID]
+		if unitItems == nil {
+			unitItems = []dto.OutlineItem{}
+		}
+		outline.Units = append(outline.Units, dto.OutlineUnit{ClassUnit: unit, Items: unitItems})
+	}
+	if outline.Unassigned == nil {
+		outline.Unassigned = []dto.OutlineItem{}
+	}
+	return outline, nil
+}
+
+// loadOutlineItems mengelompokkan materi dan tugas kelas per unit (key 0 = tanpa unit),
+// masing-masing sudah terurut berdasarkan posisi.
+func loadOutlineItems(q dbExecutor, classID int) (map[int][]dto.OutlineItem, error) {
+	grouped := map[int][]dto.OutlineItem{}
+	queries := []struct {
+		itemType string
+		query    string
+	}{
+		{OutlineItemMaterial, `SELECT id, title, position, unit_id, attachment, NULL::TIMESTAMPTZ, created_at FROM materials WHERE class_id = $1`},
+		{OutlineItemAssignment, `SELECT id, title, position, unit_id, attachment, due_date, created_at FROM assignments WHERE class_id = $1`},
+	}
+	for _, source := range queries {
+		rows, err := q.Query(source.query, classID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %ss: %w", source.itemType, err)
+		}
+		for rows.Next() {
+			item := dto.OutlineItem{Type: source.itemType}
+			var unitID sql.NullInt64
+			var attachment sql.NullString
+			if err := rows.Scan(&item.ID, &item.Title, &item.Position, &unitID, &attachment, &item.DueDate, &item.CreatedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s: %w", source.itemType, err)
+			}
+			item.Attachment = attachment.String
+			key := 0
+			if unitID.Valid {
+				key = int(unitID.Int64)
+			}
+			grouped[key] = append(grouped[key], item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating %ss: %w", source.itemType, err)
+		}
+	}
+
+	for _, items := range grouped {
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Position != items[j].Position {
+				return items[i].Position < items[j].Position
+			}
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		})
+	}
+	return grouped, nil
+}
+
+// nextItemPosition mengembalikan posisi setelah item terakhir di unit (nil = tanpa unit).
+func nextItemPosition(q dbExecutor, classID int, unitID *int) (int, error) {
+	var next int
+	err := q.QueryRow(`
+        SELECT COALESCE(MAX(position), 0) + 1 FROM (
+            SELECT position FROM materials WHERE class_id = $1 AND unit_id IS NOT DISTINCT FROM $2::INT
+            UNION ALL
+            SELECT position FROM assignments WHERE class_id = $1 AND unit_id IS NOT DISTINCT FROM $2::INT
+        ) items
+    `, classID, unitID).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next position: %w", err)
+	}
+	return next, nil
+}
+
+func checkUnitInClass(q dbExecutor, classID, unitID int) error {
+	var exists bool
+	err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM class_units WHERE id = $1 AND class_id = $2)`, unitID, classID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check unit: %w", err)
+	}
+	if !exists {
+		return ErrUnitNotFound
+	}
+	return nil
+}
+
+func checkOutlineItemInClass(q dbExecutor, classID int, item dto.OutlineItemRef) error {
+	table, ok := outlineItemTables[item.Type]
+	if !ok {
+		return ErrOutlineItemNotFound
+	}
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND class_id = $2)`, table)
+	if err := q.QueryRow(query, item.ID, classID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check %s: %w", item.Type, err)
+	}
+	if !exists {
+		return ErrOutlineItemNotFound
+	}
+	return nil
+}
+
+func setItemPlacement(q dbExecutor, item dto.OutlineItemRef, unitID *int, position int) error {
+	query := fmt.Sprintf(`UPDATE %s SET unit_id = $1, position = $2 WHERE id = $3`, outlineItemTables[item.Type])
+	if _, err := q.Exec(query, unitID, position, item.ID); err != nil {
+		return fmt.Errorf("failed to place %s: %w", item.Type, err)
+	}
+	return nil
+}
+
+const unitSelect = `SELECT id, class_id, title, description, position, created_at FROM class_units`
+
+func scanUnit(row rowScanner) (*model.ClassUnit, error) {
+	var unit model.ClassUnit
+	if err := row.Scan(&unit.ID, &unit.ClassID, &unit.Title, &unit.Description, &unit.Position, &unit.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"project/dto"
+	"project/model"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var ErrIdentifierTaken = errors.New("identifier is already used by another user")
+
+// UpdateProfile mengubah nama lengkap dan nomor induk user.
+func (s *UserService) UpdateProfile(userID int, req dto.UpdateProfileRequest) (*model.User, error) {
+	var user model.User
+	err := s.DB.QueryRow(`
+        UPDATE users SET full_name = $1, identifier = $2 WHERE id = $3
+        RETURNING id, username, role, created_at, full_name, identifier
+    `, strings.TrimSpace(req.FullName), strings.TrimSpace(req.Identifier), userID).Scan(
+		&user.ID, &user.Username, &user.Role, &user.CreatedAt, &user.FullName, &user.Identifier)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_identifier_key" {
+		return nil, ErrIdentifierTaken
+	}
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+	return &user, nil
+}
@@ -4,38 +4,77 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"project/config"
+	"project/dto"
+	"project/jwtauth"
+	"project/model"
+	"project/utils"
+	"strings"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
-
-	"github.com/dgrijalva/jwt-go"
 )
 
 type AuthService struct {
-    DB *sql.DB
+    DB       *sql.DB
+    MFA      *MFAService
+    Security *SecurityService
+    Hashers  *utils.PasswordHashers
+    Policy   *PasswordPolicy
+}
+
+func NewAuthService(db *sql.DB, mfa *MFAService, security *SecurityService) *AuthService {
+    return &AuthService{
+        DB:       db,
+        MFA:      mfa,
+        Security: security,
+        Hashers:  NewPasswordHashers(),
+        Policy:   NewPasswordPolicy(),
+    }
+}
+
+// NewPasswordHashers membuat hasher sesuai config. Algoritma yang tidak dipilih
+// tetap didaftarkan sebagai legacy supaya hash lama masih bisa diverifikasi.
+func NewPasswordHashers() *utils.PasswordHashers {
+    argon := utils.NewArgon2idHasher(uint32(config.Argon2Time), uint32(config.Argon2MemoryKiB), uint8(config.Argon2Threads))
+    bcryptHasher := &utils.BcryptHasher{Cost: config.BcryptCost}
+
+    if strings.EqualFold(config.PasswordHashAlgorithm, "bcrypt") {
+        return &utils.PasswordHashers{Default: bcryptHasher, Legacy: []utils.PasswordHasher{argon}}
+    }
+    return &utils.PasswordHashers{Default: argon, Legacy: []utils.PasswordHasher{bcryptHasher}}
 }
 
 type UserService struct {
     DB *sql.DB
 }
 
-type Claims struct {
-    Username string `json:"username"`
-    Role     string `json:"role"`
-    UserID   int    `json:"id"`
-    jwt.StandardClaims
-}
+const mfaChallengePurpose = "mfa_challenge"
 
-var jwtKey = []byte("your_secret_key")
+var (
+    ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor challenge")
+    ErrUserNotFound        = errors.New("user not found")
+    ErrInvalidCredentials  = errors.New("invalid credentials")
+)
 
 func (s *AuthService) Register(username, password, role string) error {
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+    username = strings.TrimSpace(username)
+    if username == "" {
+        violations := &ValidationError{}
+        violations.Add("username", "required", "Username is required")
+        return violations
+    }
+
+    if err := s.Policy.Check(username, password); err != nil {
+        return err
+    }
+
+    hashedPassword, err := s.Hashers.Hash(password)
     if err != nil {
         return err
     }
 
     query := `INSERT INTO users (username, password, role) VALUES ($1, $2, $3)`
-    _, err = s.DB.Exec(query, username, string(hashedPassword), role)
+    _, err = s.DB.Exec(query, username, hashedPassword, role)
     if err != nil {
         return err
     }
@@ -43,7 +82,9 @@ func (s *AuthService) Register(username, password, role string) error {
     return nil
 }
 
-func (s *AuthService) Login(username, password string) (string, error) {
+func (s *AuthService) Login(username, password string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+    // Dinormalisasi sama seperti Register
+    username = strings.TrimSpace(username)
     var id int
     var  hashedPassword, role string // Declare two variables to hold password and role
     query := `SELECT id, password, role FROM users WHERE username = $1`
@@ -52,34 +93,287 @@ func (s *AuthService) Login(username, password string) (string, error) {
     err := s.DB.QueryRow(query, username).Scan(&id, &hashedPassword, &role)
     if err != nil {
         if err == sql.ErrNoRows {
-            return "", errors.New("user not found")
+            s.Security.RecordLoginAttempt(0, username, LoginMethodPassword, false, "unknown_user", client)
+            return nil, ErrUserNotFound
         }
-        return "", err
+        return nil, err
     }
 
     // Compare hashed password with the input password
-    if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
-        return "", errors.New("invalid credentials")
+    ok, needsRehash, err := s.Hashers.Verify(hashedPassword, password)
+    if err != nil || !ok {
+        s.Security.RecordLoginAttempt(id, username, LoginMethodPassword, false, "invalid_password", client)
+        return nil, ErrInvalidCredentials
     }
 
+    // Hash lama (bcrypt / parameter argon2 lama) diganti dengan hash baru secara transparan
+    if needsRehash {
+        s.rehashPassword(id, password)
+    }
+
+    return s.completeLogin(id, username, role, LoginMethodPassword, client)
+}
+
+// completeLogin dipakai setelah langkah pertama login (password atau OIDC) berhasil.
+// User dengan 2FA aktif harus menyelesaikan langkah kedua lewat /login/mfa; percobaan login
+// baru dicatat setelah langkah kedua selesai. User yang wajib 2FA tetapi belum enroll hanya
+// mendapat token yang bisa dipakai untuk enroll.
+func (s *AuthService) completeLogin(id int, username, role, method string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+    mfaEnabled, err := s.MFA.IsEnabled(id)
+    if err != nil {
+        return nil, err
+    }
+    if mfaEnabled {
+        return s.issueMFAChallenge(id, username, role)
+    }
+
+    mfaRequired, err := s.MFA.IsRequiredForRole(role)
+    if err != nil {
+        return nil, err
+    }
+    purpose := ""
+    if mfaRequired {
+        purpose = jwtauth.MFASetupPurpose
+    }
+
+    tokenString, err := s.issueToken(id, username, role, method, purpose, client)
+    if err != nil {
+        return nil, err
+    }
+
+    return &dto.LoginResponse{Token: tokenString, MFASetupRequired: mfaRequired}, nil
+}
+
+// CompleteMFALogin menukar MFA challenge token + kode TOTP/recovery code dengan JWT biasa.
+func (s *AuthService) CompleteMFALogin(challengeToken, code string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+    claims, err := jwtauth.ParseToken(challengeToken, jwtauth.MFAAudience())
+    if err != nil || claims.Purpose != mfaChallengePurpose {
+        return nil, ErrInvalidMFAChallenge
+    }
+
+    if err := s.MFA.VerifyChallenge(claims.UserID, code); err != nil {
+        s.Security.RecordLoginAttempt(claims.UserID, claims.Username, LoginMethodMFA, false, "invalid_mfa_code", client)
+        return nil, err
+    }
+
+    // Ambil ulang username dan role supaya perubahan selama challenge ikut terbawa
+    var username, role string
+    err = s.DB.QueryRow(`SELECT username, role FROM users WHERE id = $1`, claims.UserID).Scan(&username, &role)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, errors.New("user not found")
+        }
+        return nil, err
+    }
+
+    tokenString, err := s.issueToken(claims.UserID, username, role, LoginMethodMFA, "", client)
+    if err != nil {
+        return nil, err
+    }
+    return &dto.LoginResponse{Token: tokenString}, nil
+}
+
+// issueToken membuat sesi baru, mencatat login berhasil, lalu menerbitkan JWT dengan jti = id sesi.
+// purpose diteruskan ke JWT (lihat jwtauth.GenerateSessionToken).
+func (s *AuthService) issueToken(id int, username, role, method, purpose string, client dto.ClientInfo) (string, error) {
+    sessionID, err := randomURLSafe(24)
+    if err != nil {
+        return "", err
+    }
+
+    expiresAt := time.Now().Add(jwtauth.SessionTTL())
+    if err := s.Security.CreateSession(sessionID, id, method, expiresAt, client); err != nil {
+        return "", err
+    }
+    s.Security.RecordLoginAttempt(id, username, method, true, "", client)
+
     // Generate JWT token with role included
-    expirationTime := time.Now().Add(1 * time.Hour)
-    claims := &Claims{
+    return jwtauth.GenerateSessionToken(sessionID, id, username, role, purpose)
+}
+
+// issueMFAChallenge membuat token berumur pendek yang hanya bisa dipakai di /login/mfa.
+// Audience-nya berbeda dari token API sehingga AuthMiddleware menolaknya.
+func (s *AuthService) issueMFAChallenge(id int, username, role string) (*dto.LoginResponse, error) {
+    ttl := time.Duration(config.MFAChallengeTTLSeconds) * time.Second
+    claims := &jwtauth.Claims{
         Username: username,
         Role:     role,
-        UserID:  id,
-        StandardClaims: jwt.StandardClaims{
-            ExpiresAt: expirationTime.Unix(),
-        },
+        UserID:   id,
+        Purpose:  mfaChallengePurpose,
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString(jwtKey)
+    tokenString, err := jwtauth.IssueToken(claims, jwtauth.MFAAudience(), ttl)
     if err != nil {
-        return "", err
+        return nil, err
+    }
+
+    return &dto.LoginResponse{
+        MFARequired: true,
+        MFAToken:    tokenString,
+        ExpiresIn:   int(ttl.Seconds()),
+    }, nil
+}
+
+func (s *AuthService) rehashPassword(userID int, password string) {
+    newHash, err := s.Hashers.Hash(password)
+    if err != nil {
+        log.Printf("Failed to rehash password for user %d: %v", userID, err)
+        return
+    }
+
+    if _, err := s.DB.Exec(`UPDATE users SET password = $1 WHERE id = $2`, newHash, userID); err != nil {
+        log.Printf("Failed to store rehashed password for user %d: %v", userID, err)
+    }
+}
+
+// ChangePassword mengganti password user sendiri. Semua sesi lain dan personal access token
+// dicabut, sesi saat ini tetap aktif.
+func (s *AuthService) ChangePassword(userID int, sessionID string, req dto.ChangePasswordRequest, client dto.ClientInfo) error {
+    var username, hashedPassword string
+    err := s.DB.QueryRow(`SELECT username, password FROM users WHERE id = $1`, userID).Scan(&username, &hashedPassword)
+    if err == sql.ErrNoRows {
+        return ErrUserNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+
+    ok, _, err := s.Hashers.Verify(hashedPassword, req.CurrentPassword)
+    if err != nil || !ok {
+        return ErrInvalidCredentials
+    }
+
+    if err := s.setPassword(userID, username, req.NewPassword); err != nil {
+        return err
+    }
+
+    revoked, err := s.Security.RevokeAllSessions(userID, sessionID)
+    if err != nil {
+        return err
+    }
+    revokedTokens, err := s.Security.RevokeAllAccessTokens(userID)
+    if err != nil {
+        return err
+    }
+    s.Security.RecordEvent(EventPasswordChanged, "info", userID, userID, client.IP, map[string]interface{}{
+        "revoked_sessions": revoked,
+        "revoked_tokens":   revokedTokens,
+    })
+    return nil
+}
+
+// ResetPassword dipakai admin untuk mengganti password user lain. Semua sesi dan personal
+// access token user dicabut.
+func (s *AuthService) ResetPassword(actorID, userID int, newPassword string, client dto.ClientInfo) error {
+    var username string
+    err := s.DB.QueryRow(`SELECT username FROM users WHERE id = $1`, userID).Scan(&username)
+    if err == sql.ErrNoRows {
+        return ErrUserNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+
+    if err := s.setPassword(userID, username, newPassword); err != nil {
+        return err
+    }
+
+    revoked, err := s.Security.RevokeAllSessions(userID, "")
+    if err != nil {
+        return err
+    }
+    revokedTokens, err := s.Security.RevokeAllAccessTokens(userID)
+    if err != nil {
+        return err
+    }
+    s.Security.RecordEvent(EventPasswordReset, "warning", userID, actorID, client.IP, map[string]interface{}{
+        "username":         username,
+        "revoked_sessions": revoked,
+        "revoked_tokens":   revokedTokens,
+    })
+    return nil
+}
+
+// UpdateRole mengganti role user. Role tersimpan di JWT, jadi sesi dan personal access token
+// user dicabut supaya role baru langsung berlaku.
+func (s *AuthService) UpdateRole(actorID, userID int, role string, client dto.ClientInfo) error {
+    var username, oldRole string
+    err := s.DB.QueryRow(`SELECT username, role FROM users WHERE id = $1`, userID).Scan(&username, &oldRole)
+    if err == sql.ErrNoRows {
+        return ErrUserNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+    if oldRole == role {
+        return nil
+    }
+
+    if _, err := s.DB.Exec(`UPDATE users SET role = $1 WHERE id = $2`, role, userID); err != nil {
+        return fmt.Errorf("failed to update role: %w", err)
+    }
+
+    revoked, err := s.Security.RevokeAllSessions(userID, "")
+    if err != nil {
+        return err
+    }
+    revokedTokens, err := s.Security.RevokeAllAccessTokens(userID)
+    if err != nil {
+        return err
+    }
+    s.Security.RecordEvent(EventRoleChanged, "warning", userID, actorID, client.IP, map[string]interface{}{
+        "username":         username,
+        "old_role":         oldRole,
+        "new_role":         role,
+        "revoked_sessions": revoked,
+        "revoked_tokens":   revokedTokens,
+    })
+    return nil
+}
+
+func (s *AuthService) setPassword(userID int, username, password string) error {
+    if err := s.Policy.Check(username, password); err != nil {
+        return err
+    }
+
+    hashedPassword, err := s.Hashers.Hash(password)
+    if err != nil {
+        return err
+    }
+
+    if _, err := s.DB.Exec(`UPDATE users SET password = $1 WHERE id = $2`, hashedPassword, userID); err != nil {
+        return fmt.Errorf("failed to update password: %w", err)
+    }
+    return nil
+}
+
+// GetUsers mengembalikan daftar user untuk halaman manajemen user admin.
+func (s *UserService) GetUsers(role string) ([]model.User, error) {
+    query := `
+        SELECT id, username, role, created_at, full_name, identifier FROM users
+        WHERE ($1 = '' OR role = $1)
+        ORDER BY username
+    `
+    rows, err := s.DB.Query(query, role)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query users: %w", err)
+    }
+    defer rows.Close()
+
+    var users []model.User
+    for rows.Next() {
+        var user model.User
+        if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.CreatedAt, &user.FullName, &user.Identifier); err != nil {
+            return nil, fmt.Errorf("failed to scan user: %w", err)
+        }
+        users = append(users, user)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating users: %w", err)
     }
 
-    return tokenString, nil
+    return users, nil
 }
 
 func (s *UserService) CountUsersByRole() (map[string]int, error) {
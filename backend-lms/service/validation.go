@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"project/dto"
+	"strings"
+)
+
+// ValidationError dikembalikan service ketika input tidak memenuhi aturan bisnis.
+// Handler mengubahnya menjadi response 422 yang terstruktur.
+type ValidationError struct {
+	Errors []dto.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Add(field, code, message string) {
+	e.Errors = append(e.Errors, dto.FieldError{Field: field, Code: code, Message: message})
+}
+
+// OrNil mengembalikan nil jika tidak ada pelanggaran, supaya bisa langsung di-return.
+func (e *ValidationError) OrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func AsValidationError(err error) (*ValidationError, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher membuat dan memverifikasi hash password untuk satu algoritma.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encoded, password string) (bool, error)
+	// Handles mengembalikan true jika encoded dibuat oleh algoritma ini.
+	Handles(encoded string) bool
+	// NeedsRehash mengembalikan true jika parameter encoded berbeda dari parameter hasher.
+	NeedsRehash(encoded string) bool
+}
+
+// Argon2idHasher menyimpan hash dalam format PHC: $argon2id$v=19$m=..,t=..,p=..$salt$hash
+type Argon2idHasher struct {
+	Time       uint32
+	MemoryKiB  uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+func NewArgon2idHasher(time, memoryKiB uint32, threads uint8) *Argon2idHasher {
+	return &Argon2idHasher{
+		Time:       time,
+		MemoryKiB:  memoryKiB,
+		Threads:    threads,
+		SaltLength: 16,
+		KeyLength:  32,
+	}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.MemoryKiB, h.Threads, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.MemoryKiB, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Time, params.MemoryKiB, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1, nil
+}
+
+func (h *Argon2idHasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Time != h.Time ||
+		params.MemoryKiB != h.MemoryKiB ||
+		params.Threads != h.Threads ||
+		uint32(len(salt)) != h.SaltLength ||
+		uint32(len(key)) != h.KeyLength
+}
+
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.MemoryKiB, &params.Time, &params.Threads); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher dipakai untuk hash lama yang dibuat sebelum argon2id menjadi default.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *BcryptHasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost != h.Cost
+}
+
+// PasswordHashers memilih hasher berdasarkan format hash yang tersimpan.
+// Hash baru selalu dibuat dengan Default.
+type PasswordHashers struct {
+	Default PasswordHasher
+	Legacy  []PasswordHasher
+}
+
+func (p *PasswordHashers) Hash(password string) (string, error) {
+	return p.Default.Hash(password)
+}
+
+// Verify mengecek password terhadap hash yang tersimpan. needsRehash bernilai true
+// jika password cocok tetapi hash dibuat dengan algoritma atau parameter lama.
+func (p *PasswordHashers) Verify(encoded, password string) (ok bool, needsRehash bool, err error) {
+	for _, hasher := range append([]PasswordHasher{p.Default}, p.Legacy...) {
+		if !hasher.Handles(encoded) {
+			continue
+		}
+
+		ok, err := hasher.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, hasher != p.Default || hasher.NeedsRehash(encoded), nil
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
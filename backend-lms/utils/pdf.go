@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Ukuran halaman A4 landscape dalam point (1/72 inch).
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 9.0
+	pdfRowHeight  = 16.0
+	pdfCellPad    = 4.0
+)
+
+// WriteTablePDF menulis tabel sebagai PDF siap cetak (A4 landscape, font Helvetica bawaan PDF).
+// Header tabel diulang di setiap halaman dan teks yang terlalu panjang dipotong.
+// Karakter di luar Latin-1 diganti '?' karena font standar PDF tidak memuatnya.
+func WriteTablePDF(w io.Writer, table Table) error {
+	widths := pdfColumnWidths(table)
+	tableTop := pdfPageHeight - pdfMargin - 24 - float64(len(table.Subtitle))*12 - 8
+	firstPageRows := int((tableTop-pdfMargin-20)/pdfRowHeight) - 1
+	pageHeight := pdfPageHeight - 2*pdfMargin - 20
+	otherPageRows := int(pageHeight/pdfRowHeight) - 1
+
+	var pages [][][]interface{}
+	rows := table.Rows
+	for first := true; first || len(rows) > 0; first = false {
+		limit := otherPageRows
+		if first {
+			limit = firstPageRows
+		}
+		if limit > len(rows) {
+			limit = len(rows)
+		}
+		pages = append(pages, rows[:limit])
+		rows = rows[limit:]
+	}
+
+	var contents [][]byte
+	for i, pageRows := range pages {
+		var page bytes.Buffer
+		top := pdfPageHeight - pdfMargin
+		if i == 0 {
+			pdfText(&page, "F2", 14, pdfMargin, top-14, table.Title)
+			for j, line := range table.Subtitle {
+				pdfText(&page, "F1", 10, pdfMargin, top-30-float64(j)*12, line)
+			}
+			top = tableTop
+		}
+
+		headers := make([]interface{}, len(table.Headers))
+		for j, header := range table.Headers {
+			headers[j] = header
+		}
+		page.WriteString("0.9 g\n")
+		fmt.Fprintf(&page, "%.2f %.2f %.2f %.2f re f\n", pdfMargin, top-pdfRowHeight, pdfPageWidth-2*pdfMargin, pdfRowHeight)
+		page.WriteString("0 g\n")
+		pdfRow(&page, "F2", top, widths, headers)
+		for j, row := range pageRows {
+			pdfRow(&page, "F1", top-float64(j+1)*pdfRowHeight, widths, row)
+		}
+
+		footer := fmt.Sprintf("Halaman %d/%d - dicetak %s", i+1, len(pages), time.Now().Format("02-01-2006 15:04"))
+		pdfText(&page, "F1", 8, pdfMargin, pdfMargin-12, footer)
+		contents = append(contents, page.Bytes())
+	}
+
+	return writePDFObjects(w, contents)
+}
+
+func pdfColumnWidths(table Table) []float64 {
+	available := pdfPageWidth - 2*pdfMargin
+	widths := make([]float64, len(table.Headers))
+	total := 0.0
+	for i := range widths {
+		widths[i] = 1
+		if i < len(table.Widths) && table.Widths[i] > 0 {
+			widths[i] = table.Widths[i]
+		}
+		total += widths[i]
+	}
+	for i := range widths {
+		widths[i] = widths[i] / total * available
+	}
+	return widths
+}
+
+// pdfRow menulis satu baris tabel dengan garis bawah; top adalah batas atas baris.
+func pdfRow(page *bytes.Buffer, font string, top float64, widths []float64, values []interface{}) {
+	x := pdfMargin
+	for i, width := range widths {
+		value := ""
+		if i < len(values) {
+			value = formatCell(values[i])
+		}
+		// Lebar rata-rata karakter Helvetica sekitar setengah ukuran font
+		maxChars := int((width - 2*pdfCellPad) / (pdfFontSize * 0.5))
+		if runes := []rune(value); maxChars > 2 && len(runes) > maxChars {
+			value = string(runes[:maxChars-2]) + ".."
+		}
+		pdfText(page, font, pdfFontSize, x+pdfCellPad, top-pdfRowHeight+5, value)
+		x += width
+	}
+	fmt.Fprintf(page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, top-pdfRowHeight, pdfPageWidth-pdfMargin, top-pdfRowHeight)
+}
+
+func pdfText(page *bytes.Buffer, font string, size, x, y float64, text string) {
+	fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (", font, size, x, y)
+	page.Write(pdfEscape(text))
+	page.WriteString(") Tj ET\n")
+}
+
+// pdfEscape mengubah teks ke Latin-1 dan meng-escape karakter khusus string PDF.
+func pdfEscape(text string) []byte {
+	var out []byte
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out = append(out, '\\', byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			out = append(out, ' ')
+		case r < 32 || r > 255:
+			out = append(out, '?')
+		default:
+			out = append(out, byte(r))
+		}
+	}
+	return out
+}
+
+// writePDFObjects menyusun dokumen: catalog, pages, dua font, lalu page + content stream per halaman.
+func writePDFObjects(w io.Writer, contents [][]byte) error {
+	var objects []string
+	kids := make([]string, len(contents))
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+	for i, content := range contents {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 6+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
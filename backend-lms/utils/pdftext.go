@@ -0,0 +1,668 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Pembaca teks PDF sederhana: cukup untuk PDF hasil Word/LibreOffice/Google Docs
+// (stream FlateDecode, object stream, font dengan CMap ToUnicode). PDF hasil scan
+// (gambar) tidak punya teks dan hasilnya kosong. Hanya content stream halaman, object
+// stream dan CMap ToUnicode yang didekompresi, dengan batas total per dokumen.
+
+// Batas total hasil dekompresi per dokumen; tiap stream sudah dibatasi maxDocumentPartSize,
+// tetapi banyak stream kecil yang dikompresi tinggi tetap bisa menghabiskan memori.
+const maxPDFInflatedSize = 50 << 20
+
+var (
+	pdfObjectPattern    = regexp.MustCompile(`(?s)(\d+)\s+\d+\s+obj\b(.*?)\bendobj`)
+	pdfRefPattern       = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	pdfFontDictPattern  = regexp.MustCompile(`(?s)/Font\s*<<(.*?)>>`)
+	pdfFontRefPattern   = regexp.MustCompile(`/Font\s+(\d+)\s+\d+\s+R\b`)
+	pdfFontEntryPattern = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	pdfToUnicodePattern = regexp.MustCompile(`/ToUnicode\s+(\d+)\s+\d+\s+R\b`)
+	pdfContentsPattern  = regexp.MustCompile(`(?s)/Contents\s*(\[.*?\]|\d+\s+\d+\s+R)`)
+	pdfPageTypePattern  = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfObjStmPattern    = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfIntKeyPattern    = regexp.MustCompile(`/(N|First)\s+(\d+)`)
+	pdfHexPattern       = regexp.MustCompile(`<([0-9A-Fa-f\s]*)>`)
+)
+
+type pdfObject struct {
+	dict string
+	// raw adalah isi stream apa adanya; stream hasil dekode diisi oleh pdfDocument.decode
+	raw     []byte
+	stream  []byte
+	decoded bool
+}
+
+// pdfDocument menyimpan objek PDF dan sisa jatah dekompresi dokumen.
+type pdfDocument struct {
+	objects map[int]*pdfObject
+	budget  int
+}
+
+type pdfFont struct {
+	codeLength int
+	toUnicode  map[string]string
+}
+
+// ExtractPDFText mengambil teks dari semua halaman PDF sesuai urutan halaman.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF")) {
+		return "", errors.New("invalid pdf file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", errors.New("encrypted pdf files are not supported")
+	}
+
+	doc := parsePDFObjects(data)
+	fonts := doc.loadFonts()
+
+	var buf strings.Builder
+	for _, content := range doc.pageContents() {
+		buf.WriteString(pdfContentText(content, fonts))
+		buf.WriteString("\n")
+	}
+	return buf.String(), nil
+}
+
+func parsePDFObjects(data []byte) *pdfDocument {
+	doc := &pdfDocument{objects: map[int]*pdfObject{}, budget: maxPDFInflatedSize}
+	objects := doc.objects
+	for _, m := range pdfObjectPattern.FindAllSubmatch(data, -1) {
+		num, _ := strconv.Atoi(string(m[1]))
+		objects[num] = newPDFObject(m[2])
+	}
+
+	// Object stream (PDF 1.5+) menyimpan dictionary font dan halaman dalam stream terkompresi
+	var objStreams []*pdfObject
+	for _, obj := range objects {
+		if obj.raw != nil && pdfObjStmPattern.MatchString(obj.dict) {
+			objStreams = append(objStreams, obj)
+		}
+	}
+	for _, obj := range objStreams {
+		if doc.decode(obj) == nil {
+			continue
+		}
+		var count, first int
+		for _, kv := range pdfIntKeyPattern.FindAllStringSubmatch(obj.dict, -1) {
+			n, _ := strconv.Atoi(kv[2])
+			if kv[1] == "N" {
+				count = n
+			} else {
+				first = n
+			}
+		}
+		if first <= 0 || first > len(obj.stream) {
+			continue
+		}
+		header := strings.Fields(string(obj.stream[:first]))
+		type entry struct{ num, offset int }
+		entries := make([]entry, 0, count)
+		for i := 0; i+1 < len(header) && len(entries) < count; i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			offset, err2 := strconv.Atoi(header[i+1])
+			if err1 != nil || err2 != nil {
+				break
+			}
+			entries = append(entries, entry{num, first + offset})
+		}
+		for i, e := range entries {
+			end := len(obj.stream)
+			if i+1 < len(entries) {
+				end = entries[i+1].offset
+			}
+			if e.offset > end || end > len(obj.stream) {
+				continue
+			}
+			if _, exists := objects[e.num]; !exists {
+				objects[e.num] = &pdfObject{dict: string(obj.stream[e.offset:end])}
+			}
+		}
+	}
+	return doc
+}
+
+func newPDFObject(body []byte) *pdfObject {
+	idx := bytes.Index(body, []byte("stream"))
+	if idx < 0 {
+		return &pdfObject{dict: string(body)}
+	}
+	dict := string(body[:idx])
+	raw := body[idx+len("stream"):]
+	raw = bytes.TrimPrefix(raw, []byte("\r"))
+	raw = bytes.TrimPrefix(raw, []byte("\n"))
+	if end := bytes.LastIndex(raw, []byte("endstream")); end >= 0 {
+		raw = raw[:end]
+	}
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return &pdfObject{dict: dict, raw: raw}
+}
+
+// decode mengembalikan isi stream objek, didekompresi sekali selama jatah dokumen masih ada.
+func (d *pdfDocument) decode(obj *pdfObject) []byte {
+	if obj.decoded {
+		return obj.stream
+	}
+	obj.decoded = true
+	switch {
+	case obj.raw == nil:
+	case strings.Contains(obj.dict, "/FlateDecode"):
+		limit := d.budget
+		if limit > maxDocumentPartSize {
+			limit = maxDocumentPartSize
+		}
+		if limit <= 0 {
+			return nil
+		}
+		if decoded, err := inflatePDFStream(obj.raw, limit); err == nil {
+			obj.stream = decoded
+			d.budget -= len(decoded)
+		}
+	case !strings.Contains(obj.dict, "/Filter"):
+		obj.stream = obj.raw
+	}
+	// Filter lain (gambar, LZW, dll.) tidak berisi teks yang bisa dibaca
+	return obj.stream
+}
+
+func inflatePDFStream(raw []byte, limit int) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	// Stream yang terpotong masih berguna sebagian, jadi error setelah sebagian terbaca diabaikan
+	decoded, err := io.ReadAll(io.LimitReader(reader, int64(limit)))
+	if len(decoded) == 0 && err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// loadFonts memetakan nama resource font (/F1, /TT0, ...) ke CMap ToUnicode-nya.
+func (d *pdfDocument) loadFonts() map[string]*pdfFont {
+	objects := d.objects
+	refs := map[string]int{}
+	collect := func(entries string) {
+		for _, m := range pdfFontEntryPattern.FindAllStringSubmatch(entries, -1) {
+			num, _ := strconv.Atoi(m[2])
+			refs[m[1]] = num
+		}
+	}
+	for _, obj := range objects {
+		for _, m := range pdfFontDictPattern.FindAllStringSubmatch(obj.dict, -1) {
+			collect(m[1])
+		}
+		for _, m := range pdfFontRefPattern.FindAllStringSubmatch(obj.dict, -1) {
+			num, _ := strconv.Atoi(m[1])
+			if fontDict, ok := objects[num]; ok {
+				collect(fontDict.dict)
+			}
+		}
+	}
+
+	fonts := map[string]*pdfFont{}
+	for name, num := range refs {
+		obj, ok := objects[num]
+		if !ok {
+			continue
+		}
+		font := &pdfFont{codeLength: 1}
+		if strings.Contains(obj.dict, "/Type0") {
+			font.codeLength = 2
+		}
+		if m := pdfToUnicodePattern.FindStringSubmatch(obj.dict); m != nil {
+			num, _ := strconv.Atoi(m[1])
+			if cmap, ok := objects[num]; ok {
+				if stream := d.decode(cmap); stream != nil {
+					font.toUnicode, font.codeLength = parseToUnicodeCMap(string(stream), font.codeLength)
+				}
+			}
+		}
+		fonts[name] = font
+	}
+	return fonts
+}
+
+func parseToUnicodeCMap(cmap string, codeLength int) (map[string]string, int) {
+	mapping := map[string]string{}
+	for _, section := range pdfSections(cmap, "beginbfchar", "endbfchar") {
+		hexes := pdfHexPattern.FindAllStringSubmatch(section, -1)
+		for i := 0; i+1 < len(hexes); i += 2 {
+			src := pdfHexBytes(hexes[i][1])
+			if len(src) > 0 {
+				codeLength = len(src)
+				mapping[string(src)] = utf16BEString(pdfHexBytes(hexes[i+1][1]))
+			}
+		}
+	}
+	for _, section := range pdfSections(cmap, "beginbfrange", "endbfrange") {
+		for _, line := range strings.Split(section, "\n") {
+			hexes := pdfHexPattern.FindAllStringSubmatch(line, -1)
+			if len(hexes) < 3 {
+				continue
+			}
+			lo, hi := pdfHexBytes(hexes[0][1]), pdfHexBytes(hexes[1][1])
+			if len(lo) == 0 || len(lo) != len(hi) || len(lo) > 4 {
+				continue
+			}
+			codeLength = len(lo)
+			start, end := bytesToInt(lo), bytesToInt(hi)
+			if end < start || end-start > 0xFFFF {
+				continue
+			}
+			isArray := strings.Contains(line, "[")
+			base := []rune(utf16BEString(pdfHexBytes(hexes[2][1])))
+			for code := start; code <= end; code++ {
+				offset := code - start
+				key := string(intToBytes(code, len(lo)))
+				if isArray {
+					if 2+offset < len(hexes) {
+						mapping[key] = utf16BEString(pdfHexBytes(hexes[2+offset][1]))
+					}
+					continue
+				}
+				if len(base) == 0 {
+					continue
+				}
+				value := append([]rune{}, base...)
+				value[len(value)-1] += rune(offset)
+				mapping[key] = string(value)
+			}
+		}
+	}
+	return mapping, codeLength
+}
+
+func pdfSections(s, begin, end string) []string {
+	var sections []string
+	for {
+		i := strings.Index(s, begin)
+		if i < 0 {
+			return sections
+		}
+		s = s[i+len(begin):]
+		j := strings.Index(s, end)
+		if j < 0 {
+			return sections
+		}
+		sections = append(sections, s[:j])
+		s = s[j+len(end):]
+	}
+}
+
+func pdfHexBytes(s string) []byte {
+	s = strings.Join(strings.Fields(s), "")
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+func utf16BEString(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func bytesToInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+func intToBytes(n, size int) []byte {
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
+
+// pageContents mengembalikan content stream setiap halaman; kalau struktur halaman tidak
+// terbaca, stream tanpa /Type dan /Subtype (bukan font, gambar atau metadata) yang berisi
+// operator teks dipakai sesuai nomor objek.
+func (d *pdfDocument) pageContents() [][]byte {
+	objects := d.objects
+	nums := make([]int, 0, len(objects))
+	for num := range objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	var contents [][]byte
+	for _, num := range nums {
+		obj := objects[num]
+		if !pdfPageTypePattern.MatchString(obj.dict) {
+			continue
+		}
+		m := pdfContentsPattern.FindStringSubmatch(obj.dict)
+		if m == nil {
+			continue
+		}
+		for _, ref := range pdfRefPattern.FindAllStringSubmatch(m[1], -1) {
+			n, _ := strconv.Atoi(ref[1])
+			if content, ok := objects[n]; ok {
+				if stream := d.decode(content); stream != nil {
+					contents = append(contents, stream)
+				}
+			}
+		}
+	}
+	if len(contents) > 0 {
+		return contents
+	}
+	for _, num := range nums {
+		obj := objects[num]
+		if obj.raw == nil || strings.Contains(obj.dict, "/Type") || strings.Contains(obj.dict, "/Subtype") || strings.Contains(obj.dict, "/Length1") {
+			continue
+		}
+		stream := d.decode(obj)
+		if bytes.Contains(stream, []byte("BT")) && !bytes.Contains(stream, []byte("begincmap")) {
+			contents = append(contents, stream)
+		}
+	}
+	return contents
+}
+
+// pdfContentText menjalankan operator teks (Tf, Tj, TJ, ', ", Td, TD, T*, Tm, ET) dari content stream.
+func pdfContentText(content []byte, fonts map[string]*pdfFont) string {
+	var buf strings.Builder
+	var operands []interface{}
+	var font *pdfFont
+	lastY := 0.0
+	newline := func() {
+		if buf.Len() > 0 && !strings.HasSuffix(buf.String(), "\n") {
+			buf.WriteString("\n")
+		}
+	}
+	number := func(v interface{}) float64 {
+		f, _ := v.(float64)
+		return f
+	}
+
+	lexer := &pdfLexer{data: content}
+	for {
+		token, ok := lexer.next()
+		if !ok {
+			break
+		}
+		op, isOp := token.(pdfOperator)
+		if !isOp {
+			operands = append(operands, token)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = fonts[string(name)]
+				}
+			}
+		case "Tj":
+			if len(operands) > 0 {
+				buf.WriteString(decodePDFString(operands[len(operands)-1], font))
+			}
+		case "'", "\"":
+			newline()
+			if len(operands) > 0 {
+				buf.WriteString(decodePDFString(operands[len(operands)-1], font))
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if items, ok := operands[len(operands)-1].([]interface{}); ok {
+					for _, item := range items {
+						if n, isNum := item.(float64); isNum {
+							// Geser besar ke kanan dipakai sebagai spasi antarkata
+							if n < -200 {
+								buf.WriteString(" ")
+							}
+							continue
+						}
+						buf.WriteString(decodePDFString(item, font))
+					}
+				}
+			}
+		case "Td", "TD":
+			// Geser horizontal sering dipakai per huruf, jadi hanya pindah baris yang dihitung
+			if len(operands) >= 2 && number(operands[len(operands)-1]) != 0 {
+				newline()
+			}
+		case "T*":
+			newline()
+		case "Tm":
+			if len(operands) >= 6 {
+				y := number(operands[len(operands)-1])
+				if y != lastY {
+					newline()
+				} else {
+					buf.WriteString(" ")
+				}
+				lastY = y
+			}
+		case "ET":
+			buf.WriteString(" ")
+		case "ID":
+			lexer.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return buf.String()
+}
+
+func decodePDFString(v interface{}, font *pdfFont) string {
+	s, ok := v.(pdfString)
+	if !ok {
+		return ""
+	}
+	if font == nil || font.toUnicode == nil {
+		if font != nil && font.codeLength == 2 {
+			// Font CID tanpa ToUnicode tidak bisa dipetakan ke karakter
+			return ""
+		}
+		return latin1(s)
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); {
+		size := font.codeLength
+		if i+size > len(s) {
+			size = len(s) - i
+		}
+		if text, found := font.toUnicode[string(s[i:i+size])]; found {
+			buf.WriteString(text)
+		}
+		i += size
+	}
+	return buf.String()
+}
+
+type (
+	pdfOperator string
+	pdfName     string
+	pdfString   []byte
+)
+
+// pdfLexer memecah content stream menjadi angka, nama, string, array dan operator.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) next() (interface{}, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString(), true
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return pdfOperator("<<"), true
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfOperator(">>"), true
+	case c == '<':
+		end := bytes.IndexByte(l.data[l.pos:], '>')
+		if end < 0 {
+			l.pos = len(l.data)
+			return nil, false
+		}
+		s := pdfString(pdfHexBytes(string(l.data[l.pos+1 : l.pos+end])))
+		l.pos += end + 1
+		return s, true
+	case c == '[':
+		l.pos++
+		var items []interface{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return items, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return items, true
+			}
+			item, ok := l.next()
+			if !ok {
+				return items, true
+			}
+			items = append(items, item)
+		}
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfOperator(string(c)), true
+	case c == '/':
+		l.pos++
+		return pdfName(l.word()), true
+	}
+	word := l.word()
+	if word == "" {
+		l.pos++
+		return pdfOperator(string(c)), true
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, true
+	}
+	return pdfOperator(word), true
+}
+
+func (l *pdfLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case ' ', '\t', '\r', '\n', '\f', 0:
+			l.pos++
+		case '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !strings.ContainsRune(" \t\r\n\f\x00()<>[]{}/%", rune(l.data[l.pos])) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // '('
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.peek(0) == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(n))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// skipInlineImage melompati data gambar inline (BI ... ID <data> EI).
+func (l *pdfLexer) skipInlineImage() {
+	for i := l.pos; i+2 < len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && (i == 0 || isPDFSpace(l.data[i-1])) && (i+2 == len(l.data) || isPDFSpace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t'
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"project/config"
+	"strings"
+)
+
+// trustedProxies diisi sekali dari config.TrustedProxies.
+var trustedProxies = ParseTrustedProxies(config.TrustedProxies)
+
+// ParseTrustedProxies membaca daftar IP/CIDR dipisah koma. Entri yang tidak valid dilewati.
+func ParseTrustedProxies(value string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy %q: %v", entry, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// ClientIP mengambil IP client. Header dari reverse proxy hanya dipercaya jika RemoteAddr
+// termasuk TRUSTED_PROXIES; selain itu header bisa diisi siapa saja, jadi RemoteAddr yang dipakai.
+func ClientIP(r *http.Request) string {
+	return clientIP(r, trustedProxies)
+}
+
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !isTrustedProxy(host, trusted) {
+		return host
+	}
+
+	// X-Forwarded-For dibaca dari kanan: alamat pertama yang bukan proxy tepercaya adalah client
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if i == 0 || !isTrustedProxy(hop, trusted) {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return host
+}
+
+func isTrustedProxy(value string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := ParseTrustedProxies("10.0.0.0/8, 127.0.0.1, ::1, bukan-ip")
+	if len(trusted) != 3 {
+		t.Fatalf("parsed %d trusted proxies, want 3", len(trusted))
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{name: "direct client ignores forwarded headers", remoteAddr: "203.0.113.7:5000", forwarded: "1.2.3.4", realIP: "5.6.7.8", want: "203.0.113.7"},
+		{name: "trusted proxy uses forwarded for", remoteAddr: "10.1.2.3:443", forwarded: "198.51.100.9", want: "198.51.100.9"},
+		{name: "spoofed hop before the real client is skipped", remoteAddr: "10.1.2.3:443", forwarded: "1.2.3.4, 198.51.100.9", want: "198.51.100.9"},
+		{name: "chain of trusted proxies", remoteAddr: "127.0.0.1:80", forwarded: "198.51.100.9, 10.0.0.5", want: "198.51.100.9"},
+		{name: "only trusted hops", remoteAddr: "10.1.2.3:443", forwarded: "10.0.0.4, 10.0.0.5", want: "10.0.0.4"},
+		{name: "malformed hop stops the walk", remoteAddr: "10.1.2.3:443", forwarded: "garbage", realIP: "198.51.100.10", want: "198.51.100.10"},
+		{name: "trusted proxy with real ip", remoteAddr: "[::1]:8080", realIP: "2001:db8::1", want: "2001:db8::1"},
+		{name: "trusted proxy without headers", remoteAddr: "10.1.2.3:443", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := clientIP(r, trusted); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
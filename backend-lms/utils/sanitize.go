@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// sanitizeAllowedTags adalah tag rich text yang dipertahankan beserta atribut yang boleh dibawa.
+var sanitizeAllowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+	"b": nil, "strong": nil, "i": nil, "em": nil, "u": nil, "s": nil, "sub": nil, "sup": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil,
+	"ul": nil, "ol": nil, "li": nil, "blockquote": nil, "code": nil, "pre": nil,
+	"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": nil, "td": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+}
+
+// sanitizeDroppedTags dibuang beserta seluruh isinya.
+var sanitizeDroppedTags = map[string]bool{"script": true, "style": true, "iframe": true, "object": true, "embed": true}
+
+// SanitizeHTML membersihkan rich text dari editor: hanya tag dan atribut di allowlist yang
+// dipertahankan, URL hanya boleh http/https/mailto, dan teks lain di-escape.
+func SanitizeHTML(input string) string {
+	var out strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(input))
+	dropDepth := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == xhtml.ErrorToken {
+			if tokenizer.Err() == io.EOF {
+				return strings.TrimSpace(out.String())
+			}
+			return html.EscapeString(input)
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if sanitizeDroppedTags[token.Data] {
+				if tokenType == xhtml.StartTagToken {
+					dropDepth++
+				}
+				continue
+			}
+			attrs, ok := sanitizeAllowedTags[token.Data]
+			if !ok || dropDepth > 0 {
+				continue
+			}
+			out.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if !containsString(attrs, attr.Key) {
+					continue
+				}
+				if (attr.Key == "href" || attr.Key == "src") && !safeURL(attr.Val) {
+					continue
+				}
+				out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if token.Data == "a" {
+				out.WriteString(` rel="noopener noreferrer nofollow" target="_blank"`)
+			}
+			out.WriteString(">")
+		case xhtml.EndTagToken:
+			if sanitizeDroppedTags[token.Data] {
+				if dropDepth > 0 {
+					dropDepth--
+				}
+				continue
+			}
+			if _, ok := sanitizeAllowedTags[token.Data]; ok && dropDepth == 0 && token.Data != "br" && token.Data != "hr" && token.Data != "img" {
+				out.WriteString("</" + token.Data + ">")
+			}
+		case xhtml.TextToken:
+			if dropDepth == 0 {
+				out.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+func safeURL(value string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mesin kemiripan teks offline: normalisasi, shingle kata, MinHash dan Jaccard.
+
+const (
+	// ShingleSize adalah jumlah kata per shingle
+	ShingleSize = 5
+	// MinHashSize adalah jumlah fungsi hash pada signature MinHash
+	MinHashSize = 128
+)
+
+// TextToken adalah satu kata yang sudah dinormalisasi beserta posisinya (byte) di teks asli.
+type TextToken struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// Fingerprint menyimpan hasil tokenisasi, shingle dan signature MinHash satu dokumen.
+type Fingerprint struct {
+	Text      string
+	Tokens    []TextToken
+	Shingles  []uint64 // hash shingle per posisi kata awal
+	Set       map[uint64]struct{}
+	Signature []uint64
+}
+
+// TextPassage adalah rentang teks yang sama di dua dokumen.
+type TextPassage struct {
+	Text        string
+	MatchedText string
+	Words       int
+}
+
+// NormalizeWord: huruf kecil, tanpa tanda diakritik terpisah, hanya huruf dan angka.
+func NormalizeWord(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// TokenizeText memecah teks menjadi kata; tanda baca dan spasi diabaikan.
+func TokenizeText(text string) []TextToken {
+	var tokens []TextToken
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if word := NormalizeWord(text[start:end]); word != "" {
+			tokens = append(tokens, TextToken{Word: word, Start: start, End: end})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		// Apostrof dan tanda hubung di dalam kata tidak memisahkan kata
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) ||
+			(start >= 0 && strings.ContainsRune("'’-‐‑", r))
+		if inWord {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return tokens
+}
+
+// NewFingerprint menghitung shingle dan signature MinHash sebuah teks.
+func NewFingerprint(text string) *Fingerprint {
+	fp := &Fingerprint{Text: text, Tokens: TokenizeText(text), Set: map[uint64]struct{}{}}
+	for i := 0; i+ShingleSize <= len(fp.Tokens); i++ {
+		h := fnv.New64a()
+		for _, token := range fp.Tokens[i : i+ShingleSize] {
+			h.Write([]byte(token.Word))
+			h.Write([]byte{0})
+		}
+		sum := h.Sum64()
+		fp.Shingles = append(fp.Shingles, sum)
+		fp.Set[sum] = struct{}{}
+	}
+
+	fp.Signature = make([]uint64, MinHashSize)
+	for i := range fp.Signature {
+		fp.Signature[i] = ^uint64(0)
+	}
+	for shingle := range fp.Set {
+		for i := range fp.Signature {
+			if v := mix64(shingle ^ minHashSeeds[i]); v < fp.Signature[i] {
+				fp.Signature[i] = v
+			}
+		}
+	}
+	return fp
+}
+
+// EstimateJaccard memperkirakan kemiripan Jaccard dari dua signature MinHash.
+func EstimateJaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] && a[i] != ^uint64(0) {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// Compare menghitung Jaccard persis dan containment (bagian dokumen a yang ada di b).
+func (a *Fingerprint) Compare(b *Fingerprint) (jaccard, containment float64) {
+	if len(a.Set) == 0 || len(b.Set) == 0 {
+		return 0, 0
+	}
+	shared := 0
+	for shingle := range a.Set {
+		if _, ok := b.Set[shingle]; ok {
+			shared++
+		}
+	}
+	union := len(a.Set) + len(b.Set) - shared
+	return float64(shared) / float64(union), float64(shared) / float64(len(a.Set))
+}
+
+// MatchedPassages mencari rentang kata berurutan yang sama di a dan b, dari yang terpanjang.
+func (a *Fingerprint) MatchedPassages(b *Fingerprint, limit, maxChars int) []TextPassage {
+	positions := map[uint64][]int{}
+	for j, shingle := range b.Shingles {
+		positions[shingle] = append(positions[shingle], j)
+	}
+
+	var passages []TextPassage
+	for i := 0; i < len(a.Shingles); {
+		bestJ, bestLen := -1, 0
+		for _, j := range positions[a.Shingles[i]] {
+			n := 0
+			for i+n < len(a.Shingles) && j+n < len(b.Shingles) && a.Shingles[i+n] == b.Shingles[j+n] {
+				n++
+			}
+			if n > bestLen {
+				bestJ, bestLen = j, n
+			}
+		}
+		if bestJ < 0 {
+			i++
+			continue
+		}
+		words := bestLen + ShingleSize - 1
+		passages = append(passages, TextPassage{
+			Text:        snippet(a.Text, a.Tokens[i].Start, a.Tokens[i+words-1].End, maxChars),
+			MatchedText: snippet(b.Text, b.Tokens[bestJ].Start, b.Tokens[bestJ+words-1].End, maxChars),
+			Words:       words,
+		})
+		i += words
+	}
+
+	sort.SliceStable(passages, func(i, j int) bool { return passages[i].Words > passages[j].Words })
+	if limit > 0 && len(passages) > limit {
+		passages = passages[:limit]
+	}
+	return passages
+}
+
+func snippet(text string, start, end, maxChars int) string {
+	s := strings.Join(strings.Fields(text[start:end]), " ")
+	if maxChars > 0 && utf8.RuneCountInString(s) > maxChars {
+		s = string([]rune(s)[:maxChars]) + "…"
+	}
+	return s
+}
+
+// minHashSeeds tetap (deterministik) supaya signature bisa dibandingkan antar proses.
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, MinHashSize)
+	state := uint64(0x5eed5eed5eed5eed)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}()
+
+// mix64 adalah finalizer splitmix64.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
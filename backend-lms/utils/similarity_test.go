@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "punctuation and case", text: "Halo, Dunia! Apa kabar?", want: []string{"halo", "dunia", "apa", "kabar"}},
+		{name: "hyphen and apostrophe inside words", text: "anak-anak Jum'at", want: []string{"anakanak", "jumat"}},
+		{name: "combining marks are dropped", text: "cafe\u0301 naïve", want: []string{"cafe", "naïve"}},
+		{name: "numbers", text: "tahun 2024 ke-3", want: []string{"tahun", "2024", "ke3"}},
+		{name: "empty", text: " \n\t ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, token := range TokenizeText(tt.text) {
+				got = append(got, token.Word)
+				if NormalizeWord(tt.text[token.Start:token.End]) != token.Word {
+					t.Errorf("token %q does not match its source %q", token.Word, tt.text[token.Start:token.End])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TokenizeText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFingerprint(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantShingles int
+		wantSet      int
+	}{
+		{name: "shorter than a shingle", text: "satu dua tiga empat", wantShingles: 0, wantSet: 0},
+		{name: "exactly one shingle", text: "satu dua tiga empat lima", wantShingles: 1, wantSet: 1},
+		{name: "sliding window", text: "satu dua tiga empat lima enam tujuh", wantShingles: 3, wantSet: 3},
+		{name: "repeated text shares shingles", text: "a b c d e a b c d e", wantShingles: 6, wantSet: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := NewFingerprint(tt.text)
+			if len(fp.Shingles) != tt.wantShingles || len(fp.Set) != tt.wantSet {
+				t.Errorf("got %d shingles (%d distinct), want %d (%d)", len(fp.Shingles), len(fp.Set), tt.wantShingles, tt.wantSet)
+			}
+			if len(fp.Signature) != MinHashSize {
+				t.Errorf("signature length = %d, want %d", len(fp.Signature), MinHashSize)
+			}
+		})
+	}
+
+	// Normalisasi membuat tanda baca dan huruf besar tidak berpengaruh, dan signature deterministik
+	a := NewFingerprint("Fotosintesis terjadi di daun, menghasilkan glukosa dan oksigen.")
+	b := NewFingerprint("fotosintesis TERJADI di daun menghasilkan glukosa dan oksigen")
+	if !reflect.DeepEqual(a.Shingles, b.Shingles) || !reflect.DeepEqual(a.Signature, b.Signature) {
+		t.Error("fingerprints of the same words differ")
+	}
+}
+
+func TestFingerprintCompare(t *testing.T) {
+	base := "proses fotosintesis pada tumbuhan hijau terjadi di kloroplas dengan bantuan cahaya matahari"
+	tests := []struct {
+		name            string
+		a, b            string
+		wantJaccard     float64
+		wantContainment float64
+	}{
+		{name: "identical", a: base, b: base, wantJaccard: 1, wantContainment: 1},
+		{name: "unrelated", a: base, b: "hukum newton menjelaskan hubungan gaya massa dan percepatan benda", wantJaccard: 0, wantContainment: 0},
+		// a (4 shingle) seluruhnya ada di b (8 shingle)
+		{name: "a is contained in b", a: "proses fotosintesis pada tumbuhan hijau terjadi di kloroplas", b: base,
+			wantJaccard: 0.5, wantContainment: 1},
+		{name: "too short to compare", a: "terlalu pendek", b: base, wantJaccard: 0, wantContainment: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := NewFingerprint(tt.a), NewFingerprint(tt.b)
+			jaccard, containment := a.Compare(b)
+			if math.Abs(jaccard-tt.wantJaccard) > 1e-9 || math.Abs(containment-tt.wantContainment) > 1e-9 {
+				t.Errorf("Compare = %.3f/%.3f, want %.3f/%.3f", jaccard, containment, tt.wantJaccard, tt.wantContainment)
+			}
+			estimate := EstimateJaccard(a.Signature, b.Signature)
+			if math.Abs(estimate-tt.wantJaccard) > 0.2 {
+				t.Errorf("EstimateJaccard = %.3f, too far from %.3f", estimate, tt.wantJaccard)
+			}
+		})
+	}
+}
+
+func TestMatchedPassages(t *testing.T) {
+	source := "Pendahuluan singkat. Air mendidih pada suhu seratus derajat celcius di permukaan laut. Penutup."
+	copied := "Menurut buku, air mendidih pada suhu seratus derajat Celcius di permukaan laut; itu faktanya."
+
+	tests := []struct {
+		name     string
+		a, b     string
+		limit    int
+		maxChars int
+		want     []TextPassage
+	}{
+		{
+			name: "shared passage keeps original text",
+			a:    source, b: copied,
+			want: []TextPassage{{
+				Text:        "Air mendidih pada suhu seratus derajat celcius di permukaan laut",
+				MatchedText: "air mendidih pada suhu seratus derajat Celcius di permukaan laut",
+				Words:       10,
+			}},
+		},
+		{
+			name: "snippet is truncated",
+			a:    source, b: copied, maxChars: 12,
+			want: []TextPassage{{Text: "Air mendidih…", MatchedText: "air mendidih…", Words: 10}},
+		},
+		{
+			name:  "longest passages first within limit",
+			a:     "satu dua tiga empat lima. x y. enam tujuh delapan sembilan sepuluh sebelas dua belas",
+			b:     "enam tujuh delapan sembilan sepuluh sebelas dua belas. z. satu dua tiga empat lima",
+			limit: 1,
+			want: []TextPassage{{Text: "enam tujuh delapan sembilan sepuluh sebelas dua belas",
+				MatchedText: "enam tujuh delapan sembilan sepuluh sebelas dua belas", Words: 8}},
+		},
+		{name: "nothing shared", a: source, b: "kalimat yang sama sekali berbeda isinya dari sumber", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewFingerprint(tt.a).MatchedPassages(NewFingerprint(tt.b), tt.limit, tt.maxChars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchedPassages = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
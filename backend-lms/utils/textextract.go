@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedDocument dikembalikan untuk file yang teksnya tidak bisa dibaca.
+var ErrUnsupportedDocument = errors.New("unsupported document type, only .txt, .docx and .pdf are read")
+
+// Batas ukuran isi file di dalam dokumen (docx) supaya zip bomb tidak menghabiskan memori
+const maxDocumentPartSize = 50 << 20
+
+// CanExtractText melaporkan apakah ExtractText mendukung jenis file ini.
+func CanExtractText(fileName string) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".txt", ".docx", ".pdf":
+		return true
+	}
+	return false
+}
+
+// ExtractText membaca teks biasa dari file .txt, .docx atau .pdf berdasarkan ekstensinya.
+// Hasilnya selalu UTF-8 valid tanpa karakter NUL supaya bisa disimpan di kolom TEXT.
+func ExtractText(fileName string, data []byte) (string, error) {
+	var text string
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".txt":
+		text = decodePlainText(data)
+	case ".docx":
+		text, err = extractDocxText(data)
+	case ".pdf":
+		text, err = ExtractPDFText(data)
+	default:
+		return "", ErrUnsupportedDocument
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(strings.ToValidUTF8(text, ""), "\x00", ""), nil
+}
+
+// decodePlainText mengenali BOM UTF-8/UTF-16; teks yang bukan UTF-8 dibaca sebagai Latin-1.
+func decodePlainText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		var order binary.ByteOrder = binary.LittleEndian
+		if data[0] == 0xFE {
+			order = binary.BigEndian
+		}
+		units := make([]uint16, 0, len(data)/2)
+		for i := 2; i+1 < len(data); i += 2 {
+			units = append(units, order.Uint16(data[i:]))
+		}
+		return string(utf16.Decode(units))
+	case utf8.Valid(data):
+		return string(data)
+	}
+	return latin1(data)
+}
+
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// extractDocxText membaca word/document.xml: teks di <w:t>, tab, baris baru dan paragraf.
+func extractDocxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %v", err)
+	}
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+		}
+	}
+	if document == nil {
+		return "", errors.New("invalid docx file: word/document.xml not found")
+	}
+	reader, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %v", err)
+	}
+	defer reader.Close()
+
+	var buf strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(reader, maxDocumentPartSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx file: %v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				buf.WriteString("\t")
+			case "br", "cr":
+				buf.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				buf.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				buf.Write(t)
+			}
+		}
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func deflate(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// buildPDF menyusun PDF minimal dari objek bernomor 1, 2, ... (stream diberikan apa adanya).
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func TestExtractPDFText(t *testing.T) {
+	toUnicode := []byte("begincmap\n2 beginbfchar\n<0001> <0048>\n<0002> <0069>\nendbfchar\nendcmap")
+	page, font := "<< /Type /Page /Contents 5 0 R >>", "<< /Type /Font /Subtype /TrueType >>"
+	header := fmt.Sprintf("6 0 7 %d ", len(page))
+	objStm := []byte(header + page + font)
+
+	tests := []struct {
+		name    string
+		pdf     []byte
+		want    []string
+		notWant []string
+		wantErr string
+	}{
+		{
+			name: "pages in order with flate content",
+			pdf: buildPDF(
+				"<< /Type /Page /Contents 3 0 R >>",
+				"<< /Type /Page /Contents [4 0 R] >>",
+				pdfStream("/Filter /FlateDecode", deflate(t, []byte("BT /F1 12 Tf 72 700 Td (Halaman satu) Tj ET"))),
+				pdfStream("", []byte("BT [(Halaman) -300 (dua)] TJ ET")),
+			),
+			want: []string{"Halaman satu", "Halaman dua"},
+		},
+		{
+			name: "type0 font with ToUnicode cmap",
+			pdf: buildPDF(
+				"<< /Type /Page /Resources << /Font << /F1 2 0 R >> >> /Contents 3 0 R >>",
+				"<< /Type /Font /Subtype /Type0 /ToUnicode 4 0 R >>",
+				pdfStream("", []byte("BT /F1 12 Tf <00010002> Tj ET")),
+				pdfStream("/Filter /FlateDecode", deflate(t, toUnicode)),
+			),
+			want: []string{"Hi"},
+		},
+		{
+			name: "page inside a compressed object stream",
+			pdf: buildPDF(
+				"<< /Type /Catalog >>",
+				pdfStream(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), deflate(t, objStm)),
+				"<< /Type /Pages >>",
+				pdfStream("", []byte("BT (Tidak dirujuk) Tj ET")),
+				pdfStream("/Filter /FlateDecode", deflate(t, []byte("BT (Dari object stream) Tj ET"))),
+			),
+			want:    []string{"Dari object stream"},
+			notWant: []string{"Tidak dirujuk"},
+		},
+		{
+			name: "image streams are not inflated",
+			pdf: buildPDF(
+				"<< /Type /Page /Contents 2 0 R >>",
+				pdfStream("/Filter /FlateDecode", deflate(t, []byte("BT (Teks halaman) Tj ET"))),
+				pdfStream("/Type /XObject /Subtype /Image /Filter /FlateDecode", deflate(t, []byte("BT (Bukan teks) Tj ET"))),
+			),
+			want:    []string{"Teks halaman"},
+			notWant: []string{"Bukan teks"},
+		},
+		{name: "not a pdf", pdf: []byte("hello"), wantErr: "invalid pdf"},
+		{name: "encrypted", pdf: buildPDF("<< /Encrypt 2 0 R >>"), wantErr: "encrypted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := ExtractText("tugas.pdf", tt.pdf)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractText: %v", err)
+			}
+			last := -1
+			for _, want := range tt.want {
+				i := strings.Index(text, want)
+				if i < 0 || i < last {
+					t.Errorf("text %q does not contain %q in order", text, want)
+				}
+				last = i
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("text %q should not contain %q", text, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestPDFInflateBudget(t *testing.T) {
+	// Stream yang sangat mudah dikompresi: jatah dokumen habis setelah stream pertama
+	content := []byte("BT (Awal) Tj ET " + strings.Repeat(" ", maxPDFInflatedSize))
+	pdf := buildPDF(
+		"<< /Type /Page /Contents [3 0 R 4 0 R] >>",
+		"<< /Type /Pages >>",
+		pdfStream("/Filter /FlateDecode", deflate(t, content)),
+		pdfStream("/Filter /FlateDecode", deflate(t, []byte("BT (Sesudah jatah habis) Tj ET"))),
+	)
+
+	doc := parsePDFObjects(pdf)
+	contents := doc.pageContents()
+	if len(contents) != 1 {
+		t.Fatalf("got %d content streams, want only the first within the budget", len(contents))
+	}
+	if doc.budget != 0 {
+		t.Errorf("remaining budget = %d, want 0", doc.budget)
+	}
+	if !bytes.HasPrefix(contents[0], []byte("BT (Awal)")) || len(contents[0]) != maxPDFInflatedSize {
+		t.Errorf("first stream has %d bytes, want it cut at %d", len(contents[0]), maxPDFInflatedSize)
+	}
+}
+
+func buildDocx(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractDocxText(t *testing.T) {
+	const ns = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "paragraphs, runs, tabs and breaks",
+			files: map[string]string{"word/document.xml": `<w:document ` + ns + `><w:body>
+				<w:p><w:r><w:t>Judul</w:t></w:r><w:r><w:t xml:space="preserve"> tugas</w:t></w:r></w:p>
+				<w:p><w:r><w:t>Nama</w:t><w:tab/><w:t>Budi</w:t><w:br/><w:t>Kelas 7A</w:t></w:r></w:p>
+				<w:p><w:r><w:instrText>PAGE</w:instrText></w:r></w:p>
+			</w:body></w:document>`},
+			want: "Judul tugas\nNama\tBudi\nKelas 7A\n\n",
+		},
+		{
+			name:    "document part missing",
+			files:   map[string]string{"word/styles.xml": "<w:styles/>"},
+			wantErr: "word/document.xml not found",
+		},
+		{
+			name:    "broken xml",
+			files:   map[string]string{"word/document.xml": `<w:document ` + ns + `><w:p>`},
+			wantErr: "invalid docx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := ExtractText("Tugas.DOCX", buildDocx(t, tt.files))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractText: %v", err)
+			}
+			if text != tt.want {
+				t.Errorf("text = %q, want %q", text, tt.want)
+			}
+		})
+	}
+
+	if _, err := ExtractText("tugas.docx", []byte("bukan zip")); err == nil || !strings.Contains(err.Error(), "invalid docx") {
+		t.Errorf("non-zip docx error = %v", err)
+	}
+}
+
+func TestExtractPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "utf-8 with bom", data: []byte("\xEF\xBB\xBFhalo"), want: "halo"},
+		{name: "utf-16 little endian", data: []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, want: "hi"},
+		{name: "utf-16 big endian", data: []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, want: "hi"},
+		{name: "latin-1 fallback", data: []byte("caf\xE9"), want: "café"},
+		{name: "nul bytes removed", data: []byte("a\x00b"), want: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := ExtractText("catatan.txt", tt.data)
+			if err != nil || text != tt.want {
+				t.Errorf("ExtractText = %q, %v; want %q", text, err, tt.want)
+			}
+		})
+	}
+	if _, err := ExtractText("gambar.png", nil); err != ErrUnsupportedDocument {
+		t.Errorf("png error = %v, want %v", err, ErrUnsupportedDocument)
+	}
+}
@@ -39,7 +39,8 @@ export const createMaterial = (class_id, formData) => {
     },
   });
 };
-export const deleteMaterial = (id) => api.delete(`/material/${id}`);
+export const deleteMaterial = (class_id, id) =>
+  api.delete(`/${class_id}/material/${id}`);
 export const getMaterialById = (class_id, id) =>
   api.get(`/${class_id}/material/${id}`);
 export const updateMaterial = (class_id, id, data) =>
@@ -59,7 +60,8 @@ export const createAssignment = (class_id, formData) =>
       "Content-Type": "multipart/form-data",
     },
   });
-export const deleteAssignment = (id) => api.delete(`/assignment/${id}`);
+export const deleteAssignment = (class_id, id) =>
+  api.delete(`/${class_id}/assignment/${id}`);
 export const updateAssignment = (class_id, id, data) =>
   api.put(`/${class_id}/assignment/${id}`, data);
 export const handleFileUpload = (e, assignmentId) => {
@@ -331,14 +331,14 @@ const ClassPage = () => {
   const handleDelete = async (target, id) => {
     if (target === "materials") {
       try {
-        await deleteMaterial(id);
+        await deleteMaterial(class_id, id);
         fetchMaterials();
       } catch (error) {
         console.error("Failed to delete material", error);
       }
     } else if (target === "assignments") {
       try {
-        await deleteAssignment(id);
+        await deleteAssignment(class_id, id);
         fetchAssignmentsDetails();
       } catch (error) {
         console.error("Failed to delete assignment", error);
@@ -373,10 +373,10 @@ const ClassPage = () => {
     try {
       console.log(`Deleting ${deleteTargetType} with ID: ${deleteTargetId}`);
       if (deleteTargetType === "assignments") {
-        await deleteAssignment(deleteTargetId);
+        await deleteAssignment(class_id, deleteTargetId);
         fetchAssignmentsDetails();
       } else if (deleteTargetType === "materials") {
-        await deleteMaterial(deleteTargetId);
+        await deleteMaterial(class_id, deleteTargetId);
         fetchMaterials();
       } else if (deleteTargetType === "members") {
         console.log(`Class ID: ${class_id}, Member ID: ${deleteTargetId}`);